@@ -0,0 +1,58 @@
+package analyzer
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// SetAllowedDomains restricts analysis to the given domains and their
+// subdomains. Pages on other hosts are refused with ErrDomainNotAllowed and
+// links to them are not requested. An empty list removes the restriction.
+func (a *Analyzer) SetAllowedDomains(domains []string) {
+	var allowed map[string]bool
+	for _, domain := range domains {
+		domain = strings.TrimPrefix(strings.ToLower(strings.TrimSpace(domain)), "*.")
+		domain = strings.Trim(domain, ".")
+		if domain == "" {
+			continue
+		}
+		if allowed == nil {
+			allowed = make(map[string]bool)
+		}
+		allowed[domain] = true
+	}
+
+	a.configMutex.Lock()
+	defer a.configMutex.Unlock()
+	a.allowedDomains = allowed
+}
+
+// checkDomainAllowed returns ErrDomainNotAllowed when an allowlist is set
+// and rawURL's host is not on it
+func (a *Analyzer) checkDomainAllowed(rawURL string) error {
+	a.configMutex.RLock()
+	allowed := a.allowedDomains
+	a.configMutex.RUnlock()
+	if allowed == nil {
+		return nil
+	}
+
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrDomainNotAllowed, rawURL)
+	}
+	host := strings.TrimSuffix(strings.ToLower(parsed.Hostname()), ".")
+	for host != "" {
+		if allowed[host] {
+			return nil
+		}
+		// Walk up to the parent domain
+		dot := strings.IndexByte(host, '.')
+		if dot < 0 {
+			break
+		}
+		host = host[dot+1:]
+	}
+	return fmt.Errorf("%w: %s", ErrDomainNotAllowed, parsed.Hostname())
+}
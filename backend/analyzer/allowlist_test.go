@@ -0,0 +1,65 @@
+package analyzer
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestDomainAllowlist(t *testing.T) {
+	var offList atomic.Int32
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.Host, "localhost") {
+			offList.Add(1)
+		}
+		if r.URL.Path != "/" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html")
+		fmt.Fprintf(w, `<html><head><title>Allowed</title></head><body>
+			<a href="%s/gone">elsewhere</a></body></html>`, strings.Replace(server.URL, "127.0.0.1", "localhost", 1))
+	}))
+	t.Cleanup(server.Close)
+	offListURL := strings.Replace(server.URL, "127.0.0.1", "localhost", 1)
+
+	analyzer := newTestAnalyzer(t)
+	analyzer.SetAllowedDomains([]string{" 127.0.0.1 ", "*.example.com"})
+
+	analysis, err := analyzer.Analyze(server.URL + "/")
+	if err != nil {
+		t.Fatalf("Expected an allowed host to be analyzed, got %v", err)
+	}
+	if analysis.Links.BrokenLinks != 0 {
+		t.Errorf("Expected links to other hosts to be skipped, got %d broken", analysis.Links.BrokenLinks)
+	}
+
+	if _, err := analyzer.Analyze(offListURL + "/"); !errors.Is(err, ErrDomainNotAllowed) {
+		t.Errorf("Expected ErrDomainNotAllowed for an off-list host, got %v", err)
+	}
+	if n := offList.Load(); n != 0 {
+		t.Errorf("Expected no requests to the off-list host, got %d", n)
+	}
+
+	for rawURL, want := range map[string]bool{
+		"https://example.com/":         true,
+		"https://www.example.com/page": true,
+		"https://notexample.com/":      false,
+		"https://example.com.evil.io/": false,
+	} {
+		if got := analyzer.checkDomainAllowed(rawURL) == nil; got != want {
+			t.Errorf("checkDomainAllowed(%q) allowed = %v, want %v", rawURL, got, want)
+		}
+	}
+
+	// An empty list lifts the restriction
+	analyzer.SetAllowedDomains(nil)
+	if err := analyzer.checkDomainAllowed("https://anything.test/"); err != nil {
+		t.Errorf("Expected no restriction without an allowlist, got %v", err)
+	}
+}
@@ -0,0 +1,73 @@
+package analyzer
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// linkRelURL returns the absolute href of the first <link rel="..."> with the
+// given relation, or "" if the page has none
+func linkRelURL(doc *goquery.Document, pageURL, rel string) string {
+	href := ""
+	doc.Find("link[rel][href]").EachWithBreak(func(_ int, s *goquery.Selection) bool {
+		rels, _ := s.Attr("rel")
+		for _, r := range strings.Fields(strings.ToLower(rels)) {
+			if r == rel {
+				href, _ = s.Attr("href")
+				return false
+			}
+		}
+		return true
+	})
+	if strings.TrimSpace(href) == "" {
+		return ""
+	}
+	return resolveURL(pageURL, href)
+}
+
+// analyzeAMPPair fetches the AMP variant advertised by analysis and checks
+// that its canonical link points back to the main page. The AMP page goes
+// through the regular cache so repeated checks don't refetch it.
+func (a *Analyzer) analyzeAMPPair(ctx context.Context, analysis *SEOAnalysis, opts AnalyzeOptions) *AMPAnalysis {
+	expected := analysis.Meta.Canonical
+	if expected == "" {
+		expected = analysis.URL
+	}
+	result := &AMPAnalysis{
+		AMPURL:            analysis.Meta.AMPHTML,
+		ExpectedCanonical: expected,
+		Issues:            []string{},
+	}
+
+	if sameURL(result.AMPURL, analysis.URL) {
+		result.Issues = append(result.Issues,
+			"The amphtml link points to the page itself; it should reference the separate AMP version")
+		return result
+	}
+
+	amp, _, err := a.analyze(ctx, result.AMPURL, AnalyzeOptions{Mode: opts.Mode, Device: opts.Device, ClientKey: opts.ClientKey})
+	if err != nil {
+		result.Error = err.Error()
+		result.Issues = append(result.Issues, fmt.Sprintf(
+			"The AMP version at %s could not be analyzed: %v", result.AMPURL, err))
+		return result
+	}
+
+	result.AMPCanonical = amp.Meta.Canonical
+	switch {
+	case result.AMPCanonical == "":
+		result.Issues = append(result.Issues, fmt.Sprintf(
+			"Add a rel=\"canonical\" link to the AMP page pointing back to %s", expected))
+	case !sameURL(result.AMPCanonical, expected):
+		result.Issues = append(result.Issues, fmt.Sprintf(
+			"The AMP page's canonical (%s) does not match the main page's canonical (%s)",
+			result.AMPCanonical, expected))
+	default:
+		result.RoundTrip = true
+	}
+
+	return result
+}
@@ -5,13 +5,16 @@ import (
 	"context"
 	"crypto/md5"
 	"encoding/hex"
+	"errors"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
 	"sort"
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/PuerkitoBio/goquery"
@@ -52,10 +55,23 @@ var (
 	}
 )
 
+// longContentWords is the word count above which a page is expected to be
+// broken up by subheadings
+const longContentWords = 1000
+
+// defaultWordsPerSubheading is how many words one H2/H3 may cover on long
+// pages before the content counts as heading-poor
+const defaultWordsPerSubheading = 300
+
+// selfLinkThreshold is how many links back to the page itself trigger a
+// recommendation
+const selfLinkThreshold = 3
+
 // Cache entry with expiration
 type cacheEntry struct {
 	analysis  *SEOAnalysis
 	timestamp time.Time
+	expires   time.Time
 }
 
 // CacheStats provides statistics about the analyzer's cache
@@ -73,17 +89,52 @@ type CacheStats struct {
 // Analyzer performs SEO analysis on a given URL
 type Analyzer struct {
 	client            *http.Client
-	cache             map[string]cacheEntry
+	cache             AnalysisCache
 	cacheMutex        sync.RWMutex
 	cacheTTL          time.Duration
+	negativeCache     map[string]negativeCacheEntry
+	negativeCacheTTL  time.Duration
 	linkCache         map[string]linkCacheEntry
 	linkCacheMutex    sync.RWMutex
 	linkCacheTTL      time.Duration
 	maxCacheSize      int
 	maxLinkCacheSize  int
+	maxLinkFetchSize  int64
+	configMutex       sync.RWMutex
+	analyzeIframes    bool
+	verifyOGImage     bool
+	verifyIcons       bool
+	allowedDomains    map[string]bool // nil means every domain is allowed
+	privateTargets    bool            // analyze pages on loopback and private addresses
+	trustedNetworks   []*net.IPNet    // internal ranges allowed regardless
+	outputDecimals    int
+	maxDOMNodes       int
+	maxLinksPerPage   int
+	maxHeadBytes      int
+	maxPageBytes      int64
+	subheadingWords   int
+	largeImageBytes   int64
+	maxImageProbes    int
+	queue             *analysisQueue
+	events            *cacheEventBroker
+	breaker           *circuitBreaker
+	profiles          *profileStore
+	robots            *robotsCache
+	publisher         *resultPublisherQueue
+	jobs              *jobStore
+	callbackClient    *http.Client
+	callbackDelays    []time.Duration
+	webhookSecret     string
+	maintenance       atomic.Bool
+	inFlight          sync.WaitGroup
+	inFlightMutex     sync.Mutex
+	closing           bool
+	shutdownTimeout   time.Duration
+	batchWorkers      int
+	scoreWeights      map[string]float64
 	lastCleanup       time.Time
 	cleanupInterval   time.Duration
-	stats             *stats.Storage
+	stats             stats.StatsBackend
 }
 
 // Link cache entry
@@ -92,8 +143,24 @@ type linkCacheEntry struct {
 	timestamp  time.Time
 }
 
-// New creates a new Analyzer instance
+// New creates a new Analyzer instance that keeps statistics in
+// dataDir/stats.json
 func New(dataDir string) (*Analyzer, error) {
+	statsStorage, err := stats.NewStorage(dataDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize stats storage: %w", err)
+	}
+	analyzer, err := NewWithStatsBackend(dataDir, statsStorage)
+	if err != nil {
+		statsStorage.Shutdown()
+		return nil, err
+	}
+	return analyzer, nil
+}
+
+// NewWithStatsBackend creates a new Analyzer instance that records
+// statistics in the given backend and shuts it down with the analyzer
+func NewWithStatsBackend(dataDir string, statsStorage stats.StatsBackend) (*Analyzer, error) {
 	// Create an optimized HTTP client with:
 	// - Reasonable timeout
 	// - Connection pooling
@@ -106,28 +173,61 @@ func New(dataDir string) (*Analyzer, error) {
 		DisableCompression:  false,            // Enable compression
 	}
 	
-	// Initialize statistics storage
-	statsStorage, err := stats.NewStorage(dataDir)
+	// Load the per-API-key default profiles
+	profiles, err := newProfileStore(dataDir)
 	if err != nil {
-		return nil, fmt.Errorf("failed to initialize stats storage: %w", err)
+		return nil, err
 	}
-	
+
 	analyzer := &Analyzer{
 		client: &http.Client{
-			Timeout:   15 * time.Second,
-			Transport: transport,
+			Timeout:       15 * time.Second,
+			Transport:     transport,
+			CheckRedirect: checkRedirect,
 		},
-		cache:             make(map[string]cacheEntry),
 		cacheTTL:         30 * time.Minute, // Cache results for 30 minutes
+		negativeCache:    make(map[string]negativeCacheEntry),
+		negativeCacheTTL: 30 * time.Second, // Cache fetch failures briefly
 		linkCache:        make(map[string]linkCacheEntry),
 		linkCacheTTL:     10 * time.Minute, // Cache link status for 10 minutes
 		maxCacheSize:     1000,             // Maximum number of cached analyses
 		maxLinkCacheSize: 10000,            // Maximum number of cached link statuses
+		maxLinkFetchSize: 5 << 20,          // Never GET-fallback for links over 5MB
+		outputDecimals:   DefaultOutputDecimals,
+		maxHeadBytes:     DefaultMaxHeadBytes,
+		maxPageBytes:     DefaultMaxPageBytes,
+		subheadingWords:  defaultWordsPerSubheading,
+		largeImageBytes:  defaultLargeImageBytes,
+		maxImageProbes:   defaultMaxImageProbes,
+		queue:            newAnalysisQueue(),
+		events:           newCacheEventBroker(),
+		breaker:          newCircuitBreaker(),
+		profiles:         profiles,
+		robots:           newRobotsCache(),
+		jobs:             newJobStore(),
+		callbackClient:   newCallbackClient(),
+		callbackDelays:   defaultCallbackDelays,
 		cleanupInterval:  5 * time.Minute,  // Run cleanup every 5 minutes
+		shutdownTimeout:  30 * time.Second, // Wait this long for in-flight analyses
+		batchWorkers:     4,                // URLs of one batch analyzed at once
+		scoreWeights:     defaultScoreWeights,
 		lastCleanup:      time.Now(),
 		stats:            statsStorage,
 	}
 	
+	analyzer.cache = newMemoryCache(analyzer.maxCacheSize, func(url string) {
+		analyzer.events.publish(CacheEventEvicted, url)
+	})
+
+	// Refuse connections to internal addresses at dial time, for every
+	// request made through the shared transport
+	dialer := &net.Dialer{
+		Timeout:   30 * time.Second,
+		KeepAlive: 30 * time.Second,
+		Control:   analyzer.dialControl,
+	}
+	transport.DialContext = dialer.DialContext
+
 	// Start cleanup goroutine
 	go analyzer.periodicCleanup()
 	
@@ -148,39 +248,18 @@ func (a *Analyzer) periodicCleanup() {
 func (a *Analyzer) cleanup() {
 	now := time.Now()
 	
-	// Cleanup analysis cache
+	// Cleanup analysis cache; shared caches expire entries themselves
 	a.cacheMutex.Lock()
-	for key, entry := range a.cache {
-		if now.Sub(entry.timestamp) > a.cacheTTL {
-			delete(a.cache, key)
-		}
+	if cache, ok := a.cache.(*memoryCache); ok {
+		cache.prune()
 	}
 	
-	// If still over size limit, remove oldest entries
-	if len(a.cache) > a.maxCacheSize {
-		// Convert map to slice for sorting
-		entries := make([]struct {
-			key       string
-			timestamp time.Time
-		}, 0, len(a.cache))
-		
-		for key, entry := range a.cache {
-			entries = append(entries, struct {
-				key       string
-				timestamp time.Time
-			}{key, entry.timestamp})
-		}
-		
-		// Sort by timestamp
-		sort.Slice(entries, func(i, j int) bool {
-			return entries[i].timestamp.Before(entries[j].timestamp)
-		})
-		
-		// Remove oldest entries until under limit
-		for i := 0; i < len(entries)-a.maxCacheSize; i++ {
-			delete(a.cache, entries[i].key)
+	for key, entry := range a.negativeCache {
+		if now.Sub(entry.timestamp) > a.negativeCacheTTL {
+			delete(a.negativeCache, key)
 		}
 	}
+	
 	a.cacheMutex.Unlock()
 	
 	// Cleanup link cache
@@ -217,6 +296,9 @@ func (a *Analyzer) cleanup() {
 		}
 	}
 	a.linkCacheMutex.Unlock()
+
+	a.breaker.prune()
+	a.jobs.prune()
 	
 	a.lastCleanup = now
 }
@@ -226,17 +308,128 @@ func (a *Analyzer) SetMaxCacheSize(size int) {
 	a.cacheMutex.Lock()
 	defer a.cacheMutex.Unlock()
 	a.maxCacheSize = size
-	a.cleanup() // Run cleanup immediately if new size is smaller
+	if cache, ok := a.cache.(*memoryCache); ok {
+		cache.SetMaxSize(size) // Evicts immediately if the new size is smaller
+	}
 }
 
 // SetMaxLinkCacheSize sets the maximum number of entries in the link cache
 func (a *Analyzer) SetMaxLinkCacheSize(size int) {
 	a.linkCacheMutex.Lock()
-	defer a.linkCacheMutex.Unlock()
 	a.maxLinkCacheSize = size
+	a.linkCacheMutex.Unlock()
 	a.cleanup() // Run cleanup immediately if new size is smaller
 }
 
+// SetMaxLinkFetchSize sets the Content-Length above which a link is judged
+// by its HEAD status alone instead of retrying with GET
+func (a *Analyzer) SetMaxLinkFetchSize(size int64) {
+	a.linkCacheMutex.Lock()
+	defer a.linkCacheMutex.Unlock()
+	a.maxLinkFetchSize = size
+}
+
+// SetAnalyzeIframes enables fetching same-origin iframes (one level deep)
+// and reporting their content alongside the page analysis
+func (a *Analyzer) SetAnalyzeIframes(enabled bool) {
+	a.configMutex.Lock()
+	defer a.configMutex.Unlock()
+	a.analyzeIframes = enabled
+}
+
+// SetVerifyOGImage enables a HEAD check that the og:image actually loads
+func (a *Analyzer) SetVerifyOGImage(enabled bool) {
+	a.configMutex.Lock()
+	defer a.configMutex.Unlock()
+	a.verifyOGImage = enabled
+}
+
+// SetVerifyIcons enables checking that the declared favicon,
+// apple-touch-icon and manifest actually load
+func (a *Analyzer) SetVerifyIcons(enabled bool) {
+	a.configMutex.Lock()
+	defer a.configMutex.Unlock()
+	a.verifyIcons = enabled
+}
+
+// SetOutputPrecision sets how many decimal places float fields such as the
+// score are rounded to in JSON output. Calculations keep full precision.
+func (a *Analyzer) SetOutputPrecision(decimals int) {
+	if decimals < 0 {
+		return
+	}
+	a.configMutex.Lock()
+	defer a.configMutex.Unlock()
+	a.outputDecimals = decimals
+}
+
+// SetMaxDOMNodes caps how many DOM nodes an analysis processes. Larger pages
+// are cut off at the cap and the analysis is flagged as truncated. 0 means
+// unlimited.
+func (a *Analyzer) SetMaxDOMNodes(n int) {
+	if n < 0 {
+		return
+	}
+	a.configMutex.Lock()
+	defer a.configMutex.Unlock()
+	a.maxDOMNodes = n
+}
+
+// SetMaxLinksPerPage caps how many unique links are collected and checked
+// per page. 0 means unlimited.
+func (a *Analyzer) SetMaxLinksPerPage(n int) {
+	if n < 0 {
+		return
+	}
+	a.configMutex.Lock()
+	defer a.configMutex.Unlock()
+	a.maxLinksPerPage = n
+}
+
+// SetWordsPerSubheading sets how many words of long content (over 1000
+// words) one H2/H3 subheading may cover before the page is flagged as
+// needing more structure
+func (a *Analyzer) SetWordsPerSubheading(words int) {
+	if words <= 0 {
+		return
+	}
+	a.configMutex.Lock()
+	defer a.configMutex.Unlock()
+	a.subheadingWords = words
+}
+
+// SetMaxConcurrentAnalyses limits how many analyses fetch and parse pages at
+// the same time; further requests wait for a free slot. 0 means unlimited.
+func (a *Analyzer) SetMaxConcurrentAnalyses(n int) {
+	if n < 0 {
+		return
+	}
+	a.queue.setLimit(n)
+}
+
+// SetFairScheduling makes waiting analyses take turns per client (see
+// AnalyzeOptions.ClientKey) instead of being served first-come-first-served
+func (a *Analyzer) SetFairScheduling(enabled bool) {
+	a.queue.setFair(enabled)
+}
+
+// SetQueuePriorities makes waiting interactive analyses go ahead of batch
+// jobs, and batch jobs ahead of warm-up (see AnalyzeOptions.Priority)
+func (a *Analyzer) SetQueuePriorities(enabled bool) {
+	a.queue.setPriorities(enabled)
+}
+
+// SetMaintenanceMode enables or disables maintenance mode. While enabled,
+// every analysis fails with ErrMaintenance without making outbound requests.
+func (a *Analyzer) SetMaintenanceMode(enabled bool) {
+	a.maintenance.Store(enabled)
+}
+
+// MaintenanceMode reports whether maintenance mode is enabled
+func (a *Analyzer) MaintenanceMode() bool {
+	return a.maintenance.Load()
+}
+
 // SetCacheTTL sets the cache TTL
 func (a *Analyzer) SetCacheTTL(ttl time.Duration) {
 	a.cacheMutex.Lock()
@@ -244,11 +437,28 @@ func (a *Analyzer) SetCacheTTL(ttl time.Duration) {
 	a.cacheTTL = ttl
 }
 
+// SetNegativeCacheTTL sets how long fetch failures are cached; zero disables
+// negative caching
+func (a *Analyzer) SetNegativeCacheTTL(ttl time.Duration) {
+	a.cacheMutex.Lock()
+	defer a.cacheMutex.Unlock()
+	a.negativeCacheTTL = ttl
+}
+
 // ClearCache clears the analysis cache
 func (a *Analyzer) ClearCache() {
 	a.cacheMutex.Lock()
 	defer a.cacheMutex.Unlock()
-	a.cache = make(map[string]cacheEntry)
+	a.cache.Clear()
+	a.negativeCache = make(map[string]negativeCacheEntry)
+}
+
+// SetAnalysisCache replaces the analysis cache, e.g. with a RedisCache
+// shared by several replicas. Entries in the previous cache are dropped.
+func (a *Analyzer) SetAnalysisCache(cache AnalysisCache) {
+	a.cacheMutex.Lock()
+	defer a.cacheMutex.Unlock()
+	a.cache = cache
 }
 
 // generateCacheKey creates a unique key for the URL
@@ -261,8 +471,12 @@ func generateCacheKey(url string) string {
 func (a *Analyzer) GetCacheStats() CacheStats {
 	currentStats := a.stats.GetCurrentStats()
 	
+	// Only the in-memory cache can count its entries cheaply
 	a.cacheMutex.RLock()
-	analysisEntries := len(a.cache)
+	analysisEntries := 0
+	if cache, ok := a.cache.(*memoryCache); ok {
+		analysisEntries = cache.Len()
+	}
 	analysisTTL := a.cacheTTL
 	a.cacheMutex.RUnlock()
 	
@@ -289,83 +503,247 @@ func (a *Analyzer) IsCached(url string) bool {
 	a.cacheMutex.RLock()
 	defer a.cacheMutex.RUnlock()
 	
-	entry, found := a.cache[cacheKey]
-	if found && time.Since(entry.timestamp) < a.cacheTTL {
-		return true
-	}
-	return false
+	_, found := a.cache.Get(cacheKey)
+	return found
 }
 
 // Analyze performs a complete SEO analysis of the given URL
 func (a *Analyzer) Analyze(url string) (*SEOAnalysis, error) {
+	return a.AnalyzeWithOptions(url, AnalyzeOptions{})
+}
+
+// AnalyzeWithOptions performs a complete SEO analysis of the given URL using
+// the supplied per-request options
+func (a *Analyzer) AnalyzeWithOptions(url string, opts AnalyzeOptions) (*SEOAnalysis, error) {
+	return a.AnalyzeWithOptionsContext(context.Background(), url, opts)
+}
+
+// AnalyzeWithOptionsContext is AnalyzeWithOptions bounded by ctx as well as
+// the analysis timeout, so cancelling ctx aborts the fetch and link checks
+func (a *Analyzer) AnalyzeWithOptionsContext(ctx context.Context, url string, opts AnalyzeOptions) (*SEOAnalysis, error) {
+	analysis, _, err := a.AnalyzeWithCacheStatus(ctx, url, opts)
+	return analysis, err
+}
+
+// AnalyzeWithCacheStatus is AnalyzeWithOptionsContext that also reports
+// whether the result came from the cache, for the X-Cache header
+func (a *Analyzer) AnalyzeWithCacheStatus(ctx context.Context, url string, opts AnalyzeOptions) (*SEOAnalysis, CacheStatus, error) {
+	// Create a context with timeout for the entire analysis process
+	ctx, cancel := context.WithTimeout(ctx, opts.EffectiveTimeout())
+	defer cancel()
+
+	return a.analyze(ctx, url, opts)
+}
+
+// analyze serves url from the cache or runs a fresh analysis once a slot in
+// the analysis queue is free, reporting which it did. The status is empty
+// when the request was refused before the cache was consulted.
+func (a *Analyzer) analyze(ctx context.Context, url string, opts AnalyzeOptions) (*SEOAnalysis, CacheStatus, error) {
+	if a.MaintenanceMode() {
+		return nil, "", ErrMaintenance
+	}
+	if err := a.checkDomainAllowed(url); err != nil {
+		return nil, "", err
+	}
+	done, err := a.beginAnalysis()
+	if err != nil {
+		return nil, "", err
+	}
+	defer done()
+	if err := opts.Validate(); err != nil {
+		return nil, "", err
+	}
+
 	// Check if cleanup is needed
 	if time.Since(a.lastCleanup) > a.cleanupInterval {
 		go a.cleanup() // Run cleanup in background
 	}
 	
-	// Create a context with timeout for the entire analysis process
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-	defer cancel()
-	
-	// Check cache first
-	cacheKey := generateCacheKey(url)
+	// Check cache first, unless a refresh was requested
+	cacheKey := opts.cacheKey(url)
 	a.cacheMutex.RLock()
-	if entry, found := a.cache[cacheKey]; found {
-		if time.Since(entry.timestamp) < a.cacheTTL {
-			a.stats.IncrementStats(1, 0, 0, 0) // Increment analysis cache hits
+	if opts.BypassCache {
+		// Fall through to a fresh analysis, counted as a miss
+	} else if analysis, found := a.cache.Get(cacheKey); found {
+		a.stats.IncrementStats(1, 0, 0, 0) // Increment analysis cache hits
+		a.cacheMutex.RUnlock()
+		a.events.publish(CacheEventHit, url)
+		return analysis, CacheHit, nil
+	}
+	if entry, found := a.negativeCache[cacheKey]; found && !opts.BypassCache {
+		if time.Since(entry.timestamp) < a.negativeCacheTTL {
 			a.cacheMutex.RUnlock()
-			return entry.analysis, nil
+			cached := *entry.err
+			cached.FromCache = true
+			return nil, CacheNegative, &cached
 		}
 	}
 	a.cacheMutex.RUnlock()
+	status := CacheMiss
+	if opts.BypassCache {
+		status = CacheBypass
+	}
 	
 	// Not in cache or expired
 	a.stats.IncrementStats(0, 1, 0, 0) // Increment analysis cache misses
 	
-	// Perform analysis
-	analysis, err := a.AnalyzeWithContext(ctx, url)
+	// Wait for a free slot, then perform analysis
+	ctx, release, err := a.queue.acquire(ctx, opts.ClientKey, opts.Priority)
 	if err != nil {
-		return nil, err
+		return nil, status, fmt.Errorf("waiting for an analysis slot: %w", err)
+	}
+	defer release()
+
+	started := time.Now()
+	analysis, err := a.analyzeWithContext(ctx, url, opts)
+	if err != nil {
+		// Remember fetch failures briefly so repeated requests for a dead
+		// URL don't hammer the target; user-correctable errors aren't cached,
+		// nor are cancellations or timeouts under a budget shorter than the default
+		var fetchErr *FetchError
+		if errors.As(err, &fetchErr) && !fetchErr.Category.userCorrectable() && !errors.Is(err, context.Canceled) &&
+			!(fetchErr.Category == CategoryTimeout && opts.EffectiveTimeout() < DefaultAnalysisTimeout) {
+			a.cacheMutex.Lock()
+			if a.negativeCacheTTL > 0 {
+				a.negativeCache[cacheKey] = negativeCacheEntry{
+					err:       fetchErr,
+					timestamp: time.Now(),
+				}
+			}
+			a.cacheMutex.Unlock()
+		}
+		return nil, status, err
 	}
 	
 	// Store in cache
-	a.cacheMutex.Lock()
-	a.cache[cacheKey] = cacheEntry{
-		analysis:  analysis,
-		timestamp: time.Now(),
-	}
-	a.cacheMutex.Unlock()
+	a.cacheMutex.RLock()
+	a.cache.Set(cacheKey, analysis, a.cacheTTL)
+	a.cacheMutex.RUnlock()
+	a.events.publish(CacheEventAdded, url)
+	a.publishResult(url, opts, analysis, time.Since(started))
 	
-	return analysis, nil
+	return analysis, status, nil
 }
 
-// AnalyzeWithContext performs a complete SEO analysis of the given URL with context
-func (a *Analyzer) AnalyzeWithContext(ctx context.Context, url string) (*SEOAnalysis, error) {
+// AnalyzeWithContext performs a complete SEO analysis of the given URL with
+// context. A non-empty userAgent replaces the default User-Agent; with
+// checkLinks false, links are counted but not requested.
+func (a *Analyzer) AnalyzeWithContext(ctx context.Context, url, userAgent string, checkLinks bool) (*SEOAnalysis, error) {
+	done, err := a.beginAnalysis()
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+
+	opts := AnalyzeOptions{UserAgent: userAgent, SkipLinkCheck: !checkLinks}
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+	return a.analyzeWithContext(ctx, url, opts)
+}
+
+// beginAnalysis registers an in-flight analysis so Shutdown waits for it.
+// The returned func must be called when the analysis finishes.
+func (a *Analyzer) beginAnalysis() (func(), error) {
+	a.inFlightMutex.Lock()
+	defer a.inFlightMutex.Unlock()
+	if a.closing {
+		return nil, ErrShuttingDown
+	}
+	a.inFlight.Add(1)
+	return a.inFlight.Done, nil
+}
+
+// SetShutdownTimeout sets how long Shutdown waits for in-flight analyses
+func (a *Analyzer) SetShutdownTimeout(timeout time.Duration) {
+	if timeout <= 0 {
+		return
+	}
+	a.configMutex.Lock()
+	defer a.configMutex.Unlock()
+	a.shutdownTimeout = timeout
+}
+
+// analyzeWithContext fetches and analyzes url, bypassing the result cache
+func (a *Analyzer) analyzeWithContext(ctx context.Context, url string, opts AnalyzeOptions) (*SEOAnalysis, error) {
+	if a.MaintenanceMode() {
+		return nil, ErrMaintenance
+	}
+	if err := a.checkDomainAllowed(url); err != nil {
+		return nil, err
+	}
+	if err := a.checkTargetAllowed(ctx, url); err != nil {
+		return nil, err
+	}
 	startTime := time.Now()
 
 	// Get an analysis object from the pool
 	analysis := analysisPool.Get().(*SEOAnalysis)
 	analysis.URL = url
+	analysis.URLAnalysis = analyzeURLString(url)
 	analysis.Content.KeywordDensity = make(map[string]float64)
 	analysis.Headers.H1Text = analysis.Headers.H1Text[:0]
 
-	// Create a request with context
-	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	// Create a request with context, recording any redirects it follows
+	var hops []RedirectHop
+	req, err := http.NewRequestWithContext(withRedirectRecorder(ctx, &hops), "GET", url, nil)
 	if err != nil {
 		analysisPool.Put(analysis)
-		return nil, err
+		return nil, &FetchError{URL: url, Category: CategoryInvalidURL, Err: err}
 	}
 	
-	// Set user agent to avoid being blocked by some websites
-	req.Header.Set("User-Agent", "SEOAnalyzer/1.0")
+	// Fetch the page as the selected device would
+	device := opts.deviceProfile()
+	analysis.Device = device
+	device.setHeaders(req)
+
+	// Fail fast for hosts that keep failing
+	if err := a.breaker.allow(req.URL.Host); err != nil {
+		analysisPool.Put(analysis)
+		return nil, err
+	}
+
+	// Check robots.txt while the page downloads
+	robotsResult := make(chan RobotsAnalysis, 1)
+	go func() { robotsResult <- a.analyzeRobots(ctx, url) }()
 
 	// Fetch the page
 	resp, err := a.client.Do(req)
 	if err != nil {
+		a.recordHostResult(ctx, req.URL.Host, err, 0)
 		analysisPool.Put(analysis)
-		return nil, err
+		return nil, newFetchError(url, err)
 	}
 	defer resp.Body.Close()
+	a.recordHostResult(ctx, req.URL.Host, nil, resp.StatusCode)
+	analysis.Redirects = analyzeRedirects(hops, url, resp.Request.URL.String())
+	analysis.Response = responseInfo(resp)
+	analysis.CacheHeaders = cacheHeaders(resp.Header, time.Now())
+
+	// Error statuses abort the analysis unless best effort was requested, in
+	// which case the returned body (e.g. a styled 404 page) is still analyzed
+	analysis.Warnings = nil
+	if resp.StatusCode >= 400 {
+		statusErr := fmt.Errorf("page returned HTTP status %d", resp.StatusCode)
+		if opts.Mode != FetchModeBestEffort {
+			analysisPool.Put(analysis)
+			return nil, &FetchError{URL: url, Category: CategoryHTTPStatus, Err: statusErr, StatusCode: resp.StatusCode}
+		}
+		analysis.Warnings = append(analysis.Warnings,
+			"Warning: "+statusErr.Error()+"; results describe the error page, not the intended content")
+	}
+
+	// Parsing a PDF or image as HTML only produces a meaningless score, and
+	// its "links" aren't worth checking
+	if contentType := resp.Header.Get("Content-Type"); !isHTMLContentType(contentType) {
+		notHTML := fmt.Errorf("%w (Content-Type %s)", ErrNotHTML, contentType)
+		if opts.Mode != FetchModeBestEffort {
+			analysisPool.Put(analysis)
+			return nil, &FetchError{URL: url, Category: CategoryNotHTML, Err: notHTML, StatusCode: resp.StatusCode}
+		}
+		analysis.Warnings = append(analysis.Warnings,
+			"Warning: "+notHTML.Error()+"; results are unlikely to be meaningful")
+	}
 
 	// Get actual page size from response headers if available
 	pageSize := 0
@@ -375,15 +753,36 @@ func (a *Analyzer) AnalyzeWithContext(ctx context.Context, url string) (*SEOAnal
 		}
 	}
 
+	// Oversized pages fail before download unless best effort was requested
+	a.configMutex.RLock()
+	maxPageBytes := a.maxPageBytes
+	a.configMutex.RUnlock()
+	tooLarge := &FetchError{URL: url, Category: CategoryTooLarge, StatusCode: resp.StatusCode,
+		Err: fmt.Errorf("%w of %d bytes", ErrPageTooLarge, maxPageBytes)}
+	if resp.ContentLength > maxPageBytes && opts.Mode != FetchModeBestEffort {
+		analysisPool.Put(analysis)
+		return nil, tooLarge
+	}
+
 	// Get a buffer from the pool
 	buf := bufferPool.Get().(*bytes.Buffer)
 	buf.Reset()
 	defer bufferPool.Put(buf)
 
-	// Read the response body into the buffer
-	if _, err := io.Copy(buf, resp.Body); err != nil {
+	// Read the response body into the buffer, up to the size limit
+	truncated, err := readBody(buf, resp.Body, maxPageBytes)
+	if err != nil {
 		analysisPool.Put(analysis)
-		return nil, err
+		return nil, newFetchError(url, err)
+	}
+	if truncated {
+		if opts.Mode != FetchModeBestEffort {
+			analysisPool.Put(analysis)
+			return nil, tooLarge
+		}
+		analysis.Response.Truncated = true
+		analysis.Warnings = append(analysis.Warnings, fmt.Sprintf(
+			"Warning: page is larger than %d bytes; only the start of it was analyzed", maxPageBytes))
 	}
 
 	// If we couldn't get the page size from headers, calculate it from the buffer
@@ -391,15 +790,40 @@ func (a *Analyzer) AnalyzeWithContext(ctx context.Context, url string) (*SEOAnal
 		pageSize = buf.Len()
 	}
 
-	// Parse the HTML from the buffer
-	doc, err := goquery.NewDocumentFromReader(bytes.NewReader(buf.Bytes()))
+	// Calculate load time before any processing
+	loadTime := time.Since(startTime)
+
+	return a.analyzeDocument(ctx, analysis, buf.Bytes(), url, pageSize, loadTime, opts, robotsResult)
+}
+
+// analyzeDocument runs every check on an already downloaded page. The
+// robots.txt verdict is read from robotsResult once the page checks are done;
+// a nil channel skips it.
+func (a *Analyzer) analyzeDocument(ctx context.Context, analysis *SEOAnalysis, body []byte, url string,
+	pageSize int, loadTime time.Duration, opts AnalyzeOptions, robotsResult <-chan RobotsAnalysis) (*SEOAnalysis, error) {
+	// Link checks identify as the same agent as the page fetch
+	ctx = withUserAgent(ctx, opts.UserAgent)
+
+	// Parse the HTML
+	doc, err := goquery.NewDocumentFromReader(bytes.NewReader(body))
 	if err != nil {
 		analysisPool.Put(analysis)
 		return nil, err
 	}
 
-	// Calculate load time before any processing
-	loadTime := time.Since(startTime)
+	// Measure nesting on the full tree, before any truncation
+	maxDomDepth := domDepth(doc.Nodes[0])
+
+	// Bound the work done on pathological pages
+	a.configMutex.RLock()
+	maxDOMNodes := a.maxDOMNodes
+	a.configMutex.RUnlock()
+	analysis.Truncated = false
+	if maxDOMNodes > 0 && truncateDOM(doc.Nodes[0], maxDOMNodes) {
+		analysis.Truncated = true
+		analysis.Warnings = append(analysis.Warnings, fmt.Sprintf(
+			"Warning: analysis truncated due to page complexity; only the first %d DOM nodes were analyzed", maxDOMNodes))
+	}
 
 	// Check mobile optimization
 	mobileOptimized := false
@@ -414,13 +838,81 @@ func (a *Analyzer) AnalyzeWithContext(ctx context.Context, url string) (*SEOAnal
 	analysis.Title = a.analyzeTitleTag(doc)
 	analysis.Meta = a.analyzeMetaTags(doc)
 	analysis.Headers = a.analyzeHeaders(doc)
-	analysis.Content = a.analyzeContent(doc)
-	analysis.Performance = a.analyzePerformance(pageSize, loadTime, mobileOptimized)
-	analysis.Links = a.analyzeLinksWithContext(ctx, doc, url)
+	analysis.Content = a.analyzeContent(doc, len(body))
+	a.analyzeImages(ctx, doc, url, &analysis.Content)
+	a.configMutex.RLock()
+	wordsPerSubheading := a.subheadingWords
+	a.configMutex.RUnlock()
+	analysis.Content.HeadingPoor = isHeadingPoor(analysis.Content.WordCount, analysis.Headers, wordsPerSubheading)
+	analysis.Performance = a.analyzePerformance(doc, pageSize, loadTime, mobileOptimized)
+	analysis.Performance.MaxDomDepth = maxDomDepth
+	// Uncompressed pages get an estimate of what gzip would save; pages
+	// analyzed from supplied HTML have no response to judge
+	analysis.Performance.Compressed = analysis.Response.Compressed
+	if analysis.Response.StatusCode != 0 && !analysis.Response.Compressed {
+		analysis.Performance.EstimatedCompressionSavings = gzipSavings(body)
+	}
+	analysis.Links = a.analyzeLinksWithContext(ctx, doc, url, !opts.SkipLinkCheck, opts.linkCheckTimeout())
+	analysis.Links.TargetKeyword = opts.TargetKeyword
+	analysis.Links.KeywordAnchors, analysis.Links.GenericAnchors = analyzeAnchors(doc, url, opts.TargetKeyword)
+	linkAttrs := analyzeLinkAttributes(doc, url)
+	analysis.Links.NofollowLinks = linkAttrs.nofollow
+	analysis.Links.SponsoredLinks = linkAttrs.sponsored
+	analysis.Links.UGCLinks = linkAttrs.ugc
+	analysis.Links.AnchorTextIssues = linkAttrs.anchorIssues
+	if analysis.Links.Truncated {
+		analysis.Truncated = true
+		analysis.Warnings = append(analysis.Warnings,
+			"Warning: link analysis truncated due to page complexity; not all links were checked")
+	}
+	analysis.Meta.Canonical = linkRelURL(doc, url, "canonical")
+	analysis.Meta.AMPHTML = linkRelURL(doc, url, "amphtml")
+	analysis.HeadOrder = a.analyzeHeadOrder(body, doc)
+	analysis.Document = documentInfo(body, doc)
+	analysis.Breadcrumbs = a.analyzeBreadcrumbs(doc)
+	analysis.StructuredData = analyzeStructuredData(doc)
+	analysis.Hreflang = a.analyzeHreflang(ctx, doc, url)
+	analysis.ResourceHints = a.analyzeResourceHints(doc, url)
+	// The scheme that matters is the one the page ended up on
+	analysis.MixedContent = findMixedContent(doc, analysis.Redirects.FinalURL)
+	analysis.Robots = RobotsAnalysis{}
+	if robotsResult != nil {
+		analysis.Robots = <-robotsResult
+	}
+	a.configMutex.RLock()
+	analyzeIframes := a.analyzeIframes
+	verifyOGImage := a.verifyOGImage
+	verifyIcons := a.verifyIcons
+	outputDecimals := a.outputDecimals
+	a.configMutex.RUnlock()
+
+	analysis.outputDecimals = &outputDecimals
+
+	analysis.Social = a.analyzeSocialTags(ctx, doc, url, verifyOGImage)
+	checkOGURLCanonical(&analysis.Social, analysis.Meta.Canonical)
+	analysis.Icons = a.analyzeIcons(ctx, doc, url, verifyIcons)
+	analysis.PWA = a.analyzePWA(ctx, doc, url)
+	analysis.SERPPreview = buildSERPPreview(analysis)
+
+	analysis.Iframes = nil
+	if analyzeIframes {
+		analysis.Iframes = a.analyzeFrames(ctx, doc, url, analysis.Device)
+	}
+
+	analysis.DeprecatedMarkup = nil
+	if opts.Profile == ProfileThorough {
+		analysis.DeprecatedMarkup = a.analyzeDeprecatedMarkup(doc)
+	}
+
+	analysis.AMP = nil
+	if opts.CheckAMP && analysis.Meta.AMPHTML != "" {
+		analysis.AMP = a.analyzeAMPPair(ctx, analysis, opts)
+	}
 
 	// Calculate overall score and recommendations
-	analysis.Score = a.calculateOverallScore(analysis)
-	analysis.Recommendations = a.generateRecommendations(analysis)
+	analysis.Score = a.calculateOverallScore(analysis, opts.Weights)
+	analysis.Grade = scoreGrade(analysis.Score)
+	analysis.Recommendations = append(append([]string{}, analysis.Warnings...), a.generateRecommendations(analysis)...)
 
 	return analysis, nil
 }
@@ -461,8 +953,8 @@ func (a *Analyzer) analyzeMetaTags(doc *goquery.Document) MetaAnalysis {
 	meta.Keywords, _ = doc.Find("meta[name='keywords']").Attr("content")
 	meta.HasKeywords = len(meta.Keywords) > 0
 
-	// Robots
-	meta.Robots, _ = doc.Find("meta[name='robots']").Attr("content")
+	// Robots, merged across all robots tags (most restrictive wins)
+	meta.RobotsTags, meta.Robots, meta.RobotsConflict = robotsMeta(doc)
 
 	// Viewport
 	meta.Viewport, _ = doc.Find("meta[name='viewport']").Attr("content")
@@ -495,11 +987,17 @@ func (a *Analyzer) analyzeHeaders(doc *goquery.Document) HeaderAnalysis {
 	headers.H1Count = doc.Find("h1").Length()
 	headers.H2Count = doc.Find("h2").Length()
 	headers.H3Count = doc.Find("h3").Length()
+	headers.H4Count = doc.Find("h4").Length()
+	headers.H5Count = doc.Find("h5").Length()
+	headers.H6Count = doc.Find("h6").Length()
 
 	doc.Find("h1").Each(func(_ int, s *goquery.Selection) {
 		headers.H1Text = append(headers.H1Text, strings.TrimSpace(s.Text()))
 	})
 
+	headers.HierarchyIssues = checkHeadingHierarchy(doc)
+	headers.HasProperHierarchy = len(headers.HierarchyIssues) == 0
+
 	// Score calculation
 	score := 0
 	if headers.H1Count == 1 {
@@ -520,7 +1018,13 @@ func (a *Analyzer) analyzeHeaders(doc *goquery.Document) HeaderAnalysis {
 	return headers
 }
 
-func (a *Analyzer) analyzeContent(doc *goquery.Document) ContentAnalysis {
+// minTextToHTMLRatio is the share of visible text in the HTML, in percent,
+// below which a page is flagged as thin or bloated
+const minTextToHTMLRatio = 10
+
+// analyzeContent measures the page's text and images; htmlSize is the size
+// of the decoded HTML in bytes
+func (a *Analyzer) analyzeContent(doc *goquery.Document, htmlSize int) ContentAnalysis {
 	content := ContentAnalysis{
 		KeywordDensity: make(map[string]float64),
 	}
@@ -529,6 +1033,21 @@ func (a *Analyzer) analyzeContent(doc *goquery.Document) ContentAnalysis {
 	text := doc.Find("body").Text()
 	words := strings.Fields(text)
 	content.WordCount = len(words)
+	content.LikelyInfiniteScroll = detectInfiniteScroll(doc, content.WordCount)
+	content.ReadabilityScore, content.ReadabilityGrade, _ = readability(doc)
+
+	// Keyword density over the visible text
+	visible := visibleText(doc.Find("body"))
+	content.TopKeywords = keywordDensity(visible)
+	for _, keyword := range content.TopKeywords {
+		content.KeywordDensity[keyword.Keyword] = keyword.Density
+	}
+
+	// Visible text, with runs of whitespace counted once, as a share of the
+	// HTML
+	if htmlSize > 0 {
+		content.TextToHTMLRatio = float64(len(strings.Join(strings.Fields(visible), " "))) / float64(htmlSize) * 100
+	}
 
 	// Image analysis
 	images := doc.Find("img")
@@ -539,6 +1058,12 @@ func (a *Analyzer) analyzeContent(doc *goquery.Document) ContentAnalysis {
 		if _, exists := s.Attr("alt"); exists {
 			content.ImagesWithAlt++
 		}
+		if strings.EqualFold(strings.TrimSpace(s.AttrOr("loading", "")), "lazy") {
+			content.LazyLoadedImages++
+		}
+		if strings.TrimSpace(s.AttrOr("srcset", "")) != "" {
+			content.ResponsiveImages++
+		}
 	})
 
 	// Calculate score
@@ -559,7 +1084,7 @@ func (a *Analyzer) analyzeContent(doc *goquery.Document) ContentAnalysis {
 	return content
 }
 
-func (a *Analyzer) analyzePerformance(pageSize int, loadTime time.Duration, mobileOptimized bool) Performance {
+func (a *Analyzer) analyzePerformance(doc *goquery.Document, pageSize int, loadTime time.Duration, mobileOptimized bool) Performance {
 	perf := Performance{
 		PageSize:        pageSize,
 		LoadTime:        int(loadTime.Milliseconds()),
@@ -567,6 +1092,8 @@ func (a *Analyzer) analyzePerformance(pageSize int, loadTime time.Duration, mobi
 		PageSizeSeverity: "good",
 		LoadTimeSeverity: "good",
 	}
+	perf.RenderBlockingCSS, perf.RenderBlockingJS = countRenderBlocking(doc)
+	countResources(doc, &perf)
 
 	// Score calculation - Total 100 points possible
 	score := 100
@@ -612,13 +1139,32 @@ func (a *Analyzer) analyzePerformance(pageSize int, loadTime time.Duration, mobi
 		score -= 20
 	}
 
+	// Render-blocking resources (up to 20 points): a couple of stylesheets
+	// are normal, synchronous scripts in the head never are
+	blockingPenalty := 5 * perf.RenderBlockingJS
+	if perf.RenderBlockingCSS > 2 {
+		blockingPenalty += 2 * (perf.RenderBlockingCSS - 2)
+	}
+	if blockingPenalty > 20 {
+		blockingPenalty = 20
+	}
+	score -= blockingPenalty
+	if score < 0 {
+		score = 0
+	}
+
 	perf.Score = score
 	return perf
 }
 
-// analyzeLinksWithContext analyzes links with context awareness
-func (a *Analyzer) analyzeLinksWithContext(ctx context.Context, doc *goquery.Document, baseURL string) LinkAnalysis {
-	links := LinkAnalysis{}
+// analyzeLinksWithContext analyzes links with context awareness. With check
+// set, each link is requested to find broken ones until linkTimeout.
+func (a *Analyzer) analyzeLinksWithContext(ctx context.Context, doc *goquery.Document, baseURL string, check bool, linkTimeout time.Duration) LinkAnalysis {
+	links := LinkAnalysis{LinksChecked: check}
+
+	a.configMutex.RLock()
+	maxLinks := a.maxLinksPerPage
+	a.configMutex.RUnlock()
 	
 	// Get a map from the pool
 	checkedLinks := mapPool.Get().(map[string]bool)
@@ -633,43 +1179,64 @@ func (a *Analyzer) analyzeLinksWithContext(ctx context.Context, doc *goquery.Doc
 	defer urlSlicePool.Put(linkURLs)
 
 	// First, collect all unique links
-	doc.Find("a[href]").Each(func(_ int, s *goquery.Selection) {
+	doc.Find("a[href]").EachWithBreak(func(_ int, s *goquery.Selection) bool {
+		if maxLinks > 0 && len(linkURLs) >= maxLinks {
+			links.Truncated = true
+			return false
+		}
 		href, exists := s.Attr("href")
 		if !exists || href == "" || href == "#" {
-			return
+			return true
+		}
+
+		// Resolve the link against the page like a browser would;
+		// fragment-only links are in-page navigation and aren't checked
+		rawHref := strings.TrimSpace(href)
+		if strings.HasPrefix(rawHref, "#") {
+			return true
+		}
+		href, ok := resolveLink(baseURL, rawHref)
+		if !ok {
+			return true
 		}
 
-		// Clean and normalize the URL
-		href = strings.TrimSpace(href)
-		if strings.HasPrefix(href, "//") {
-			href = "https:" + href
-		} else if strings.HasPrefix(href, "/") {
-			href = baseURL + href
+		// Count every link back to the page itself
+		if sameURL(href, baseURL) {
+			links.SelfLinks++
 		}
 
 		// Skip if we've already seen this link
 		if checkedLinks[href] {
-			return
+			return true
 		}
 		checkedLinks[href] = true
 		
-		// Categorize the link
-		if strings.HasPrefix(href, baseURL) || strings.HasPrefix(href, "/") {
+		// Categorize the link by host, so other schemes and www variants of
+		// the site still count as internal
+		if isInternalHref(baseURL, href) {
 			links.InternalLinks++
 			linkURLs = append(linkURLs, href)
-		} else if strings.HasPrefix(href, "http") {
+			links.InternalHrefs = append(links.InternalHrefs, href)
+		} else {
 			links.ExternalLinks++
 			linkURLs = append(linkURLs, href)
 		}
+		return true
 	})
 	
+	// Links are counted; without checks, none are requested and BrokenLinks
+	// stays 0
+	if !check {
+		linkURLs = linkURLs[:0]
+	}
+
 	// Now check all links concurrently with controlled parallelism
 	var wg sync.WaitGroup
 	semaphore := make(chan struct{}, 10) // Limit to 10 concurrent requests
 	var mu sync.Mutex // Mutex to protect the brokenLinks counter
 	
 	// Create a context that will be canceled when the function returns
-	linkCtx, cancel := context.WithTimeout(ctx, 15*time.Second)
+	linkCtx, cancel := context.WithTimeout(ctx, linkTimeout)
 	defer cancel()
 	
 	for _, url := range linkURLs {
@@ -749,8 +1316,15 @@ func (a *Analyzer) analyzeLinksWithContext(ctx context.Context, doc *goquery.Doc
 
 // isLinkAccessibleWithContext checks if a link is accessible with context support
 func (a *Analyzer) isLinkAccessibleWithContext(ctx context.Context, url string) bool {
-	// Check cache first
-	cacheKey := generateCacheKey(url)
+	// Hosts outside the allowlist are never requested, so they can't be
+	// judged broken either
+	if a.checkDomainAllowed(url) != nil {
+		return true
+	}
+
+	// Check cache first; a custom User-Agent may see a different status
+	userAgent := linkUserAgent(ctx)
+	cacheKey := linkCacheKey(url, userAgent)
 	a.linkCacheMutex.RLock()
 	if entry, found := a.linkCache[cacheKey]; found {
 		if time.Since(entry.timestamp) < a.linkCacheTTL {
@@ -771,7 +1345,13 @@ func (a *Analyzer) isLinkAccessibleWithContext(ctx context.Context, url string)
 	}
 	
 	// Set user agent to avoid being blocked by some websites
-	req.Header.Set("User-Agent", "SEOAnalyzer/1.0")
+	req.Header.Set("User-Agent", userAgent)
+
+	// Don't wait on hosts that keep failing; the result isn't cached so the
+	// link is rechecked once the host recovers
+	if a.breaker.allow(req.URL.Host) != nil {
+		return false
+	}
 	
 	// Create a client with a shorter timeout for link checking
 	client := &http.Client{
@@ -780,15 +1360,64 @@ func (a *Analyzer) isLinkAccessibleWithContext(ctx context.Context, url string)
 	}
 	
 	resp, err := client.Do(req)
+	if errors.Is(err, ErrPrivateAddress) {
+		// Internal hosts are never contacted, so they can't be judged broken
+		return true
+	}
+	a.recordHostResult(ctx, req.URL.Host, err, 0)
 	if err != nil {
 		return a.cacheAndReturnLinkStatus(cacheKey, false)
 	}
-	defer resp.Body.Close()
+	resp.Body.Close()
 	
 	accessible := resp.StatusCode >= 200 && resp.StatusCode < 400
+	
+	// Some servers reject HEAD; retry with GET unless the HEAD response shows
+	// a binary or large resource, which is judged on its status alone
+	if !accessible && !a.isLargeOrBinary(resp) {
+		getReq, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+		if err != nil {
+			return a.cacheAndReturnLinkStatus(cacheKey, false)
+		}
+		getReq.Header.Set("User-Agent", userAgent)
+		
+		getResp, err := client.Do(getReq)
+		if err != nil {
+			return a.cacheAndReturnLinkStatus(cacheKey, false)
+		}
+		// Only the status matters, so the body is closed unread
+		getResp.Body.Close()
+		accessible = getResp.StatusCode >= 200 && getResp.StatusCode < 400
+	}
+	
 	return a.cacheAndReturnLinkStatus(cacheKey, accessible)
 }
 
+// linkCacheKey returns the link cache key of url fetched with userAgent
+func linkCacheKey(url, userAgent string) string {
+	if userAgent != defaultLinkUserAgent {
+		return generateCacheKey(url + "|ua:" + userAgent)
+	}
+	return generateCacheKey(url)
+}
+
+// isLargeOrBinary reports whether a HEAD response describes a non-HTML
+// resource or one larger than maxLinkFetchSize
+func (a *Analyzer) isLargeOrBinary(resp *http.Response) bool {
+	a.linkCacheMutex.RLock()
+	maxSize := a.maxLinkFetchSize
+	a.linkCacheMutex.RUnlock()
+	if resp.ContentLength > maxSize {
+		return true
+	}
+	contentType := strings.ToLower(resp.Header.Get("Content-Type"))
+	if contentType == "" {
+		return false
+	}
+	return !strings.Contains(contentType, "text/html") &&
+		!strings.Contains(contentType, "application/xhtml+xml")
+}
+
 // cacheAndReturnLinkStatus caches the link status and returns it
 func (a *Analyzer) cacheAndReturnLinkStatus(cacheKey string, accessible bool) bool {
 	a.linkCacheMutex.Lock()
@@ -802,9 +1431,31 @@ func (a *Analyzer) cacheAndReturnLinkStatus(cacheKey string, accessible bool) bo
 	return accessible
 }
 
+// lazyLoadingMinImages is how many images a long page needs before lazy
+// loading is recommended
+const lazyLoadingMinImages = 3
+
+// needsLazyLoading reports whether a long page with several images
+// lazy-loads fewer than half of them
+func needsLazyLoading(content ContentAnalysis) bool {
+	if content.WordCount <= longContentWords || content.TotalImages < lazyLoadingMinImages {
+		return false
+	}
+	return content.LazyLoadedImages*2 < content.TotalImages
+}
+
+// isHeadingPoor reports whether long content has fewer H2/H3 subheadings
+// than one per wordsPerSubheading words
+func isHeadingPoor(wordCount int, headers HeaderAnalysis, wordsPerSubheading int) bool {
+	if wordCount <= longContentWords || wordsPerSubheading <= 0 {
+		return false
+	}
+	return headers.H2Count+headers.H3Count < wordCount/wordsPerSubheading
+}
+
 // For backward compatibility
 func (a *Analyzer) analyzeLinks(doc *goquery.Document, baseURL string) LinkAnalysis {
-	return a.analyzeLinksWithContext(context.Background(), doc, baseURL)
+	return a.analyzeLinksWithContext(context.Background(), doc, baseURL, true, AnalyzeOptions{}.linkCheckTimeout())
 }
 
 // For backward compatibility
@@ -812,15 +1463,11 @@ func (a *Analyzer) isLinkAccessible(url string) bool {
 	return a.isLinkAccessibleWithContext(context.Background(), url)
 }
 
-func (a *Analyzer) calculateOverallScore(analysis *SEOAnalysis) float64 {
-	weights := map[string]float64{
-		"title":       0.2,
-		"meta":        0.2,
-		"headers":     0.15,
-		"content":     0.2,
-		"performance": 0.15,
-		"links":       0.1,
-	}
+func (a *Analyzer) calculateOverallScore(analysis *SEOAnalysis, overrides map[string]float64) float64 {
+	a.configMutex.RLock()
+	base := a.scoreWeights
+	a.configMutex.RUnlock()
+	weights := mergeWeights(base, overrides)
 
 	score := 0.0
 	score += float64(analysis.Title.Score) * weights["title"]
@@ -830,7 +1477,41 @@ func (a *Analyzer) calculateOverallScore(analysis *SEOAnalysis) float64 {
 	score += float64(analysis.Performance.Score) * weights["performance"]
 	score += float64(analysis.Links.Score) * weights["links"]
 
-	return score
+	if len(overrides) == 0 {
+		return score
+	}
+
+	// Keep the score on a 0-100 scale when overrides don't sum to 1
+	total := 0.0
+	for _, weight := range weights {
+		total += weight
+	}
+	if total == 0 {
+		return 0
+	}
+	return score / total
+}
+
+// gradeThresholds are the lowest scores earning each letter grade; anything
+// below the last one is an F
+var gradeThresholds = []struct {
+	min   float64
+	grade string
+}{
+	{90, "A"},
+	{80, "B"},
+	{70, "C"},
+	{60, "D"},
+}
+
+// scoreGrade converts an overall score to a letter grade
+func scoreGrade(score float64) string {
+	for _, threshold := range gradeThresholds {
+		if score >= threshold.min {
+			return threshold.grade
+		}
+	}
+	return "F"
 }
 
 func (a *Analyzer) generateRecommendations(analysis *SEOAnalysis) []string {
@@ -843,6 +1524,10 @@ func (a *Analyzer) generateRecommendations(analysis *SEOAnalysis) []string {
 		recommendations = append(recommendations, "Title tag is too short (should be 30-60 characters)")
 	} else if analysis.Title.Length > 60 {
 		recommendations = append(recommendations, "Title tag is too long (should be 30-60 characters)")
+	} else if analysis.SERPPreview.TitleTruncated {
+		recommendations = append(recommendations, fmt.Sprintf(
+			"Title is about %dpx wide and will be truncated in search results (about %dpx fit) - use narrower or fewer words",
+			analysis.SERPPreview.TitlePixelWidth, serpTitleMaxPixels))
 	}
 
 	// Meta recommendations
@@ -853,6 +1538,226 @@ func (a *Analyzer) generateRecommendations(analysis *SEOAnalysis) []string {
 	} else if analysis.Meta.DescriptionLen > 160 {
 		recommendations = append(recommendations, "Meta description is too long (should be 120-160 characters)")
 	}
+	if analysis.Meta.RobotsConflict {
+		recommendations = append(recommendations, 
+			"Conflicting meta robots tags found (" + strings.Join(analysis.Meta.RobotsTags, " / ") + ") - search engines apply the most restrictive: " + analysis.Meta.Robots)
+	}
+
+	// Response recommendations
+	if savings := analysis.Performance.EstimatedCompressionSavings; savings >= minCompressionSavings {
+		recommendations = append(recommendations, 
+			"Enable gzip or Brotli compression - the page was served uncompressed and gzip alone would save about " + strconv.Itoa(savings/1024) + " KB")
+	}
+	if cache := analysis.CacheHeaders; analysis.Response.StatusCode != 0 && !cache.HasCacheControl && !cache.HasExpires {
+		if !cache.HasETag && !cache.HasLastModified {
+			recommendations = append(recommendations, 
+				"Add caching headers - the page has no Cache-Control, Expires, ETag or Last-Modified header, so repeat visits download it again")
+		} else {
+			recommendations = append(recommendations, 
+				"Add a Cache-Control header (e.g. max-age) so browsers can reuse the page without revalidating it")
+		}
+	}
+
+	// Mixed content recommendations
+	if n := len(analysis.MixedContent); n > 0 {
+		recommendations = append(recommendations, 
+			"Critical: " + strconv.Itoa(n) + " resource(s) load over insecure HTTP on this HTTPS page, e.g. " + analysis.MixedContent[0] + " - browsers block them and drop the padlock; serve them over HTTPS")
+	}
+
+	// Redirect recommendations
+	if analysis.Redirects.DowngradesToHTTP {
+		recommendations = append(recommendations, 
+			"Critical: an HTTPS URL redirects to insecure HTTP - keep every redirect on HTTPS")
+	}
+	if analysis.Redirects.Redirects > 2 {
+		recommendations = append(recommendations, fmt.Sprintf(
+			"URL goes through %d redirects before resolving - link to %s directly or redirect in a single hop",
+			analysis.Redirects.Redirects, analysis.Redirects.FinalURL))
+	}
+
+	// URL recommendations
+	if analysis.URLAnalysis.Length > maxURLLength {
+		recommendations = append(recommendations, "URL is very long (over 100 characters) - use a shorter, descriptive slug")
+	}
+	if analysis.URLAnalysis.Depth > maxURLPathDepth {
+		recommendations = append(recommendations, "URL is nested " + strconv.Itoa(analysis.URLAnalysis.Depth) + " levels deep - flatten the path structure")
+	}
+	if analysis.URLAnalysis.HasUnderscores {
+		recommendations = append(recommendations, "URL uses underscores; prefer hyphens to separate words")
+	}
+	if analysis.URLAnalysis.HasUppercase {
+		recommendations = append(recommendations, "URL contains uppercase letters; use lowercase to avoid duplicate URLs")
+	}
+	if analysis.URLAnalysis.QueryParams > 0 {
+		recommendations = append(recommendations, "Avoid query parameters in indexable URLs; use path-based URLs instead")
+	}
+	if len(analysis.URLAnalysis.StopWords) > 0 {
+		recommendations = append(recommendations, "Remove stop words (" + strings.Join(analysis.URLAnalysis.StopWords, ", ") + ") from the URL slug")
+	}
+
+	// Document recommendations
+	if !analysis.Document.HasCharset {
+		recommendations = append(recommendations, 
+			"Declare the character encoding with <meta charset=\"utf-8\"> at the top of the head")
+	} else if analysis.Document.Charset != "utf-8" && analysis.Document.Charset != "utf8" {
+		recommendations = append(recommendations, 
+			"Use UTF-8 instead of the declared " + analysis.Document.Charset + " character encoding")
+	}
+	if !analysis.Document.HasDoctype {
+		recommendations = append(recommendations, 
+			"Add <!DOCTYPE html> as the first line - without a doctype browsers render the page in quirks mode")
+	} else if !analysis.Document.HTML5Doctype {
+		recommendations = append(recommendations, 
+			"Replace the legacy doctype with <!DOCTYPE html>")
+	}
+
+	// Head order recommendations
+	if analysis.HeadOrder.CharsetPosition >= 0 && !analysis.HeadOrder.CharsetEarly {
+		recommendations = append(recommendations, 
+			"Move the charset declaration (e.g., <meta charset=\"utf-8\">) into the first 1024 bytes of the document")
+	}
+	if analysis.HeadOrder.HasTitle && !analysis.HeadOrder.TitleBeforeScripts {
+		recommendations = append(recommendations, 
+			"Place the title tag before blocking scripts in the head")
+	}
+	if analysis.HeadOrder.HasViewport && !analysis.HeadOrder.ViewportBeforeBlocking {
+		recommendations = append(recommendations, 
+			"Declare the viewport meta tag before stylesheets and blocking scripts")
+	}
+
+	// Social recommendations
+	if analysis.Social.OGImage == "" {
+		recommendations = append(recommendations, 
+			"Add an og:image meta tag: without it, links to this page are shared without a preview image")
+	}
+	if analysis.Social.TwitterCard == "" {
+		recommendations = append(recommendations, 
+			"Add a twitter:card meta tag (e.g. summary_large_image) so X/Twitter renders a rich preview")
+	}
+	if missing := analysis.Social.MissingOpenGraph; len(missing) > 0 && analysis.Social.OGImage != "" {
+		recommendations = append(recommendations, 
+			"Complete the Open Graph tags for better share previews; missing: " + strings.Join(missing, ", "))
+	}
+	if analysis.Social.OGURLCanonicalMismatch {
+		recommendations = append(recommendations, 
+			"Align og:url (" + analysis.Social.OGURL + ") with the canonical URL (" + analysis.Meta.Canonical + ") so social shares and search results point to the same page")
+	}
+	if analysis.Social.OGImage != "" {
+		if !analysis.Social.HasImageDimensions {
+			recommendations = append(recommendations, 
+				"Add og:image:width and og:image:height so social platforms can render large preview cards")
+		} else if analysis.Social.OGImageWidth < minOGImageDimension || analysis.Social.OGImageHeight < minOGImageDimension {
+			recommendations = append(recommendations, 
+				"og:image is too small for social previews (at least 200x200 pixels; 1200x630 recommended)")
+		}
+		if analysis.Social.OGImageReachable != nil && !*analysis.Social.OGImageReachable {
+			recommendations = append(recommendations, 
+				"Fix the og:image URL: the image could not be loaded, which breaks social share previews")
+		}
+	}
+
+	// Robots.txt recommendations
+	if analysis.Robots.IsBlocked {
+		recommendations = append(recommendations, 
+			"Critical: robots.txt blocks crawlers from this URL (" + analysis.Robots.MatchedRule + "); search engines can't crawl this page")
+	}
+
+	// Resource hint recommendations
+	if missing := analysis.ResourceHints.MissingHints; len(missing) > 0 {
+		if len(missing) > maxPreconnectSuggestions {
+			missing = missing[:maxPreconnectSuggestions]
+		}
+		recommendations = append(recommendations, 
+			"Add <link rel=\"preconnect\"> hints for the most used third-party origins to save connection setup time: " + strings.Join(missing, ", "))
+	}
+
+	// Deprecated markup recommendations
+	if analysis.DeprecatedMarkup != nil && analysis.DeprecatedMarkup.Total > 0 {
+		recommendations = append(recommendations, 
+			"Replace deprecated HTML (" + strconv.Itoa(analysis.DeprecatedMarkup.Total) + " use(s) of elements like <center>/<font> or attributes like bgcolor/align) with CSS")
+	}
+
+	// Hreflang recommendations
+	if hreflang := analysis.Hreflang; len(hreflang.Links) > 0 {
+		if len(hreflang.InvalidCodes) > 0 {
+			recommendations = append(recommendations, 
+				"Fix malformed hreflang value(s) " + strings.Join(hreflang.InvalidCodes, ", ") + " - use an ISO 639-1 language code with an optional ISO 3166-1 region, e.g. en-GB")
+		}
+		if len(hreflang.Duplicates) > 0 {
+			recommendations = append(recommendations, 
+				"Remove duplicate hreflang entries for " + strings.Join(hreflang.Duplicates, ", ") + " - each language should be declared once")
+		}
+		if !hreflang.HasSelfReference && !hreflang.HasXDefault {
+			recommendations = append(recommendations, 
+				"Add a self-referencing hreflang link (or an x-default) to the page's hreflang set")
+		}
+		for _, broken := range hreflang.Broken {
+			recommendations = append(recommendations, 
+				"Fix hreflang alternate that doesn't load: " + broken)
+		}
+	}
+
+	// Breadcrumb recommendations
+	if analysis.Breadcrumbs.HasVisible && !analysis.Breadcrumbs.HasStructured {
+		recommendations = append(recommendations, 
+			"Add BreadcrumbList structured data (JSON-LD) matching the visible breadcrumb trail")
+	} else if analysis.Breadcrumbs.HasStructured && !analysis.Breadcrumbs.HasVisible {
+		recommendations = append(recommendations, 
+			"BreadcrumbList structured data has no visible breadcrumb trail on the page - show the breadcrumbs to users too")
+	}
+
+	// Icon recommendations
+	if analysis.Icons.Favicon == "" {
+		recommendations = append(recommendations, 
+			"Add a favicon (<link rel=\"icon\">) - search results and browser tabs show it next to the page title")
+	}
+	if !analysis.Icons.HasAppleTouchIcon {
+		recommendations = append(recommendations, 
+			"Add an apple-touch-icon for when the page is saved to an iOS home screen")
+	}
+	for _, broken := range analysis.Icons.Broken {
+		recommendations = append(recommendations, 
+			"Fix broken icon or manifest URL: " + broken)
+	}
+
+	// PWA recommendations; pages without a manifest aren't meant to be
+	// installable, so they aren't asked to add one
+	if analysis.PWA.HasManifest {
+		if !analysis.PWA.ManifestValid {
+			recommendations = append(recommendations, 
+				"Fix the web app manifest at " + analysis.PWA.ManifestURL + ": " + analysis.PWA.ManifestError)
+		} else {
+			var missing []string
+			if !analysis.PWA.HasName {
+				missing = append(missing, "name")
+			}
+			if !analysis.PWA.HasIcons {
+				missing = append(missing, "icons")
+			}
+			if !analysis.PWA.HasStartURL {
+				missing = append(missing, "start_url")
+			}
+			if len(missing) > 0 {
+				recommendations = append(recommendations, 
+					"Add " + strings.Join(missing, ", ") + " to the web app manifest - browsers need them to offer installing the site")
+			}
+		}
+		if !analysis.PWA.ServiceWorker {
+			recommendations = append(recommendations, 
+				"No service worker registration found in the page's inline scripts - register one so the site can be installed and work offline")
+		}
+	}
+
+	// Structured data recommendations
+	if analysis.StructuredData.InvalidBlocks > 0 {
+		recommendations = append(recommendations, 
+			"Fix " + strconv.Itoa(analysis.StructuredData.InvalidBlocks) + " JSON-LD block(s) that fail to parse - search engines ignore invalid structured data")
+	}
+
+	// AMP recommendations
+	if analysis.AMP != nil {
+		recommendations = append(recommendations, analysis.AMP.Issues...)
+	}
 
 	// Headers recommendations
 	if analysis.Headers.H1Count == 0 {
@@ -860,16 +1765,45 @@ func (a *Analyzer) generateRecommendations(analysis *SEOAnalysis) []string {
 	} else if analysis.Headers.H1Count > 1 {
 		recommendations = append(recommendations, "Multiple H1 headings found - consider using only one")
 	}
+	recommendations = append(recommendations, headingRecommendations(analysis.Headers.HierarchyIssues)...)
 
 	// Content recommendations
 	if analysis.Content.WordCount < 300 {
 		recommendations = append(recommendations, "Add more content (aim for at least 300 words)")
 	}
+	if analysis.Content.HeadingPoor {
+		recommendations = append(recommendations, 
+			"Break up long content with more H2/H3 subheadings (" + strconv.Itoa(analysis.Content.WordCount) + " words but only " + strconv.Itoa(analysis.Headers.H2Count+analysis.Headers.H3Count) + " subheading(s))")
+	}
+	if analysis.Content.ReadabilityGrade != "" && analysis.Content.ReadabilityScore < hardToReadScore {
+		recommendations = append(recommendations, 
+			"Simplify the content: it is " + analysis.Content.ReadabilityGrade + " to read (Flesch reading ease " + strconv.FormatFloat(analysis.Content.ReadabilityScore, 'f', 0, 64) + ") - use shorter sentences and plainer words")
+	}
+	if analysis.Performance.PageSize > 0 && analysis.Content.TextToHTMLRatio < minTextToHTMLRatio {
+		recommendations = append(recommendations, 
+			"Low text-to-HTML ratio (" + strconv.FormatFloat(analysis.Content.TextToHTMLRatio, 'f', 1, 64) + "%) - add more visible content or trim bloated markup and inline code")
+	}
+	if analysis.Content.LikelyInfiniteScroll {
+		recommendations = append(recommendations, 
+			"Content appears to load via infinite scroll or client-side pagination - provide crawlable paginated links (e.g., ?page=2) or render listings server-side")
+	}
 	if analysis.Content.TotalImages > 0 && analysis.Content.ImagesWithAlt < analysis.Content.TotalImages {
 		recommendations = append(recommendations, "Add alt text to all images")
 	}
+	if analysis.Content.BrokenImages > 0 {
+		recommendations = append(recommendations, 
+			"Fix " + strconv.Itoa(analysis.Content.BrokenImages) + " broken image(s) that fail to load")
+	}
+	if needsLazyLoading(analysis.Content) {
+		recommendations = append(recommendations, 
+			"Add loading=\"lazy\" to images below the fold (only " + strconv.Itoa(analysis.Content.LazyLoadedImages) + " of " + strconv.Itoa(analysis.Content.TotalImages) + " images are lazy-loaded on this long page)")
+	}
 
 	// Performance recommendations
+	if len(analysis.Content.LargeImages) > 0 {
+		recommendations = append(recommendations, 
+			"Compress or resize " + strconv.Itoa(len(analysis.Content.LargeImages)) + " oversized image(s), e.g. " + analysis.Content.LargeImages[0])
+	}
 	pageSizeKB := float64(analysis.Performance.PageSize) / 1024.0
 	if pageSizeKB > 5120 {
 		recommendations = append(recommendations, 
@@ -903,12 +1837,59 @@ func (a *Analyzer) generateRecommendations(analysis *SEOAnalysis) []string {
 		recommendations = append(recommendations, 
 			"Add a proper viewport meta tag for mobile optimization (e.g., <meta name=\"viewport\" content=\"width=device-width, initial-scale=1\">)")
 	}
+	if analysis.Performance.MaxDomDepth > maxRecommendedDOMDepth {
+		recommendations = append(recommendations, fmt.Sprintf(
+			"Reduce DOM nesting: elements are nested %d levels deep (aim for at most %d); deep trees slow down style calculation and rendering",
+			analysis.Performance.MaxDomDepth, maxRecommendedDOMDepth))
+	}
+	if analysis.Performance.RenderBlockingJS > 0 {
+		recommendations = append(recommendations, 
+			"Add async or defer to " + strconv.Itoa(analysis.Performance.RenderBlockingJS) + " render-blocking script(s) in the head, or move them to the end of the body")
+	}
+	if analysis.Performance.RenderBlockingCSS > 2 {
+		recommendations = append(recommendations, 
+			"Reduce " + strconv.Itoa(analysis.Performance.RenderBlockingCSS) + " render-blocking stylesheets: inline the critical CSS and combine or load the rest asynchronously")
+	}
+	if analysis.Performance.ExternalStylesheets > maxExternalStylesheets {
+		recommendations = append(recommendations, 
+			"Combine stylesheets: the page loads " + strconv.Itoa(analysis.Performance.ExternalStylesheets) + " separate CSS files (aim for at most " + strconv.Itoa(maxExternalStylesheets) + ")")
+	}
+	if analysis.Performance.ExternalScripts > maxExternalScripts {
+		recommendations = append(recommendations, 
+			"Bundle scripts: the page loads " + strconv.Itoa(analysis.Performance.ExternalScripts) + " separate JavaScript files (aim for at most " + strconv.Itoa(maxExternalScripts) + ")")
+	}
+	if analysis.Performance.InlineStyleBlocks > maxInlineStyleBlocks || analysis.Performance.InlineStyleAttributes > maxInlineStyleAttributes {
+		recommendations = append(recommendations, 
+			"Move inline styles into a cacheable stylesheet (" + strconv.Itoa(analysis.Performance.InlineStyleBlocks) + " <style> block(s), " + strconv.Itoa(analysis.Performance.InlineStyleAttributes) + " style attribute(s))")
+	}
+	if analysis.Performance.InlineScripts > maxInlineScripts {
+		recommendations = append(recommendations, 
+			"Move " + strconv.Itoa(analysis.Performance.InlineScripts) + " inline scripts into external files so browsers can cache them")
+	}
 
 	// Links recommendations
 	if analysis.Links.BrokenLinks > 0 {
 		recommendations = append(recommendations, 
 			"Fix broken links: Found " + strconv.Itoa(analysis.Links.BrokenLinks) + " broken link(s)")
 	}
+	if analysis.Links.TargetKeyword != "" && analysis.Links.InternalLinks > 0 {
+		if analysis.Links.KeywordAnchors == 0 {
+			recommendations = append(recommendations, 
+				"No internal link uses the target keyword \"" + analysis.Links.TargetKeyword + "\" in its anchor text; use it in anchors pointing to the pages it describes")
+		}
+		if analysis.Links.GenericAnchors > 0 {
+			recommendations = append(recommendations, 
+				"Replace " + strconv.Itoa(analysis.Links.GenericAnchors) + " generic anchor(s) like \"click here\" or \"read more\" with descriptive, keyword-rich text")
+		}
+	}
+	if len(analysis.Links.AnchorTextIssues) > 0 {
+		recommendations = append(recommendations, 
+			"Give " + strconv.Itoa(len(analysis.Links.AnchorTextIssues)) + " link(s) descriptive anchor text - empty or generic anchors like \"click here\" tell neither screen reader users nor search engines where they lead")
+	}
+	if analysis.Links.SelfLinks >= selfLinkThreshold {
+		recommendations = append(recommendations, 
+			"The page links to itself " + strconv.Itoa(analysis.Links.SelfLinks) + " times - check templates for redundant self-referencing links")
+	}
 	if analysis.Links.InternalLinks < 3 {
 		recommendations = append(recommendations, 
 			"Add more internal links to improve site navigation and SEO (aim for at least 3-5)")
@@ -925,16 +1906,42 @@ func (a *Analyzer) generateRecommendations(analysis *SEOAnalysis) []string {
 }
 
 // GetStats returns the statistics storage instance
-func (a *Analyzer) GetStats() *stats.Storage {
+func (a *Analyzer) GetStats() stats.StatsBackend {
 	return a.stats
 }
 
-// Shutdown performs cleanup and ensures all statistics are saved
+// Shutdown waits for in-flight analyses (up to the shutdown timeout), then
+// ensures all statistics are saved and clears the caches
 func (a *Analyzer) Shutdown() error {
 	if a == nil {
 		return nil
 	}
 
+	// Refuse new analyses, then let the running ones finish
+	a.inFlightMutex.Lock()
+	if a.closing {
+		a.inFlightMutex.Unlock()
+		return nil
+	}
+	a.closing = true
+	a.inFlightMutex.Unlock()
+
+	a.configMutex.RLock()
+	timeout := a.shutdownTimeout
+	a.configMutex.RUnlock()
+
+	drained := make(chan struct{})
+	go func() {
+		a.inFlight.Wait()
+		close(drained)
+	}()
+	var drainErr error
+	select {
+	case <-drained:
+	case <-time.After(timeout):
+		drainErr = fmt.Errorf("timed out after %v waiting for in-flight analyses", timeout)
+	}
+
 	// Stop the cleanup goroutine by closing a channel
 	if a.stats != nil {
 		if err := a.stats.Shutdown(); err != nil {
@@ -942,9 +1949,21 @@ func (a *Analyzer) Shutdown() error {
 		}
 	}
 
+	// Analyses still running after the timeout write to the caches, so
+	// they are only cleared once everything has drained
+	if drainErr != nil {
+		return drainErr
+	}
+
 	// Clear caches
+	// A shared cache outlives this replica, so it is only disconnected
 	a.cacheMutex.Lock()
-	a.cache = nil
+	if closer, ok := a.cache.(io.Closer); ok {
+		closer.Close()
+	} else {
+		a.cache.Clear()
+	}
+	a.negativeCache = nil
 	a.cacheMutex.Unlock()
 
 	a.linkCacheMutex.Lock()
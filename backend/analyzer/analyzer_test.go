@@ -2,9 +2,17 @@ package analyzer
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"runtime"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 )
@@ -31,30 +39,81 @@ func getMemStats() MemStats {
 
 func printMemStats(t *testing.T, before, after MemStats) {
 	t.Logf("Memory Statistics:")
-	t.Logf("Heap Allocation: %d bytes -> %d bytes (Delta: %d bytes)", 
+	t.Logf("Heap Allocation: %d bytes -> %d bytes (Delta: %d bytes)",
 		before.HeapAlloc, after.HeapAlloc, after.HeapAlloc-before.HeapAlloc)
-	t.Logf("Total Allocation: %d bytes -> %d bytes (Delta: %d bytes)", 
+	t.Logf("Total Allocation: %d bytes -> %d bytes (Delta: %d bytes)",
 		before.TotalAlloc, after.TotalAlloc, after.TotalAlloc-before.TotalAlloc)
-	t.Logf("System Memory: %d bytes -> %d bytes (Delta: %d bytes)", 
+	t.Logf("System Memory: %d bytes -> %d bytes (Delta: %d bytes)",
 		before.Sys, after.Sys, after.Sys-before.Sys)
-	t.Logf("Number of GC runs: %d -> %d (Delta: %d)", 
+	t.Logf("Number of GC runs: %d -> %d (Delta: %d)",
 		before.NumGC, after.NumGC, after.NumGC-before.NumGC)
-	t.Logf("Total GC Pause: %d ns -> %d ns (Delta: %d ns)", 
+	t.Logf("Total GC Pause: %d ns -> %d ns (Delta: %d ns)",
 		before.PauseTotalNs, after.PauseTotalNs, after.PauseTotalNs-before.PauseTotalNs)
 }
 
+// newTestAnalyzer creates an analyzer backed by a temporary stats directory
+func newTestAnalyzer(t *testing.T) *Analyzer {
+	t.Helper()
+	a, err := New(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to create analyzer: %v", err)
+	}
+	t.Cleanup(func() { a.Shutdown() })
+	// Test sites run on loopback
+	a.SetAllowPrivateTargets(true)
+	return a
+}
+
+// testPage is a small but complete HTML document used by the test site
+const testPage = `<!DOCTYPE html>
+<html>
+<head>
+<title>Test page for the SEO analyzer test suite</title>
+<meta name="description" content="A small test page">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+</head>
+<body>
+<h1>Test page</h1>
+<p>Some content with <a href="/about">about</a>, <a href="/blog">blog</a> and <a href="/missing">missing</a> links.</p>
+<img src="/logo.png" alt="Logo">
+</body>
+</html>`
+
+// newTestSite starts a local HTTP server serving testPage for every path
+// except /missing, which returns 404
+func newTestSite(t *testing.T) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		fmt.Fprint(w, testPage)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
 func TestMemoryEfficiency(t *testing.T) {
 	// Test URLs with different characteristics
+	server := newTestSite(t)
 	urls := []string{
-		"https://www.example.com",
-		"https://www.google.com",
-		"https://www.github.com",
-		"https://www.wikipedia.org",
-		"https://www.reddit.com",
+		server.URL + "/",
+		server.URL + "/about",
+		server.URL + "/blog",
+		server.URL + "/contact",
+		server.URL + "/products",
 	}
 
 	// Create analyzer instance
-	analyzer := New()
+	analyzer := newTestAnalyzer(t)
+
+	// Warm up the HTTP transport and object pools with an unrelated page so
+	// one-time initialization isn't mistaken for a leak
+	if _, err := analyzer.Analyze(server.URL + "/warmup"); err != nil {
+		t.Fatalf("Warm-up analysis failed: %v", err)
+	}
 
 	// Force garbage collection before starting
 	runtime.GC()
@@ -100,7 +159,7 @@ func TestMemoryEfficiency(t *testing.T) {
 
 				// Check cache first
 				isCached := analyzer.IsCached(url)
-				
+
 				// Analyze URL
 				_, err := analyzer.Analyze(url)
 				if err != nil {
@@ -151,37 +210,37 @@ func TestMemoryEfficiency(t *testing.T) {
 	// Check if cache size is within expected bounds
 	expectedCacheSize := len(urls)
 	if stats.AnalysisEntries > expectedCacheSize*2 {
-		t.Errorf("Cache size larger than expected: got %d entries, expected maximum %d", 
+		t.Errorf("Cache size larger than expected: got %d entries, expected maximum %d",
 			stats.AnalysisEntries, expectedCacheSize*2)
 	}
 }
 
 func TestCachePurging(t *testing.T) {
-	analyzer := New()
-	
+	analyzer := newTestAnalyzer(t)
+
 	// Set a very short TTL for testing
 	analyzer.SetCacheTTL(1 * time.Second)
-	
+
 	// Analyze a URL
-	url := "https://www.example.com"
+	url := newTestSite(t).URL
 	_, err := analyzer.Analyze(url)
 	if err != nil {
 		t.Fatalf("Failed to analyze URL: %v", err)
 	}
-	
+
 	// Verify it's cached
 	if !analyzer.IsCached(url) {
 		t.Error("URL should be cached immediately after analysis")
 	}
-	
+
 	// Wait for TTL to expire
 	time.Sleep(2 * time.Second)
-	
+
 	// Verify it's no longer cached
 	if analyzer.IsCached(url) {
 		t.Error("URL should not be cached after TTL expiration")
 	}
-	
+
 	// Get cache stats
 	stats := analyzer.GetCacheStats()
 	t.Logf("Cache Statistics after TTL expiration:")
@@ -190,22 +249,92 @@ func TestCachePurging(t *testing.T) {
 	t.Logf("Analysis Cache Misses: %d", stats.AnalysisCacheMisses)
 }
 
+func TestBypassCache(t *testing.T) {
+	var fetches atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/" {
+			fetches.Add(1)
+		}
+		w.Header().Set("Content-Type", "text/html")
+		fmt.Fprintf(w, "<html><head><title>Version %d</title></head><body></body></html>", fetches.Load())
+	}))
+	defer server.Close()
+
+	analyzer := newTestAnalyzer(t)
+	if _, err := analyzer.Analyze(server.URL); err != nil {
+		t.Fatalf("Failed to analyze: %v", err)
+	}
+	misses := analyzer.GetCacheStats().AnalysisCacheMisses
+
+	refreshed, err := analyzer.AnalyzeWithOptions(server.URL, AnalyzeOptions{BypassCache: true})
+	if err != nil {
+		t.Fatalf("Failed to refresh: %v", err)
+	}
+	if fetches.Load() != 2 || refreshed.Title.Title != "Version 2" {
+		t.Errorf("Expected a fresh fetch, got %d fetches and title %q", fetches.Load(), refreshed.Title.Title)
+	}
+	if got := analyzer.GetCacheStats().AnalysisCacheMisses; got != misses+1 {
+		t.Errorf("Expected the refresh to count as a miss (%d), got %d", misses+1, got)
+	}
+
+	// The fresh result replaced the cached one
+	cached, err := analyzer.Analyze(server.URL)
+	if err != nil {
+		t.Fatalf("Failed to analyze: %v", err)
+	}
+	if fetches.Load() != 2 || cached.Title.Title != "Version 2" {
+		t.Errorf("Expected the refreshed result from the cache, got %d fetches and title %q", fetches.Load(), cached.Title.Title)
+	}
+}
+
+func TestCustomUserAgent(t *testing.T) {
+	var mu sync.Mutex
+	agents := make(map[string]string)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		agents[r.URL.Path] = r.UserAgent()
+		mu.Unlock()
+		w.Header().Set("Content-Type", "text/html")
+		fmt.Fprint(w, `<html><head><title>UA</title></head><body><a href="/linked">Linked</a></body></html>`)
+	}))
+	defer server.Close()
+
+	const googlebot = "Mozilla/5.0 (compatible; Googlebot/2.1; +http://www.google.com/bot.html)"
+	analyzer := newTestAnalyzer(t)
+	analysis, err := analyzer.AnalyzeWithContext(context.Background(), server.URL, googlebot, true)
+	if err != nil {
+		t.Fatalf("Failed to analyze: %v", err)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if agents["/"] != googlebot || agents["/linked"] != googlebot {
+		t.Errorf("Expected the page and link checks to use the custom agent, got %v", agents)
+	}
+	if analysis.Device.UserAgent != googlebot {
+		t.Errorf("Expected the custom agent in the device profile, got %q", analysis.Device.UserAgent)
+	}
+
+	if _, err := analyzer.AnalyzeWithOptions(server.URL, AnalyzeOptions{UserAgent: "bad\r\nX-Injected: 1"}); err == nil {
+		t.Error("Expected a user agent with line breaks to be rejected")
+	}
+}
+
 func TestConcurrentCacheAccess(t *testing.T) {
-	analyzer := New()
-	url := "https://www.example.com"
-	
+	analyzer := newTestAnalyzer(t)
+	url := newTestSite(t).URL
+
 	// Number of concurrent goroutines
 	concurrency := 100
-	
+
 	var wg sync.WaitGroup
 	errChan := make(chan error, concurrency)
-	
+
 	// Launch concurrent goroutines
 	for i := 0; i < concurrency; i++ {
 		wg.Add(1)
-		go func() {
+		go func(i int) {
 			defer wg.Done()
-			
+
 			// Randomly either read from or write to cache
 			if i%2 == 0 {
 				_, err := analyzer.Analyze(url)
@@ -215,22 +344,1836 @@ func TestConcurrentCacheAccess(t *testing.T) {
 			} else {
 				analyzer.IsCached(url)
 			}
-		}()
+		}(i)
 	}
-	
+
 	// Wait for all goroutines to complete
 	wg.Wait()
 	close(errChan)
-	
+
 	// Check for errors
 	for err := range errChan {
 		t.Errorf("Concurrent access error: %v", err)
 	}
-	
+
 	// Get final cache stats
 	stats := analyzer.GetCacheStats()
 	t.Logf("Cache Statistics after concurrent access:")
 	t.Logf("Analysis Cache Entries: %d", stats.AnalysisEntries)
 	t.Logf("Analysis Cache Hits: %d", stats.AnalysisCacheHits)
 	t.Logf("Analysis Cache Misses: %d", stats.AnalysisCacheMisses)
-} 
\ No newline at end of file
+}
+func TestNegativeCache(t *testing.T) {
+	// Server that drops every connection so the fetch fails at transport level
+	var fetches int
+	var mu sync.Mutex
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		fetches++
+		mu.Unlock()
+		conn, _, err := w.(http.Hijacker).Hijack()
+		if err == nil {
+			conn.Close()
+		}
+	}))
+	defer server.Close()
+
+	analyzer := newTestAnalyzer(t)
+
+	_, err := analyzer.Analyze(server.URL)
+	var fetchErr *FetchError
+	if !errors.As(err, &fetchErr) {
+		t.Fatalf("Expected a FetchError, got %v", err)
+	}
+	if fetchErr.FromCache {
+		t.Error("First failure should not come from the negative cache")
+	}
+
+	mu.Lock()
+	fetchesAfterFirst := fetches
+	mu.Unlock()
+
+	_, err = analyzer.Analyze(server.URL)
+	if !errors.As(err, &fetchErr) || !fetchErr.FromCache {
+		t.Fatalf("Expected second failure to be served from the negative cache, got %v", err)
+	}
+	if fetchErr.Category != CategoryConnection {
+		t.Errorf("Expected category %q, got %q", CategoryConnection, fetchErr.Category)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if fetches != fetchesAfterFirst {
+		t.Errorf("Expected no new fetch, got %d additional", fetches-fetchesAfterFirst)
+	}
+}
+
+func TestNegativeCacheSkipsUserCorrectableErrors(t *testing.T) {
+	analyzer := newTestAnalyzer(t)
+
+	for i := 0; i < 2; i++ {
+		_, err := analyzer.Analyze("http://bad host/")
+		var fetchErr *FetchError
+		if !errors.As(err, &fetchErr) {
+			t.Fatalf("Expected a FetchError, got %v", err)
+		}
+		if fetchErr.Category != CategoryInvalidURL {
+			t.Errorf("Expected category %q, got %q", CategoryInvalidURL, fetchErr.Category)
+		}
+		if fetchErr.FromCache {
+			t.Error("User-correctable errors must not be served from the negative cache")
+		}
+	}
+}
+
+func TestMaxLinkFetchSizeConcurrentUpdate(t *testing.T) {
+	analyzer := newTestAnalyzer(t)
+	resp := &http.Response{ContentLength: 1 << 20, Header: http.Header{"Content-Type": {"text/html"}}}
+
+	// Run with -race: the link pass reads the limit while it is reconfigured
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 100; i++ {
+			analyzer.SetMaxLinkFetchSize(int64(i) << 20)
+		}
+	}()
+	for i := 0; i < 100; i++ {
+		analyzer.isLargeOrBinary(resp)
+	}
+	wg.Wait()
+
+	analyzer.SetMaxLinkFetchSize(512 << 10)
+	if !analyzer.isLargeOrBinary(resp) {
+		t.Error("Expected a 1MB page to be over a 512KB limit")
+	}
+}
+
+func TestLinkCheckSkipsLargeBinaryDownloads(t *testing.T) {
+	var mu sync.Mutex
+	gets := make(map[string]int)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			mu.Lock()
+			gets[r.URL.Path]++
+			mu.Unlock()
+		}
+		switch r.URL.Path {
+		case "/video.mp4":
+			// HEAD is rejected but reveals a huge binary
+			w.Header().Set("Content-Type", "video/mp4")
+			w.Header().Set("Content-Length", "2147483648")
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		case "/page":
+			if r.Method == http.MethodHead {
+				w.Header().Set("Content-Type", "text/html")
+				w.WriteHeader(http.StatusMethodNotAllowed)
+				return
+			}
+			w.Header().Set("Content-Type", "text/html")
+			fmt.Fprint(w, testPage)
+		}
+	}))
+	defer server.Close()
+
+	analyzer := newTestAnalyzer(t)
+
+	if analyzer.isLinkAccessibleWithContext(context.Background(), server.URL+"/video.mp4") {
+		t.Error("Large binary rejecting HEAD should be judged by its status")
+	}
+	if !analyzer.isLinkAccessibleWithContext(context.Background(), server.URL+"/page") {
+		t.Error("HTML page rejecting HEAD should be checked with a GET fallback")
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if gets["/video.mp4"] != 0 {
+		t.Errorf("Large binary should never be fetched with GET, got %d requests", gets["/video.mp4"])
+	}
+	if gets["/page"] != 1 {
+		t.Errorf("Expected one GET fallback for the HTML page, got %d", gets["/page"])
+	}
+}
+
+func TestIframeAnalysis(t *testing.T) {
+	external := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("Cross-origin iframe must not be fetched")
+	}))
+	defer external.Close()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		switch r.URL.Path {
+		case "/embed":
+			fmt.Fprint(w, `<html><body><h2>Embedded heading</h2>
+<p>four words of content</p></body></html>`)
+		default:
+			fmt.Fprintf(w, `<html><body><iframe src="/embed"></iframe><iframe src="%s/widget"></iframe></body></html>`, external.URL)
+		}
+	}))
+	defer server.Close()
+
+	analyzer := newTestAnalyzer(t)
+	analyzer.SetAnalyzeIframes(true)
+
+	analysis, err := analyzer.Analyze(server.URL)
+	if err != nil {
+		t.Fatalf("Failed to analyze URL: %v", err)
+	}
+	if analysis.Iframes == nil {
+		t.Fatal("Expected iframe analysis to be reported")
+	}
+	if len(analysis.Iframes.Frames) != 1 {
+		t.Fatalf("Expected 1 same-origin frame, got %d", len(analysis.Iframes.Frames))
+	}
+	frame := analysis.Iframes.Frames[0]
+	if frame.URL != server.URL+"/embed" {
+		t.Errorf("Unexpected frame URL %q", frame.URL)
+	}
+	if frame.WordCount != 6 {
+		t.Errorf("Expected 6 framed words, got %d", frame.WordCount)
+	}
+	if len(frame.Headings) != 1 || frame.Headings[0] != "Embedded heading" {
+		t.Errorf("Unexpected framed headings %v", frame.Headings)
+	}
+	if analysis.Iframes.SkippedCrossOrigin != 1 {
+		t.Errorf("Expected 1 skipped cross-origin frame, got %d", analysis.Iframes.SkippedCrossOrigin)
+	}
+}
+
+func TestFetchModes(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, `<html><head><title>Page not found</title></head><body><h1>Not found</h1></body></html>`)
+	}))
+	defer server.Close()
+
+	analyzer := newTestAnalyzer(t)
+
+	_, err := analyzer.AnalyzeWithOptions(server.URL, AnalyzeOptions{Mode: FetchModeFailFast})
+	var fetchErr *FetchError
+	if !errors.As(err, &fetchErr) || fetchErr.Category != CategoryHTTPStatus || fetchErr.StatusCode != http.StatusNotFound {
+		t.Fatalf("Expected fail-fast to return an HTTP status error with the status, got %v", err)
+	}
+
+	analysis, err := analyzer.AnalyzeWithOptions(server.URL, AnalyzeOptions{Mode: FetchModeBestEffort})
+	if err != nil {
+		t.Fatalf("Expected best-effort to analyze the error page, got %v", err)
+	}
+	if analysis.Title.Title != "Page not found" {
+		t.Errorf("Expected the error page body to be analyzed, got title %q", analysis.Title.Title)
+	}
+	if analysis.Response.StatusCode != http.StatusNotFound || analysis.Response.ContentType != "text/html" {
+		t.Errorf("Expected the 404 response to be reported, got %+v", analysis.Response)
+	}
+	if len(analysis.Warnings) != 1 || !strings.Contains(analysis.Warnings[0], "404") {
+		t.Errorf("Expected a warning about the 404 status, got %v", analysis.Warnings)
+	}
+	if len(analysis.Recommendations) == 0 || analysis.Recommendations[0] != analysis.Warnings[0] {
+		t.Error("Expected the status warning to lead the recommendations")
+	}
+}
+
+func TestHeadOrderLateCharset(t *testing.T) {
+	padding := strings.Repeat("<!-- padding comment -->\n", 60)
+	page := `<html><head>` + padding + `<script src="/app.js"></script><title>Late</title>` +
+		`<link rel="stylesheet" href="/site.css"><meta name="viewport" content="width=device-width">` +
+		`<meta charset="utf-8"></head><body></body></html>`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		fmt.Fprint(w, page)
+	}))
+	defer server.Close()
+
+	analyzer := newTestAnalyzer(t)
+	analysis, err := analyzer.Analyze(server.URL)
+	if err != nil {
+		t.Fatalf("Failed to analyze URL: %v", err)
+	}
+
+	head := analysis.HeadOrder
+	if head.CharsetPosition < charsetPrescanLimit || head.CharsetEarly {
+		t.Errorf("Expected late charset to be flagged, got position %d", head.CharsetPosition)
+	}
+	if head.TitleBeforeScripts {
+		t.Error("Expected title after a blocking script to be flagged")
+	}
+	if head.ViewportBeforeBlocking {
+		t.Error("Expected viewport after a stylesheet to be flagged")
+	}
+	if len(head.Violations) != 3 {
+		t.Errorf("Expected 3 violations, got %v", head.Violations)
+	}
+
+	found := false
+	for _, rec := range analysis.Recommendations {
+		if strings.Contains(rec, "first 1024 bytes") {
+			found = true
+		}
+	}
+	if !found {
+		t.Error("Expected a recommendation for the late charset declaration")
+	}
+}
+
+func TestOGImageChecks(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		switch r.URL.Path {
+		case "/share.png":
+			w.Header().Set("Content-Type", "image/png")
+		case "/no-dimensions":
+			fmt.Fprint(w, `<html><head><meta property="og:image" content="/share.png"></head><body></body></html>`)
+		case "/broken":
+			fmt.Fprint(w, `<html><head><meta property="og:image" content="/gone.png">`+
+				`<meta property="og:image:width" content="1200"><meta property="og:image:height" content="630"></head><body></body></html>`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	analyzer := newTestAnalyzer(t)
+	analyzer.SetVerifyOGImage(true)
+
+	hasRecommendation := func(analysis *SEOAnalysis, fragment string) bool {
+		for _, rec := range analysis.Recommendations {
+			if strings.Contains(rec, fragment) {
+				return true
+			}
+		}
+		return false
+	}
+
+	analysis, err := analyzer.Analyze(server.URL + "/no-dimensions")
+	if err != nil {
+		t.Fatalf("Failed to analyze URL: %v", err)
+	}
+	if analysis.Social.HasImageDimensions {
+		t.Error("Expected missing og:image dimensions to be detected")
+	}
+	if !hasRecommendation(analysis, "og:image:width") {
+		t.Error("Expected a recommendation to add og:image dimensions")
+	}
+	if analysis.Social.OGImageReachable == nil || !*analysis.Social.OGImageReachable {
+		t.Error("Expected the existing og:image to be reachable")
+	}
+
+	analysis, err = analyzer.Analyze(server.URL + "/broken")
+	if err != nil {
+		t.Fatalf("Failed to analyze URL: %v", err)
+	}
+	if !analysis.Social.HasImageDimensions {
+		t.Error("Expected og:image dimensions to be detected")
+	}
+	if analysis.Social.OGImageReachable == nil || *analysis.Social.OGImageReachable {
+		t.Error("Expected the 404 og:image to be flagged as unreachable")
+	}
+	if !hasRecommendation(analysis, "could not be loaded") {
+		t.Error("Expected a recommendation to fix the broken og:image")
+	}
+}
+
+func TestAMPCanonicalRoundTrip(t *testing.T) {
+	server := newSiteServer(t, map[string]string{
+		"/good":       `<html><head><link rel="canonical" href="/good"><link rel="amphtml" href="/good/amp"></head><body></body></html>`,
+		"/good/amp":   `<html amp><head><link rel="canonical" href="/good"></head><body></body></html>`,
+		"/bad":        `<html><head><link rel="amphtml" href="/bad/amp"></head><body></body></html>`,
+		"/bad/amp":    `<html amp><head><link rel="canonical" href="/elsewhere"></head><body></body></html>`,
+		"/orphan":     `<html><head><link rel="amphtml" href="/orphan/amp"></head><body></body></html>`,
+		"/orphan/amp": `<html amp><head></head><body></body></html>`,
+	})
+	analyzer := newTestAnalyzer(t)
+	opts := AnalyzeOptions{CheckAMP: true}
+
+	analysis, err := analyzer.AnalyzeWithOptions(server.URL+"/good", opts)
+	if err != nil {
+		t.Fatalf("Failed to analyze URL: %v", err)
+	}
+	if analysis.AMP == nil || !analysis.AMP.RoundTrip || len(analysis.AMP.Issues) != 0 {
+		t.Errorf("Expected a valid AMP round trip, got %+v", analysis.AMP)
+	}
+
+	analysis, err = analyzer.AnalyzeWithOptions(server.URL+"/bad", opts)
+	if err != nil {
+		t.Fatalf("Failed to analyze URL: %v", err)
+	}
+	if analysis.AMP == nil || analysis.AMP.RoundTrip {
+		t.Fatalf("Expected a broken AMP round trip, got %+v", analysis.AMP)
+	}
+	if analysis.AMP.AMPCanonical != server.URL+"/elsewhere" {
+		t.Errorf("Expected AMP canonical to be resolved, got %q", analysis.AMP.AMPCanonical)
+	}
+	found := false
+	for _, rec := range analysis.Recommendations {
+		if strings.Contains(rec, "does not match the main page's canonical") {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("Expected a canonical mismatch recommendation, got %v", analysis.Recommendations)
+	}
+
+	analysis, err = analyzer.AnalyzeWithOptions(server.URL+"/orphan", opts)
+	if err != nil {
+		t.Fatalf("Failed to analyze URL: %v", err)
+	}
+	if analysis.AMP == nil || analysis.AMP.RoundTrip || len(analysis.AMP.Issues) != 1 {
+		t.Errorf("Expected a missing AMP canonical to be reported, got %+v", analysis.AMP)
+	}
+
+	// Without the option the AMP variant is not checked
+	analysis, err = analyzer.Analyze(server.URL + "/bad")
+	if err != nil {
+		t.Fatalf("Failed to analyze URL: %v", err)
+	}
+	if analysis.AMP != nil {
+		t.Errorf("Expected no AMP check without CheckAMP, got %+v", analysis.AMP)
+	}
+}
+
+func TestOutputPrecision(t *testing.T) {
+	analysis := &SEOAnalysis{
+		Score: 250.0 / 3,
+		Content: ContentAnalysis{
+			KeywordDensity: map[string]float64{"seo": 1.0 / 3},
+		},
+	}
+
+	decimalsOf := func(data []byte, field string) int {
+		idx := strings.Index(string(data), `"`+field+`":`)
+		if idx < 0 {
+			t.Fatalf("Field %s not found in %s", field, data)
+		}
+		value := string(data[idx+len(field)+3:])
+		value = value[:strings.IndexAny(value, ",}")]
+		if dot := strings.Index(value, "."); dot >= 0 {
+			return len(value) - dot - 1
+		}
+		return 0
+	}
+
+	// Analyses not produced by an analyzer use the default precision
+	data, err := json.Marshal(analysis)
+	if err != nil {
+		t.Fatalf("Failed to marshal analysis: %v", err)
+	}
+	if got := decimalsOf(data, "score"); got > DefaultOutputDecimals {
+		t.Errorf("Expected at most %d decimals in score, got %d (%s)", DefaultOutputDecimals, got, data)
+	}
+
+	server := newTestSite(t)
+	analyzer := newTestAnalyzer(t)
+	analyzer.SetOutputPrecision(1)
+
+	analysis, err = analyzer.Analyze(server.URL)
+	if err != nil {
+		t.Fatalf("Failed to analyze URL: %v", err)
+	}
+	analysis.Score = 250.0 / 3
+	data, err = json.Marshal(analysis)
+	if err != nil {
+		t.Fatalf("Failed to marshal analysis: %v", err)
+	}
+	if got := decimalsOf(data, "score"); got > 1 {
+		t.Errorf("Expected at most 1 decimal in score, got %d (%s)", got, data)
+	}
+	var decoded struct {
+		Content struct {
+			KeywordDensity map[string]float64 `json:"keywordDensity"`
+		} `json:"content"`
+	}
+	analysis.Content.KeywordDensity["seo"] = 1.0 / 3
+	data, _ = json.Marshal(analysis)
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("Failed to decode analysis: %v", err)
+	}
+	if got := decoded.Content.KeywordDensity["seo"]; got != 0.3 {
+		t.Errorf("Expected keyword density rounded to 0.3, got %v", got)
+	}
+	if analysis.Score != 250.0/3 {
+		t.Error("Expected the in-memory score to keep full precision")
+	}
+}
+
+func TestSelfLinks(t *testing.T) {
+	server := newSiteServer(t, map[string]string{
+		"/page": `<html><body>
+			<a href="/page">Home</a>
+			<a href="/page#top">Top</a>
+			<a href="#section">Section</a>
+			<a href="/other">Other</a>
+			</body></html>`,
+		"/other": `<html><body>
+			<a href="/other">One</a> <a href="/other">Two</a> <a href="/other">Three</a>
+			</body></html>`,
+	})
+	analyzer := newTestAnalyzer(t)
+
+	analysis, err := analyzer.Analyze(server.URL + "/page")
+	if err != nil {
+		t.Fatalf("Failed to analyze URL: %v", err)
+	}
+	if analysis.Links.SelfLinks != 2 {
+		t.Errorf("Expected 2 self links (fragment-only excluded), got %d", analysis.Links.SelfLinks)
+	}
+	if analysis.Links.InternalLinks != 3 {
+		t.Errorf("Expected self links to be categorized as internal, got %d internal", analysis.Links.InternalLinks)
+	}
+	for _, rec := range analysis.Recommendations {
+		if strings.Contains(rec, "links to itself") {
+			t.Errorf("Did not expect a self-link recommendation below the threshold: %q", rec)
+		}
+	}
+
+	analysis, err = analyzer.Analyze(server.URL + "/other")
+	if err != nil {
+		t.Fatalf("Failed to analyze URL: %v", err)
+	}
+	if analysis.Links.SelfLinks != 3 {
+		t.Errorf("Expected 3 self links, got %d", analysis.Links.SelfLinks)
+	}
+	found := false
+	for _, rec := range analysis.Recommendations {
+		if strings.Contains(rec, "links to itself 3 times") {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("Expected a self-link recommendation, got %v", analysis.Recommendations)
+	}
+}
+
+func TestDeprecatedMarkup(t *testing.T) {
+	server := newSiteServer(t, map[string]string{
+		"/old": `<html><body bgcolor="#fff">
+			<center><font color="red">Hello</font> <font size="2">there</font></center>
+			<marquee>News</marquee>
+			<table cellpadding="2"><tr><td align="left" valign="top">Cell</td></tr></table>
+			<p align="center">Text</p>
+			</body></html>`,
+	})
+	analyzer := newTestAnalyzer(t)
+
+	analysis, err := analyzer.Analyze(server.URL + "/old")
+	if err != nil {
+		t.Fatalf("Failed to analyze URL: %v", err)
+	}
+	if analysis.DeprecatedMarkup != nil {
+		t.Error("Expected deprecated markup to be skipped by the standard profile")
+	}
+
+	analysis, err = analyzer.AnalyzeWithOptions(server.URL+"/old", AnalyzeOptions{Profile: ProfileThorough})
+	if err != nil {
+		t.Fatalf("Failed to analyze URL: %v", err)
+	}
+	deprecated := analysis.DeprecatedMarkup
+	if deprecated == nil {
+		t.Fatal("Expected deprecated markup to be checked by the thorough profile")
+	}
+	wantElements := map[string]int{"center": 1, "font": 2, "marquee": 1}
+	for name, want := range wantElements {
+		if got := deprecated.Elements[name]; got != want {
+			t.Errorf("Expected %d <%s>, got %d", want, name, got)
+		}
+	}
+	wantAttributes := map[string]int{"bgcolor": 1, "cellpadding": 1, "align": 2, "valign": 1}
+	for name, want := range wantAttributes {
+		if got := deprecated.Attributes[name]; got != want {
+			t.Errorf("Expected %d %s attributes, got %d", want, name, got)
+		}
+	}
+	if deprecated.Total != 9 {
+		t.Errorf("Expected 9 deprecated uses, got %d", deprecated.Total)
+	}
+	found := false
+	for _, rec := range analysis.Recommendations {
+		if strings.Contains(rec, "Replace deprecated HTML") {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("Expected a modernization recommendation, got %v", analysis.Recommendations)
+	}
+
+	if err := (AnalyzeOptions{Profile: "exhaustive"}).Validate(); err == nil {
+		t.Error("Expected an unknown profile to be rejected")
+	}
+}
+
+func TestMemoryGuard(t *testing.T) {
+	var page strings.Builder
+	page.WriteString("<html><head><title>Huge</title></head><body>")
+	for i := 0; i < 5000; i++ {
+		fmt.Fprintf(&page, `<div><a href="/item/%d">Item %d</a></div>`, i, i)
+	}
+	page.WriteString("</body></html>")
+	server := newSiteServer(t, map[string]string{"/huge": page.String()})
+
+	analyzer := newTestAnalyzer(t)
+	analyzer.SetMaxLinksPerPage(50)
+
+	analysis, err := analyzer.Analyze(server.URL + "/huge")
+	if err != nil {
+		t.Fatalf("Failed to analyze URL: %v", err)
+	}
+	if !analysis.Truncated || !analysis.Links.Truncated {
+		t.Error("Expected the link cap to flag the analysis as truncated")
+	}
+	if got := analysis.Links.InternalLinks + analysis.Links.ExternalLinks; got != 50 {
+		t.Errorf("Expected 50 collected links, got %d", got)
+	}
+
+	analyzer.ClearCache()
+	analyzer.SetMaxDOMNodes(200)
+	analysis, err = analyzer.Analyze(server.URL + "/huge")
+	if err != nil {
+		t.Fatalf("Failed to analyze URL: %v", err)
+	}
+	if !analysis.Truncated {
+		t.Error("Expected the DOM cap to flag the analysis as truncated")
+	}
+	if analysis.Title.Title != "Huge" {
+		t.Errorf("Expected the head to survive truncation, got title %q", analysis.Title.Title)
+	}
+	if analysis.Links.InternalLinks >= 200 {
+		t.Errorf("Expected links beyond the DOM cap to be ignored, got %d", analysis.Links.InternalLinks)
+	}
+	found := false
+	for _, rec := range analysis.Recommendations {
+		if strings.Contains(rec, "truncated due to page complexity") {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("Expected a truncation warning, got %v", analysis.Recommendations)
+	}
+
+	// Pages within the caps are not truncated
+	analysis, err = analyzer.Analyze(newTestSite(t).URL)
+	if err != nil {
+		t.Fatalf("Failed to analyze URL: %v", err)
+	}
+	if analysis.Truncated {
+		t.Error("Did not expect a small page to be truncated")
+	}
+}
+
+func TestDeviceProfiles(t *testing.T) {
+	var mu sync.Mutex
+	userAgents := map[string]string{}
+	viewports := map[string]string{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		userAgents[r.URL.Path] = r.UserAgent()
+		viewports[r.URL.Path] = r.Header.Get("Viewport-Width") + "/" + r.Header.Get("Width")
+		mu.Unlock()
+		w.Header().Set("Content-Type", "text/html")
+		fmt.Fprint(w, testPage)
+	}))
+	defer server.Close()
+	analyzer := newTestAnalyzer(t)
+
+	analysis, err := analyzer.Analyze(server.URL + "/desktop")
+	if err != nil {
+		t.Fatalf("Failed to analyze URL: %v", err)
+	}
+	if analysis.Device.Name != DeviceDesktop {
+		t.Errorf("Expected the desktop profile by default, got %q", analysis.Device.Name)
+	}
+
+	analysis, err = analyzer.AnalyzeWithOptions(server.URL+"/mobile", AnalyzeOptions{Device: DeviceMobile})
+	if err != nil {
+		t.Fatalf("Failed to analyze URL: %v", err)
+	}
+	if analysis.Device.Name != DeviceMobile || analysis.Device.ViewportWidth != 412 {
+		t.Errorf("Expected the mobile profile to be reported, got %+v", analysis.Device)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if ua := userAgents["/desktop"]; ua != "SEOAnalyzer/1.0" {
+		t.Errorf("Expected the desktop UA, got %q", ua)
+	}
+	if ua := userAgents["/mobile"]; !strings.Contains(ua, "Mobile") {
+		t.Errorf("Expected a mobile UA, got %q", ua)
+	}
+	if hints := viewports["/mobile"]; hints != "412/412" {
+		t.Errorf("Expected mobile viewport hints of 412, got %q", hints)
+	}
+	if hints := viewports["/desktop"]; hints != "/" {
+		t.Errorf("Expected no viewport hints for desktop, got %q", hints)
+	}
+
+	if err := (AnalyzeOptions{Device: "tablet"}).Validate(); err == nil {
+		t.Error("Expected an unknown device to be rejected")
+	}
+}
+
+func TestSkipLinkCheck(t *testing.T) {
+	var probes atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/":
+			w.Header().Set("Content-Type", "text/html")
+			fmt.Fprint(w, `<html><body><a href="/missing">Missing</a> <a href="/gone">Gone</a></body></html>`)
+		case "/missing", "/gone":
+			probes.Add(1)
+			http.NotFound(w, r)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+	analyzer := newTestAnalyzer(t)
+
+	analysis, err := analyzer.AnalyzeWithOptions(server.URL+"/", AnalyzeOptions{SkipLinkCheck: true})
+	if err != nil {
+		t.Fatalf("Failed to analyze URL: %v", err)
+	}
+	if analysis.Links.LinksChecked || analysis.Links.BrokenLinks != 0 || analysis.Links.InternalLinks != 2 {
+		t.Errorf("Expected 2 unchecked internal links, got %+v", analysis.Links)
+	}
+	if n := probes.Load(); n != 0 {
+		t.Errorf("Expected no link requests, got %d", n)
+	}
+
+	// The checked result is cached separately
+	analysis, err = analyzer.Analyze(server.URL + "/")
+	if err != nil {
+		t.Fatalf("Failed to analyze URL: %v", err)
+	}
+	if !analysis.Links.LinksChecked || analysis.Links.BrokenLinks != 2 {
+		t.Errorf("Expected 2 broken links once checked, got %+v", analysis.Links)
+	}
+}
+
+func TestEffectiveTimeout(t *testing.T) {
+	tests := []struct {
+		timeout, want, links time.Duration
+	}{
+		{0, 30 * time.Second, 15 * time.Second},
+		{45 * time.Second, 45 * time.Second, 22500 * time.Millisecond},
+		{time.Second, 5 * time.Second, 2500 * time.Millisecond},
+		{10 * time.Minute, 60 * time.Second, 30 * time.Second},
+	}
+	for _, tt := range tests {
+		opts := AnalyzeOptions{Timeout: tt.timeout}
+		if got := opts.EffectiveTimeout(); got != tt.want {
+			t.Errorf("EffectiveTimeout(%v) = %v, want %v", tt.timeout, got, tt.want)
+		}
+		if got := opts.linkCheckTimeout(); got != tt.links {
+			t.Errorf("linkCheckTimeout(%v) = %v, want %v", tt.timeout, got, tt.links)
+		}
+	}
+	if err := (AnalyzeOptions{Timeout: -time.Second}).Validate(); err == nil {
+		t.Error("Expected a negative timeout to be rejected")
+	}
+}
+
+func TestAnalyzeCancelledContext(t *testing.T) {
+	var hang atomic.Bool
+	hang.Store(true)
+	started := make(chan struct{}, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if hang.Load() {
+			select {
+			case started <- struct{}{}:
+			default:
+			}
+			<-r.Context().Done()
+			return
+		}
+		w.Header().Set("Content-Type", "text/html")
+		fmt.Fprint(w, `<html><head><title>Back</title></head><body></body></html>`)
+	}))
+	defer server.Close()
+
+	analyzer := newTestAnalyzer(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		<-started
+		cancel()
+	}()
+	begin := time.Now()
+	_, err := analyzer.AnalyzeWithOptionsContext(ctx, server.URL, AnalyzeOptions{})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected the analysis to be cancelled, got %v", err)
+	}
+	if elapsed := time.Since(begin); elapsed > 5*time.Second {
+		t.Errorf("Expected cancellation to abort the fetch promptly, took %v", elapsed)
+	}
+
+	// A cancelled request says nothing about the target, so it mustn't be
+	// negatively cached
+	hang.Store(false)
+	analysis, err := analyzer.AnalyzeWithOptionsContext(context.Background(), server.URL, AnalyzeOptions{})
+	if err != nil {
+		t.Fatalf("Expected a fresh analysis after the cancellation, got %v", err)
+	}
+	if analysis.Title.Title != "Back" {
+		t.Errorf("Expected title %q, got %q", "Back", analysis.Title.Title)
+	}
+}
+
+func TestLikelyInfiniteScroll(t *testing.T) {
+	longText := strings.Repeat("Plenty of server rendered listing text. ", 60)
+	server := newSiteServer(t, map[string]string{
+		"/listing": `<html><body><h1>Products</h1><ul id="results"></ul>
+			<nav aria-label="Pagination"><a href="?page=1">1</a> <a href="?page=2">2</a></nav>
+			</body></html>`,
+		"/feed":      `<html><body><div class="feed" data-infinite-scroll='{"path": "/feed?page={{#}}"}'>` + longText + `</div></body></html>`,
+		"/load-more": `<html><body><h1>News</h1><button>Load more</button></body></html>`,
+		"/article": `<html><body><p>` + longText + `</p>
+			<div class="pagination"><a href="/article?page=2">Next</a></div></body></html>`,
+	})
+	analyzer := newTestAnalyzer(t)
+
+	tests := map[string]bool{
+		"/listing":   true,
+		"/feed":      true,
+		"/load-more": true,
+		"/article":   false,
+	}
+	for path, want := range tests {
+		analysis, err := analyzer.Analyze(server.URL + path)
+		if err != nil {
+			t.Fatalf("Failed to analyze %s: %v", path, err)
+		}
+		if analysis.Content.LikelyInfiniteScroll != want {
+			t.Errorf("%s: expected LikelyInfiniteScroll=%v", path, want)
+		}
+		found := false
+		for _, rec := range analysis.Recommendations {
+			if strings.Contains(rec, "infinite scroll") {
+				found = true
+			}
+		}
+		if found != want {
+			t.Errorf("%s: expected infinite scroll recommendation=%v", path, want)
+		}
+	}
+}
+
+func TestConflictingRobotsMeta(t *testing.T) {
+	server := newSiteServer(t, map[string]string{
+		"/conflict": `<html><head>
+			<meta name="robots" content="index, follow">
+			<meta name="ROBOTS" content="noindex, noarchive">
+			</head><body></body></html>`,
+		"/single": `<html><head><meta name="robots" content="index, nofollow"></head><body></body></html>`,
+	})
+	analyzer := newTestAnalyzer(t)
+
+	analysis, err := analyzer.Analyze(server.URL + "/conflict")
+	if err != nil {
+		t.Fatalf("Failed to analyze URL: %v", err)
+	}
+	if analysis.Meta.Robots != "noindex, follow, noarchive" {
+		t.Errorf("Expected the most restrictive merged directives, got %q", analysis.Meta.Robots)
+	}
+	if !analysis.Meta.RobotsConflict || len(analysis.Meta.RobotsTags) != 2 {
+		t.Errorf("Expected a conflict between 2 robots tags, got %+v", analysis.Meta)
+	}
+	found := false
+	for _, rec := range analysis.Recommendations {
+		if strings.Contains(rec, "Conflicting meta robots") {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("Expected a conflict warning, got %v", analysis.Recommendations)
+	}
+
+	analysis, err = analyzer.Analyze(server.URL + "/single")
+	if err != nil {
+		t.Fatalf("Failed to analyze URL: %v", err)
+	}
+	if analysis.Meta.Robots != "index, nofollow" || analysis.Meta.RobotsConflict {
+		t.Errorf("Expected a single tag to be reported as is, got %q (conflict %v)", analysis.Meta.Robots, analysis.Meta.RobotsConflict)
+	}
+}
+
+func TestBreadcrumbMismatch(t *testing.T) {
+	server := newSiteServer(t, map[string]string{
+		"/visible-only": `<html><body>
+			<nav aria-label="Breadcrumb"><ol><li><a href="/">Home</a></li> <li>Shoes</li></ol></nav>
+			</body></html>`,
+		"/both": `<html><head><script type="application/ld+json">
+			{"@context": "https://schema.org", "@graph": [{"@type": "BreadcrumbList", "itemListElement": []}]}
+			</script></head><body><ol class="breadcrumbs"><li>Home</li></ol></body></html>`,
+		"/structured-only": `<html><head><script type="application/ld+json">
+			{"@context": "https://schema.org", "@type": "BreadcrumbList", "itemListElement": []}
+			</script></head><body></body></html>`,
+	})
+	analyzer := newTestAnalyzer(t)
+
+	hasRecommendation := func(analysis *SEOAnalysis, fragment string) bool {
+		for _, rec := range analysis.Recommendations {
+			if strings.Contains(rec, fragment) {
+				return true
+			}
+		}
+		return false
+	}
+
+	analysis, err := analyzer.Analyze(server.URL + "/visible-only")
+	if err != nil {
+		t.Fatalf("Failed to analyze URL: %v", err)
+	}
+	if !analysis.Breadcrumbs.HasVisible || analysis.Breadcrumbs.HasStructured || !analysis.Breadcrumbs.Mismatch {
+		t.Errorf("Expected visible breadcrumbs without structured data, got %+v", analysis.Breadcrumbs)
+	}
+	if !hasRecommendation(analysis, "Add BreadcrumbList structured data") {
+		t.Errorf("Expected a recommendation to add BreadcrumbList, got %v", analysis.Recommendations)
+	}
+
+	analysis, err = analyzer.Analyze(server.URL + "/both")
+	if err != nil {
+		t.Fatalf("Failed to analyze URL: %v", err)
+	}
+	if analysis.Breadcrumbs.Mismatch || !analysis.Breadcrumbs.HasStructured {
+		t.Errorf("Expected matching breadcrumbs, got %+v", analysis.Breadcrumbs)
+	}
+
+	analysis, err = analyzer.Analyze(server.URL + "/structured-only")
+	if err != nil {
+		t.Fatalf("Failed to analyze URL: %v", err)
+	}
+	if !analysis.Breadcrumbs.Mismatch || !hasRecommendation(analysis, "no visible breadcrumb trail") {
+		t.Errorf("Expected structured-only breadcrumbs to be flagged, got %+v", analysis.Breadcrumbs)
+	}
+}
+
+func TestHeadingPoorLongContent(t *testing.T) {
+	paragraph := "<p>" + strings.Repeat("word ", 300) + "</p>\n"
+	flat := "<html><body><h1>Guide</h1>" + strings.Repeat(paragraph, 5) + "</body></html>"
+	structured := "<html><body><h1>Guide</h1>"
+	for i := 0; i < 5; i++ {
+		structured += fmt.Sprintf("<h2>Part %d</h2>\n%s", i, paragraph)
+	}
+	structured += "</body></html>"
+	sparse := "<html><body><h2>One</h2>\n" + strings.Repeat(paragraph, 3) + "<h2>Two</h2>\n" + strings.Repeat(paragraph, 2) + "</body></html>"
+	server := newSiteServer(t, map[string]string{"/flat": flat, "/structured": structured, "/sparse": sparse})
+	analyzer := newTestAnalyzer(t)
+
+	hasRecommendation := func(analysis *SEOAnalysis) bool {
+		for _, rec := range analysis.Recommendations {
+			if strings.Contains(rec, "more H2/H3 subheadings") {
+				return true
+			}
+		}
+		return false
+	}
+
+	analysis, err := analyzer.Analyze(server.URL + "/flat")
+	if err != nil {
+		t.Fatalf("Failed to analyze URL: %v", err)
+	}
+	if !analysis.Content.HeadingPoor || !hasRecommendation(analysis) {
+		t.Errorf("Expected a flat 1500-word page to be flagged, got %+v", analysis.Content)
+	}
+
+	analysis, err = analyzer.Analyze(server.URL + "/structured")
+	if err != nil {
+		t.Fatalf("Failed to analyze URL: %v", err)
+	}
+	if analysis.Content.HeadingPoor || hasRecommendation(analysis) {
+		t.Error("Expected a well-structured long page not to be flagged")
+	}
+
+	// Two subheadings are too few at the default ratio but enough at a
+	// looser one
+	analysis, err = analyzer.Analyze(server.URL + "/sparse")
+	if err != nil {
+		t.Fatalf("Failed to analyze URL: %v", err)
+	}
+	if !analysis.Content.HeadingPoor {
+		t.Error("Expected 2 subheadings over 1500 words to be flagged by default")
+	}
+	analyzer.SetWordsPerSubheading(1000)
+	analyzer.ClearCache()
+	analysis, err = analyzer.Analyze(server.URL + "/sparse")
+	if err != nil {
+		t.Fatalf("Failed to analyze URL: %v", err)
+	}
+	if analysis.Content.HeadingPoor {
+		t.Error("Expected the configured ratio to accept 2 subheadings")
+	}
+}
+
+func TestShutdownDrainsInFlightAnalyses(t *testing.T) {
+	started := make(chan struct{}, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Only the page request signals; later ones such as the favicon
+		// check must not block
+		select {
+		case started <- struct{}{}:
+		default:
+		}
+		time.Sleep(200 * time.Millisecond)
+		fmt.Fprint(w, `<html><head><title>Slow page</title></head><body>Slow</body></html>`)
+	}))
+	defer server.Close()
+
+	a := newTestAnalyzer(t)
+	result := make(chan error, 1)
+	go func() {
+		_, err := a.Analyze(server.URL)
+		result <- err
+	}()
+	<-started
+
+	if err := a.Shutdown(); err != nil {
+		t.Fatalf("Expected Shutdown to drain cleanly, got %v", err)
+	}
+	select {
+	case err := <-result:
+		if err != nil {
+			t.Errorf("Expected the in-flight analysis to complete, got %v", err)
+		}
+	default:
+		t.Fatal("Expected Shutdown to wait for the in-flight analysis")
+	}
+
+	if _, err := a.Analyze(server.URL + "/later"); !errors.Is(err, ErrShuttingDown) {
+		t.Errorf("Expected ErrShuttingDown after Shutdown, got %v", err)
+	}
+}
+
+func TestShutdownTimeout(t *testing.T) {
+	started := make(chan struct{}, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Only the page request signals; later ones such as the favicon
+		// check must not block
+		select {
+		case started <- struct{}{}:
+		default:
+		}
+		time.Sleep(300 * time.Millisecond)
+		fmt.Fprint(w, `<html><body>Very slow</body></html>`)
+	}))
+	defer server.Close()
+
+	a := newTestAnalyzer(t)
+	a.SetShutdownTimeout(50 * time.Millisecond)
+	result := make(chan error, 1)
+	go func() {
+		_, err := a.Analyze(server.URL)
+		result <- err
+	}()
+	<-started
+
+	start := time.Now()
+	if err := a.Shutdown(); err == nil {
+		t.Error("Expected Shutdown to report the timeout")
+	}
+	if elapsed := time.Since(start); elapsed > 250*time.Millisecond {
+		t.Errorf("Expected Shutdown to give up after the timeout, took %v", elapsed)
+	}
+
+	// The abandoned analysis still finishes without touching cleared caches
+	if err := <-result; err != nil {
+		t.Errorf("Expected the abandoned analysis to finish, got %v", err)
+	}
+}
+
+func TestMaxDomDepth(t *testing.T) {
+	nested := func(depth int) string {
+		// <html> and <body> account for two levels
+		return "<html><head><title>Nested</title></head><body>" +
+			strings.Repeat("<div>", depth-2) + "deep" + strings.Repeat("</div>", depth-2) +
+			"<p>shallow</p></body></html>"
+	}
+	server := newSiteServer(t, map[string]string{
+		"/shallow": nested(10),
+		"/deep":    nested(40),
+	})
+	analyzer := newTestAnalyzer(t)
+
+	shallow, err := analyzer.Analyze(server.URL + "/shallow")
+	if err != nil {
+		t.Fatalf("Failed to analyze URL: %v", err)
+	}
+	if shallow.Performance.MaxDomDepth != 10 {
+		t.Errorf("Expected depth 10, got %d", shallow.Performance.MaxDomDepth)
+	}
+
+	deep, err := analyzer.Analyze(server.URL + "/deep")
+	if err != nil {
+		t.Fatalf("Failed to analyze URL: %v", err)
+	}
+	if deep.Performance.MaxDomDepth != 40 {
+		t.Errorf("Expected depth 40, got %d", deep.Performance.MaxDomDepth)
+	}
+
+	hasRecommendation := func(analysis *SEOAnalysis) bool {
+		for _, rec := range analysis.Recommendations {
+			if strings.Contains(rec, "Reduce DOM nesting") {
+				return true
+			}
+		}
+		return false
+	}
+	if hasRecommendation(shallow) {
+		t.Error("Expected no nesting recommendation for a shallow page")
+	}
+	if !hasRecommendation(deep) {
+		t.Errorf("Expected a nesting recommendation past depth %d, got %v", maxRecommendedDOMDepth, deep.Recommendations)
+	}
+}
+
+func TestRenderBlockingResources(t *testing.T) {
+	const viewport = `<meta name="viewport" content="width=device-width, initial-scale=1">`
+	server := newSiteServer(t, map[string]string{
+		"/clean": `<html><head>` + viewport + `
+			<link rel="stylesheet" href="/main.css">
+			<script src="/app.js" defer></script>
+			</head><body></body></html>`,
+		"/blocking": `<html><head>` + viewport + `
+			<link rel="stylesheet" href="/a.css">
+			<link rel="Stylesheet" href="/b.css" media="all">
+			<link rel="stylesheet" href="/c.css" media="screen">
+			<link rel="stylesheet" href="/d.css">
+			<link rel="stylesheet" href="/print.css" media="print">
+			<link rel="stylesheet" href="/wide.css" media="(min-width: 1200px)">
+			<link rel="preload" href="/font.woff2" as="font">
+			<script src="/jquery.js"></script>
+			<script src="/plugins.js"></script>
+			<script src="/analytics.js" async></script>
+			<script src="/app.js" defer></script>
+			<script type="module" src="/module.js"></script>
+			<script>window.inline = true;</script>
+			</head><body><script src="/footer.js"></script></body></html>`,
+	})
+	analyzer := newTestAnalyzer(t)
+
+	clean, err := analyzer.Analyze(server.URL + "/clean")
+	if err != nil {
+		t.Fatalf("Failed to analyze URL: %v", err)
+	}
+	if clean.Performance.RenderBlockingCSS != 1 || clean.Performance.RenderBlockingJS != 0 {
+		t.Errorf("Expected 1 blocking stylesheet and no blocking scripts, got %+v", clean.Performance)
+	}
+
+	blocking, err := analyzer.Analyze(server.URL + "/blocking")
+	if err != nil {
+		t.Fatalf("Failed to analyze URL: %v", err)
+	}
+	if blocking.Performance.RenderBlockingCSS != 4 || blocking.Performance.RenderBlockingJS != 2 {
+		t.Errorf("Expected 4 blocking stylesheets and 2 blocking scripts, got %+v", blocking.Performance)
+	}
+	// 5 points per script, 2 per stylesheet past the second
+	if got := clean.Performance.Score - blocking.Performance.Score; got != 14 {
+		t.Errorf("Expected blocking resources to cost 14 points, got %d", got)
+	}
+
+	var scripts, stylesheets bool
+	for _, rec := range blocking.Recommendations {
+		scripts = scripts || strings.Contains(rec, "2 render-blocking script(s)")
+		stylesheets = stylesheets || strings.Contains(rec, "4 render-blocking stylesheets")
+	}
+	if !scripts || !stylesheets {
+		t.Errorf("Expected render-blocking recommendations, got %v", blocking.Recommendations)
+	}
+}
+
+func TestKeyProfilePersistence(t *testing.T) {
+	dataDir := t.TempDir()
+	a, err := New(dataDir)
+	if err != nil {
+		t.Fatalf("Failed to create analyzer: %v", err)
+	}
+	profile := KeyProfile{Device: DeviceMobile, Weights: map[string]float64{"performance": 0.5}}
+	if err := a.SetKeyProfile("secret-key", profile); err != nil {
+		t.Fatalf("Failed to save profile: %v", err)
+	}
+	if err := a.SetKeyProfile("secret-key", KeyProfile{Device: "tablet"}); err == nil {
+		t.Error("Expected an invalid profile to be rejected")
+	}
+	a.Shutdown()
+
+	data, _ := os.ReadFile(filepath.Join(dataDir, "profiles.json"))
+	if strings.Contains(string(data), "secret-key") {
+		t.Error("Expected API keys not to be stored in plain text")
+	}
+
+	reloaded, err := New(dataDir)
+	if err != nil {
+		t.Fatalf("Failed to reload analyzer: %v", err)
+	}
+	defer reloaded.Shutdown()
+	stored, ok := reloaded.KeyProfile("secret-key")
+	if !ok || stored.Device != DeviceMobile || stored.Weights["performance"] != 0.5 {
+		t.Fatalf("Expected the profile to survive a restart, got %+v (found %v)", stored, ok)
+	}
+
+	// Request options win; unset ones come from the profile
+	opts := stored.Apply(AnalyzeOptions{Device: DeviceDesktop, Weights: map[string]float64{"links": 0.3}})
+	if opts.Device != DeviceDesktop || opts.Weights["performance"] != 0.5 || opts.Weights["links"] != 0.3 {
+		t.Errorf("Expected request options merged over the profile, got %+v", opts)
+	}
+}
+
+func TestMissingPreconnectHints(t *testing.T) {
+	server := newSiteServer(t, map[string]string{
+		"/bare": `<html><head><title>Third parties</title>
+			<script src="https://cdn.example.com/a.js"></script>
+			<script src="https://cdn.example.com/b.js"></script>
+			<link rel="stylesheet" href="https://fonts.example.net/css">
+			<script src="/local.js"></script>
+			</head><body>
+			<img src="https://cdn.example.com/logo.png" alt="Logo">
+			<img src="https://img.example.org/1.jpg" alt="One">
+			<img src="https://img.example.org/2.jpg" alt="Two">
+			<iframe src="https://video.example.io/embed"></iframe>
+			<img src="https://pixel.example.biz/p.gif" alt="">
+			</body></html>`,
+		"/hinted": `<html><head><title>Hinted</title>
+			<link rel="preconnect" href="https://cdn.example.com">
+			<link rel="dns-prefetch" href="//fonts.example.net">
+			<script src="https://cdn.example.com/a.js"></script>
+			<link rel="stylesheet" href="https://fonts.example.net/css">
+			</head><body>Hinted</body></html>`,
+	})
+	analyzer := newTestAnalyzer(t)
+
+	bare, err := analyzer.Analyze(server.URL + "/bare")
+	if err != nil {
+		t.Fatalf("Failed to analyze URL: %v", err)
+	}
+	hints := bare.ResourceHints
+	if len(hints.ThirdPartyOrigins) != 5 {
+		t.Fatalf("Expected 5 third-party origins, got %+v", hints.ThirdPartyOrigins)
+	}
+	if top := hints.ThirdPartyOrigins[0]; top.Origin != "https://cdn.example.com" || top.Requests != 3 {
+		t.Errorf("Expected cdn.example.com with 3 requests first, got %+v", top)
+	}
+	var rec string
+	for _, r := range bare.Recommendations {
+		if strings.Contains(r, "preconnect") {
+			rec = r
+		}
+	}
+	// The three most used origins are named; the rest are left out
+	for _, origin := range []string{"https://cdn.example.com", "https://img.example.org"} {
+		if !strings.Contains(rec, origin) {
+			t.Errorf("Expected the recommendation to name %s, got %q", origin, rec)
+		}
+	}
+	if strings.Contains(rec, "127.0.0.1") || strings.Count(rec, "https://") != maxPreconnectSuggestions {
+		t.Errorf("Expected exactly %d third-party origins to be named, got %q", maxPreconnectSuggestions, rec)
+	}
+
+	hinted, err := analyzer.Analyze(server.URL + "/hinted")
+	if err != nil {
+		t.Fatalf("Failed to analyze URL: %v", err)
+	}
+	if len(hinted.ResourceHints.MissingHints) != 0 {
+		t.Errorf("Expected preconnect and dns-prefetch to cover both origins, got %v", hinted.ResourceHints.MissingHints)
+	}
+	for _, r := range hinted.Recommendations {
+		if strings.Contains(r, "preconnect") {
+			t.Errorf("Expected no preconnect recommendation, got %q", r)
+		}
+	}
+}
+
+func TestKeywordDensity(t *testing.T) {
+	server := newSiteServer(t, map[string]string{
+		"/article": `<html><head><title>Coffee</title>
+			<style>.coffee { color: brown }</style></head><body>
+			<h1>Coffee brewing guide</h1>
+			<p>Coffee brewing is simple. Good coffee brewing needs fresh beans, and fresh beans need a grinder!!!</p>
+			<p>Café owners know that café culture loves coffee. The grinder matters; the water matters too.</p>
+			<script>var coffee = "coffee coffee coffee coffee";</script>
+			</body></html>`,
+		"/thin": `<html><body><p>Coffee coffee coffee.</p></body></html>`,
+	})
+	analyzer := newTestAnalyzer(t)
+
+	analysis, err := analyzer.Analyze(server.URL + "/article")
+	if err != nil {
+		t.Fatalf("Failed to analyze URL: %v", err)
+	}
+	top := analysis.Content.TopKeywords
+	if len(top) == 0 {
+		t.Fatal("Expected keywords to be reported")
+	}
+	// Script text is ignored: coffee appears 4 times in the visible body
+	if top[0].Keyword != "coffee" || top[0].Count != 4 {
+		t.Errorf("Expected coffee (4) to be the top keyword, got %+v", top[0])
+	}
+
+	byKeyword := make(map[string]KeywordFrequency)
+	for i, keyword := range top {
+		byKeyword[keyword.Keyword] = keyword
+		if i > 0 && keyword.Density > top[i-1].Density {
+			t.Errorf("Expected keywords sorted by density, got %+v", top)
+		}
+		if analysis.Content.KeywordDensity[keyword.Keyword] != keyword.Density {
+			t.Errorf("Expected KeywordDensity to match TopKeywords for %q", keyword.Keyword)
+		}
+	}
+	for _, expected := range []string{"coffee brewing", "fresh beans", "café", "grinder", "matters"} {
+		if _, ok := byKeyword[expected]; !ok {
+			t.Errorf("Expected %q among the keywords, got %+v", expected, top)
+		}
+	}
+	for _, unexpected := range []string{"the", "is", "coffee coffee"} {
+		if _, ok := byKeyword[unexpected]; ok {
+			t.Errorf("Expected %q not to be a keyword", unexpected)
+		}
+	}
+	if got := byKeyword["coffee"].Density; got <= 0 || got >= 100 {
+		t.Errorf("Expected a percentage density, got %v", got)
+	}
+
+	thin, err := analyzer.Analyze(server.URL + "/thin")
+	if err != nil {
+		t.Fatalf("Failed to analyze URL: %v", err)
+	}
+	if len(thin.Content.TopKeywords) != 0 || len(thin.Content.KeywordDensity) != 0 {
+		t.Errorf("Expected no keywords for a page with almost no text, got %+v", thin.Content.TopKeywords)
+	}
+}
+
+func TestTextToHTMLRatio(t *testing.T) {
+	prose := "<p>" + strings.Repeat("Plain readable text. ", 20) + "</p>"
+	bloated := `<div class="a"><div class="b"><span style="color: red"></span></div></div>`
+	server := newSiteServer(t, map[string]string{
+		"/text":    "<html><body>" + prose + "</body></html>",
+		"/bloated": "<html><body>" + strings.Repeat(bloated, 40) + "<p>Little text.</p><script>" + strings.Repeat("var x = 1;", 50) + "</script></body></html>",
+	})
+	analyzer := newTestAnalyzer(t)
+	hasRecommendation := func(analysis *SEOAnalysis) bool {
+		for _, rec := range analysis.Recommendations {
+			if strings.Contains(rec, "text-to-HTML ratio") {
+				return true
+			}
+		}
+		return false
+	}
+
+	analysis, err := analyzer.Analyze(server.URL + "/text")
+	if err != nil {
+		t.Fatalf("Failed to analyze URL: %v", err)
+	}
+	if ratio := analysis.Content.TextToHTMLRatio; ratio < 80 || ratio > 100 {
+		t.Errorf("Expected a mostly text page, got a ratio of %.1f%%", ratio)
+	}
+	if hasRecommendation(analysis) {
+		t.Error("Expected no text-to-HTML recommendation for a text page")
+	}
+
+	analysis, err = analyzer.Analyze(server.URL + "/bloated")
+	if err != nil {
+		t.Fatalf("Failed to analyze URL: %v", err)
+	}
+	if ratio := analysis.Content.TextToHTMLRatio; ratio <= 0 || ratio >= minTextToHTMLRatio {
+		t.Errorf("Expected a low ratio for a markup-heavy page, got %.1f%%", ratio)
+	}
+	if !hasRecommendation(analysis) {
+		t.Errorf("Expected a text-to-HTML recommendation, got %v", analysis.Recommendations)
+	}
+}
+
+func TestKeywordAnchors(t *testing.T) {
+	server := newSiteServer(t, map[string]string{
+		"/shop": `<html><head><title>Shop</title></head><body>
+			<a href="/espresso-machines">Espresso machines</a>
+			<a href="/espresso-machines/compact">Compact ESPRESSO   machines for small kitchens</a>
+			<a href="/grinders">Grinders</a>
+			<a href="/guide">Click here</a>
+			<a href="/guide#more">Read more »</a>
+			<a href="/deals"><img src="/deal.png" alt="Espresso machines on sale"></a>
+			<a href="https://other.example/espresso-machines">Espresso machines elsewhere</a>
+			</body></html>`,
+	})
+	analyzer := newTestAnalyzer(t)
+
+	analysis, err := analyzer.AnalyzeWithOptions(server.URL+"/shop", AnalyzeOptions{TargetKeyword: "Espresso Machines"})
+	if err != nil {
+		t.Fatalf("Failed to analyze URL: %v", err)
+	}
+	// The external link doesn't count; the image alt text does
+	if analysis.Links.KeywordAnchors != 3 {
+		t.Errorf("Expected 3 keyword anchors, got %d", analysis.Links.KeywordAnchors)
+	}
+	if analysis.Links.GenericAnchors != 2 {
+		t.Errorf("Expected 2 generic anchors, got %d", analysis.Links.GenericAnchors)
+	}
+	found := false
+	for _, rec := range analysis.Recommendations {
+		if strings.Contains(rec, "generic anchor") {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("Expected a generic anchor recommendation, got %v", analysis.Recommendations)
+	}
+
+	// Without a keyword nothing is keyword-matched, and results are cached apart
+	plain, err := analyzer.Analyze(server.URL + "/shop")
+	if err != nil {
+		t.Fatalf("Failed to analyze URL: %v", err)
+	}
+	if plain.Links.KeywordAnchors != 0 || plain.Links.TargetKeyword != "" {
+		t.Errorf("Expected no keyword anchors without a target keyword, got %+v", plain.Links)
+	}
+}
+
+func TestLinkRelAndAnchorText(t *testing.T) {
+	server := newSiteServer(t, map[string]string{
+		"/post": `<html><head><title>Post</title></head><body>
+			<a href="/about">About us</a>
+			<a href="https://ads.example/" rel="sponsored nofollow">Our sponsor</a>
+			<a href="https://forum.example/u/1" rel="UGC">A commenter</a>
+			<a href="https://other.example/" rel="nofollow">Click here</a>
+			<a href="/cart"><img src="/cart.png"></a>
+			<a href="/search" aria-label="Search"><svg></svg></a>
+			<a href="#top"></a>
+			</body></html>`,
+	})
+
+	analysis, err := newTestAnalyzer(t).Analyze(server.URL + "/post")
+	if err != nil {
+		t.Fatalf("Failed to analyze URL: %v", err)
+	}
+	links := analysis.Links
+	if links.NofollowLinks != 2 || links.SponsoredLinks != 1 || links.UGCLinks != 1 {
+		t.Errorf("Expected 2 nofollow, 1 sponsored and 1 ugc link, got %d, %d and %d",
+			links.NofollowLinks, links.SponsoredLinks, links.UGCLinks)
+	}
+
+	// The aria-label names the icon link; fragment links are skipped
+	want := []string{
+		`generic anchor text "click here": https://other.example/`,
+		"empty anchor text: " + server.URL + "/cart",
+	}
+	if strings.Join(links.AnchorTextIssues, "\n") != strings.Join(want, "\n") {
+		t.Errorf("Expected anchor text issues %q, got %q", want, links.AnchorTextIssues)
+	}
+	if !strings.Contains(strings.Join(analysis.Recommendations, "\n"), "Give 2 link(s) descriptive anchor text") {
+		t.Errorf("Expected an anchor text recommendation, got %v", analysis.Recommendations)
+	}
+}
+
+// recordingPublisher is a ResultPublisher that hands messages to a channel
+type recordingPublisher struct {
+	messages chan ResultMessage
+}
+
+func (p *recordingPublisher) Publish(ctx context.Context, msg ResultMessage) error {
+	p.messages <- msg
+	return nil
+}
+
+func TestResultPublisher(t *testing.T) {
+	server := newSiteServer(t, map[string]string{
+		"/": `<html><head><title>Published</title></head><body>Published</body></html>`,
+	})
+	analyzer := newTestAnalyzer(t)
+	publisher := &recordingPublisher{messages: make(chan ResultMessage, 4)}
+	analyzer.SetResultPublisher(publisher)
+
+	analysis, err := analyzer.AnalyzeWithOptions(server.URL+"/", AnalyzeOptions{Device: DeviceMobile})
+	if err != nil {
+		t.Fatalf("Failed to analyze URL: %v", err)
+	}
+
+	select {
+	case msg := <-publisher.messages:
+		if msg.URL != server.URL+"/" || msg.Device != DeviceMobile || msg.CompletedAt.IsZero() {
+			t.Errorf("Expected the analysis metadata, got %+v", msg)
+		}
+		if msg.Analysis != analysis || msg.Analysis.Title.Title != "Published" {
+			t.Errorf("Expected the full result, got %+v", msg.Analysis)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Expected the analysis to be published")
+	}
+
+	// Cache hits aren't published again
+	if _, err := analyzer.AnalyzeWithOptions(server.URL+"/", AnalyzeOptions{Device: DeviceMobile}); err != nil {
+		t.Fatalf("Failed to analyze URL: %v", err)
+	}
+	select {
+	case msg := <-publisher.messages:
+		t.Errorf("Expected no message for a cache hit, got %+v", msg)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	// A blocked publisher never delays analyses
+	analyzer.SetResultPublisher(&recordingPublisher{messages: make(chan ResultMessage)})
+	start := time.Now()
+	if _, err := analyzer.Analyze(server.URL + "/"); err != nil {
+		t.Fatalf("Failed to analyze URL: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Expected publishing to be asynchronous, analysis took %v", elapsed)
+	}
+}
+
+func TestOGURLCanonicalMismatch(t *testing.T) {
+	server := newSiteServer(t, map[string]string{
+		"/diverging": `<html><head><title>Diverging</title>
+			<link rel="canonical" href="/products/shoes">
+			<meta property="og:url" content="https://promo.example.com/shoes?utm_source=og">
+			</head><body>Shoes</body></html>`,
+		"/matching": `<html><head><title>Matching</title>
+			<link rel="canonical" href="/products/boots">
+			<meta property="og:url" content="/products/boots#top">
+			</head><body>Boots</body></html>`,
+		"/no-og": `<html><head><title>No OG</title>
+			<link rel="canonical" href="/products/sandals">
+			</head><body>Sandals</body></html>`,
+	})
+	analyzer := newTestAnalyzer(t)
+	hasRecommendation := func(analysis *SEOAnalysis) bool {
+		for _, rec := range analysis.Recommendations {
+			if strings.Contains(rec, "Align og:url") {
+				return true
+			}
+		}
+		return false
+	}
+
+	diverging, err := analyzer.Analyze(server.URL + "/diverging")
+	if err != nil {
+		t.Fatalf("Failed to analyze URL: %v", err)
+	}
+	if !diverging.Social.OGURLCanonicalMismatch || !hasRecommendation(diverging) {
+		t.Errorf("Expected a mismatch for differing og:url and canonical, got %+v", diverging.Social)
+	}
+	if diverging.Social.OGURL != "https://promo.example.com/shoes?utm_source=og" ||
+		diverging.Meta.Canonical != server.URL+"/products/shoes" {
+		t.Errorf("Expected both URLs to be reported, got %q and %q", diverging.Social.OGURL, diverging.Meta.Canonical)
+	}
+
+	// Relative og:url and fragments are normalized before comparing
+	for _, path := range []string{"/matching", "/no-og"} {
+		analysis, err := analyzer.Analyze(server.URL + path)
+		if err != nil {
+			t.Fatalf("Failed to analyze URL: %v", err)
+		}
+		if analysis.Social.OGURLCanonicalMismatch || hasRecommendation(analysis) {
+			t.Errorf("%s: expected no mismatch, got %+v", path, analysis.Social)
+		}
+	}
+}
+
+func TestSocialTags(t *testing.T) {
+	server := newSiteServer(t, map[string]string{
+		"/full": `<html><head><title>Full</title>
+			<meta property="og:title" content="Full preview">
+			<meta property="og:description" content="Everything declared">
+			<meta property="og:image" content="/share.png">
+			<meta property="og:image:width" content="1200">
+			<meta property="og:image:height" content="630">
+			<meta property="og:url" content="/full">
+			<meta property="og:type" content="article">
+			<meta name="twitter:card" content="summary_large_image">
+			<meta name="twitter:title" content="Full preview">
+			<meta name="twitter:image" content="/share.png">
+			</head><body>Full</body></html>`,
+		"/bare": `<html><head><title>Bare</title>
+			<meta property="og:title" content="Only a title">
+			<meta property="og:description" content="">
+			</head><body>Bare</body></html>`,
+	})
+	analyzer := newTestAnalyzer(t)
+	socialRecommendations := func(analysis *SEOAnalysis) []string {
+		var recs []string
+		for _, rec := range analysis.Recommendations {
+			if strings.Contains(rec, "og:") || strings.Contains(rec, "twitter:") || strings.Contains(rec, "Open Graph") {
+				recs = append(recs, rec)
+			}
+		}
+		return recs
+	}
+
+	full, err := analyzer.Analyze(server.URL + "/full")
+	if err != nil {
+		t.Fatalf("Failed to analyze URL: %v", err)
+	}
+	social := full.Social
+	if social.OGTitle != "Full preview" || social.OGType != "article" || social.TwitterCard != "summary_large_image" {
+		t.Errorf("Expected the social tags to be extracted, got %+v", social)
+	}
+	if len(social.MissingOpenGraph) != 0 || len(social.MissingTwitterCard) != 0 {
+		t.Errorf("Expected no missing tags, got %v and %v", social.MissingOpenGraph, social.MissingTwitterCard)
+	}
+	if recs := socialRecommendations(full); len(recs) != 0 {
+		t.Errorf("Expected no social recommendations, got %v", recs)
+	}
+
+	bare, err := analyzer.Analyze(server.URL + "/bare")
+	if err != nil {
+		t.Fatalf("Failed to analyze URL: %v", err)
+	}
+	// Empty tags count as missing
+	expectedOG := []string{"og:description", "og:image", "og:url", "og:type"}
+	if fmt.Sprint(bare.Social.MissingOpenGraph) != fmt.Sprint(expectedOG) {
+		t.Errorf("Expected missing Open Graph tags %v, got %v", expectedOG, bare.Social.MissingOpenGraph)
+	}
+	if len(bare.Social.MissingTwitterCard) != 3 {
+		t.Errorf("Expected all Twitter Card tags missing, got %v", bare.Social.MissingTwitterCard)
+	}
+	recs := strings.Join(socialRecommendations(bare), "\n")
+	if !strings.Contains(recs, "og:image") || !strings.Contains(recs, "twitter:card") {
+		t.Errorf("Expected og:image and twitter:card recommendations, got %q", recs)
+	}
+}
+
+func TestStructuredData(t *testing.T) {
+	server := newSiteServer(t, map[string]string{
+		"/": `<html><head>
+			<script type="application/ld+json">
+			{"@context": "https://schema.org", "@type": "Product", "name": "Shoe",
+			 "offers": {"@type": "Offer", "price": "10"}}
+			</script>
+			<script type="application/ld+json">
+			{"@context": "https://schema.org", "@graph": [{"@type": "Organization"}, {"@type": ["Article", "NewsArticle"]}]}
+			</script>
+			<script type="application/ld+json">{"@type": "Event",}</script>
+			</head><body></body></html>`,
+	})
+	analyzer := newTestAnalyzer(t)
+
+	analysis, err := analyzer.Analyze(server.URL + "/")
+	if err != nil {
+		t.Fatalf("Failed to analyze URL: %v", err)
+	}
+	data := analysis.StructuredData
+	if len(data.Blocks) != 3 || data.InvalidBlocks != 1 {
+		t.Fatalf("Expected 3 blocks with 1 invalid, got %+v", data)
+	}
+	if !data.Blocks[0].Valid || strings.Join(data.Blocks[0].Types, ",") != "Offer,Product" {
+		t.Errorf("Expected the first block to declare Offer and Product, got %+v", data.Blocks[0])
+	}
+	if data.Blocks[2].Valid || data.Blocks[2].Error == "" {
+		t.Errorf("Expected the malformed block to carry its parse error, got %+v", data.Blocks[2])
+	}
+	if got := strings.Join(data.Types, ","); got != "Article,NewsArticle,Offer,Organization,Product" {
+		t.Errorf("Unexpected types %q", got)
+	}
+	found := false
+	for _, rec := range analysis.Recommendations {
+		found = found || strings.Contains(rec, "JSON-LD block(s) that fail to parse")
+	}
+	if !found {
+		t.Errorf("Expected a recommendation about the invalid block, got %v", analysis.Recommendations)
+	}
+}
+
+func TestIconReachability(t *testing.T) {
+	server := newSiteServer(t, map[string]string{
+		"/": `<html><head>
+			<link rel="shortcut icon" href="/missing.ico">
+			<link rel="apple-touch-icon" href="icons/touch.png">
+			<link rel="manifest" href="/site.webmanifest">
+			</head><body></body></html>`,
+		"/icons/touch.png":  "png",
+		"/site.webmanifest": `{"name": "Test"}`,
+	})
+	analyzer := newTestAnalyzer(t)
+
+	// Unverified by default
+	analysis, err := analyzer.Analyze(server.URL + "/")
+	if err != nil {
+		t.Fatalf("Failed to analyze URL: %v", err)
+	}
+	if analysis.Icons.Verified || len(analysis.Icons.Broken) != 0 {
+		t.Errorf("Expected icons to be left unverified, got %+v", analysis.Icons)
+	}
+	if analysis.Icons.AppleTouchIcon != server.URL+"/icons/touch.png" {
+		t.Errorf("Expected the relative icon href to be resolved, got %q", analysis.Icons.AppleTouchIcon)
+	}
+
+	analyzer.SetVerifyIcons(true)
+	analyzer.ClearCache()
+	analysis, err = analyzer.Analyze(server.URL + "/")
+	if err != nil {
+		t.Fatalf("Failed to analyze URL: %v", err)
+	}
+	if !analysis.Icons.Verified {
+		t.Fatal("Expected icons to be verified")
+	}
+	if len(analysis.Icons.Broken) != 1 || analysis.Icons.Broken[0] != server.URL+"/missing.ico" {
+		t.Errorf("Expected only the favicon to be broken, got %v", analysis.Icons.Broken)
+	}
+	found := false
+	for _, rec := range analysis.Recommendations {
+		found = found || strings.Contains(rec, "missing.ico")
+	}
+	if !found {
+		t.Errorf("Expected a recommendation for the broken favicon, got %v", analysis.Recommendations)
+	}
+}
+
+func TestFaviconDetection(t *testing.T) {
+	hasRecommendation := func(analysis *SEOAnalysis, text string) bool {
+		for _, rec := range analysis.Recommendations {
+			if strings.Contains(rec, text) {
+				return true
+			}
+		}
+		return false
+	}
+
+	// Declared icons
+	server := newSiteServer(t, map[string]string{
+		"/": `<html><head>
+			<link rel="shortcut icon" href="/static/icon.png">
+			<link rel="apple-touch-icon" href="/static/touch.png">
+			</head><body></body></html>`,
+	})
+	analysis, err := newTestAnalyzer(t).Analyze(server.URL + "/")
+	if err != nil {
+		t.Fatalf("Failed to analyze URL: %v", err)
+	}
+	if !analysis.Icons.HasFavicon || analysis.Icons.FaviconFallback || !analysis.Icons.HasAppleTouchIcon {
+		t.Errorf("Expected the declared favicon and touch icon, got %+v", analysis.Icons)
+	}
+
+	// No declaration, but /favicon.ico loads
+	server = newSiteServer(t, map[string]string{
+		"/":            `<html><head><title>Fallback</title></head><body></body></html>`,
+		"/favicon.ico": "ico",
+	})
+	analysis, err = newTestAnalyzer(t).Analyze(server.URL + "/")
+	if err != nil {
+		t.Fatalf("Failed to analyze URL: %v", err)
+	}
+	if !analysis.Icons.HasFavicon || !analysis.Icons.FaviconFallback || analysis.Icons.Favicon != server.URL+"/favicon.ico" {
+		t.Errorf("Expected the /favicon.ico fallback, got %+v", analysis.Icons)
+	}
+	if analysis.Icons.HasAppleTouchIcon || !hasRecommendation(analysis, "apple-touch-icon") {
+		t.Errorf("Expected a recommendation for the missing touch icon, got %v", analysis.Recommendations)
+	}
+	if hasRecommendation(analysis, "Add a favicon") {
+		t.Error("Expected no favicon recommendation when /favicon.ico loads")
+	}
+
+	// Neither
+	server = newSiteServer(t, map[string]string{
+		"/": `<html><head><title>None</title></head><body></body></html>`,
+	})
+	analysis, err = newTestAnalyzer(t).Analyze(server.URL + "/")
+	if err != nil {
+		t.Fatalf("Failed to analyze URL: %v", err)
+	}
+	if analysis.Icons.HasFavicon || analysis.Icons.Favicon != "" {
+		t.Errorf("Expected no favicon, got %+v", analysis.Icons)
+	}
+	if !hasRecommendation(analysis, "Add a favicon") {
+		t.Errorf("Expected a favicon recommendation, got %v", analysis.Recommendations)
+	}
+}
+
+func TestAnalyzeHTML(t *testing.T) {
+	var fetchedDraft atomic.Bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/":
+			fetchedDraft.Store(true)
+		case "/about":
+			w.Header().Set("Content-Type", "text/html")
+			fmt.Fprint(w, "<html></html>")
+			return
+		}
+		http.NotFound(w, r)
+	}))
+	defer server.Close()
+
+	html := []byte(`<html><head><title>Staging draft</title></head><body>
+		<h1>Draft</h1><a href="/about">About</a><a href="/missing">Missing</a></body></html>`)
+	analyzer := newTestAnalyzer(t)
+
+	analysis, err := analyzer.AnalyzeHTML(context.Background(), html, server.URL, AnalyzeOptions{})
+	if err != nil {
+		t.Fatalf("Failed to analyze HTML: %v", err)
+	}
+	if fetchedDraft.Load() {
+		t.Error("Expected the supplied HTML to be analyzed without fetching the base URL")
+	}
+	if analysis.Title.Title != "Staging draft" || analysis.URL != server.URL {
+		t.Errorf("Unexpected title %q for %s", analysis.Title.Title, analysis.URL)
+	}
+	if analysis.Performance.PageSize != len(html) || analysis.Performance.LoadTime != 0 {
+		t.Errorf("Expected size %d and no load time, got %d and %d",
+			len(html), analysis.Performance.PageSize, analysis.Performance.LoadTime)
+	}
+	if analysis.Links.InternalLinks != 2 || analysis.Links.BrokenLinks != 1 {
+		t.Errorf("Expected relative links resolved against the base URL (2 internal, 1 broken), got %d and %d",
+			analysis.Links.InternalLinks, analysis.Links.BrokenLinks)
+	}
+
+	if _, err := analyzer.AnalyzeHTML(context.Background(), html, "/relative", AnalyzeOptions{}); err == nil {
+		t.Error("Expected a relative base URL to be rejected")
+	}
+}
+
+func TestScoreGrade(t *testing.T) {
+	for score, want := range map[float64]string{
+		100: "A", 90: "A", 89.99: "B", 80: "B", 79.5: "C", 70: "C", 65: "D", 60: "D", 59.99: "F", 0: "F",
+	} {
+		if got := scoreGrade(score); got != want {
+			t.Errorf("scoreGrade(%v) = %q, want %q", score, got, want)
+		}
+	}
+}
+
+func TestInternalLinkClassification(t *testing.T) {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host := strings.TrimPrefix(server.URL, "http://")
+		w.Header().Set("Content-Type", "text/html")
+		fmt.Fprintf(w, `<html><head><title>Links</title></head><body>
+			<a href="/a">Relative</a>
+			<a href="http://%[1]s/b">Absolute</a>
+			<a href="//%[1]s/c">Protocol-relative</a>
+			<a href="https://elsewhere.example/">External</a>
+			</body></html>`, host)
+	}))
+	defer server.Close()
+
+	analysis, err := newTestAnalyzer(t).Analyze(server.URL + "/page")
+	if err != nil {
+		t.Fatalf("Failed to analyze URL: %v", err)
+	}
+	if analysis.Links.InternalLinks != 3 || analysis.Links.ExternalLinks != 1 {
+		t.Errorf("Expected 3 internal and 1 external link, got %d and %d",
+			analysis.Links.InternalLinks, analysis.Links.ExternalLinks)
+	}
+}
+
+func TestRelativeLinkResolution(t *testing.T) {
+	server := newSiteServer(t, map[string]string{
+		"/blog/post": `<html><head><title>Post</title></head><body>
+			<a href="/about">Root-relative</a>
+			<a href="../page.html">Parent</a>
+			<a href="sibling">Sibling</a>
+			<a href="mailto:team@example.com">Mail</a>
+			</body></html>`,
+		"/about":        `<html><body>About</body></html>`,
+		"/page.html":    `<html><body>Page</body></html>`,
+		"/blog/sibling": `<html><body>Sibling</body></html>`,
+	})
+
+	analysis, err := newTestAnalyzer(t).Analyze(server.URL + "/blog/post")
+	if err != nil {
+		t.Fatalf("Failed to analyze URL: %v", err)
+	}
+	want := []string{server.URL + "/about", server.URL + "/page.html", server.URL + "/blog/sibling"}
+	if strings.Join(analysis.Links.InternalHrefs, " ") != strings.Join(want, " ") {
+		t.Errorf("Expected internal links %v, got %v", want, analysis.Links.InternalHrefs)
+	}
+	// Each resolves to a page that exists
+	if analysis.Links.BrokenLinks != 0 {
+		t.Errorf("Expected no broken links, got %d", analysis.Links.BrokenLinks)
+	}
+}
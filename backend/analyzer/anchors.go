@@ -0,0 +1,108 @@
+package analyzer
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// genericAnchorTexts are anchors that tell neither users nor search engines
+// anything about the target page
+var genericAnchorTexts = map[string]bool{
+	"click here": true, "here": true, "read more": true, "more": true,
+	"learn more": true, "this": true, "link": true, "this link": true,
+	"continue": true, "continue reading": true, "go": true, "details": true,
+	"more info": true, "see more": true, "click": true,
+}
+
+// anchorText returns the normalized visible text of a link, falling back to
+// the alt text of a linked image
+func anchorText(s *goquery.Selection) string {
+	text := strings.Join(strings.Fields(s.Text()), " ")
+	if text == "" {
+		text, _ = s.Find("img[alt]").First().Attr("alt")
+	}
+	return strings.ToLower(strings.TrimSpace(text))
+}
+
+// analyzeAnchors counts the internal links whose anchor text contains the
+// target keyword and those using generic anchors like "click here"
+func analyzeAnchors(doc *goquery.Document, baseURL, targetKeyword string) (keywordAnchors, genericAnchors int) {
+	keyword := strings.ToLower(strings.Join(strings.Fields(targetKeyword), " "))
+
+	doc.Find("a[href]").Each(func(_ int, s *goquery.Selection) {
+		href, _ := s.Attr("href")
+		href = strings.TrimSpace(href)
+		if href == "" || strings.HasPrefix(href, "#") || !isInternalHref(baseURL, href) {
+			return
+		}
+
+		text := strings.Trim(anchorText(s), ".!?:»›→ ")
+		if genericAnchorTexts[text] {
+			genericAnchors++
+		}
+		if keyword != "" && strings.Contains(text, keyword) {
+			keywordAnchors++
+		}
+	})
+
+	return keywordAnchors, genericAnchors
+}
+
+// maxAnchorTextIssues caps how many anchor text issues one analysis lists
+const maxAnchorTextIssues = 50
+
+// linkAttributes holds the rel qualifiers and anchor text problems of a
+// page's links
+type linkAttributes struct {
+	nofollow, sponsored, ugc int
+	anchorIssues             []string
+}
+
+// analyzeLinkAttributes counts links qualified with rel="nofollow",
+// "sponsored" or "ugc" and lists links with empty or generic anchor text.
+// A link without text can still be named by aria-label or title.
+func analyzeLinkAttributes(doc *goquery.Document, baseURL string) linkAttributes {
+	attrs := linkAttributes{anchorIssues: []string{}}
+
+	doc.Find("a[href]").Each(func(_ int, s *goquery.Selection) {
+		href := strings.TrimSpace(s.AttrOr("href", ""))
+		if href == "" || strings.HasPrefix(href, "#") || strings.HasPrefix(strings.ToLower(href), "javascript:") {
+			return
+		}
+
+		for _, rel := range strings.Fields(strings.ToLower(s.AttrOr("rel", ""))) {
+			switch rel {
+			case "nofollow":
+				attrs.nofollow++
+			case "sponsored":
+				attrs.sponsored++
+			case "ugc":
+				attrs.ugc++
+			}
+		}
+
+		if len(attrs.anchorIssues) >= maxAnchorTextIssues {
+			return
+		}
+		text := anchorText(s)
+		if text == "" {
+			text = strings.ToLower(strings.TrimSpace(s.AttrOr("aria-label", s.AttrOr("title", ""))))
+		}
+		target := resolveURL(baseURL, href)
+		if text == "" {
+			attrs.anchorIssues = append(attrs.anchorIssues, "empty anchor text: "+target)
+		} else if trimmed := strings.Trim(text, ".!?:»›→ "); genericAnchorTexts[trimmed] {
+			attrs.anchorIssues = append(attrs.anchorIssues, fmt.Sprintf("generic anchor text %q: %s", trimmed, target))
+		}
+	})
+
+	return attrs
+}
+
+// isInternalHref reports whether href, resolved against baseURL, points to
+// the analyzed site's host (see sameSite)
+func isInternalHref(baseURL, href string) bool {
+	return sameSite(baseURL, resolveURL(baseURL, href))
+}
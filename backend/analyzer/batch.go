@@ -0,0 +1,123 @@
+package analyzer
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// MaxBatchSize is the most URLs AnalyzeBatch accepts in one call
+const MaxBatchSize = 20
+
+// ErrBatchSize is returned for empty batches and batches over MaxBatchSize
+var ErrBatchSize = fmt.Errorf("a batch must contain between 1 and %d URLs", MaxBatchSize)
+
+// BatchResult is the outcome of one URL of a batch. Exactly one of Analysis
+// and Error is set.
+type BatchResult struct {
+	URL      string       `json:"url"`
+	Analysis *SEOAnalysis `json:"analysis,omitempty"`
+	Error    string       `json:"error,omitempty"`
+
+	err     error
+	started time.Time
+}
+
+// Err returns the error the URL failed with, or nil
+func (r BatchResult) Err() error {
+	return r.err
+}
+
+// Started returns when the analysis of the URL began
+func (r BatchResult) Started() time.Time {
+	return r.started
+}
+
+// BatchAnalysis holds the per-URL results of a batch, in request order, and
+// the cross-page checks run over the pages that succeeded
+type BatchAnalysis struct {
+	Results   []BatchResult `json:"results"`
+	Succeeded int           `json:"succeeded"`
+	Failed    int           `json:"failed"`
+	Site      SiteAnalysis  `json:"site"`
+}
+
+// SetBatchWorkers sets how many URLs of one batch are analyzed at the same
+// time
+func (a *Analyzer) SetBatchWorkers(n int) {
+	if n <= 0 {
+		return
+	}
+	a.configMutex.Lock()
+	defer a.configMutex.Unlock()
+	a.batchWorkers = n
+}
+
+// AnalyzeBatch analyzes every URL with opts using a bounded pool of workers.
+// Each URL gets its own timeout, and a failing URL does not affect the
+// others. Batch analyses are queued with PriorityBatch unless opts sets a
+// priority.
+func (a *Analyzer) AnalyzeBatch(urls []string, opts AnalyzeOptions) (*BatchAnalysis, error) {
+	return a.AnalyzeBatchContext(context.Background(), urls, opts)
+}
+
+// AnalyzeBatchContext is AnalyzeBatch bounded by ctx as well, so cancelling
+// ctx fails the URLs not analyzed yet
+func (a *Analyzer) AnalyzeBatchContext(ctx context.Context, urls []string, opts AnalyzeOptions) (*BatchAnalysis, error) {
+	if len(urls) == 0 || len(urls) > MaxBatchSize {
+		return nil, ErrBatchSize
+	}
+	if opts.Priority == "" {
+		opts.Priority = PriorityBatch
+	}
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+
+	a.configMutex.RLock()
+	workers := a.batchWorkers
+	a.configMutex.RUnlock()
+	if workers > len(urls) {
+		workers = len(urls)
+	}
+
+	batch := &BatchAnalysis{Results: make([]BatchResult, len(urls))}
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for idx := range jobs {
+				result := BatchResult{URL: urls[idx], started: time.Now()}
+				analysis, err := a.AnalyzeWithOptionsContext(ctx, urls[idx], opts)
+				if err != nil {
+					result.err = err
+					result.Error = err.Error()
+				} else {
+					result.Analysis = analysis
+				}
+				batch.Results[idx] = result
+			}
+		}()
+	}
+	for i := range urls {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	pages := make(map[string]*SEOAnalysis)
+	for _, result := range batch.Results {
+		if result.Analysis != nil {
+			pages[result.URL] = result.Analysis
+			batch.Succeeded++
+		} else {
+			batch.Failed++
+		}
+	}
+	batch.Site = AnalyzeSite(pages)
+
+	return batch, nil
+}
@@ -0,0 +1,82 @@
+package analyzer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestAnalyzeBatch(t *testing.T) {
+	var active, peak atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/robots.txt" || r.URL.Path == "/missing" {
+			http.NotFound(w, r)
+			return
+		}
+		n := active.Add(1)
+		defer active.Add(-1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+		w.Header().Set("Content-Type", "text/html")
+		fmt.Fprintf(w, `<html><head><title>Page %s</title></head><body></body></html>`, r.URL.Path)
+	}))
+	t.Cleanup(server.Close)
+
+	analyzer := newTestAnalyzer(t)
+	analyzer.SetBatchWorkers(2)
+
+	urls := []string{server.URL + "/missing"}
+	for i := 0; i < 6; i++ {
+		urls = append(urls, fmt.Sprintf("%s/page/%d", server.URL, i))
+	}
+	batch, err := analyzer.AnalyzeBatch(urls, AnalyzeOptions{})
+	if err != nil {
+		t.Fatalf("Failed to analyze batch: %v", err)
+	}
+
+	if batch.Succeeded != 6 || batch.Failed != 1 || len(batch.Results) != len(urls) {
+		t.Fatalf("Expected 6 successes and 1 failure, got %d/%d", batch.Succeeded, batch.Failed)
+	}
+	if batch.Results[0].Error == "" || batch.Results[0].Analysis != nil {
+		t.Errorf("Expected the missing page to carry an error, got %+v", batch.Results[0])
+	}
+	for i, result := range batch.Results[1:] {
+		if result.URL != urls[i+1] || result.Analysis == nil {
+			t.Errorf("Expected result %d for %s in request order, got %+v", i+1, urls[i+1], result)
+		}
+	}
+	if batch.Site.Pages != 6 {
+		t.Errorf("Expected site checks over the 6 successful pages, got %d", batch.Site.Pages)
+	}
+	if got := peak.Load(); got > 2 {
+		t.Errorf("Expected at most 2 concurrent fetches, got %d", got)
+	}
+
+	if _, err := analyzer.AnalyzeBatch(make([]string, MaxBatchSize+1), AnalyzeOptions{}); !errors.Is(err, ErrBatchSize) {
+		t.Errorf("Expected ErrBatchSize for an oversized batch, got %v", err)
+	}
+	if _, err := analyzer.AnalyzeBatch(nil, AnalyzeOptions{}); !errors.Is(err, ErrBatchSize) {
+		t.Errorf("Expected ErrBatchSize for an empty batch, got %v", err)
+	}
+
+	// A cancelled context fails every URL instead of analyzing it
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	batch, err = analyzer.AnalyzeBatchContext(ctx, []string{server.URL + "/?cancelled"}, AnalyzeOptions{})
+	if err != nil {
+		t.Fatalf("Failed to run batch: %v", err)
+	}
+	if batch.Failed != 1 || !errors.Is(batch.Results[0].Err(), context.Canceled) {
+		t.Errorf("Expected the URL to fail with the cancelled context, got %+v", batch.Results[0])
+	}
+}
@@ -0,0 +1,175 @@
+package analyzer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is returned without contacting a host that has failed
+// repeatedly, until its cooldown has elapsed
+var ErrCircuitOpen = errors.New("circuit open: host is failing repeatedly")
+
+// Circuit breaker defaults
+const (
+	defaultBreakerThreshold = 5
+	defaultBreakerWindow    = time.Minute
+	defaultBreakerCooldown  = 30 * time.Second
+)
+
+type circuitState int
+
+const (
+	circuitClosed circuitState = iota
+	circuitOpen
+	circuitHalfOpen
+)
+
+// hostCircuit tracks recent failures for one host
+type hostCircuit struct {
+	state        circuitState
+	failures     int
+	firstFailure time.Time
+	openedAt     time.Time
+	trialStarted time.Time
+}
+
+// circuitBreaker short-circuits requests to hosts with too many consecutive
+// failures. After the cooldown a single trial request is let through
+// (half-open); its outcome closes or re-opens the circuit.
+type circuitBreaker struct {
+	mu        sync.Mutex
+	threshold int // 0 disables the breaker
+	window    time.Duration
+	cooldown  time.Duration
+	hosts     map[string]*hostCircuit
+	now       func() time.Time
+}
+
+func newCircuitBreaker() *circuitBreaker {
+	return &circuitBreaker{
+		threshold: defaultBreakerThreshold,
+		window:    defaultBreakerWindow,
+		cooldown:  defaultBreakerCooldown,
+		hosts:     make(map[string]*hostCircuit),
+		now:       time.Now,
+	}
+}
+
+// allow returns ErrCircuitOpen if requests to host should not be made
+func (b *circuitBreaker) allow(host string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	c, ok := b.hosts[host]
+	if b.threshold <= 0 || !ok {
+		return nil
+	}
+
+	now := b.now()
+	switch c.state {
+	case circuitOpen:
+		if now.Sub(c.openedAt) < b.cooldown {
+			return fmt.Errorf("%w (%s)", ErrCircuitOpen, host)
+		}
+		c.state = circuitHalfOpen
+		c.trialStarted = now
+	case circuitHalfOpen:
+		// Only one trial at a time, unless the last one never reported back
+		if now.Sub(c.trialStarted) < b.cooldown {
+			return fmt.Errorf("%w (%s)", ErrCircuitOpen, host)
+		}
+		c.trialStarted = now
+	}
+	return nil
+}
+
+// success records a request that reached host, closing its circuit
+func (b *circuitBreaker) success(host string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	delete(b.hosts, host)
+}
+
+// failure records a failed request to host
+func (b *circuitBreaker) failure(host string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.threshold <= 0 {
+		return
+	}
+
+	now := b.now()
+	c, ok := b.hosts[host]
+	if !ok {
+		c = &hostCircuit{}
+		b.hosts[host] = c
+	}
+
+	switch c.state {
+	case circuitHalfOpen:
+		c.state = circuitOpen
+		c.openedAt = now
+	case circuitClosed:
+		if c.failures == 0 || now.Sub(c.firstFailure) > b.window {
+			c.failures = 0
+			c.firstFailure = now
+		}
+		c.failures++
+		if c.failures >= b.threshold {
+			c.state = circuitOpen
+			c.openedAt = now
+		}
+	}
+}
+
+// prune forgets hosts whose failures are too old to matter
+func (b *circuitBreaker) prune() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := b.now()
+	for host, c := range b.hosts {
+		if c.state == circuitClosed && now.Sub(c.firstFailure) > b.window {
+			delete(b.hosts, host)
+		}
+	}
+}
+
+// configure replaces the breaker settings; threshold 0 disables it
+func (b *circuitBreaker) configure(threshold int, window, cooldown time.Duration) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.threshold = threshold
+	b.window = window
+	b.cooldown = cooldown
+	if threshold <= 0 {
+		b.hosts = make(map[string]*hostCircuit)
+	}
+}
+
+// recordHostResult feeds the outcome of a request to host into the circuit
+// breaker. Transport errors and 5xx responses count as failures; requests
+// abandoned by the caller don't count either way.
+func (a *Analyzer) recordHostResult(ctx context.Context, host string, err error, status int) {
+	switch {
+	case err != nil && ctx.Err() == context.Canceled:
+	case err != nil || status >= 500:
+		a.breaker.failure(host)
+	default:
+		a.breaker.success(host)
+	}
+}
+
+// SetCircuitBreaker configures the per-host circuit breaker: after
+// threshold consecutive failures within window, requests to the host fail
+// fast with ErrCircuitOpen for cooldown. A threshold of 0 disables it.
+func (a *Analyzer) SetCircuitBreaker(threshold int, window, cooldown time.Duration) {
+	if threshold < 0 || window <= 0 || cooldown <= 0 {
+		return
+	}
+	a.breaker.configure(threshold, window, cooldown)
+}
@@ -0,0 +1,75 @@
+package analyzer
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	var mu sync.Mutex
+	fetches := 0
+	healthy := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		fetches++
+		ok := healthy
+		mu.Unlock()
+		if ok {
+			w.Header().Set("Content-Type", "text/html")
+			fmt.Fprint(w, testPage)
+			return
+		}
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	analyzer := newTestAnalyzer(t)
+	analyzer.SetCircuitBreaker(3, time.Minute, 30*time.Second)
+	now := time.Now()
+	analyzer.breaker.now = func() time.Time { return now }
+
+	// Distinct paths so the negative cache doesn't answer for us
+	for i := 0; i < 3; i++ {
+		_, err := analyzer.Analyze(fmt.Sprintf("%s/fail/%d", server.URL, i))
+		if err == nil || errors.Is(err, ErrCircuitOpen) {
+			t.Fatalf("Expected fetch %d to reach the failing host, got %v", i, err)
+		}
+	}
+
+	mu.Lock()
+	before := fetches
+	mu.Unlock()
+	_, err := analyzer.Analyze(server.URL + "/fail/open")
+	if !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("Expected ErrCircuitOpen after 3 failures, got %v", err)
+	}
+	if analyzer.isLinkAccessible(server.URL + "/some-link") {
+		t.Error("Expected link checks to the host to fail fast")
+	}
+	mu.Lock()
+	if fetches != before {
+		t.Errorf("Expected no requests while the circuit is open, got %d", fetches-before)
+	}
+	healthy = true
+	mu.Unlock()
+
+	// Still open until the cooldown elapses
+	now = now.Add(29 * time.Second)
+	if _, err := analyzer.Analyze(server.URL + "/recovered/1"); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("Expected the circuit to stay open during cooldown, got %v", err)
+	}
+
+	// Half-open trial succeeds and closes the circuit
+	now = now.Add(2 * time.Second)
+	if _, err := analyzer.Analyze(server.URL + "/recovered/2"); err != nil {
+		t.Fatalf("Expected the trial request to succeed, got %v", err)
+	}
+	if _, err := analyzer.Analyze(server.URL + "/recovered/3"); err != nil {
+		t.Fatalf("Expected the circuit to be closed after recovery, got %v", err)
+	}
+}
@@ -0,0 +1,159 @@
+package analyzer
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// AnalysisCache stores finished analyses under their cache key (see
+// AnalyzeOptions.cacheKey). The in-memory cache is the default; RedisCache
+// shares results between replicas.
+type AnalysisCache interface {
+	Get(key string) (*SEOAnalysis, bool)
+	Set(key string, analysis *SEOAnalysis, ttl time.Duration)
+	Delete(key string)
+	Clear()
+}
+
+// CacheStatus is how the cache served an analysis request, as reported in
+// the X-Cache header
+type CacheStatus string
+
+const (
+	// CacheHit means the analysis came from the cache
+	CacheHit CacheStatus = "HIT"
+	// CacheMiss means the page was analyzed and the result cached
+	CacheMiss CacheStatus = "MISS"
+	// CacheBypass means a refresh skipped the cache
+	CacheBypass CacheStatus = "BYPASS"
+	// CacheNegative means a recent fetch failure was returned again
+	CacheNegative CacheStatus = "NEGATIVE"
+)
+
+// memoryCache is the default process-local AnalysisCache. It evicts the
+// least recently used entry once it holds more than maxSize.
+type memoryCache struct {
+	mutex   sync.Mutex
+	items   map[string]*list.Element
+	order   *list.List // of *memoryCacheItem, most recently used first
+	maxSize int
+	evicted func(url string)
+}
+
+// memoryCacheItem is one entry of the LRU list
+type memoryCacheItem struct {
+	key   string
+	entry cacheEntry
+}
+
+// newMemoryCache creates a cache holding at most maxSize entries; evicted
+// is called with the URL of each entry dropped for space or expiry
+func newMemoryCache(maxSize int, evicted func(url string)) *memoryCache {
+	return &memoryCache{
+		items:   make(map[string]*list.Element),
+		order:   list.New(),
+		maxSize: maxSize,
+		evicted: evicted,
+	}
+}
+
+// Get returns the analysis stored under key unless it has expired, marking
+// it as recently used
+func (c *memoryCache) Get(key string) (*SEOAnalysis, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	element, found := c.items[key]
+	if !found {
+		return nil, false
+	}
+	item := element.Value.(*memoryCacheItem)
+	if time.Now().After(item.entry.expires) {
+		c.remove(element)
+		return nil, false
+	}
+	c.order.MoveToFront(element)
+	return item.entry.analysis, true
+}
+
+// Set stores analysis under key for ttl, evicting the least recently used
+// entries if the cache is full
+func (c *memoryCache) Set(key string, analysis *SEOAnalysis, ttl time.Duration) {
+	now := time.Now()
+	entry := cacheEntry{analysis: analysis, timestamp: now, expires: now.Add(ttl)}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if element, found := c.items[key]; found {
+		element.Value.(*memoryCacheItem).entry = entry
+		c.order.MoveToFront(element)
+	} else {
+		c.items[key] = c.order.PushFront(&memoryCacheItem{key: key, entry: entry})
+	}
+	c.evictOverflow()
+}
+
+// Delete removes key from the cache
+func (c *memoryCache) Delete(key string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if element, found := c.items[key]; found {
+		c.order.Remove(element)
+		delete(c.items, key)
+	}
+}
+
+// Clear removes every entry
+func (c *memoryCache) Clear() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.items = make(map[string]*list.Element)
+	c.order.Init()
+}
+
+// Len returns the number of stored entries, expired ones included
+func (c *memoryCache) Len() int {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return len(c.items)
+}
+
+// SetMaxSize changes the capacity, evicting entries if it shrank
+func (c *memoryCache) SetMaxSize(maxSize int) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.maxSize = maxSize
+	c.evictOverflow()
+}
+
+// prune drops expired entries
+func (c *memoryCache) prune() {
+	now := time.Now()
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	for element := c.order.Front(); element != nil; {
+		next := element.Next()
+		if now.After(element.Value.(*memoryCacheItem).entry.expires) {
+			c.remove(element)
+		}
+		element = next
+	}
+}
+
+// evictOverflow drops least recently used entries until the cache fits.
+// It must be called with c.mutex held.
+func (c *memoryCache) evictOverflow() {
+	for c.order.Len() > c.maxSize && c.order.Len() > 0 {
+		c.remove(c.order.Back())
+	}
+}
+
+// remove drops element and reports it as evicted. It must be called with
+// c.mutex held.
+func (c *memoryCache) remove(element *list.Element) {
+	item := c.order.Remove(element).(*memoryCacheItem)
+	delete(c.items, item.key)
+	if c.evicted != nil {
+		c.evicted(item.entry.analysis.URL)
+	}
+}
@@ -0,0 +1,74 @@
+package analyzer
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMemoryCacheLRU(t *testing.T) {
+	var evicted []string
+	cache := newMemoryCache(2, func(url string) { evicted = append(evicted, url) })
+	page := func(url string) *SEOAnalysis { return &SEOAnalysis{URL: url} }
+
+	cache.Set("a", page("a"), time.Minute)
+	cache.Set("b", page("b"), time.Minute)
+
+	// Reading "a" makes "b" the least recently used, though it is newer
+	if _, ok := cache.Get("a"); !ok {
+		t.Fatal("Expected a hit for a")
+	}
+	cache.Set("c", page("c"), time.Minute)
+	if _, ok := cache.Get("b"); ok {
+		t.Error("Expected b to be evicted as least recently used")
+	}
+	if _, ok := cache.Get("a"); !ok {
+		t.Error("Expected the recently read entry to survive")
+	}
+	if len(evicted) != 1 || evicted[0] != "b" {
+		t.Errorf("Expected one eviction of b, got %v", evicted)
+	}
+
+	// Shrinking evicts right away
+	cache.SetMaxSize(1)
+	if cache.Len() != 1 {
+		t.Errorf("Expected 1 entry after shrinking, got %d", cache.Len())
+	}
+	if _, ok := cache.Get("a"); !ok {
+		t.Error("Expected the most recently used entry to be kept")
+	}
+
+	// Expired entries are misses and pruned
+	cache.Set("short", page("short"), time.Millisecond)
+	time.Sleep(5 * time.Millisecond)
+	if _, ok := cache.Get("short"); ok {
+		t.Error("Expected an expired entry to miss")
+	}
+	cache.Set("short", page("short"), time.Millisecond)
+	time.Sleep(5 * time.Millisecond)
+	cache.prune()
+	if cache.Len() != 0 {
+		t.Errorf("Expected prune to drop the expired entry, got %d entries", cache.Len())
+	}
+
+	cache.Set("d", page("d"), time.Minute)
+	cache.Clear()
+	if cache.Len() != 0 {
+		t.Errorf("Expected Clear to empty the cache, got %d entries", cache.Len())
+	}
+}
+
+func TestSetMaxCacheSizesDoNotDeadlock(t *testing.T) {
+	analyzer := newTestAnalyzer(t)
+
+	done := make(chan struct{})
+	go func() {
+		analyzer.SetMaxCacheSize(10)
+		analyzer.SetMaxLinkCacheSize(10)
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("SetMaxCacheSize/SetMaxLinkCacheSize deadlocked")
+	}
+}
@@ -0,0 +1,174 @@
+package analyzer
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// ScoreDelta compares a score of two pages; Delta is B minus A
+type ScoreDelta struct {
+	A     float64 `json:"a"`
+	B     float64 `json:"b"`
+	Delta float64 `json:"delta"`
+}
+
+// MetricDelta compares a measurement of two pages; Delta is B minus A
+type MetricDelta struct {
+	A     int `json:"a"`
+	B     int `json:"b"`
+	Delta int `json:"delta"`
+}
+
+// RecommendationDiff splits the recommendations of two pages into the ones
+// they share and the ones only one of them gets
+type RecommendationDiff struct {
+	Both  []string `json:"both"`
+	OnlyA []string `json:"onlyA"`
+	OnlyB []string `json:"onlyB"`
+}
+
+// Comparison is a side-by-side analysis of two pages, e.g. a client's page
+// and a competitor's
+type Comparison struct {
+	URLA string       `json:"urlA"`
+	URLB string       `json:"urlB"`
+	A    *SEOAnalysis `json:"a"`
+	B    *SEOAnalysis `json:"b"`
+	// Score compares the overall scores, Categories the section scores
+	Score      ScoreDelta            `json:"score"`
+	Categories map[string]ScoreDelta `json:"categories"`
+	// Metrics compares page measurements; Differing names the ones that
+	// aren't equal
+	Metrics         map[string]MetricDelta `json:"metrics"`
+	Differing       []string               `json:"differing"`
+	Recommendations RecommendationDiff     `json:"recommendations"`
+}
+
+// Compare analyzes urlA and urlB concurrently with opts and compares the
+// results
+func (a *Analyzer) Compare(urlA, urlB string, opts AnalyzeOptions) (*Comparison, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+
+	var analysisA, analysisB *SEOAnalysis
+	var errA, errB error
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		analysisA, errA = a.AnalyzeWithOptions(urlA, opts)
+	}()
+	go func() {
+		defer wg.Done()
+		analysisB, errB = a.AnalyzeWithOptions(urlB, opts)
+	}()
+	wg.Wait()
+	if errA != nil || errB != nil {
+		return nil, &CompareError{ErrA: errA, ErrB: errB}
+	}
+
+	return CompareAnalyses(analysisA, analysisB), nil
+}
+
+// CompareError reports which of the compared URLs failed; the error of a
+// URL that was analyzed is nil
+type CompareError struct {
+	ErrA, ErrB error
+}
+
+func (e *CompareError) Error() string {
+	var parts []string
+	if e.ErrA != nil {
+		parts = append(parts, fmt.Sprintf("urlA: %v", e.ErrA))
+	}
+	if e.ErrB != nil {
+		parts = append(parts, fmt.Sprintf("urlB: %v", e.ErrB))
+	}
+	return strings.Join(parts, "; ")
+}
+
+// Unwrap returns the errors of the URLs that failed
+func (e *CompareError) Unwrap() []error {
+	var errs []error
+	for _, err := range []error{e.ErrA, e.ErrB} {
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errs
+}
+
+// CompareAnalyses compares two finished analyses
+func CompareAnalyses(analysisA, analysisB *SEOAnalysis) *Comparison {
+	scoreDelta := func(a, b float64) ScoreDelta {
+		return ScoreDelta{A: a, B: b, Delta: b - a}
+	}
+	comparison := &Comparison{
+		URLA:  analysisA.URL,
+		URLB:  analysisB.URL,
+		A:     analysisA,
+		B:     analysisB,
+		Score: scoreDelta(analysisA.Score, analysisB.Score),
+		Categories: map[string]ScoreDelta{
+			"title":       scoreDelta(float64(analysisA.Title.Score), float64(analysisB.Title.Score)),
+			"meta":        scoreDelta(float64(analysisA.Meta.Score), float64(analysisB.Meta.Score)),
+			"headers":     scoreDelta(float64(analysisA.Headers.Score), float64(analysisB.Headers.Score)),
+			"content":     scoreDelta(float64(analysisA.Content.Score), float64(analysisB.Content.Score)),
+			"performance": scoreDelta(float64(analysisA.Performance.Score), float64(analysisB.Performance.Score)),
+			"links":       scoreDelta(float64(analysisA.Links.Score), float64(analysisB.Links.Score)),
+		},
+		Metrics:   make(map[string]MetricDelta),
+		Differing: []string{},
+	}
+
+	metrics := map[string][2]int{
+		"wordCount":     {analysisA.Content.WordCount, analysisB.Content.WordCount},
+		"pageSize":      {analysisA.Performance.PageSize, analysisB.Performance.PageSize},
+		"loadTime":      {analysisA.Performance.LoadTime, analysisB.Performance.LoadTime},
+		"internalLinks": {analysisA.Links.InternalLinks, analysisB.Links.InternalLinks},
+		"externalLinks": {analysisA.Links.ExternalLinks, analysisB.Links.ExternalLinks},
+		"brokenLinks":   {analysisA.Links.BrokenLinks, analysisB.Links.BrokenLinks},
+		"totalImages":   {analysisA.Content.TotalImages, analysisB.Content.TotalImages},
+	}
+	for name, values := range metrics {
+		comparison.Metrics[name] = MetricDelta{A: values[0], B: values[1], Delta: values[1] - values[0]}
+		if values[0] != values[1] {
+			comparison.Differing = append(comparison.Differing, name)
+		}
+	}
+	sort.Strings(comparison.Differing)
+
+	comparison.Recommendations = diffRecommendations(analysisA.Recommendations, analysisB.Recommendations)
+	return comparison
+}
+
+// diffRecommendations splits recommendations by which page they apply to,
+// keeping the order they were given in
+func diffRecommendations(a, b []string) RecommendationDiff {
+	inA := make(map[string]bool, len(a))
+	for _, recommendation := range a {
+		inA[recommendation] = true
+	}
+	inB := make(map[string]bool, len(b))
+	for _, recommendation := range b {
+		inB[recommendation] = true
+	}
+
+	diff := RecommendationDiff{Both: []string{}, OnlyA: []string{}, OnlyB: []string{}}
+	for _, recommendation := range a {
+		if inB[recommendation] {
+			diff.Both = append(diff.Both, recommendation)
+		} else {
+			diff.OnlyA = append(diff.OnlyA, recommendation)
+		}
+	}
+	for _, recommendation := range b {
+		if !inA[recommendation] {
+			diff.OnlyB = append(diff.OnlyB, recommendation)
+		}
+	}
+	return diff
+}
@@ -0,0 +1,59 @@
+package analyzer
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCompare(t *testing.T) {
+	site := newSiteServer(t, map[string]string{
+		"/client": `<html><head><title>Client</title></head><body><p>Just a few words here.</p></body></html>`,
+		"/rival":  testPage,
+	})
+
+	analyzer := newTestAnalyzer(t)
+	comparison, err := analyzer.Compare(site.URL+"/client", site.URL+"/rival", AnalyzeOptions{})
+	if err != nil {
+		t.Fatalf("Failed to compare: %v", err)
+	}
+
+	if comparison.A == nil || comparison.B == nil || comparison.URLA != site.URL+"/client" {
+		t.Fatalf("Expected both analyses in order, got %+v", comparison)
+	}
+	if want := comparison.B.Score - comparison.A.Score; comparison.Score.Delta != want {
+		t.Errorf("Expected score delta %g, got %g", want, comparison.Score.Delta)
+	}
+	if len(comparison.Categories) != len(defaultScoreWeights) {
+		t.Errorf("Expected a delta for every scored section, got %v", comparison.Categories)
+	}
+	title := comparison.Categories["title"]
+	if title.Delta != title.B-title.A {
+		t.Errorf("Unexpected title delta %+v", title)
+	}
+
+	words := comparison.Metrics["wordCount"]
+	if words.A != comparison.A.Content.WordCount || words.Delta != words.B-words.A || words.Delta == 0 {
+		t.Errorf("Unexpected word count comparison %+v", words)
+	}
+	if !strings.Contains(strings.Join(comparison.Differing, ","), "wordCount") {
+		t.Errorf("Expected wordCount among the differing metrics, got %v", comparison.Differing)
+	}
+
+	// Each recommendation lands in exactly one bucket
+	recs := comparison.Recommendations
+	if len(recs.Both)+len(recs.OnlyA) != len(comparison.A.Recommendations) ||
+		len(recs.Both)+len(recs.OnlyB) != len(comparison.B.Recommendations) {
+		t.Errorf("Recommendation buckets don't add up: %+v", recs)
+	}
+
+	if _, err := analyzer.Compare(site.URL+"/client", site.URL+"/missing", AnalyzeOptions{}); err == nil || !strings.HasPrefix(err.Error(), "urlB:") {
+		t.Errorf("Expected the failing page to be named, got %v", err)
+	}
+}
+
+func TestDiffRecommendations(t *testing.T) {
+	diff := diffRecommendations([]string{"x", "shared", "y"}, []string{"shared", "z"})
+	if strings.Join(diff.Both, ",") != "shared" || strings.Join(diff.OnlyA, ",") != "x,y" || strings.Join(diff.OnlyB, ",") != "z" {
+		t.Errorf("Unexpected diff %+v", diff)
+	}
+}
@@ -0,0 +1,129 @@
+package analyzer
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/seo-optimizer/backend/logging"
+)
+
+// MaxCrawlPages is the most pages one Crawl analyzes, whatever maxPages is
+const MaxCrawlPages = 200
+
+// Crawl analyzes startURL and follows its internal links (same host)
+// breadth-first up to maxDepth levels, analyzing at most maxPages pages.
+// Pages robots.txt disallows are skipped, and pages that fail to analyze are
+// left out of the result. The result is keyed by the normalized page URL, so
+// it can be passed to AnalyzeSite.
+func (a *Analyzer) Crawl(ctx context.Context, startURL string, maxDepth, maxPages int) (map[string]*SEOAnalysis, error) {
+	return a.CrawlWithOptions(ctx, startURL, maxDepth, maxPages, AnalyzeOptions{})
+}
+
+// CrawlWithOptions is Crawl analyzing every page with opts. Pages are queued
+// with PriorityBatch unless opts sets a priority.
+func (a *Analyzer) CrawlWithOptions(ctx context.Context, startURL string, maxDepth, maxPages int, opts AnalyzeOptions) (map[string]*SEOAnalysis, error) {
+	start, err := url.Parse(startURL)
+	if err != nil || (start.Scheme != "http" && start.Scheme != "https") || start.Host == "" {
+		return nil, fmt.Errorf("start URL must be an absolute http(s) URL")
+	}
+	if opts.Priority == "" {
+		opts.Priority = PriorityBatch
+	}
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+	if maxDepth < 0 {
+		maxDepth = 0
+	}
+	if maxPages <= 0 || maxPages > MaxCrawlPages {
+		maxPages = MaxCrawlPages
+	}
+
+	a.configMutex.RLock()
+	workers := a.batchWorkers
+	a.configMutex.RUnlock()
+
+	pages := make(map[string]*SEOAnalysis)
+	visited := map[string]bool{normalizeURL(startURL): true}
+	level := []string{startURL}
+
+	for depth := 0; depth <= maxDepth && len(level) > 0; depth++ {
+		if err := ctx.Err(); err != nil {
+			return pages, err
+		}
+		if room := maxPages - len(pages); len(level) > room {
+			level = level[:room]
+		}
+
+		results := make([]*SEOAnalysis, len(level))
+		jobs := make(chan int)
+		var wg sync.WaitGroup
+		for i := 0; i < workers && i < len(level); i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for idx := range jobs {
+					results[idx] = a.crawlPage(ctx, level[idx], opts)
+				}
+			}()
+		}
+		for i := range level {
+			jobs <- i
+		}
+		close(jobs)
+		wg.Wait()
+
+		var next []string
+		for i, analysis := range results {
+			if analysis == nil {
+				if depth == 0 {
+					return nil, fmt.Errorf("failed to analyze start URL %s", level[i])
+				}
+				continue
+			}
+			pages[normalizeURL(level[i])] = analysis
+			if depth == maxDepth {
+				continue
+			}
+			for _, href := range analysis.Links.InternalHrefs {
+				link, err := url.Parse(href)
+				if err != nil || !strings.EqualFold(link.Host, start.Host) {
+					continue
+				}
+				link.Fragment = ""
+				key := normalizeURL(link.String())
+				if !visited[key] {
+					visited[key] = true
+					next = append(next, link.String())
+				}
+			}
+		}
+		if len(pages) >= maxPages {
+			break
+		}
+		level = next
+	}
+
+	return pages, nil
+}
+
+// crawlPage analyzes one crawled page, returning nil when robots.txt
+// disallows it or the analysis fails
+func (a *Analyzer) crawlPage(ctx context.Context, pageURL string, opts AnalyzeOptions) *SEOAnalysis {
+	if robots := a.analyzeRobots(ctx, pageURL); robots.IsBlocked {
+		logging.Debug("Crawl skipping page disallowed by robots.txt", "url", pageURL)
+		return nil
+	}
+
+	pageCtx, cancel := context.WithTimeout(ctx, opts.EffectiveTimeout())
+	defer cancel()
+	analysis, _, err := a.analyze(pageCtx, pageURL, opts)
+	if err != nil {
+		logging.Warn("Crawl failed to analyze page", "url", pageURL, "error", err)
+		return nil
+	}
+	return analysis
+}
@@ -0,0 +1,71 @@
+package analyzer
+
+import (
+	"context"
+	"testing"
+)
+
+func TestCrawl(t *testing.T) {
+	page := func(links ...string) string {
+		html := `<html><head><title>Crawl</title></head><body>`
+		for _, link := range links {
+			html += `<a href="` + link + `">link</a>`
+		}
+		return html + `</body></html>`
+	}
+	server := newSiteServer(t, map[string]string{
+		"/robots.txt": "User-agent: *\nDisallow: /secret\n",
+		"/":           page("/a", "/b#top", "/secret", "https://other.example/"),
+		"/a":          page("/c", "/"),
+		"/b":          page("/a"),
+		"/c":          page("/d"),
+		"/d":          page(),
+		"/secret":     page(),
+	})
+	analyzer := newTestAnalyzer(t)
+
+	pages, err := analyzer.Crawl(context.Background(), server.URL+"/", 2, 0)
+	if err != nil {
+		t.Fatalf("Failed to crawl: %v", err)
+	}
+	for _, path := range []string{"/", "/a", "/b", "/c"} {
+		if pages[server.URL+path] == nil {
+			t.Errorf("Expected %s to be crawled", path)
+		}
+	}
+	if pages[server.URL+"/d"] != nil {
+		t.Error("Expected /d, three links deep, to be beyond maxDepth")
+	}
+	if pages[server.URL+"/secret"] != nil {
+		t.Error("Expected the robots.txt-disallowed page to be skipped")
+	}
+	if len(pages) != 4 {
+		t.Errorf("Expected 4 pages, got %d", len(pages))
+	}
+
+	pages, err = analyzer.Crawl(context.Background(), server.URL+"/", 5, 2)
+	if err != nil {
+		t.Fatalf("Failed to crawl: %v", err)
+	}
+	if len(pages) != 2 {
+		t.Errorf("Expected maxPages to cap the crawl at 2 pages, got %d", len(pages))
+	}
+
+	if _, err := analyzer.Crawl(context.Background(), server.URL+"/missing", 1, 0); err == nil {
+		t.Error("Expected an error when the start URL fails")
+	}
+
+	// Every page is analyzed with the options given
+	pages, err = analyzer.CrawlWithOptions(context.Background(), server.URL+"/", 1, 0, AnalyzeOptions{Device: DeviceMobile})
+	if err != nil {
+		t.Fatalf("Failed to crawl: %v", err)
+	}
+	for key, page := range pages {
+		if page.Device.Name != DeviceMobile {
+			t.Errorf("Expected %s to be analyzed as mobile, got %s", key, page.Device.Name)
+		}
+	}
+	if _, err := analyzer.CrawlWithOptions(context.Background(), server.URL+"/", 1, 0, AnalyzeOptions{Device: "tablet"}); err == nil {
+		t.Error("Expected invalid options to be rejected")
+	}
+}
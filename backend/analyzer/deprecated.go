@@ -0,0 +1,64 @@
+package analyzer
+
+import (
+	"github.com/PuerkitoBio/goquery"
+)
+
+// deprecatedElements are obsolete in HTML5 and should be replaced with
+// semantic markup or CSS
+var deprecatedElements = map[string]bool{
+	"acronym":   true,
+	"applet":    true,
+	"basefont":  true,
+	"big":       true,
+	"blink":     true,
+	"center":    true,
+	"dir":       true,
+	"font":      true,
+	"frame":     true,
+	"frameset":  true,
+	"isindex":   true,
+	"marquee":   true,
+	"noframes":  true,
+	"plaintext": true,
+	"strike":    true,
+	"tt":        true,
+	"xmp":       true,
+}
+
+// deprecatedAttributes are presentational attributes superseded by CSS
+var deprecatedAttributes = map[string]bool{
+	"align":       true,
+	"background":  true,
+	"bgcolor":     true,
+	"cellpadding": true,
+	"cellspacing": true,
+	"hspace":      true,
+	"nowrap":      true,
+	"valign":      true,
+	"vspace":      true,
+}
+
+// analyzeDeprecatedMarkup counts deprecated elements and attributes in a
+// single pass over the DOM
+func (a *Analyzer) analyzeDeprecatedMarkup(doc *goquery.Document) *DeprecatedMarkupAnalysis {
+	result := &DeprecatedMarkupAnalysis{
+		Elements:   map[string]int{},
+		Attributes: map[string]int{},
+	}
+
+	doc.Find("*").Each(func(_ int, s *goquery.Selection) {
+		if name := goquery.NodeName(s); deprecatedElements[name] {
+			result.Elements[name]++
+			result.Total++
+		}
+		for _, attr := range s.Nodes[0].Attr {
+			if attr.Namespace == "" && deprecatedAttributes[attr.Key] {
+				result.Attributes[attr.Key]++
+				result.Total++
+			}
+		}
+	})
+
+	return result
+}
@@ -0,0 +1,80 @@
+package analyzer
+
+import (
+	"bytes"
+	"mime"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// documentInfo reads the declared charset and the doctype. The doctype is
+// taken from the raw bytes since the parsed DOM doesn't keep it as written.
+func documentInfo(raw []byte, doc *goquery.Document) DocumentInfo {
+	info := DocumentInfo{}
+
+	if charset, ok := doc.Find("meta[charset]").First().Attr("charset"); ok {
+		info.Charset = strings.ToLower(strings.TrimSpace(charset))
+		info.CharsetSource = "meta charset"
+	} else {
+		doc.Find("meta[http-equiv]").EachWithBreak(func(_ int, s *goquery.Selection) bool {
+			if !strings.EqualFold(strings.TrimSpace(s.AttrOr("http-equiv", "")), "content-type") {
+				return true
+			}
+			if _, params, err := mime.ParseMediaType(s.AttrOr("content", "")); err == nil && params["charset"] != "" {
+				info.Charset = strings.ToLower(params["charset"])
+				info.CharsetSource = "http-equiv"
+				return false
+			}
+			return true
+		})
+	}
+	info.HasCharset = info.Charset != ""
+
+	info.Doctype = leadingDoctype(raw)
+	info.HasDoctype = info.Doctype != ""
+	info.HTML5Doctype = isHTML5Doctype(info.Doctype)
+	return info
+}
+
+// leadingDoctype returns the doctype declaration at the start of raw,
+// skipping a byte order mark, whitespace and comments, or "" if the document
+// doesn't start with one
+func leadingDoctype(raw []byte) string {
+	rest := bytes.TrimPrefix(raw, []byte("\xef\xbb\xbf"))
+	for {
+		rest = bytes.TrimLeft(rest, " \t\r\n\f")
+		if !bytes.HasPrefix(rest, []byte("<!--")) {
+			break
+		}
+		end := bytes.Index(rest, []byte("-->"))
+		if end < 0 {
+			return ""
+		}
+		rest = rest[end+3:]
+	}
+
+	if len(rest) < len("<!doctype") || !strings.EqualFold(string(rest[:len("<!doctype")]), "<!doctype") {
+		return ""
+	}
+	end := bytes.IndexByte(rest, '>')
+	if end < 0 {
+		return ""
+	}
+	return string(rest[:end+1])
+}
+
+// isHTML5Doctype reports whether doctype is <!DOCTYPE html> or its legacy
+// compatible form, the ones that keep browsers in standards mode without a
+// DTD
+func isHTML5Doctype(doctype string) bool {
+	fields := strings.Fields(strings.ToLower(strings.TrimSuffix(doctype, ">")))
+	switch {
+	case len(fields) == 2:
+		return fields[0] == "<!doctype" && fields[1] == "html"
+	case len(fields) == 4:
+		return fields[0] == "<!doctype" && fields[1] == "html" && fields[2] == "system" &&
+			strings.Trim(fields[3], `"'`) == "about:legacy-compat"
+	}
+	return false
+}
@@ -0,0 +1,65 @@
+package analyzer
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+func TestDocumentInfo(t *testing.T) {
+	tests := []struct {
+		name string
+		html string
+		want DocumentInfo
+	}{
+		{
+			name: "html5 with meta charset",
+			html: "\xef\xbb\xbf<!-- build 42 -->\n<!doctype HTML>\n<html><head><meta charset=\"UTF-8\"></head></html>",
+			want: DocumentInfo{Charset: "utf-8", CharsetSource: "meta charset", HasCharset: true,
+				Doctype: "<!doctype HTML>", HasDoctype: true, HTML5Doctype: true},
+		},
+		{
+			name: "http-equiv and legacy doctype",
+			html: `<!DOCTYPE HTML PUBLIC "-//W3C//DTD HTML 4.01//EN" "http://www.w3.org/TR/html4/strict.dtd">` +
+				`<html><head><meta http-equiv="Content-Type" content="text/html; charset=ISO-8859-1"></head></html>`,
+			want: DocumentInfo{Charset: "iso-8859-1", CharsetSource: "http-equiv", HasCharset: true,
+				Doctype: `<!DOCTYPE HTML PUBLIC "-//W3C//DTD HTML 4.01//EN" "http://www.w3.org/TR/html4/strict.dtd">`, HasDoctype: true},
+		},
+		{
+			name: "legacy compat",
+			html: `<!DOCTYPE html SYSTEM "about:legacy-compat"><html></html>`,
+			want: DocumentInfo{Doctype: `<!DOCTYPE html SYSTEM "about:legacy-compat">`, HasDoctype: true, HTML5Doctype: true},
+		},
+		{
+			name: "neither",
+			html: `<html><head><title>Quirks</title></head><body><!DOCTYPE html></body></html>`,
+			want: DocumentInfo{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := goquery.NewDocumentFromReader(strings.NewReader(tt.html))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := documentInfo([]byte(tt.html), doc); got != tt.want {
+				t.Errorf("Expected %+v, got %+v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestDocumentRecommendations(t *testing.T) {
+	site := newSiteServer(t, map[string]string{
+		"/": `<html><head><title>Quirks</title></head><body></body></html>`,
+	})
+	analysis, err := newTestAnalyzer(t).Analyze(site.URL + "/")
+	if err != nil {
+		t.Fatalf("Failed to analyze: %v", err)
+	}
+	recommendations := strings.Join(analysis.Recommendations, "\n")
+	if !strings.Contains(recommendations, `<meta charset="utf-8">`) || !strings.Contains(recommendations, "quirks mode") {
+		t.Errorf("Expected charset and doctype recommendations, got %v", analysis.Recommendations)
+	}
+}
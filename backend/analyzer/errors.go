@@ -0,0 +1,84 @@
+package analyzer
+
+import (
+	"context"
+	"errors"
+	"net"
+	"time"
+)
+
+// ErrMaintenance is returned while maintenance mode disables outbound fetching
+var ErrMaintenance = errors.New("analyzer is in maintenance mode; outbound fetching is disabled")
+
+// ErrShuttingDown is returned for analyses started after Shutdown was called
+var ErrShuttingDown = errors.New("analyzer is shutting down")
+
+// ErrDomainNotAllowed is returned for URLs outside the domain allowlist
+var ErrDomainNotAllowed = errors.New("domain is not on the allowlist")
+
+// ErrorCategory classifies why fetching a page failed
+type ErrorCategory string
+
+const (
+	CategoryTimeout    ErrorCategory = "timeout"
+	CategoryDNS        ErrorCategory = "dns"
+	CategoryConnection ErrorCategory = "connection"
+	CategoryInvalidURL ErrorCategory = "invalid_url"
+	CategoryHTTPStatus ErrorCategory = "http_status"
+	CategoryTooLarge   ErrorCategory = "too_large"
+	CategoryNotHTML    ErrorCategory = "not_html"
+)
+
+// userCorrectable reports whether the error is caused by the request itself
+// rather than the target, so retrying with a fixed request may succeed
+func (c ErrorCategory) userCorrectable() bool {
+	return c == CategoryInvalidURL
+}
+
+// FetchError is returned when the analyzed page could not be retrieved
+type FetchError struct {
+	URL      string
+	Category ErrorCategory
+	Err      error
+	// StatusCode is the HTTP status for CategoryHTTPStatus errors
+	StatusCode int
+	// FromCache is set when the error was served from the negative cache
+	FromCache bool
+}
+
+func (e *FetchError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *FetchError) Unwrap() error {
+	return e.Err
+}
+
+// newFetchError wraps err with the category derived from it
+func newFetchError(url string, err error) *FetchError {
+	return &FetchError{URL: url, Category: classifyFetchError(err), Err: err}
+}
+
+// classifyFetchError maps a transport error to an ErrorCategory
+func classifyFetchError(err error) ErrorCategory {
+	var dnsErr *net.DNSError
+	var netErr net.Error
+	switch {
+	case errors.Is(err, ErrPrivateAddress):
+		return CategoryInvalidURL
+	case errors.Is(err, context.DeadlineExceeded):
+		return CategoryTimeout
+	case errors.As(err, &dnsErr):
+		return CategoryDNS
+	case errors.As(err, &netErr) && netErr.Timeout():
+		return CategoryTimeout
+	default:
+		return CategoryConnection
+	}
+}
+
+// Negative cache entry for a failed fetch
+type negativeCacheEntry struct {
+	err       *FetchError
+	timestamp time.Time
+}
@@ -0,0 +1,107 @@
+package analyzer
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// CacheEventType describes what happened to a cached analysis
+type CacheEventType string
+
+const (
+	CacheEventAdded   CacheEventType = "added"
+	CacheEventHit     CacheEventType = "hit"
+	CacheEventEvicted CacheEventType = "evicted"
+)
+
+// maxCacheEventsPerSecond caps how many events are published per second;
+// anything beyond is dropped so a busy cache can't flood subscribers
+const maxCacheEventsPerSecond = 100
+
+// cacheEventBuffer is how many events a slow subscriber may fall behind
+// before further events are dropped for it
+const cacheEventBuffer = 64
+
+// CacheEvent is published when an analysis is added to, served from or
+// evicted from the result cache
+type CacheEvent struct {
+	Type CacheEventType `json:"type"`
+	URL  string         `json:"url"`
+	Time time.Time      `json:"time"`
+}
+
+// cacheEventBroker fans cache events out to subscribers
+type cacheEventBroker struct {
+	enabled     atomic.Bool
+	subscribers atomic.Int32
+
+	mu          sync.Mutex
+	channels    map[chan CacheEvent]struct{}
+	windowStart time.Time
+	windowCount int
+}
+
+func newCacheEventBroker() *cacheEventBroker {
+	return &cacheEventBroker{channels: make(map[chan CacheEvent]struct{})}
+}
+
+// publish sends an event to all subscribers without blocking. It is a
+// no-op unless events are enabled and someone is listening.
+func (b *cacheEventBroker) publish(eventType CacheEventType, url string) {
+	if !b.enabled.Load() || b.subscribers.Load() == 0 {
+		return
+	}
+
+	now := time.Now()
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if now.Sub(b.windowStart) >= time.Second {
+		b.windowStart = now
+		b.windowCount = 0
+	}
+	if b.windowCount >= maxCacheEventsPerSecond {
+		return
+	}
+	b.windowCount++
+
+	event := CacheEvent{Type: eventType, URL: url, Time: now}
+	for ch := range b.channels {
+		select {
+		case ch <- event:
+		default: // subscriber is behind; drop
+		}
+	}
+}
+
+// SetCacheEvents enables publishing cache events to subscribers
+func (a *Analyzer) SetCacheEvents(enabled bool) {
+	a.events.enabled.Store(enabled)
+}
+
+// CacheEventsEnabled reports whether cache events are published
+func (a *Analyzer) CacheEventsEnabled() bool {
+	return a.events.enabled.Load()
+}
+
+// SubscribeCacheEvents returns a channel of cache events and a function
+// that unsubscribes and must be called when the caller stops reading
+func (a *Analyzer) SubscribeCacheEvents() (<-chan CacheEvent, func()) {
+	ch := make(chan CacheEvent, cacheEventBuffer)
+
+	a.events.mu.Lock()
+	a.events.channels[ch] = struct{}{}
+	a.events.mu.Unlock()
+	a.events.subscribers.Add(1)
+
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			a.events.mu.Lock()
+			delete(a.events.channels, ch)
+			a.events.mu.Unlock()
+			a.events.subscribers.Add(-1)
+		})
+	}
+}
@@ -0,0 +1,88 @@
+package analyzer
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// maxFrames caps how many iframes are fetched for a single page
+const maxFrames = 5
+
+// analyzeFrames fetches same-origin iframes one level deep and summarizes
+// their content. Cross-origin frames are counted but never fetched.
+func (a *Analyzer) analyzeFrames(ctx context.Context, doc *goquery.Document, pageURL string, device DeviceProfile) *IframeAnalysis {
+	result := &IframeAnalysis{Frames: []FrameAnalysis{}}
+
+	base, err := url.Parse(pageURL)
+	if err != nil {
+		return result
+	}
+
+	seen := make(map[string]bool)
+	doc.Find("iframe[src]").Each(func(_ int, s *goquery.Selection) {
+		src, _ := s.Attr("src")
+		ref, err := url.Parse(strings.TrimSpace(src))
+		if err != nil || src == "" {
+			return
+		}
+		frameURL := base.ResolveReference(ref)
+		frameURL.Fragment = ""
+
+		if frameURL.Scheme != base.Scheme || frameURL.Host != base.Host {
+			result.SkippedCrossOrigin++
+			return
+		}
+		if seen[frameURL.String()] || len(seen) >= maxFrames {
+			return
+		}
+		seen[frameURL.String()] = true
+
+		result.Frames = append(result.Frames, a.analyzeFrame(ctx, frameURL.String(), device))
+	})
+
+	return result
+}
+
+// analyzeFrame fetches a single framed document and summarizes it
+func (a *Analyzer) analyzeFrame(ctx context.Context, frameURL string, device DeviceProfile) FrameAnalysis {
+	frame := FrameAnalysis{URL: frameURL, Headings: []string{}}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", frameURL, nil)
+	if err != nil {
+		frame.Error = err.Error()
+		return frame
+	}
+	device.setHeaders(req)
+
+	resp, err := a.client.Do(req)
+	if err != nil {
+		frame.Error = err.Error()
+		return frame
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		frame.Error = fmt.Sprintf("frame returned status %d", resp.StatusCode)
+		return frame
+	}
+
+	doc, err := goquery.NewDocumentFromReader(resp.Body)
+	if err != nil {
+		frame.Error = err.Error()
+		return frame
+	}
+
+	frame.WordCount = len(strings.Fields(doc.Find("body").Text()))
+	doc.Find("h1, h2, h3").Each(func(_ int, s *goquery.Selection) {
+		if text := strings.TrimSpace(s.Text()); text != "" {
+			frame.Headings = append(frame.Headings, text)
+		}
+	})
+
+	return frame
+}
@@ -0,0 +1,70 @@
+package analyzer
+
+import (
+	"golang.org/x/net/html"
+)
+
+// truncateDOM detaches every node after the first maxNodes (in document
+// order), so the expensive passes that follow work on a bounded tree. It
+// reports whether anything was removed.
+func truncateDOM(root *html.Node, maxNodes int) bool {
+	count := 0
+	truncated := false
+
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		for c := n.FirstChild; c != nil; {
+			next := c.NextSibling
+			if count >= maxNodes {
+				n.RemoveChild(c)
+				truncated = true
+			} else {
+				count++
+				walk(c)
+			}
+			c = next
+		}
+	}
+	walk(root)
+
+	return truncated
+}
+
+// maxRecommendedDOMDepth is the element nesting depth past which Lighthouse
+// reports an excessive DOM
+const maxRecommendedDOMDepth = 32
+
+// domDepth returns the deepest element nesting level below root, counting
+// only element nodes (<html> is depth 1). The tree is walked iteratively so
+// pathologically deep documents can't exhaust the stack.
+func domDepth(root *html.Node) int {
+	maxDepth, depth := 0, 0
+	n := root.FirstChild
+	for n != nil {
+		if n.Type == html.ElementNode {
+			depth++
+			if depth > maxDepth {
+				maxDepth = depth
+			}
+		}
+		if n.FirstChild != nil {
+			n = n.FirstChild
+			continue
+		}
+		// Climb until a sibling is found, leaving each element on the way
+		for n != root && n.NextSibling == nil {
+			if n.Type == html.ElementNode {
+				depth--
+			}
+			n = n.Parent
+		}
+		if n == root {
+			break
+		}
+		if n.Type == html.ElementNode {
+			depth--
+		}
+		n = n.NextSibling
+	}
+	return maxDepth
+}
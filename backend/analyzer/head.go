@@ -0,0 +1,119 @@
+package analyzer
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// charsetPrescanLimit is how far into the document browsers look for a
+// charset declaration before guessing
+const charsetPrescanLimit = 1024
+
+var charsetPattern = regexp.MustCompile(`(?i)<meta[^>]+charset\s*=`)
+
+// analyzeHeadOrder checks the position of critical head tags. The raw bytes
+// are needed for byte offsets, which the parsed DOM doesn't preserve.
+func (a *Analyzer) analyzeHeadOrder(raw []byte, doc *goquery.Document) HeadOrderAnalysis {
+	head := HeadOrderAnalysis{CharsetPosition: -1, Violations: []string{}}
+
+	if loc := charsetPattern.FindIndex(raw); loc != nil {
+		head.CharsetPosition = loc[0]
+		head.CharsetEarly = loc[1] <= charsetPrescanLimit
+		if !head.CharsetEarly {
+			head.Violations = append(head.Violations, "charset declared after the first 1024 bytes")
+		}
+	} else {
+		head.Violations = append(head.Violations, "no charset declaration")
+	}
+
+	// Walk the head in document order, noting which tags come before the
+	// first render-blocking resource
+	seenBlockingScript := false
+	seenBlocking := false
+	doc.Find("head").Children().Each(func(_ int, s *goquery.Selection) {
+		switch goquery.NodeName(s) {
+		case "title":
+			if !head.HasTitle {
+				head.HasTitle = true
+				head.TitleBeforeScripts = !seenBlockingScript
+			}
+		case "meta":
+			if name, _ := s.Attr("name"); strings.EqualFold(name, "viewport") && !head.HasViewport {
+				head.HasViewport = true
+				head.ViewportBeforeBlocking = !seenBlocking
+			}
+		case "script":
+			if isBlockingScript(s) {
+				seenBlockingScript = true
+				seenBlocking = true
+			}
+		case "link":
+			if rel, _ := s.Attr("rel"); strings.EqualFold(strings.TrimSpace(rel), "stylesheet") {
+				seenBlocking = true
+			}
+		}
+	})
+
+	if !head.HasTitle {
+		head.Violations = append(head.Violations, "title is not declared in the head")
+	} else if !head.TitleBeforeScripts {
+		head.Violations = append(head.Violations, "title appears after blocking scripts")
+	}
+	if head.HasViewport && !head.ViewportBeforeBlocking {
+		head.Violations = append(head.Violations, "viewport appears after render-blocking resources")
+	}
+
+	return head
+}
+
+// countRenderBlocking counts the stylesheets and external scripts in the
+// head that hold up the first render
+func countRenderBlocking(doc *goquery.Document) (css, js int) {
+	doc.Find("head link[rel], head script").Each(func(_ int, s *goquery.Selection) {
+		switch goquery.NodeName(s) {
+		case "script":
+			if isBlockingScript(s) {
+				js++
+			}
+		case "link":
+			if isBlockingStylesheet(s) {
+				css++
+			}
+		}
+	})
+	return css, js
+}
+
+// isBlockingStylesheet reports whether a link is a stylesheet that applies
+// to the screen straight away. Stylesheets for other media, such as print or
+// a media query, are downloaded without blocking rendering.
+func isBlockingStylesheet(s *goquery.Selection) bool {
+	isStylesheet := false
+	for _, token := range strings.Fields(s.AttrOr("rel", "")) {
+		isStylesheet = isStylesheet || strings.EqualFold(token, "stylesheet")
+	}
+	if !isStylesheet {
+		return false
+	}
+	if _, disabled := s.Attr("disabled"); disabled {
+		return false
+	}
+	switch strings.ToLower(strings.TrimSpace(s.AttrOr("media", ""))) {
+	case "", "all", "screen":
+		return true
+	}
+	return false
+}
+
+// isBlockingScript reports whether an external script blocks parsing
+func isBlockingScript(s *goquery.Selection) bool {
+	if _, ok := s.Attr("src"); !ok {
+		return false
+	}
+	_, isAsync := s.Attr("async")
+	_, isDefer := s.Attr("defer")
+	scriptType, _ := s.Attr("type")
+	return !isAsync && !isDefer && !strings.EqualFold(scriptType, "module")
+}
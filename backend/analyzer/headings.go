@@ -0,0 +1,90 @@
+package analyzer
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// Heading issue types
+const (
+	HeadingSkippedLevel  = "skippedLevel"
+	HeadingMissingParent = "missingParent"
+	HeadingDuplicateText = "duplicateText"
+)
+
+// maxHeadingIssues caps how many heading issues one analysis reports
+const maxHeadingIssues = 50
+
+// checkHeadingHierarchy walks the headings in document order and reports
+// skipped levels, headings that come before any heading of the level above
+// and headings repeating the text of an earlier one at the same level. A
+// missing H1 is left to the H1 checks.
+func checkHeadingHierarchy(doc *goquery.Document) []HeadingIssue {
+	issues := []HeadingIssue{}
+	var seen [7]bool
+	texts := make(map[string]bool)
+	previous := 0
+
+	doc.Find("h1, h2, h3, h4, h5, h6").EachWithBreak(func(_ int, s *goquery.Selection) bool {
+		level := int(goquery.NodeName(s)[1] - '0')
+		text := strings.Join(strings.Fields(s.Text()), " ")
+
+		switch {
+		case previous > 0 && level > previous+1:
+			issues = append(issues, HeadingIssue{Type: HeadingSkippedLevel, Level: level, Previous: previous, Text: text})
+		case level > 2 && !seen[level-1]:
+			issues = append(issues, HeadingIssue{Type: HeadingMissingParent, Level: level, Text: text})
+		}
+
+		key := strings.ToLower(text)
+		if key != "" {
+			key = fmt.Sprintf("%d:%s", level, key)
+			if texts[key] {
+				issues = append(issues, HeadingIssue{Type: HeadingDuplicateText, Level: level, Text: text})
+			}
+			texts[key] = true
+		}
+
+		seen[level] = true
+		previous = level
+		return len(issues) < maxHeadingIssues
+	})
+	if len(issues) > maxHeadingIssues {
+		issues = issues[:maxHeadingIssues]
+	}
+	return issues
+}
+
+// headingRecommendations returns one recommendation per type of heading issue
+func headingRecommendations(issues []HeadingIssue) []string {
+	counts := make(map[string]int)
+	first := make(map[string]HeadingIssue)
+	for _, issue := range issues {
+		if counts[issue.Type] == 0 {
+			first[issue.Type] = issue
+		}
+		counts[issue.Type]++
+	}
+
+	var recommendations []string
+	if n := counts[HeadingSkippedLevel]; n > 0 {
+		issue := first[HeadingSkippedLevel]
+		recommendations = append(recommendations, fmt.Sprintf(
+			"Don't skip heading levels (e.g. H%d followed by H%d, %d place(s)) - screen readers and search engines use the heading outline",
+			issue.Previous, issue.Level, n))
+	}
+	if n := counts[HeadingMissingParent]; n > 0 {
+		issue := first[HeadingMissingParent]
+		recommendations = append(recommendations, fmt.Sprintf(
+			"Introduce H%d sections before using H%d (%d heading(s) appear before any heading of the level above)",
+			issue.Level-1, issue.Level, n))
+	}
+	if n := counts[HeadingDuplicateText]; n > 0 {
+		recommendations = append(recommendations, fmt.Sprintf(
+			"Give headings distinct text (%d heading(s) repeat an earlier heading of the same level, e.g. %q)",
+			n, first[HeadingDuplicateText].Text))
+	}
+	return recommendations
+}
@@ -0,0 +1,60 @@
+package analyzer
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+func TestCheckHeadingHierarchy(t *testing.T) {
+	tests := []struct {
+		name  string
+		html  string
+		types []string
+	}{
+		{"proper", `<h1>A</h1><h2>B</h2><h3>C</h3><h2>D</h2><h3>E</h3><h4>F</h4>`, nil},
+		{"skipped", `<h1>A</h1><h4>B</h4>`, []string{HeadingSkippedLevel}},
+		{"h3 before h2", `<h3>Intro</h3><h1>A</h1><h2>B</h2>`, []string{HeadingMissingParent}},
+		{"duplicate", `<h1>A</h1><h2>Pricing</h2><h2> pricing </h2><h3>Pricing</h3>`, []string{HeadingDuplicateText}},
+		// A missing H1 is reported by the H1 checks instead
+		{"no h1", `<h2>A</h2><h3>B</h3>`, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := goquery.NewDocumentFromReader(strings.NewReader(tt.html))
+			if err != nil {
+				t.Fatal(err)
+			}
+			issues := checkHeadingHierarchy(doc)
+			var types []string
+			for _, issue := range issues {
+				types = append(types, issue.Type)
+			}
+			if strings.Join(types, ",") != strings.Join(tt.types, ",") {
+				t.Errorf("Expected issues %v, got %+v", tt.types, issues)
+			}
+		})
+	}
+}
+
+func TestHeadingHierarchyInAnalysis(t *testing.T) {
+	site := newSiteServer(t, map[string]string{
+		"/": `<html><head><title>Headings</title></head><body><h1>Guide</h1><h4>Details</h4><h5>More</h5><h6>Fine print</h6></body></html>`,
+	})
+
+	analysis, err := newTestAnalyzer(t).Analyze(site.URL + "/")
+	if err != nil {
+		t.Fatalf("Failed to analyze: %v", err)
+	}
+	headers := analysis.Headers
+	if headers.H4Count != 1 || headers.H5Count != 1 || headers.H6Count != 1 {
+		t.Errorf("Expected H4-H6 counts, got %+v", headers)
+	}
+	if headers.HasProperHierarchy || len(headers.HierarchyIssues) != 1 || headers.HierarchyIssues[0].Previous != 1 {
+		t.Errorf("Expected one skipped level after the H1, got %+v", headers.HierarchyIssues)
+	}
+	if !strings.Contains(strings.Join(analysis.Recommendations, "\n"), "H1 followed by H4") {
+		t.Errorf("Expected a skipped-level recommendation, got %v", analysis.Recommendations)
+	}
+}
@@ -0,0 +1,108 @@
+package analyzer
+
+import (
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// maxPreconnectSuggestions bounds how many origins the preconnect
+// recommendation names; preconnecting to many origins wastes connections
+const maxPreconnectSuggestions = 3
+
+// resourceSelectors lists the elements and attributes that make the
+// browser fetch a subresource
+var resourceSelectors = []struct {
+	selector string
+	attr     string
+}{
+	{"script[src]", "src"},
+	{"link[rel~='stylesheet'][href]", "href"},
+	{"link[rel~='preload'][href]", "href"},
+	{"img[src]", "src"},
+	{"iframe[src]", "src"},
+	{"video[src]", "src"},
+	{"audio[src]", "src"},
+	{"source[src]", "src"},
+}
+
+// originOf returns the scheme://host origin of an absolute http(s) URL
+func originOf(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return ""
+	}
+	return strings.ToLower(u.Scheme + "://" + u.Host)
+}
+
+// analyzeResourceHints counts subresource requests per third-party origin
+// and checks which of those origins have a preconnect or dns-prefetch hint
+func (a *Analyzer) analyzeResourceHints(doc *goquery.Document, pageURL string) ResourceHintAnalysis {
+	result := ResourceHintAnalysis{
+		ThirdPartyOrigins: []OriginUsage{},
+		Hinted:            []string{},
+		MissingHints:      []string{},
+	}
+	pageOrigin := originOf(pageURL)
+
+	usage := make(map[string]int)
+	for _, rs := range resourceSelectors {
+		doc.Find(rs.selector).Each(func(_ int, s *goquery.Selection) {
+			ref, _ := s.Attr(rs.attr)
+			origin := originOf(resolveURL(pageURL, ref))
+			if origin != "" && origin != pageOrigin {
+				usage[origin]++
+			}
+		})
+	}
+
+	hinted := make(map[string]bool)
+	doc.Find("link[href]").Each(func(_ int, s *goquery.Selection) {
+		rel, _ := s.Attr("rel")
+		rels := strings.Fields(strings.ToLower(rel))
+		isHint := false
+		for _, r := range rels {
+			if r == "preconnect" || r == "dns-prefetch" {
+				isHint = true
+			}
+		}
+		if !isHint {
+			return
+		}
+		href, _ := s.Attr("href")
+		if origin := originOf(resolveURL(pageURL, href)); origin != "" && !hinted[origin] {
+			hinted[origin] = true
+			result.Hinted = append(result.Hinted, origin)
+		}
+	})
+
+	for origin, count := range usage {
+		result.ThirdPartyOrigins = append(result.ThirdPartyOrigins, OriginUsage{Origin: origin, Requests: count})
+	}
+	// Most used first, so the top of MissingHints is where hints help most
+	sort.Slice(result.ThirdPartyOrigins, func(i, j int) bool {
+		oi, oj := result.ThirdPartyOrigins[i], result.ThirdPartyOrigins[j]
+		if oi.Requests != oj.Requests {
+			return oi.Requests > oj.Requests
+		}
+		return oi.Origin < oj.Origin
+	})
+	for _, o := range result.ThirdPartyOrigins {
+		if !hinted[o.Origin] && !hinted[hostOnlyOrigin(o.Origin)] {
+			result.MissingHints = append(result.MissingHints, o.Origin)
+		}
+	}
+
+	return result
+}
+
+// hostOnlyOrigin swaps the scheme of an origin, since a dns-prefetch hint
+// for http://cdn.example.com also resolves https://cdn.example.com
+func hostOnlyOrigin(origin string) string {
+	if strings.HasPrefix(origin, "https://") {
+		return "http://" + strings.TrimPrefix(origin, "https://")
+	}
+	return "https://" + strings.TrimPrefix(origin, "http://")
+}
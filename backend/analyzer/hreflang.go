@@ -0,0 +1,176 @@
+package analyzer
+
+import (
+	"context"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// hreflangCodePattern matches a language code with an optional script and
+// region, e.g. "en", "en-gb", "zh-hant-tw" or "es-419"
+var hreflangCodePattern = regexp.MustCompile(`^[a-z]{2,3}(-[a-z]{4})?(-([a-z]{2}|[0-9]{3}))?$`)
+
+// maxHreflangChecks caps how many alternate URLs of a page are checked
+const maxHreflangChecks = 50
+
+// analyzeHreflang collects the page's hreflang alternate links and checks
+// them for malformed codes, duplicates, a missing self-reference or
+// x-default, and alternates that don't load
+func (a *Analyzer) analyzeHreflang(ctx context.Context, doc *goquery.Document, pageURL string) HreflangAnalysis {
+	result := HreflangAnalysis{
+		Links:        []HreflangLink{},
+		InvalidCodes: []string{},
+		Duplicates:   []string{},
+		Broken:       []string{},
+	}
+
+	doc.Find("link[hreflang][href]").Each(func(_ int, s *goquery.Selection) {
+		rel, _ := s.Attr("rel")
+		if !strings.EqualFold(strings.TrimSpace(rel), "alternate") {
+			return
+		}
+		lang, _ := s.Attr("hreflang")
+		href, _ := s.Attr("href")
+		result.Links = append(result.Links, HreflangLink{
+			Lang: strings.ToLower(strings.TrimSpace(lang)),
+			URL:  resolveURL(pageURL, href),
+		})
+	})
+	if len(result.Links) == 0 {
+		return result
+	}
+
+	// Count each language and URL once however often it's repeated
+	langs := make(map[string]int)
+	checked := make(map[string]bool)
+	var targets []string
+	for _, link := range result.Links {
+		langs[link.Lang]++
+		switch n := langs[link.Lang]; {
+		case n == 2:
+			result.Duplicates = append(result.Duplicates, link.Lang)
+		case n > 2:
+		case link.Lang == "x-default":
+			result.HasXDefault = true
+		case !hreflangCodePattern.MatchString(link.Lang):
+			result.InvalidCodes = append(result.InvalidCodes, link.Lang)
+		}
+
+		if sameURL(link.URL, pageURL) {
+			result.HasSelfReference = true
+		} else if !checked[link.URL] {
+			checked[link.URL] = true
+			targets = append(targets, link.URL)
+		}
+	}
+
+	result.Broken = a.brokenHreflangTargets(ctx, targets)
+	return result
+}
+
+// brokenHreflangTargets checks the alternate URLs through the link cache and
+// returns the ones that fail to load, in the order given
+func (a *Analyzer) brokenHreflangTargets(ctx context.Context, targets []string) []string {
+	if len(targets) > maxHreflangChecks {
+		targets = targets[:maxHreflangChecks]
+	}
+	checkCtx, cancel := context.WithTimeout(ctx, 15*time.Second)
+	defer cancel()
+
+	accessible := make([]bool, len(targets))
+	var wg sync.WaitGroup
+	semaphore := make(chan struct{}, 10) // Limit to 10 concurrent requests
+	for i, target := range targets {
+		wg.Add(1)
+		go func(i int, target string) {
+			defer wg.Done()
+
+			semaphore <- struct{}{}
+			defer func() { <-semaphore }()
+
+			accessible[i] = a.isLinkAccessibleWithContext(checkCtx, target)
+		}(i, target)
+	}
+	wg.Wait()
+
+	broken := []string{}
+	for i, target := range targets {
+		if !accessible[i] {
+			broken = append(broken, target)
+		}
+	}
+	return broken
+}
+
+// findHreflangErrors verifies hreflang relationships across pages: every
+// alternate that was analyzed must link back to the declaring page, and a
+// page must not map one language to several URLs
+func findHreflangErrors(pages map[string]*SEOAnalysis) []HreflangError {
+	byURL := make(map[string]*SEOAnalysis, len(pages))
+	for pageURL, page := range pages {
+		if page != nil {
+			byURL[normalizeURL(pageURL)] = page
+		}
+	}
+
+	issues := []HreflangError{}
+	for _, pageURL := range sortedPageURLs(pages) {
+		page := pages[pageURL]
+		if page == nil {
+			continue
+		}
+
+		langs := make(map[string]string)
+		for _, link := range page.Hreflang.Links {
+			if previous, ok := langs[link.Lang]; ok && !sameURL(previous, link.URL) {
+				issues = append(issues, HreflangError{
+					Page: pageURL, Target: link.URL, Lang: link.Lang,
+					Issue: "conflicting hreflang: " + link.Lang + " also points to " + previous,
+				})
+				continue
+			}
+			langs[link.Lang] = link.URL
+
+			if sameURL(link.URL, pageURL) {
+				continue // self-reference
+			}
+			target, analyzed := byURL[normalizeURL(link.URL)]
+			if !analyzed {
+				continue // can't verify pages outside the set
+			}
+			if !linksBack(target, pageURL) {
+				issues = append(issues, HreflangError{
+					Page: pageURL, Target: link.URL, Lang: link.Lang,
+					Issue: "missing return tag: " + link.URL + " has no hreflang link back to " + pageURL,
+				})
+			}
+		}
+	}
+
+	return issues
+}
+
+// linksBack reports whether page declares an hreflang alternate for target
+func linksBack(page *SEOAnalysis, target string) bool {
+	for _, link := range page.Hreflang.Links {
+		if sameURL(link.URL, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// sortedPageURLs returns the keys of pages in ascending order
+func sortedPageURLs(pages map[string]*SEOAnalysis) []string {
+	urls := make([]string, 0, len(pages))
+	for pageURL := range pages {
+		urls = append(urls, pageURL)
+	}
+	sort.Strings(urls)
+	return urls
+}
@@ -0,0 +1,54 @@
+package analyzer
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+)
+
+// MaxInlineHTMLSize is the largest document AnalyzeHTML accepts
+const MaxInlineHTMLSize = 5 << 20
+
+// AnalyzeHTML runs the full analysis on html supplied by the caller instead
+// of fetching a page, e.g. markup from a staging build. baseURL is the URL
+// the page will be served at; relative links are resolved against it and
+// link checks still make outbound requests. The page size is the length of
+// html and load time metrics are zero. Results are not cached.
+func (a *Analyzer) AnalyzeHTML(ctx context.Context, html []byte, baseURL string, opts AnalyzeOptions) (*SEOAnalysis, error) {
+	if a.MaintenanceMode() {
+		return nil, ErrMaintenance
+	}
+	if len(html) > MaxInlineHTMLSize {
+		return nil, fmt.Errorf("HTML exceeds the %d byte limit", MaxInlineHTMLSize)
+	}
+	if parsed, err := url.Parse(baseURL); err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return nil, fmt.Errorf("base URL must be an absolute http(s) URL")
+	}
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+	done, err := a.beginAnalysis()
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+
+	ctx, release, err := a.queue.acquire(ctx, opts.ClientKey, opts.Priority)
+	if err != nil {
+		return nil, fmt.Errorf("waiting for an analysis slot: %w", err)
+	}
+	defer release()
+
+	analysis := analysisPool.Get().(*SEOAnalysis)
+	analysis.URL = baseURL
+	analysis.URLAnalysis = analyzeURLString(baseURL)
+	analysis.Content.KeywordDensity = make(map[string]float64)
+	analysis.Headers.H1Text = analysis.Headers.H1Text[:0]
+	analysis.Device = opts.deviceProfile()
+	analysis.Warnings = nil
+	analysis.Redirects = analyzeRedirects(nil, baseURL, baseURL)
+	analysis.Response = ResponseInfo{}
+	analysis.CacheHeaders = CacheHeaders{}
+
+	return a.analyzeDocument(ctx, analysis, html, baseURL, len(html), 0, opts, nil)
+}
@@ -0,0 +1,54 @@
+package analyzer
+
+import (
+	"context"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// analyzeIcons finds the declared favicon ("icon" also matches "shortcut
+// icon"), apple-touch-icon and web app manifest. Pages declaring no favicon
+// get one from /favicon.ico if it loads, as browsers would. With verify
+// set, each declared URL is checked through the link cache and the ones that
+// fail to load are reported as broken.
+func (a *Analyzer) analyzeIcons(ctx context.Context, doc *goquery.Document, pageURL string, verify bool) IconAnalysis {
+	icons := IconAnalysis{
+		Favicon:        linkRelURL(doc, pageURL, "icon"),
+		AppleTouchIcon: linkRelURL(doc, pageURL, "apple-touch-icon"),
+		Manifest:       linkRelURL(doc, pageURL, "manifest"),
+		Broken:         []string{},
+	}
+	if icons.AppleTouchIcon == "" {
+		icons.AppleTouchIcon = linkRelURL(doc, pageURL, "apple-touch-icon-precomposed")
+	}
+	icons.HasFavicon = icons.Favicon != ""
+	icons.HasAppleTouchIcon = icons.AppleTouchIcon != ""
+
+	if !icons.HasFavicon {
+		if fallback := resolveURL(pageURL, "/favicon.ico"); a.isLinkAccessibleWithContext(ctx, fallback) {
+			icons.Favicon = fallback
+			icons.HasFavicon = true
+			icons.FaviconFallback = true
+		}
+	}
+	if !verify {
+		return icons
+	}
+
+	icons.Verified = true
+	declared := []string{icons.AppleTouchIcon, icons.Manifest}
+	if !icons.FaviconFallback {
+		// The fallback was loaded above; a declared favicon that doesn't load
+		// doesn't count
+		declared = append(declared, icons.Favicon)
+	}
+	for _, assetURL := range declared {
+		if assetURL != "" && !a.isLinkAccessibleWithContext(ctx, assetURL) {
+			icons.Broken = append(icons.Broken, assetURL)
+			if assetURL == icons.Favicon {
+				icons.HasFavicon = false
+			}
+		}
+	}
+	return icons
+}
@@ -0,0 +1,191 @@
+package analyzer
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+const (
+	// defaultLargeImageBytes is the size above which an image is flagged as
+	// oversized
+	defaultLargeImageBytes = 200 << 10
+	// defaultMaxImageProbes caps how many images of a page are probed
+	defaultMaxImageProbes = 20
+	// imageProbeTimeout bounds all image probes of one page
+	imageProbeTimeout = 10 * time.Second
+)
+
+// SetLargeImageThreshold sets the size in bytes above which an image is
+// reported in LargeImages
+func (a *Analyzer) SetLargeImageThreshold(bytes int64) {
+	if bytes <= 0 {
+		return
+	}
+	a.configMutex.Lock()
+	defer a.configMutex.Unlock()
+	a.largeImageBytes = bytes
+}
+
+// SetMaxImageProbes caps how many unique images per page are probed for
+// their size. 0 disables probing.
+func (a *Analyzer) SetMaxImageProbes(n int) {
+	if n < 0 {
+		return
+	}
+	a.configMutex.Lock()
+	defer a.configMutex.Unlock()
+	a.maxImageProbes = n
+}
+
+// imageURLs returns the unique absolute http(s) URLs of the page's <img>
+// elements in document order
+func imageURLs(doc *goquery.Document, pageURL string) []string {
+	var urls []string
+	seen := make(map[string]bool)
+	doc.Find("img[src]").Each(func(_ int, s *goquery.Selection) {
+		src := strings.TrimSpace(s.AttrOr("src", ""))
+		if src == "" || strings.HasPrefix(src, "data:") {
+			return
+		}
+		resolved := resolveURL(pageURL, src)
+		if !strings.HasPrefix(resolved, "http://") && !strings.HasPrefix(resolved, "https://") {
+			return
+		}
+		if !seen[resolved] {
+			seen[resolved] = true
+			urls = append(urls, resolved)
+		}
+	})
+	return urls
+}
+
+// analyzeImages checks the page's images: their sizes, then whether each
+// one loads. Probing records the status in the link cache, so the images it
+// covers aren't requested again by the broken image check.
+func (a *Analyzer) analyzeImages(ctx context.Context, doc *goquery.Document, pageURL string, content *ContentAnalysis) {
+	urls := imageURLs(doc, pageURL)
+	a.analyzeImageSizes(ctx, urls, content)
+	content.BrokenImages = a.countBrokenImages(ctx, urls)
+}
+
+// analyzeImageSizes reads the Content-Length of up to maxImageProbes images
+// with HEAD requests, flagging those over the large image threshold. Images
+// that don't report a length are left out of the total.
+func (a *Analyzer) analyzeImageSizes(ctx context.Context, urls []string, content *ContentAnalysis) {
+	a.configMutex.RLock()
+	threshold := a.largeImageBytes
+	maxProbes := a.maxImageProbes
+	a.configMutex.RUnlock()
+
+	content.LargeImages = []string{}
+	if len(urls) > maxProbes {
+		urls = urls[:maxProbes]
+	}
+	if len(urls) == 0 {
+		return
+	}
+
+	probeCtx, cancel := context.WithTimeout(ctx, imageProbeTimeout)
+	defer cancel()
+
+	sizes := make([]int64, len(urls))
+	var wg sync.WaitGroup
+	semaphore := make(chan struct{}, 10) // Limit to 10 concurrent requests
+	for i, imageURL := range urls {
+		wg.Add(1)
+		go func(i int, imageURL string) {
+			defer wg.Done()
+
+			semaphore <- struct{}{}
+			defer func() { <-semaphore }()
+
+			sizes[i] = a.probeImageSize(probeCtx, imageURL)
+		}(i, imageURL)
+	}
+	wg.Wait()
+
+	// Keep document order in the report
+	for i, size := range sizes {
+		if size < 0 {
+			continue
+		}
+		content.ImagesProbed++
+		content.TotalImageBytes += int(size)
+		if size > threshold {
+			content.LargeImages = append(content.LargeImages, urls[i])
+		}
+	}
+}
+
+// countBrokenImages checks each image URL through the link cache with the
+// link checker's concurrency limit and counts the ones that fail to load
+func (a *Analyzer) countBrokenImages(ctx context.Context, urls []string) int {
+	if len(urls) == 0 {
+		return 0
+	}
+	checkCtx, cancel := context.WithTimeout(ctx, 15*time.Second)
+	defer cancel()
+
+	broken := 0
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	semaphore := make(chan struct{}, 10) // Limit to 10 concurrent requests
+	for _, imageURL := range urls {
+		wg.Add(1)
+		go func(imageURL string) {
+			defer wg.Done()
+
+			semaphore <- struct{}{}
+			defer func() { <-semaphore }()
+
+			if !a.isLinkAccessibleWithContext(checkCtx, imageURL) {
+				mu.Lock()
+				broken++
+				mu.Unlock()
+			}
+		}(imageURL)
+	}
+	wg.Wait()
+	return broken
+}
+
+// probeImageSize returns the Content-Length of a HEAD request for imageURL,
+// or -1 if it can't be determined. The response status is cached like a
+// link check.
+func (a *Analyzer) probeImageSize(ctx context.Context, imageURL string) int64 {
+	if a.checkDomainAllowed(imageURL) != nil {
+		return -1
+	}
+	req, err := http.NewRequestWithContext(ctx, "HEAD", imageURL, nil)
+	if err != nil {
+		return -1
+	}
+	userAgent := linkUserAgent(ctx)
+	req.Header.Set("User-Agent", userAgent)
+	if a.breaker.allow(req.URL.Host) != nil {
+		return -1
+	}
+
+	client := &http.Client{
+		Timeout:   5 * time.Second,
+		Transport: a.client.Transport,
+	}
+	resp, err := client.Do(req)
+	a.recordHostResult(ctx, req.URL.Host, err, 0)
+	if err != nil {
+		return -1
+	}
+	resp.Body.Close()
+	// Images are judged on their HEAD status, as the link checker does for
+	// binary resources
+	a.cacheAndReturnLinkStatus(linkCacheKey(imageURL, userAgent), resp.StatusCode >= 200 && resp.StatusCode < 400)
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return -1
+	}
+	return resp.ContentLength
+}
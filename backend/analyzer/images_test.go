@@ -0,0 +1,104 @@
+package analyzer
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestAnalyzeImageSizes(t *testing.T) {
+	var probes atomic.Int32
+	site := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/":
+			w.Header().Set("Content-Type", "text/html")
+			fmt.Fprint(w, `<html><head><title>Images</title></head><body>
+<img src="/big.jpg" alt="Big"><img src="/small.png" alt="Small"><img src="/big.jpg" alt="Again">
+<img src="/unknown.gif" alt="Unknown"><img src="data:image/gif;base64,R0lGOD" alt="Inline">
+</body></html>`)
+		case "/big.jpg":
+			probes.Add(1)
+			w.Header().Set("Content-Length", "500000")
+		case "/small.png":
+			probes.Add(1)
+			w.Header().Set("Content-Length", "1000")
+		case "/unknown.gif":
+			probes.Add(1)
+			http.NotFound(w, r)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer site.Close()
+
+	analyzer := newTestAnalyzer(t)
+	analysis, err := analyzer.Analyze(site.URL + "/")
+	if err != nil {
+		t.Fatalf("Failed to analyze: %v", err)
+	}
+	content := analysis.Content
+	if content.ImagesProbed != 2 || content.TotalImageBytes != 501000 {
+		t.Errorf("Expected 2 sized images totalling 501000 bytes, got %d and %d", content.ImagesProbed, content.TotalImageBytes)
+	}
+	if len(content.LargeImages) != 1 || content.LargeImages[0] != site.URL+"/big.jpg" {
+		t.Errorf("Expected big.jpg to be flagged, got %v", content.LargeImages)
+	}
+	// The broken image check reuses the probe results from the link cache
+	if got := probes.Load(); got != 3 {
+		t.Errorf("Expected each unique image to be requested once, got %d requests", got)
+	}
+	if content.BrokenImages != 1 {
+		t.Errorf("Expected unknown.gif to be broken, got %d broken images", content.BrokenImages)
+	}
+	recommendations := strings.Join(analysis.Recommendations, "\n")
+	if !strings.Contains(recommendations, "oversized image") || !strings.Contains(recommendations, "1 broken image") {
+		t.Errorf("Expected oversized and broken image recommendations, got %v", analysis.Recommendations)
+	}
+
+	// The probe cap bounds the size checks of image-heavy pages
+	analyzer = newTestAnalyzer(t)
+	analyzer.SetMaxImageProbes(1)
+	analysis, err = analyzer.Analyze(site.URL + "/")
+	if err != nil {
+		t.Fatalf("Failed to analyze: %v", err)
+	}
+	if analysis.Content.ImagesProbed != 1 || analysis.Content.BrokenImages != 1 {
+		t.Errorf("Expected 1 sized image and every image checked, got %d sized and %d broken",
+			analysis.Content.ImagesProbed, analysis.Content.BrokenImages)
+	}
+}
+
+func TestLazyAndResponsiveImages(t *testing.T) {
+	images := `<img src="/a.jpg" alt="A" loading="lazy" srcset="/a-2x.jpg 2x">` +
+		`<img src="/b.jpg" alt="B" srcset="/b-480.jpg 480w, /b-800.jpg 800w" sizes="50vw">` +
+		`<img src="/c.jpg" alt="C" loading="eager"><img src="/d.jpg" alt="D">`
+	words := strings.Repeat("word ", longContentWords+100)
+	site := newSiteServer(t, map[string]string{
+		"/long":  `<html><head><title>Long</title></head><body><p>` + words + `</p>` + images + `</body></html>`,
+		"/short": `<html><head><title>Short</title></head><body>` + images + `</body></html>`,
+	})
+
+	analyzer := newTestAnalyzer(t)
+	analysis, err := analyzer.Analyze(site.URL + "/long")
+	if err != nil {
+		t.Fatalf("Failed to analyze: %v", err)
+	}
+	if analysis.Content.LazyLoadedImages != 1 || analysis.Content.ResponsiveImages != 2 {
+		t.Errorf("Expected 1 lazy and 2 responsive images, got %d and %d",
+			analysis.Content.LazyLoadedImages, analysis.Content.ResponsiveImages)
+	}
+	if !strings.Contains(strings.Join(analysis.Recommendations, "\n"), `loading="lazy"`) {
+		t.Errorf("Expected a lazy loading recommendation on a long page, got %v", analysis.Recommendations)
+	}
+
+	analysis, err = analyzer.Analyze(site.URL + "/short")
+	if err != nil {
+		t.Fatalf("Failed to analyze: %v", err)
+	}
+	if strings.Contains(strings.Join(analysis.Recommendations, "\n"), `loading="lazy"`) {
+		t.Error("Expected no lazy loading recommendation on a short page")
+	}
+}
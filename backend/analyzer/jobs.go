@@ -0,0 +1,308 @@
+package analyzer
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/seo-optimizer/backend/logging"
+)
+
+// JobKind selects what an async job runs
+type JobKind string
+
+const (
+	// JobAnalyze analyzes one URL
+	JobAnalyze JobKind = "analyze"
+	// JobBatch analyzes a list of URLs like AnalyzeBatch
+	JobBatch JobKind = "batch"
+	// JobCrawl crawls a site like Crawl and runs the cross-page checks
+	JobCrawl JobKind = "crawl"
+)
+
+// JobStatus is the lifecycle state of an async job
+type JobStatus string
+
+const (
+	JobPending JobStatus = "pending"
+	JobRunning JobStatus = "running"
+	JobDone    JobStatus = "done"
+	JobFailed  JobStatus = "failed"
+)
+
+const (
+	// defaultMaxJobs bounds how many jobs are kept, finished ones included
+	defaultMaxJobs = 1000
+	// defaultJobTTL is how long finished jobs can still be polled
+	defaultJobTTL = time.Hour
+	// jobTimeout bounds how long one job may run
+	jobTimeout = 10 * time.Minute
+)
+
+// ErrTooManyJobs is returned when the job store is full of unfinished or
+// recently finished jobs
+var ErrTooManyJobs = errors.New("too many jobs; try again later")
+
+// JobRequest describes the work of an async job
+type JobRequest struct {
+	Kind JobKind
+	// URL is the page to analyze, or the start URL of a crawl
+	URL string
+	// URLs are the pages of a batch
+	URLs []string
+	// MaxDepth and MaxPages bound a crawl (see Crawl)
+	MaxDepth int
+	MaxPages int
+	Options  AnalyzeOptions
+	// CallbackURL, if set, receives the finished job as a signed POST
+	CallbackURL string
+}
+
+// CrawlResult is the result of a crawl job
+type CrawlResult struct {
+	Pages map[string]*SEOAnalysis `json:"pages"`
+	Site  SiteAnalysis            `json:"site"`
+}
+
+// Job is a snapshot of an async job. Result is a *SEOAnalysis, a
+// *BatchAnalysis or a *CrawlResult depending on Kind, and is only set once
+// the job is done.
+type Job struct {
+	ID         string      `json:"id"`
+	Kind       JobKind     `json:"kind"`
+	Status     JobStatus   `json:"status"`
+	CreatedAt  time.Time   `json:"createdAt"`
+	StartedAt  *time.Time  `json:"startedAt,omitempty"`
+	FinishedAt *time.Time  `json:"finishedAt,omitempty"`
+	Error      string      `json:"error,omitempty"`
+	Result     interface{} `json:"result,omitempty"`
+	// Callback is "pending", "delivered" or "failed" for jobs with a
+	// callback URL
+	Callback string `json:"callback,omitempty"`
+}
+
+// jobStore keeps jobs in memory, bounded in number, and forgets finished
+// jobs after a TTL
+type jobStore struct {
+	mutex   sync.Mutex
+	jobs    map[string]*Job
+	maxJobs int
+	ttl     time.Duration
+}
+
+func newJobStore() *jobStore {
+	return &jobStore{
+		jobs:    make(map[string]*Job),
+		maxJobs: defaultMaxJobs,
+		ttl:     defaultJobTTL,
+	}
+}
+
+// add stores a new pending job, failing with ErrTooManyJobs if the store is
+// full even after dropping expired jobs
+func (s *jobStore) add(job *Job) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if len(s.jobs) >= s.maxJobs {
+		s.pruneLocked()
+	}
+	if len(s.jobs) >= s.maxJobs {
+		return ErrTooManyJobs
+	}
+	s.jobs[job.ID] = job
+	return nil
+}
+
+// get returns a copy of the job with the given ID
+func (s *jobStore) get(id string) (Job, bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	job, ok := s.jobs[id]
+	if !ok {
+		return Job{}, false
+	}
+	return *job, true
+}
+
+// update applies change to the job with the given ID
+func (s *jobStore) update(id string, change func(*Job)) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if job, ok := s.jobs[id]; ok {
+		change(job)
+	}
+}
+
+// prune drops finished jobs older than the TTL
+func (s *jobStore) prune() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.pruneLocked()
+}
+
+func (s *jobStore) pruneLocked() {
+	now := time.Now()
+	for id, job := range s.jobs {
+		if job.FinishedAt != nil && now.Sub(*job.FinishedAt) > s.ttl {
+			delete(s.jobs, id)
+		}
+	}
+}
+
+// newJobID returns a random job ID
+func newJobID() (string, error) {
+	var id [16]byte
+	if _, err := rand.Read(id[:]); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(id[:]), nil
+}
+
+// SetJobLimits sets how many jobs are kept at most and how long finished
+// jobs remain available; non-positive values keep the current setting
+func (a *Analyzer) SetJobLimits(maxJobs int, ttl time.Duration) {
+	a.jobs.mutex.Lock()
+	defer a.jobs.mutex.Unlock()
+	if maxJobs > 0 {
+		a.jobs.maxJobs = maxJobs
+	}
+	if ttl > 0 {
+		a.jobs.ttl = ttl
+	}
+}
+
+// SubmitJob validates req and starts it in the background, returning the
+// pending job. Poll it with GetJob. Jobs are queued with PriorityBatch unless
+// req.Options sets a priority.
+func (a *Analyzer) SubmitJob(req JobRequest) (Job, error) {
+	if a.MaintenanceMode() {
+		return Job{}, ErrMaintenance
+	}
+	if req.Kind == "" {
+		req.Kind = JobAnalyze
+	}
+	if req.Options.Priority == "" {
+		req.Options.Priority = PriorityBatch
+	}
+	if err := req.Options.Validate(); err != nil {
+		return Job{}, err
+	}
+	switch req.Kind {
+	case JobAnalyze, JobCrawl:
+		target, err := ParseTargetURL(req.URL)
+		if err != nil {
+			return Job{}, fmt.Errorf("url: %w", err)
+		}
+		if err := a.checkDomainAllowed(target); err != nil {
+			return Job{}, err
+		}
+		req.URL = target
+	case JobBatch:
+		if len(req.URLs) == 0 || len(req.URLs) > MaxBatchSize {
+			return Job{}, ErrBatchSize
+		}
+		urls := make([]string, len(req.URLs))
+		for i, raw := range req.URLs {
+			target, err := ParseTargetURL(raw)
+			if err != nil {
+				return Job{}, fmt.Errorf("urls[%d]: %w", i, err)
+			}
+			urls[i] = target
+		}
+		req.URLs = urls
+	default:
+		return Job{}, fmt.Errorf("unknown job type %q", req.Kind)
+	}
+	if req.CallbackURL != "" {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		err := validateCallbackURL(ctx, req.CallbackURL)
+		cancel()
+		if err != nil {
+			return Job{}, err
+		}
+	}
+
+	id, err := newJobID()
+	if err != nil {
+		return Job{}, fmt.Errorf("failed to create job ID: %w", err)
+	}
+	job := &Job{ID: id, Kind: req.Kind, Status: JobPending, CreatedAt: time.Now()}
+	if req.CallbackURL != "" {
+		job.Callback = "pending"
+	}
+
+	// Shutdown waits for running jobs like for any other analysis
+	done, err := a.beginAnalysis()
+	if err != nil {
+		return Job{}, err
+	}
+	if err := a.jobs.add(job); err != nil {
+		done()
+		return Job{}, err
+	}
+
+	snapshot := *job
+	go func() {
+		defer done()
+		a.runJob(id, req)
+	}()
+	return snapshot, nil
+}
+
+// GetJob returns the current state of a job
+func (a *Analyzer) GetJob(id string) (Job, bool) {
+	return a.jobs.get(id)
+}
+
+// runJob runs a submitted job and records its outcome
+func (a *Analyzer) runJob(id string, req JobRequest) {
+	started := time.Now()
+	a.jobs.update(id, func(job *Job) {
+		job.Status = JobRunning
+		job.StartedAt = &started
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), jobTimeout)
+	defer cancel()
+
+	var result interface{}
+	var err error
+	switch req.Kind {
+	case JobAnalyze:
+		result, _, err = a.analyze(ctx, req.URL, req.Options)
+	case JobBatch:
+		result, err = a.AnalyzeBatchContext(ctx, req.URLs, req.Options)
+	case JobCrawl:
+		var pages map[string]*SEOAnalysis
+		if pages, err = a.CrawlWithOptions(ctx, req.URL, req.MaxDepth, req.MaxPages, req.Options); err == nil {
+			result = &CrawlResult{Pages: pages, Site: AnalyzeSite(pages)}
+		}
+	}
+
+	finished := time.Now()
+	a.jobs.update(id, func(job *Job) {
+		job.FinishedAt = &finished
+		if err != nil {
+			job.Status = JobFailed
+			job.Error = err.Error()
+			return
+		}
+		job.Status = JobDone
+		job.Result = result
+	})
+	logging.Debug("Job finished", "id", id, "kind", req.Kind, "error", err, "duration", finished.Sub(started))
+
+	if req.CallbackURL != "" {
+		job, _ := a.jobs.get(id)
+		job.Callback = ""
+		status := "failed"
+		if a.deliverCallback(req.CallbackURL, job) {
+			status = "delivered"
+		}
+		a.jobs.update(id, func(stored *Job) { stored.Callback = status })
+	}
+}
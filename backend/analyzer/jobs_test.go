@@ -0,0 +1,115 @@
+package analyzer
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// waitForJob polls a job until it finishes
+func waitForJob(t *testing.T, analyzer *Analyzer, id string) Job {
+	t.Helper()
+	deadline := time.Now().Add(10 * time.Second)
+	for {
+		job, ok := analyzer.GetJob(id)
+		if !ok {
+			t.Fatalf("Job %s disappeared", id)
+		}
+		if job.Status == JobDone || job.Status == JobFailed {
+			return job
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Timed out waiting for job %s (status %s)", id, job.Status)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestJobs(t *testing.T) {
+	server := newSiteServer(t, map[string]string{
+		"/":      `<html><head><title>Home</title></head><body><a href="/about">About</a></body></html>`,
+		"/about": `<html><head><title>About</title></head><body><a href="/">Home</a></body></html>`,
+	})
+	analyzer := newTestAnalyzer(t)
+
+	job, err := analyzer.SubmitJob(JobRequest{URL: server.URL + "/"})
+	if err != nil {
+		t.Fatalf("Failed to submit job: %v", err)
+	}
+	if job.ID == "" || job.Status != JobPending || job.Kind != JobAnalyze {
+		t.Errorf("Expected a pending analyze job, got %+v", job)
+	}
+	job = waitForJob(t, analyzer, job.ID)
+	analysis, ok := job.Result.(*SEOAnalysis)
+	if job.Status != JobDone || !ok || analysis.Title.Title != "Home" {
+		t.Errorf("Expected a finished analysis of Home, got %+v", job)
+	}
+	if job.StartedAt == nil || job.FinishedAt == nil {
+		t.Error("Expected start and finish times")
+	}
+
+	crawl, err := analyzer.SubmitJob(JobRequest{Kind: JobCrawl, URL: server.URL + "/", MaxDepth: 1})
+	if err != nil {
+		t.Fatalf("Failed to submit crawl: %v", err)
+	}
+	crawl = waitForJob(t, analyzer, crawl.ID)
+	if result, ok := crawl.Result.(*CrawlResult); !ok || len(result.Pages) != 2 {
+		t.Errorf("Expected a crawl of 2 pages, got %+v", crawl)
+	}
+
+	failed, err := analyzer.SubmitJob(JobRequest{URL: server.URL + "/missing"})
+	if err != nil {
+		t.Fatalf("Failed to submit job: %v", err)
+	}
+	if failed = waitForJob(t, analyzer, failed.ID); failed.Status != JobFailed || failed.Error == "" {
+		t.Errorf("Expected a failed job with an error, got %+v", failed)
+	}
+
+	if _, err := analyzer.SubmitJob(JobRequest{Kind: "export", URL: server.URL}); err == nil {
+		t.Error("Expected an unknown job type to be rejected")
+	}
+	if _, err := analyzer.SubmitJob(JobRequest{Kind: JobBatch}); err != ErrBatchSize {
+		t.Errorf("Expected ErrBatchSize for an empty batch, got %v", err)
+	}
+	if _, err := analyzer.SubmitJob(JobRequest{Kind: JobBatch, URLs: []string{server.URL, "ftp://example.com/"}}); !errors.Is(err, ErrInvalidURL) {
+		t.Errorf("Expected ErrInvalidURL for a batch with an ftp URL, got %v", err)
+	}
+	if _, ok := analyzer.GetJob("unknown"); ok {
+		t.Error("Expected an unknown job ID to be missing")
+	}
+}
+
+func TestJobStoreLimits(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+		w.Header().Set("Content-Type", "text/html")
+		fmt.Fprint(w, `<html><head><title>Home</title></head></html>`)
+	}))
+	defer server.Close()
+	analyzer := newTestAnalyzer(t)
+	analyzer.SetJobLimits(1, time.Millisecond)
+
+	first, err := analyzer.SubmitJob(JobRequest{URL: server.URL + "/"})
+	if err != nil {
+		t.Fatalf("Failed to submit job: %v", err)
+	}
+	// The unfinished job holds the only slot
+	if _, err := analyzer.SubmitJob(JobRequest{URL: server.URL + "/"}); err != ErrTooManyJobs {
+		t.Errorf("Expected ErrTooManyJobs, got %v", err)
+	}
+
+	// Once finished and expired, its slot is reused
+	close(release)
+	waitForJob(t, analyzer, first.ID)
+	time.Sleep(5 * time.Millisecond)
+	if _, err := analyzer.SubmitJob(JobRequest{URL: server.URL + "/"}); err != nil {
+		t.Errorf("Expected the expired job to make room, got %v", err)
+	}
+	if _, ok := analyzer.GetJob(first.ID); ok {
+		t.Error("Expected the expired job to be dropped")
+	}
+}
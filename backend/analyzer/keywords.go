@@ -0,0 +1,146 @@
+package analyzer
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/PuerkitoBio/goquery"
+	"golang.org/x/net/html"
+)
+
+// Keyword density limits
+const (
+	// maxKeywords is how many terms (single words and bigrams) are reported
+	maxKeywords = 15
+	// minKeywordWords is the least text a page needs before densities mean
+	// anything; shorter pages report no keywords
+	minKeywordWords = 20
+	// minKeywordCount drops terms that appear only once
+	minKeywordCount = 2
+)
+
+// contentStopWords are common English words that never count as keywords
+var contentStopWords = map[string]bool{
+	"a": true, "about": true, "after": true, "all": true, "also": true, "an": true,
+	"and": true, "any": true, "are": true, "as": true, "at": true, "be": true,
+	"because": true, "been": true, "but": true, "by": true, "can": true, "could": true,
+	"did": true, "do": true, "does": true, "each": true, "for": true, "from": true,
+	"had": true, "has": true, "have": true, "he": true, "her": true, "here": true,
+	"his": true, "how": true, "i": true, "if": true, "in": true, "into": true,
+	"is": true, "it": true, "its": true, "it's": true, "just": true, "me": true,
+	"more": true, "most": true, "my": true, "no": true, "not": true, "now": true,
+	"of": true, "on": true, "one": true, "only": true, "or": true, "other": true,
+	"our": true, "out": true, "over": true, "she": true, "so": true, "some": true,
+	"such": true, "than": true, "that": true, "the": true, "their": true, "them": true,
+	"then": true, "there": true, "these": true, "they": true, "this": true, "those": true,
+	"to": true, "too": true, "up": true, "us": true, "very": true, "was": true,
+	"we": true, "were": true, "what": true, "when": true, "where": true, "which": true,
+	"while": true, "who": true, "why": true, "will": true, "with": true, "would": true,
+	"you": true, "your": true,
+}
+
+// invisibleElements hold text that is never shown as page content
+var invisibleElements = map[string]bool{
+	"script": true, "style": true, "noscript": true, "template": true, "svg": true,
+}
+
+// KeywordFrequency is one term of the keyword density report
+type KeywordFrequency struct {
+	Keyword string  `json:"keyword"`
+	Count   int     `json:"count"`
+	Density float64 `json:"density"` // percentage of all words on the page
+}
+
+// visibleText returns the text of the selection, skipping scripts and
+// styles and separating text nodes so words in adjacent tags don't merge
+func visibleText(s *goquery.Selection) string {
+	var b strings.Builder
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		switch {
+		case n.Type == html.TextNode:
+			b.WriteString(n.Data)
+			b.WriteByte(' ')
+			return
+		case n.Type == html.ElementNode && invisibleElements[n.Data]:
+			return
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	for _, n := range s.Nodes {
+		walk(n)
+	}
+	return b.String()
+}
+
+// tokenize lowercases text and splits it into words made of letters and
+// digits (in any script), keeping inner apostrophes and hyphens
+func tokenize(text string) []string {
+	fields := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r) && r != '\'' && r != '’' && r != '-'
+	})
+	words := fields[:0]
+	for _, field := range fields {
+		word := strings.Trim(strings.ReplaceAll(field, "’", "'"), "'-")
+		if word != "" {
+			words = append(words, word)
+		}
+	}
+	return words
+}
+
+// isKeyword reports whether a word can be a keyword: not a stop word, not
+// a bare number and longer than one character
+func isKeyword(word string) bool {
+	if contentStopWords[word] || len([]rune(word)) < 2 {
+		return false
+	}
+	return strings.IndexFunc(word, unicode.IsLetter) >= 0
+}
+
+// keywordDensity returns the most frequent single words and two-word
+// phrases of the text, most dense first. Density is the number of
+// occurrences as a percentage of all words, stop words included.
+func keywordDensity(text string) []KeywordFrequency {
+	words := tokenize(text)
+	if len(words) < minKeywordWords {
+		return []KeywordFrequency{}
+	}
+
+	counts := make(map[string]int)
+	for i, word := range words {
+		if !isKeyword(word) {
+			continue
+		}
+		counts[word]++
+		// Phrases are only formed from adjacent keywords
+		if i+1 < len(words) && isKeyword(words[i+1]) {
+			counts[word+" "+words[i+1]]++
+		}
+	}
+
+	keywords := []KeywordFrequency{}
+	for keyword, count := range counts {
+		if count < minKeywordCount {
+			continue
+		}
+		keywords = append(keywords, KeywordFrequency{
+			Keyword: keyword,
+			Count:   count,
+			Density: float64(count) / float64(len(words)) * 100,
+		})
+	}
+	sort.Slice(keywords, func(i, j int) bool {
+		if keywords[i].Count != keywords[j].Count {
+			return keywords[i].Count > keywords[j].Count
+		}
+		return keywords[i].Keyword < keywords[j].Keyword
+	})
+	if len(keywords) > maxKeywords {
+		keywords = keywords[:maxKeywords]
+	}
+	return keywords
+}
@@ -0,0 +1,60 @@
+package analyzer
+
+import (
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// mixedContentLinkRels are the link relations whose target the browser
+// loads as a subresource; navigational links such as canonical don't count
+var mixedContentLinkRels = map[string]bool{
+	"stylesheet": true, "icon": true, "apple-touch-icon": true, "manifest": true,
+	"preload": true, "modulepreload": true,
+}
+
+// findMixedContent returns the unique http:// resources an https:// page
+// loads from img, script, link and iframe elements, in document order.
+// Pages served over plain HTTP have no mixed content.
+func findMixedContent(doc *goquery.Document, pageURL string) []string {
+	mixed := []string{}
+	if !strings.HasPrefix(strings.ToLower(pageURL), "https://") {
+		return mixed
+	}
+
+	seen := make(map[string]bool)
+	add := func(ref string) {
+		ref = strings.TrimSpace(ref)
+		if ref == "" {
+			return
+		}
+		resolved := resolveURL(pageURL, ref)
+		if strings.HasPrefix(strings.ToLower(resolved), "http://") && !seen[resolved] {
+			seen[resolved] = true
+			mixed = append(mixed, resolved)
+		}
+	}
+
+	doc.Find("img, script[src], link[href], iframe[src]").Each(func(_ int, s *goquery.Selection) {
+		switch goquery.NodeName(s) {
+		case "img":
+			add(s.AttrOr("src", ""))
+			// srcset candidates are "URL [descriptor]" separated by commas
+			for _, candidate := range strings.Split(s.AttrOr("srcset", ""), ",") {
+				if fields := strings.Fields(candidate); len(fields) > 0 {
+					add(fields[0])
+				}
+			}
+		case "link":
+			for _, rel := range strings.Fields(strings.ToLower(s.AttrOr("rel", ""))) {
+				if mixedContentLinkRels[rel] {
+					add(s.AttrOr("href", ""))
+					return
+				}
+			}
+		default:
+			add(s.AttrOr("src", ""))
+		}
+	})
+	return mixed
+}
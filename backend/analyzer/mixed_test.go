@@ -0,0 +1,64 @@
+package analyzer
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+func TestFindMixedContent(t *testing.T) {
+	page := `<html><head>
+		<link rel="stylesheet" href="http://cdn.example.com/site.css">
+		<link rel="canonical" href="http://example.com/page">
+		<link rel="alternate" hreflang="de" href="http://example.com/de/">
+		<link rel="icon" href="/favicon.ico">
+		<script src="http://cdn.example.com/app.js"></script>
+		<script src="//cdn.example.com/relative-scheme.js"></script>
+		</head><body>
+		<img src="http://img.example.com/a.png" srcset="http://img.example.com/a-2x.png 2x, https://img.example.com/a-3x.png 3x">
+		<img src="HTTP://img.example.com/a.png">
+		<iframe src="http://video.example.com/embed"></iframe>
+		<a href="http://example.com/other">Plain links aren't loaded</a>
+		</body></html>`
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(page))
+	if err != nil {
+		t.Fatalf("Failed to parse HTML: %v", err)
+	}
+
+	want := []string{
+		"http://cdn.example.com/site.css",
+		"http://cdn.example.com/app.js",
+		"http://img.example.com/a.png",
+		"http://img.example.com/a-2x.png",
+		"http://video.example.com/embed",
+	}
+	if got := findMixedContent(doc, "https://example.com/page"); !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+	if got := findMixedContent(doc, "http://example.com/page"); len(got) != 0 {
+		t.Errorf("Expected no mixed content on an HTTP page, got %v", got)
+	}
+}
+
+func TestMixedContentRecommendation(t *testing.T) {
+	// Nothing listens on port 1, so the resource checks fail fast
+	html := []byte(`<html><head><title>Secure</title>
+		<script src="http://127.0.0.1:1/app.js"></script></head><body></body></html>`)
+	analysis, err := newTestAnalyzer(t).AnalyzeHTML(context.Background(), html, "https://127.0.0.1:1/", AnalyzeOptions{})
+	if err != nil {
+		t.Fatalf("Failed to analyze HTML: %v", err)
+	}
+	if len(analysis.MixedContent) != 1 || analysis.MixedContent[0] != "http://127.0.0.1:1/app.js" {
+		t.Errorf("Expected the script to be reported, got %v", analysis.MixedContent)
+	}
+	found := false
+	for _, rec := range analysis.Recommendations {
+		found = found || strings.HasPrefix(rec, "Critical: 1 resource(s) load over insecure HTTP")
+	}
+	if !found {
+		t.Errorf("Expected a mixed content recommendation, got %v", analysis.Recommendations)
+	}
+}
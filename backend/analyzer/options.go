@@ -0,0 +1,241 @@
+package analyzer
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Analysis timeouts. Link checks get half of the overall budget.
+const (
+	DefaultAnalysisTimeout = 30 * time.Second
+	MinAnalysisTimeout     = 5 * time.Second
+	MaxAnalysisTimeout     = 60 * time.Second
+)
+
+// FetchMode controls how error status codes from the analyzed page are handled
+type FetchMode string
+
+const (
+	// FetchModeFailFast aborts the analysis when the page returns an error status
+	FetchModeFailFast FetchMode = "failFast"
+	// FetchModeBestEffort analyzes whatever body came back, with a warning
+	FetchModeBestEffort FetchMode = "bestEffort"
+)
+
+// Profile selects how many optional checks an analysis runs
+type Profile string
+
+const (
+	// ProfileStandard runs the default set of checks
+	ProfileStandard Profile = "standard"
+	// ProfileThorough adds checks that are noisy on older-but-functional
+	// sites, such as deprecated markup
+	ProfileThorough Profile = "thorough"
+)
+
+// Device selects which kind of visitor the analyzed page is fetched as
+type Device string
+
+const (
+	DeviceDesktop Device = "desktop"
+	DeviceMobile  Device = "mobile"
+)
+
+// DeviceProfile describes how a page is requested for a device
+type DeviceProfile struct {
+	Name          Device `json:"name"`
+	UserAgent     string `json:"userAgent"`
+	ViewportWidth int    `json:"viewportWidth"`
+}
+
+// deviceProfiles maps each supported device to its request profile
+var deviceProfiles = map[Device]DeviceProfile{
+	DeviceDesktop: {
+		Name:          DeviceDesktop,
+		UserAgent:     "SEOAnalyzer/1.0",
+		ViewportWidth: 1366,
+	},
+	// The mobile UA is modeled on Googlebot Smartphone but identifies as
+	// this analyzer
+	DeviceMobile: {
+		Name:          DeviceMobile,
+		UserAgent:     "Mozilla/5.0 (Linux; Android 6.0.1; Nexus 5X Build/MMB29P) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Mobile Safari/537.36 (compatible; SEOAnalyzer/1.0)",
+		ViewportWidth: 412,
+	},
+}
+
+// setHeaders identifies a page request as coming from this device. Mobile
+// requests also send the viewport width as Viewport-Width and Width client
+// hints, for servers that adapt markup to the screen.
+func (p DeviceProfile) setHeaders(req *http.Request) {
+	req.Header.Set("User-Agent", p.UserAgent)
+	if p.Name == DeviceMobile {
+		width := strconv.Itoa(p.ViewportWidth)
+		req.Header.Set("Viewport-Width", width)
+		req.Header.Set("Width", width)
+	}
+}
+
+// Priority orders waiting analyses when queue priorities are enabled
+type Priority string
+
+const (
+	// PriorityInteractive is for a user waiting on the result (default)
+	PriorityInteractive Priority = "interactive"
+	// PriorityBatch is for batch and crawl jobs
+	PriorityBatch Priority = "batch"
+	// PriorityWarmup is for background cache warm-up
+	PriorityWarmup Priority = "warmup"
+)
+
+// rank orders priorities; lower ranks are served first
+func (p Priority) rank() int {
+	switch p {
+	case PriorityBatch:
+		return 1
+	case PriorityWarmup:
+		return 2
+	default:
+		return 0
+	}
+}
+
+// maxTargetKeywordLength bounds the target keyword option
+const maxTargetKeywordLength = 100
+
+// AnalyzeOptions customizes a single analysis. The zero value matches the
+// behavior of Analyze.
+type AnalyzeOptions struct {
+	Mode    FetchMode
+	Profile Profile
+	// Device is the visitor the page is fetched as (default desktop)
+	Device Device
+	// CheckAMP also fetches the page's amphtml variant and verifies that the
+	// AMP and canonical pages point at each other
+	CheckAMP bool
+	// ClientKey identifies the requesting client (IP or API key) for fair
+	// scheduling. It does not affect the result or its cache key.
+	ClientKey string
+	// Priority decides the order of waiting analyses when queue priorities
+	// are enabled (default interactive). It does not affect the result.
+	Priority Priority
+	// Weights overrides the overall score weight of individual sections
+	// (title, meta, headers, content, performance, links)
+	Weights map[string]float64
+	// TargetKeyword is the keyword the page is optimized for; internal link
+	// anchors containing it are counted
+	TargetKeyword string
+	// UserAgent overrides the device's User-Agent for the page fetch and
+	// link checks, e.g. to emulate Googlebot
+	UserAgent string
+	// BypassCache skips the cache lookup and overwrites the cached entry
+	// with the fresh result. It does not affect the cache key.
+	BypassCache bool
+	// SkipLinkCheck counts and categorizes links without requesting them;
+	// BrokenLinks stays 0 and LinksChecked false
+	SkipLinkCheck bool
+	// Timeout bounds the whole analysis (default DefaultAnalysisTimeout),
+	// clamped to MinAnalysisTimeout-MaxAnalysisTimeout. It does not affect
+	// the cache key.
+	Timeout time.Duration
+}
+
+// Validate checks that all option values are known
+func (o AnalyzeOptions) Validate() error {
+	switch o.Mode {
+	case "", FetchModeFailFast, FetchModeBestEffort:
+	default:
+		return fmt.Errorf("unknown mode %q", o.Mode)
+	}
+	switch o.Profile {
+	case "", ProfileStandard, ProfileThorough:
+	default:
+		return fmt.Errorf("unknown profile %q", o.Profile)
+	}
+	if _, ok := deviceProfiles[o.Device]; !ok && o.Device != "" {
+		return fmt.Errorf("unknown device %q", o.Device)
+	}
+	switch o.Priority {
+	case "", PriorityInteractive, PriorityBatch, PriorityWarmup:
+	default:
+		return fmt.Errorf("unknown priority %q", o.Priority)
+	}
+	if err := validateWeights(o.Weights); err != nil {
+		return err
+	}
+	if o.Timeout < 0 {
+		return fmt.Errorf("timeout must not be negative")
+	}
+	if len(o.TargetKeyword) > maxTargetKeywordLength {
+		return fmt.Errorf("target keyword is longer than %d characters", maxTargetKeywordLength)
+	}
+	return validateUserAgent(o.UserAgent)
+}
+
+// cacheKey returns the cache key for url analyzed with these options, so
+// results produced under different options never collide
+func (o AnalyzeOptions) cacheKey(url string) string {
+	var variant []string
+	if o.Mode == FetchModeBestEffort {
+		variant = append(variant, string(o.Mode))
+	}
+	if o.Profile == ProfileThorough {
+		variant = append(variant, string(o.Profile))
+	}
+	if o.Device == DeviceMobile {
+		variant = append(variant, string(o.Device))
+	}
+	if o.CheckAMP {
+		variant = append(variant, "amp")
+	}
+	if o.SkipLinkCheck {
+		variant = append(variant, "nolinks")
+	}
+	if o.TargetKeyword != "" {
+		variant = append(variant, "keyword:"+strings.ToLower(o.TargetKeyword))
+	}
+	if len(o.Weights) > 0 {
+		variant = append(variant, "weights:"+weightsKey(o.Weights))
+	}
+	if o.UserAgent != "" {
+		variant = append(variant, "ua:"+o.UserAgent)
+	}
+	if len(variant) == 0 {
+		return generateCacheKey(url)
+	}
+	return generateCacheKey(url + "|" + strings.Join(variant, "|"))
+}
+
+// EffectiveTimeout returns the timeout an analysis with these options runs
+// under
+func (o AnalyzeOptions) EffectiveTimeout() time.Duration {
+	switch {
+	case o.Timeout <= 0:
+		return DefaultAnalysisTimeout
+	case o.Timeout < MinAnalysisTimeout:
+		return MinAnalysisTimeout
+	case o.Timeout > MaxAnalysisTimeout:
+		return MaxAnalysisTimeout
+	}
+	return o.Timeout
+}
+
+// linkCheckTimeout bounds the link checks of an analysis
+func (o AnalyzeOptions) linkCheckTimeout() time.Duration {
+	return o.EffectiveTimeout() / 2
+}
+
+// deviceProfile returns the request profile for the selected device
+func (o AnalyzeOptions) deviceProfile() DeviceProfile {
+	profile := deviceProfiles[DeviceDesktop]
+	if o.Device != "" {
+		profile = deviceProfiles[o.Device]
+	}
+	if o.UserAgent != "" {
+		profile.UserAgent = o.UserAgent
+	}
+	return profile
+}
@@ -0,0 +1,51 @@
+package analyzer
+
+import (
+	"encoding/json"
+	"math"
+)
+
+// DefaultOutputDecimals is the number of decimal places float fields are
+// rounded to when an analysis is serialized
+const DefaultOutputDecimals = 2
+
+// roundTo rounds v to the given number of decimal places
+func roundTo(v float64, decimals int) float64 {
+	p := math.Pow10(decimals)
+	return math.Round(v*p) / p
+}
+
+// plainAnalysis has the fields of SEOAnalysis but no MarshalJSON, so it
+// serializes at full precision
+type plainAnalysis SEOAnalysis
+
+// MarshalJSON rounds float fields to the output precision configured on the
+// analyzer that produced the analysis. The analysis itself keeps full
+// precision; only the serialized form is rounded.
+func (s SEOAnalysis) MarshalJSON() ([]byte, error) {
+	out := plainAnalysis(s)
+
+	decimals := DefaultOutputDecimals
+	if s.outputDecimals != nil {
+		decimals = *s.outputDecimals
+	}
+
+	out.Score = roundTo(s.Score, decimals)
+	out.Content.ReadabilityScore = roundTo(s.Content.ReadabilityScore, decimals)
+	out.Content.TextToHTMLRatio = roundTo(s.Content.TextToHTMLRatio, decimals)
+	if s.Content.KeywordDensity != nil {
+		out.Content.KeywordDensity = make(map[string]float64, len(s.Content.KeywordDensity))
+		for word, density := range s.Content.KeywordDensity {
+			out.Content.KeywordDensity[word] = roundTo(density, decimals)
+		}
+	}
+	if s.Content.TopKeywords != nil {
+		out.Content.TopKeywords = make([]KeywordFrequency, len(s.Content.TopKeywords))
+		for i, keyword := range s.Content.TopKeywords {
+			keyword.Density = roundTo(keyword.Density, decimals)
+			out.Content.TopKeywords[i] = keyword
+		}
+	}
+
+	return json.Marshal(out)
+}
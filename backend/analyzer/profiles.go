@@ -0,0 +1,122 @@
+package analyzer
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// KeyProfile holds the default analysis settings stored for an API key.
+// Options sent with a request take precedence over the profile.
+type KeyProfile struct {
+	Mode     FetchMode          `json:"mode,omitempty"`
+	Profile  Profile            `json:"profile,omitempty"`
+	Device   Device             `json:"device,omitempty"`
+	CheckAMP bool               `json:"checkAmp,omitempty"`
+	Weights  map[string]float64 `json:"weights,omitempty"`
+}
+
+// Apply fills the options a request left unset from the profile. Weights
+// are merged per section, with the request's values winning.
+func (p KeyProfile) Apply(opts AnalyzeOptions) AnalyzeOptions {
+	if opts.Mode == "" {
+		opts.Mode = p.Mode
+	}
+	if opts.Profile == "" {
+		opts.Profile = p.Profile
+	}
+	if opts.Device == "" {
+		opts.Device = p.Device
+	}
+	opts.CheckAMP = opts.CheckAMP || p.CheckAMP
+	if len(p.Weights) > 0 {
+		opts.Weights = mergeWeights(p.Weights, opts.Weights)
+	}
+	return opts
+}
+
+// Validate checks that all profile values are known
+func (p KeyProfile) Validate() error {
+	return p.Apply(AnalyzeOptions{}).Validate()
+}
+
+// profileStore persists KeyProfiles in the data directory. Profiles are
+// keyed by a hash of the API key so the keys themselves are never written
+// to disk.
+type profileStore struct {
+	mutex    sync.RWMutex
+	filePath string
+	profiles map[string]KeyProfile
+}
+
+func newProfileStore(dataDir string) (*profileStore, error) {
+	store := &profileStore{
+		filePath: filepath.Join(dataDir, "profiles.json"),
+		profiles: make(map[string]KeyProfile),
+	}
+
+	data, err := os.ReadFile(store.filePath)
+	if os.IsNotExist(err) {
+		return store, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read profiles: %w", err)
+	}
+	if err := json.Unmarshal(data, &store.profiles); err != nil {
+		return nil, fmt.Errorf("failed to parse profiles: %w", err)
+	}
+	return store, nil
+}
+
+func hashAPIKey(apiKey string) string {
+	sum := sha256.Sum256([]byte(apiKey))
+	return hex.EncodeToString(sum[:])
+}
+
+func (s *profileStore) get(apiKey string) (KeyProfile, bool) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	profile, ok := s.profiles[hashAPIKey(apiKey)]
+	return profile, ok
+}
+
+func (s *profileStore) set(apiKey string, profile KeyProfile) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.profiles[hashAPIKey(apiKey)] = profile
+	data, err := json.Marshal(s.profiles)
+	if err != nil {
+		return fmt.Errorf("failed to marshal profiles: %w", err)
+	}
+
+	// Write to a temporary file first so a crash never leaves a partial file
+	tempFile := s.filePath + ".tmp"
+	if err := os.WriteFile(tempFile, data, 0600); err != nil {
+		return fmt.Errorf("failed to write profiles: %w", err)
+	}
+	if err := os.Rename(tempFile, s.filePath); err != nil {
+		return fmt.Errorf("failed to save profiles: %w", err)
+	}
+	return nil
+}
+
+// KeyProfile returns the default settings stored for apiKey
+func (a *Analyzer) KeyProfile(apiKey string) (KeyProfile, bool) {
+	return a.profiles.get(apiKey)
+}
+
+// SetKeyProfile validates and persists the default settings for apiKey
+func (a *Analyzer) SetKeyProfile(apiKey string, profile KeyProfile) error {
+	if apiKey == "" {
+		return fmt.Errorf("an API key is required")
+	}
+	if err := profile.Validate(); err != nil {
+		return err
+	}
+	return a.profiles.set(apiKey, profile)
+}
@@ -0,0 +1,99 @@
+package analyzer
+
+import (
+	"context"
+	"time"
+
+	"github.com/seo-optimizer/backend/logging"
+)
+
+// resultQueueSize is how many completed analyses may wait to be published
+// before new ones are dropped
+const resultQueueSize = 256
+
+// publishTimeout bounds a single Publish call
+const publishTimeout = 10 * time.Second
+
+// ResultMessage is what a ResultPublisher receives for every fresh analysis
+type ResultMessage struct {
+	URL         string       `json:"url"`
+	Mode        FetchMode    `json:"mode,omitempty"`
+	Profile     Profile      `json:"profile,omitempty"`
+	Device      Device       `json:"device,omitempty"`
+	CompletedAt time.Time    `json:"completedAt"`
+	DurationMs  int64        `json:"durationMs"`
+	Analysis    *SEOAnalysis `json:"analysis"`
+}
+
+// ResultPublisher receives completed analyses, e.g. to push them onto a
+// message queue. Publish is called from a background goroutine, one
+// message at a time.
+type ResultPublisher interface {
+	Publish(ctx context.Context, msg ResultMessage) error
+}
+
+// resultPublisherQueue hands messages to the publisher asynchronously so
+// publishing never delays an analysis response
+type resultPublisherQueue struct {
+	publisher ResultPublisher
+	messages  chan ResultMessage
+}
+
+func newResultPublisherQueue(publisher ResultPublisher) *resultPublisherQueue {
+	q := &resultPublisherQueue{
+		publisher: publisher,
+		messages:  make(chan ResultMessage, resultQueueSize),
+	}
+	go q.run()
+	return q
+}
+
+func (q *resultPublisherQueue) run() {
+	for msg := range q.messages {
+		ctx, cancel := context.WithTimeout(context.Background(), publishTimeout)
+		if err := q.publisher.Publish(ctx, msg); err != nil {
+			logging.Error("Failed to publish analysis", "url", logging.RedactURL(msg.URL), "error", err)
+		}
+		cancel()
+	}
+}
+
+func (q *resultPublisherQueue) enqueue(msg ResultMessage) {
+	select {
+	case q.messages <- msg:
+	default:
+		logging.Warn("Result publisher is behind; dropping analysis", "url", logging.RedactURL(msg.URL))
+	}
+}
+
+// SetResultPublisher publishes every fresh (non-cached) analysis to p.
+// Passing nil disables publishing, which is the default.
+func (a *Analyzer) SetResultPublisher(p ResultPublisher) {
+	a.configMutex.Lock()
+	defer a.configMutex.Unlock()
+	if a.publisher != nil {
+		close(a.publisher.messages)
+		a.publisher = nil
+	}
+	if p != nil {
+		a.publisher = newResultPublisherQueue(p)
+	}
+}
+
+// publishResult queues a completed analysis for the result publisher
+func (a *Analyzer) publishResult(url string, opts AnalyzeOptions, analysis *SEOAnalysis, duration time.Duration) {
+	a.configMutex.RLock()
+	defer a.configMutex.RUnlock()
+	if a.publisher == nil {
+		return
+	}
+	a.publisher.enqueue(ResultMessage{
+		URL:         url,
+		Mode:        opts.Mode,
+		Profile:     opts.Profile,
+		Device:      opts.deviceProfile().Name,
+		CompletedAt: time.Now(),
+		DurationMs:  duration.Milliseconds(),
+		Analysis:    analysis,
+	})
+}
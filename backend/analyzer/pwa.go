@@ -0,0 +1,103 @@
+package analyzer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// maxManifestBytes is the largest web app manifest read
+const maxManifestBytes = 1 << 20
+
+// serviceWorkerRegisterPattern matches navigator.serviceWorker.register()
+// calls, capturing the script URL when it's a string literal
+var serviceWorkerRegisterPattern = regexp.MustCompile(`serviceWorker\s*\.\s*register\s*\(\s*(?:["'\x60]([^"'\x60]+)["'\x60])?`)
+
+// webManifest holds the web app manifest members that installability
+// depends on
+type webManifest struct {
+	Name      string            `json:"name"`
+	ShortName string            `json:"short_name"`
+	StartURL  string            `json:"start_url"`
+	Icons     []json.RawMessage `json:"icons"`
+}
+
+// analyzePWA checks the page's installability signals: the web app manifest
+// declared with <link rel="manifest">, which is fetched and parsed, and
+// inline scripts registering a service worker
+func (a *Analyzer) analyzePWA(ctx context.Context, doc *goquery.Document, pageURL string) PWAAnalysis {
+	var pwa PWAAnalysis
+
+	doc.Find("script:not([src])").EachWithBreak(func(_ int, s *goquery.Selection) bool {
+		match := serviceWorkerRegisterPattern.FindStringSubmatch(s.Text())
+		if match == nil {
+			return true
+		}
+		pwa.ServiceWorker = true
+		if match[1] != "" {
+			pwa.ServiceWorkerURL = resolveURL(pageURL, match[1])
+		}
+		return false
+	})
+
+	pwa.ManifestURL = linkRelURL(doc, pageURL, "manifest")
+	pwa.HasManifest = pwa.ManifestURL != ""
+	if !pwa.HasManifest {
+		return pwa
+	}
+
+	manifest, err := a.fetchManifest(ctx, pwa.ManifestURL)
+	if err != nil {
+		pwa.ManifestError = err.Error()
+		return pwa
+	}
+	pwa.ManifestValid = true
+	pwa.HasName = strings.TrimSpace(manifest.Name) != "" || strings.TrimSpace(manifest.ShortName) != ""
+	pwa.HasIcons = len(manifest.Icons) > 0
+	pwa.HasStartURL = strings.TrimSpace(manifest.StartURL) != ""
+	pwa.Installable = pwa.HasName && pwa.HasIcons && pwa.HasStartURL && pwa.ServiceWorker
+	return pwa
+}
+
+// fetchManifest downloads and parses the web app manifest at manifestURL
+func (a *Analyzer) fetchManifest(ctx context.Context, manifestURL string) (*webManifest, error) {
+	if err := a.checkDomainAllowed(manifestURL); err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, "GET", manifestURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", linkUserAgent(ctx))
+	if err := a.breaker.allow(req.URL.Host); err != nil {
+		return nil, err
+	}
+
+	client := &http.Client{
+		Timeout:   10 * time.Second,
+		Transport: a.client.Transport,
+	}
+	resp, err := client.Do(req)
+	a.recordHostResult(ctx, req.URL.Host, err, 0)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("manifest returned HTTP status %d", resp.StatusCode)
+	}
+
+	var manifest webManifest
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxManifestBytes)).Decode(&manifest); err != nil {
+		return nil, fmt.Errorf("invalid manifest: %w", err)
+	}
+	return &manifest, nil
+}
@@ -0,0 +1,77 @@
+package analyzer
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPWAAnalysis(t *testing.T) {
+	server := newSiteServer(t, map[string]string{
+		"/app": `<html><head>
+			<link rel="manifest" href="/app.webmanifest">
+			<script>
+			if ('serviceWorker' in navigator) {
+				navigator.serviceWorker.register('/sw.js');
+			}
+			</script>
+			</head><body></body></html>`,
+		"/app.webmanifest": `{"short_name": "App", "start_url": "/", "icons": [{"src": "/icon.png", "sizes": "192x192"}]}`,
+		"/partial":         `<html><head><link rel="manifest" href="/partial.json"></head><body></body></html>`,
+		"/partial.json":    `{"name": "Partial"}`,
+		"/invalid":         `<html><head><link rel="manifest" href="/invalid.json"></head><body></body></html>`,
+		"/invalid.json":    `not json`,
+		"/plain":           `<html><head><title>Plain</title></head><body></body></html>`,
+	})
+	analyzer := newTestAnalyzer(t)
+
+	analysis, err := analyzer.Analyze(server.URL + "/app")
+	if err != nil {
+		t.Fatalf("Failed to analyze URL: %v", err)
+	}
+	pwa := analysis.PWA
+	if !pwa.HasManifest || !pwa.ManifestValid || !pwa.HasName || !pwa.HasIcons || !pwa.HasStartURL {
+		t.Errorf("Expected a complete manifest, got %+v", pwa)
+	}
+	if !pwa.ServiceWorker || pwa.ServiceWorkerURL != server.URL+"/sw.js" {
+		t.Errorf("Expected the service worker registration, got %+v", pwa)
+	}
+	if !pwa.Installable {
+		t.Error("Expected the page to be installable")
+	}
+
+	analysis, err = analyzer.Analyze(server.URL + "/partial")
+	if err != nil {
+		t.Fatalf("Failed to analyze URL: %v", err)
+	}
+	if !analysis.PWA.ManifestValid || !analysis.PWA.HasName || analysis.PWA.HasIcons || analysis.PWA.Installable {
+		t.Errorf("Expected a manifest without icons, got %+v", analysis.PWA)
+	}
+	found := false
+	for _, rec := range analysis.Recommendations {
+		found = found || strings.Contains(rec, "icons, start_url")
+	}
+	if !found {
+		t.Errorf("Expected a recommendation for the missing manifest members, got %v", analysis.Recommendations)
+	}
+
+	analysis, err = analyzer.Analyze(server.URL + "/invalid")
+	if err != nil {
+		t.Fatalf("Failed to analyze URL: %v", err)
+	}
+	if analysis.PWA.ManifestValid || analysis.PWA.ManifestError == "" {
+		t.Errorf("Expected the manifest to fail to parse, got %+v", analysis.PWA)
+	}
+
+	analysis, err = analyzer.Analyze(server.URL + "/plain")
+	if err != nil {
+		t.Fatalf("Failed to analyze URL: %v", err)
+	}
+	if analysis.PWA.HasManifest || analysis.PWA.ServiceWorker {
+		t.Errorf("Expected no PWA signals, got %+v", analysis.PWA)
+	}
+	for _, rec := range analysis.Recommendations {
+		if strings.Contains(rec, "manifest") || strings.Contains(rec, "service worker") {
+			t.Errorf("Expected no PWA recommendations without a manifest, got %q", rec)
+		}
+	}
+}
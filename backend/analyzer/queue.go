@@ -0,0 +1,181 @@
+package analyzer
+
+import (
+	"context"
+	"sync"
+)
+
+// analysisQueue limits how many analyses run at once and decides which
+// waiting request gets the next free slot. Waiters are served in arrival
+// order unless fair scheduling is enabled, in which case slots rotate
+// between clients so one client's burst can't starve the others. With
+// priorities enabled, higher-priority waiters always go first.
+type analysisQueue struct {
+	mu         sync.Mutex
+	limit      int // 0 means unlimited
+	active     int
+	fair       bool
+	priorities bool
+	seq        uint64
+	waiting    []*queueWaiter
+	lastServed map[string]uint64 // client key -> seq of its last granted slot
+}
+
+type queueWaiter struct {
+	key      string
+	priority Priority
+	ready    chan struct{}
+}
+
+// slotHolderKey marks a context whose analysis already holds a slot, so
+// nested analyses (e.g. the AMP variant) don't wait for a second one
+type slotHolderKey struct{}
+
+func newAnalysisQueue() *analysisQueue {
+	return &analysisQueue{lastServed: make(map[string]uint64)}
+}
+
+// setLimit changes the number of concurrent analyses (0 = unlimited)
+func (q *analysisQueue) setLimit(limit int) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.limit = limit
+	q.dispatch()
+}
+
+// setFair switches between FIFO and per-client round-robin scheduling
+func (q *analysisQueue) setFair(fair bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.fair = fair
+}
+
+// setPriorities enables serving higher-priority waiters first
+func (q *analysisQueue) setPriorities(enabled bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.priorities = enabled
+}
+
+// acquire waits for a free slot on behalf of the client identified by key.
+// The returned release function must be called when the analysis is done.
+func (q *analysisQueue) acquire(ctx context.Context, key string, priority Priority) (context.Context, func(), error) {
+	if ctx.Value(slotHolderKey{}) != nil {
+		return ctx, func() {}, nil
+	}
+	slotCtx := context.WithValue(ctx, slotHolderKey{}, true)
+
+	q.mu.Lock()
+	if q.hasFreeSlot() && len(q.waiting) == 0 {
+		q.grant(key)
+		q.mu.Unlock()
+		return slotCtx, q.release, nil
+	}
+	w := &queueWaiter{key: key, priority: priority, ready: make(chan struct{})}
+	q.waiting = append(q.waiting, w)
+	q.mu.Unlock()
+
+	select {
+	case <-w.ready:
+		return slotCtx, q.release, nil
+	case <-ctx.Done():
+		q.mu.Lock()
+		for i, waiting := range q.waiting {
+			if waiting == w {
+				q.waiting = append(q.waiting[:i], q.waiting[i+1:]...)
+				q.mu.Unlock()
+				return ctx, nil, ctx.Err()
+			}
+		}
+		q.mu.Unlock()
+		// The slot was granted while we were giving up; hand it on
+		q.release()
+		return ctx, nil, ctx.Err()
+	}
+}
+
+// release frees a slot and hands it to the next waiter
+func (q *analysisQueue) release() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.active--
+	q.dispatch()
+}
+
+// waitingCount returns the number of analyses waiting for a slot
+func (q *analysisQueue) waitingCount() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.waiting)
+}
+
+func (q *analysisQueue) hasFreeSlot() bool {
+	return q.limit <= 0 || q.active < q.limit
+}
+
+// grant records a slot handed to key. Must be called with q.mu held.
+func (q *analysisQueue) grant(key string) {
+	q.active++
+	q.seq++
+	q.lastServed[key] = q.seq
+}
+
+// dispatch wakes waiters while slots are free. Must be called with q.mu held.
+func (q *analysisQueue) dispatch() {
+	for q.hasFreeSlot() && len(q.waiting) > 0 {
+		i := q.next()
+		w := q.waiting[i]
+		q.waiting = append(q.waiting[:i], q.waiting[i+1:]...)
+		q.grant(w.key)
+		close(w.ready)
+	}
+
+	// Forget clients with nothing queued so the map doesn't grow unbounded
+	if len(q.lastServed) > len(q.waiting) {
+		queued := make(map[string]bool, len(q.waiting))
+		for _, w := range q.waiting {
+			queued[w.key] = true
+		}
+		for key := range q.lastServed {
+			if !queued[key] {
+				delete(q.lastServed, key)
+			}
+		}
+	}
+}
+
+// next returns the index of the waiter to serve next. Only waiters of the
+// highest waiting priority are considered when priorities are enabled.
+// Among those, fair mode picks the oldest waiter of the client served least
+// recently; otherwise the oldest waiter wins.
+func (q *analysisQueue) next() int {
+	top := q.waiting[0].priority.rank()
+	if q.priorities {
+		for _, w := range q.waiting {
+			if r := w.priority.rank(); r < top {
+				top = r
+			}
+		}
+	}
+	eligible := func(w *queueWaiter) bool {
+		return !q.priorities || w.priority.rank() == top
+	}
+
+	best := -1
+	for i, w := range q.waiting {
+		if !eligible(w) {
+			continue
+		}
+		if best < 0 {
+			best = i
+			if !q.fair {
+				break
+			}
+			continue
+		}
+		if q.lastServed[w.key] < q.lastServed[q.waiting[best].key] {
+			best = i
+		}
+	}
+	return best
+}
@@ -0,0 +1,155 @@
+package analyzer
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// runQueueScenario floods the analyzer from one client, then sends a few
+// requests from another, and returns the order in which pages were fetched
+func runQueueScenario(t *testing.T, fair bool) []string {
+	t.Helper()
+
+	var mu sync.Mutex
+	var order []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/robots.txt" || r.URL.Path == "/favicon.ico" {
+			http.NotFound(w, r)
+			return
+		}
+		mu.Lock()
+		order = append(order, r.URL.Path)
+		mu.Unlock()
+		time.Sleep(10 * time.Millisecond)
+		w.Header().Set("Content-Type", "text/html")
+		fmt.Fprint(w, "<html><head><title>Queued</title></head><body></body></html>")
+	}))
+	t.Cleanup(server.Close)
+
+	analyzer := newTestAnalyzer(t)
+	analyzer.SetMaxConcurrentAnalyses(1)
+	analyzer.SetFairScheduling(fair)
+
+	var wg sync.WaitGroup
+	analyze := func(path, client string) {
+		defer wg.Done()
+		opts := AnalyzeOptions{ClientKey: client}
+		if _, err := analyzer.AnalyzeWithOptions(server.URL+path, opts); err != nil {
+			t.Errorf("Failed to analyze %s: %v", path, err)
+		}
+	}
+	waitForQueued := func(n int) {
+		deadline := time.Now().Add(5 * time.Second)
+		for analyzer.queue.waitingCount() < n {
+			if time.Now().After(deadline) {
+				t.Fatalf("Timed out waiting for %d queued analyses", n)
+			}
+			time.Sleep(time.Millisecond)
+		}
+	}
+
+	const flood = 10
+	for i := 0; i < flood; i++ {
+		wg.Add(1)
+		go analyze(fmt.Sprintf("/heavy/%d", i), "heavy")
+	}
+	waitForQueued(flood - 1)
+	for i := 0; i < 2; i++ {
+		wg.Add(1)
+		go analyze(fmt.Sprintf("/light/%d", i), "light")
+		waitForQueued(flood + i)
+	}
+	wg.Wait()
+
+	return order
+}
+
+func TestQueueFairScheduling(t *testing.T) {
+	lastLight := func(order []string) int {
+		last := -1
+		for i, path := range order {
+			if strings.HasPrefix(path, "/light/") {
+				last = i
+			}
+		}
+		return last
+	}
+
+	// FIFO (default): the light client waits behind the whole flood
+	if got := lastLight(runQueueScenario(t, false)); got != 11 {
+		t.Errorf("Expected FIFO to serve the light client last (position 11), got %d", got)
+	}
+
+	// Fair: the light client alternates with the heavy one
+	if got := lastLight(runQueueScenario(t, true)); got > 4 {
+		t.Errorf("Expected fair scheduling to serve the light client within 5 fetches, got position %d", got)
+	}
+}
+
+func TestQueuePriorities(t *testing.T) {
+	run := func(priorities bool) int {
+		var mu sync.Mutex
+		var order []string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/robots.txt" || r.URL.Path == "/favicon.ico" {
+				http.NotFound(w, r)
+				return
+			}
+			mu.Lock()
+			order = append(order, r.URL.Path)
+			mu.Unlock()
+			time.Sleep(20 * time.Millisecond)
+			w.Header().Set("Content-Type", "text/html")
+			fmt.Fprint(w, "<html><head><title>Queued</title></head><body></body></html>")
+		}))
+		defer server.Close()
+
+		analyzer := newTestAnalyzer(t)
+		analyzer.SetMaxConcurrentAnalyses(1)
+		analyzer.SetQueuePriorities(priorities)
+
+		var wg sync.WaitGroup
+		analyze := func(path string, priority Priority) {
+			defer wg.Done()
+			opts := AnalyzeOptions{ClientKey: "client", Priority: priority}
+			if _, err := analyzer.AnalyzeWithOptions(server.URL+path, opts); err != nil {
+				t.Errorf("Failed to analyze %s: %v", path, err)
+			}
+		}
+
+		const batch = 10
+		for i := 0; i < batch; i++ {
+			wg.Add(1)
+			go analyze(fmt.Sprintf("/batch/%d", i), PriorityBatch)
+		}
+		for deadline := time.Now().Add(5 * time.Second); analyzer.queue.waitingCount() < batch-1; {
+			if time.Now().After(deadline) {
+				t.Fatal("Timed out waiting for the batch to queue")
+			}
+			time.Sleep(time.Millisecond)
+		}
+		wg.Add(1)
+		go analyze("/interactive", "")
+		wg.Wait()
+
+		for i, path := range order {
+			if path == "/interactive" {
+				return i
+			}
+		}
+		t.Fatal("Interactive request was never fetched")
+		return -1
+	}
+
+	if got := run(false); got != 10 {
+		t.Errorf("Expected equal priorities to serve the interactive request last (position 10), got %d", got)
+	}
+	if got := run(true); got != 1 {
+		t.Errorf("Expected the interactive request right after the running batch item, got position %d", got)
+	}
+}
@@ -0,0 +1,164 @@
+package analyzer
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// DefaultMaxHeadBytes is how much of a page QuickAnalyze reads looking for
+// the end of the head before falling back to reading the whole body
+const DefaultMaxHeadBytes = 256 << 10
+
+// headReadChunk is the read size used while scanning for </head>
+const headReadChunk = 4 << 10
+
+var headClose = []byte("</head")
+
+// headCloseMaxLen bounds the length of a closing head tag we look for,
+// including some whitespace before the '>'
+const headCloseMaxLen = 16
+
+// readHead reads r until the closing head tag has been seen and stops
+// there, so the rest of a large body is never downloaded. If no closing tag
+// appears within limit bytes, it falls back to reading everything. It
+// reports whether the read stopped early.
+func readHead(r io.Reader, limit int) ([]byte, bool, error) {
+	var buf bytes.Buffer
+	chunk := make([]byte, headReadChunk)
+	scanFrom := 0
+
+	for buf.Len() < limit {
+		n, err := r.Read(chunk)
+		buf.Write(chunk[:n])
+
+		// Search only the new bytes (plus overlap for tags split across reads)
+		if end := headCloseEnd(buf.Bytes(), scanFrom); end > 0 {
+			return buf.Bytes()[:end], true, nil
+		}
+		if scanFrom = buf.Len() - headCloseMaxLen; scanFrom < 0 {
+			scanFrom = 0
+		}
+
+		if err == io.EOF {
+			return buf.Bytes(), false, nil
+		}
+		if err != nil {
+			return nil, false, err
+		}
+	}
+
+	// No </head> within the cap; read the full body
+	if _, err := io.Copy(&buf, r); err != nil {
+		return nil, false, err
+	}
+	return buf.Bytes(), false, nil
+}
+
+// headCloseEnd returns the offset just past the first closing head tag at or
+// after from, or 0 if the data doesn't contain a complete one yet.
+// "</header>" is not a closing head tag.
+func headCloseEnd(data []byte, from int) int {
+	lower := bytes.ToLower(data)
+	for from < len(lower) {
+		idx := bytes.Index(lower[from:], headClose)
+		if idx < 0 {
+			return 0
+		}
+		end := from + idx + len(headClose)
+		rest := bytes.TrimLeft(lower[end:], " \t\r\n")
+		if len(rest) == 0 {
+			return 0 // tag not complete yet
+		}
+		if rest[0] == '>' {
+			return len(data) - len(rest) + 1
+		}
+		from = end
+	}
+	return 0
+}
+
+// SetMaxHeadBytes sets how many bytes QuickAnalyze reads looking for the
+// end of the head before falling back to a full read
+func (a *Analyzer) SetMaxHeadBytes(n int) {
+	if n <= 0 {
+		return
+	}
+	a.configMutex.Lock()
+	defer a.configMutex.Unlock()
+	a.maxHeadBytes = n
+}
+
+// QuickAnalyze runs only the checks that need the document head (title,
+// meta tags, head order and social tags). It stops downloading once the
+// head is complete, so it is much cheaper than Analyze on large pages.
+func (a *Analyzer) QuickAnalyze(ctx context.Context, url string) (*QuickAnalysis, error) {
+	if a.MaintenanceMode() {
+		return nil, ErrMaintenance
+	}
+	if err := a.checkDomainAllowed(url); err != nil {
+		return nil, err
+	}
+	if err := a.checkTargetAllowed(ctx, url); err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, &FetchError{URL: url, Category: CategoryInvalidURL, Err: err}
+	}
+	req.Header.Set("User-Agent", deviceProfiles[DeviceDesktop].UserAgent)
+
+	start := time.Now()
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return nil, newFetchError(url, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		return nil, &FetchError{URL: url, Category: CategoryHTTPStatus, StatusCode: resp.StatusCode,
+			Err: fmt.Errorf("page returned HTTP status %d", resp.StatusCode)}
+	}
+
+	if contentType := resp.Header.Get("Content-Type"); !isHTMLContentType(contentType) {
+		return nil, &FetchError{URL: url, Category: CategoryNotHTML, StatusCode: resp.StatusCode,
+			Err: fmt.Errorf("%w (Content-Type %s)", ErrNotHTML, contentType)}
+	}
+
+	a.configMutex.RLock()
+	limit := a.maxHeadBytes
+	maxPageBytes := a.maxPageBytes
+	a.configMutex.RUnlock()
+
+	raw, headOnly, err := readHead(io.LimitReader(resp.Body, maxPageBytes), limit)
+	if err != nil {
+		return nil, newFetchError(url, err)
+	}
+
+	doc, err := goquery.NewDocumentFromReader(bytes.NewReader(raw))
+	if err != nil {
+		return nil, err
+	}
+
+	quick := &QuickAnalysis{
+		URL:       url,
+		Title:     a.analyzeTitleTag(doc),
+		Meta:      a.analyzeMetaTags(doc),
+		HeadOrder: a.analyzeHeadOrder(raw, doc),
+		Social:    a.analyzeSocialTags(ctx, doc, url, false),
+		BytesRead: len(raw),
+		HeadOnly:  headOnly,
+		LoadTime:  int(time.Since(start).Milliseconds()),
+	}
+	quick.Meta.Canonical = linkRelURL(doc, url, "canonical")
+	quick.Meta.AMPHTML = linkRelURL(doc, url, "amphtml")
+	checkOGURLCanonical(&quick.Social, quick.Meta.Canonical)
+
+	return quick, nil
+}
@@ -0,0 +1,82 @@
+package analyzer
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestQuickAnalyzeReadsOnlyHead(t *testing.T) {
+	body := strings.Repeat("<p>Lots of body content that a head-only check never needs.</p>\n", 50000)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		fmt.Fprint(w, `<html><head><meta charset="utf-8"><title>Big page</title>`)
+		if r.URL.Path != "/headless" {
+			fmt.Fprint(w, `<meta name="description" content="A big page"></HEAD>`)
+		}
+		fmt.Fprint(w, "<body>"+body+"</body></html>")
+	}))
+	defer server.Close()
+
+	analyzer := newTestAnalyzer(t)
+	quick, err := analyzer.QuickAnalyze(context.Background(), server.URL)
+	if err != nil {
+		t.Fatalf("Failed to quick-analyze URL: %v", err)
+	}
+	if !quick.HeadOnly {
+		t.Error("Expected the read to stop at </head>")
+	}
+	if quick.Title.Title != "Big page" || !quick.Meta.HasDescription {
+		t.Errorf("Expected head checks to run, got title %q and meta %+v", quick.Title.Title, quick.Meta)
+	}
+
+	full, err := analyzer.Analyze(server.URL)
+	if err != nil {
+		t.Fatalf("Failed to analyze URL: %v", err)
+	}
+	if quick.BytesRead*100 > full.Performance.PageSize {
+		t.Errorf("Expected the quick analysis to read far fewer bytes: %d vs %d", quick.BytesRead, full.Performance.PageSize)
+	}
+
+	// Without a closing head tag within the cap, the whole page is read
+	analyzer.SetMaxHeadBytes(16 << 10)
+	quick, err = analyzer.QuickAnalyze(context.Background(), server.URL+"/headless")
+	if err != nil {
+		t.Fatalf("Failed to quick-analyze URL: %v", err)
+	}
+	if quick.HeadOnly || quick.BytesRead < len(body) {
+		t.Errorf("Expected a full read fallback, got headOnly=%v after %d bytes", quick.HeadOnly, quick.BytesRead)
+	}
+}
+
+func TestReadHeadSplitTag(t *testing.T) {
+	// The closing tag straddles two reads
+	r := io.MultiReader(strings.NewReader("<head><title>x</title></he"), strings.NewReader("ad><body>rest</body>"))
+	raw, headOnly, err := readHead(r, 1024)
+	if err != nil {
+		t.Fatalf("readHead failed: %v", err)
+	}
+	if !headOnly || string(raw) != "<head><title>x</title></head>" {
+		t.Errorf("Expected to stop right after </head>, got %q (headOnly=%v)", raw, headOnly)
+	}
+}
+
+func TestHeadCloseEnd(t *testing.T) {
+	tests := map[string]int{
+		"<head></head><body>":       13,
+		"<head></head \n><body>":    15,
+		"<body><header></header>":   0,
+		"<header>x</header></head>": 25,
+		"<head></hea":               0,
+		"<head></head ":             0,
+	}
+	for input, want := range tests {
+		if got := headCloseEnd([]byte(input), 0); got != want {
+			t.Errorf("headCloseEnd(%q) = %d, want %d", input, got, want)
+		}
+	}
+}
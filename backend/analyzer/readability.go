@@ -0,0 +1,161 @@
+package analyzer
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/PuerkitoBio/goquery"
+	"golang.org/x/net/html"
+)
+
+// Readability limits
+const (
+	// minReadabilityWords is the least prose a page needs to be scored
+	minReadabilityWords = 100
+	// maxWordsPerSentence is the average sentence length above which
+	// sentence detection is taken to have failed, e.g. on unpunctuated
+	// lists of links
+	maxWordsPerSentence = 40
+	// hardToReadScore is the reading ease below which simpler content is
+	// recommended
+	hardToReadScore = 50
+)
+
+// readabilitySkipElements hold text that isn't prose: navigation, code and
+// form controls. Their text would skew sentence and syllable counts.
+var readabilitySkipElements = map[string]bool{
+	"nav": true, "header": true, "footer": true, "aside": true, "form": true,
+	"pre": true, "code": true, "kbd": true, "samp": true, "button": true,
+	"select": true, "textarea": true, "table": true,
+}
+
+// readabilityBlockElements end a sentence even without punctuation, as
+// headings and list items usually do
+var readabilityBlockElements = map[string]bool{
+	"p": true, "div": true, "section": true, "article": true, "main": true,
+	"li": true, "dt": true, "dd": true, "blockquote": true, "br": true,
+	"h1": true, "h2": true, "h3": true, "h4": true, "h5": true, "h6": true,
+	"figcaption": true, "caption": true,
+}
+
+// proseBlocks returns the visible prose of the body split at block
+// boundaries
+func proseBlocks(doc *goquery.Document) []string {
+	var blocks []string
+	var b strings.Builder
+	flush := func() {
+		if text := strings.TrimSpace(b.String()); text != "" {
+			blocks = append(blocks, text)
+		}
+		b.Reset()
+	}
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		switch {
+		case n.Type == html.TextNode:
+			b.WriteString(n.Data)
+			b.WriteByte(' ')
+			return
+		case n.Type == html.ElementNode && (invisibleElements[n.Data] || readabilitySkipElements[n.Data]):
+			return
+		}
+		block := n.Type == html.ElementNode && readabilityBlockElements[n.Data]
+		if block {
+			flush()
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+		if block {
+			flush()
+		}
+	}
+	for _, n := range doc.Find("body").Nodes {
+		walk(n)
+	}
+	flush()
+	return blocks
+}
+
+// readability computes the Flesch Reading Ease of the page's prose and its
+// band, from "very easy" to "very difficult". ok is false when there is too
+// little prose or sentences can't be told apart.
+func readability(doc *goquery.Document) (score float64, grade string, ok bool) {
+	var words, sentences, syllables int
+	for _, block := range proseBlocks(doc) {
+		blockWords := 0
+		ended := false
+		for _, field := range strings.Fields(block) {
+			word := strings.TrimFunc(field, func(r rune) bool { return !unicode.IsLetter(r) })
+			if word != "" {
+				blockWords++
+				syllables += countSyllables(word)
+			}
+			trimmed := strings.TrimRight(field, `"')]”’`)
+			ended = strings.HasSuffix(trimmed, ".") || strings.HasSuffix(trimmed, "!") || strings.HasSuffix(trimmed, "?")
+			if ended && blockWords > 0 {
+				sentences++
+			}
+		}
+		// A block that doesn't end in punctuation, such as a heading, is a
+		// sentence of its own
+		if !ended && blockWords > 0 {
+			sentences++
+		}
+		words += blockWords
+	}
+
+	if words < minReadabilityWords || sentences == 0 || words/sentences > maxWordsPerSentence {
+		return 0, "", false
+	}
+
+	score = 206.835 - 1.015*float64(words)/float64(sentences) - 84.6*float64(syllables)/float64(words)
+	if score < 0 {
+		score = 0
+	} else if score > 100 {
+		score = 100
+	}
+	return score, readabilityGrade(score), true
+}
+
+// readabilityGrade names the Flesch Reading Ease band of score
+func readabilityGrade(score float64) string {
+	switch {
+	case score >= 90:
+		return "very easy"
+	case score >= 80:
+		return "easy"
+	case score >= 70:
+		return "fairly easy"
+	case score >= 60:
+		return "standard"
+	case score >= 50:
+		return "fairly difficult"
+	case score >= 30:
+		return "difficult"
+	default:
+		return "very difficult"
+	}
+}
+
+// countSyllables estimates the syllables of an English word by counting
+// groups of vowels, not counting a silent final "e"
+func countSyllables(word string) int {
+	word = strings.ToLower(word)
+	count := 0
+	previousVowel := false
+	for _, r := range word {
+		vowel := strings.ContainsRune("aeiouy", r)
+		if vowel && !previousVowel {
+			count++
+		}
+		previousVowel = vowel
+	}
+	if count > 1 && strings.HasSuffix(word, "e") && !strings.HasSuffix(word, "le") {
+		count--
+	}
+	if count == 0 {
+		count = 1
+	}
+	return count
+}
@@ -0,0 +1,66 @@
+package analyzer
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+func parseHTML(t *testing.T, body string) *goquery.Document {
+	t.Helper()
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("Failed to parse HTML: %v", err)
+	}
+	return doc
+}
+
+func TestCountSyllables(t *testing.T) {
+	tests := map[string]int{
+		"cat":           1,
+		"make":          1,
+		"table":         2,
+		"reading":       2,
+		"readability":   5,
+		"communication": 5,
+		"rhythm":        1,
+	}
+	for word, want := range tests {
+		if got := countSyllables(word); got != want {
+			t.Errorf("countSyllables(%q) = %d, want %d", word, got, want)
+		}
+	}
+}
+
+func TestReadability(t *testing.T) {
+	easy := strings.Repeat("<p>The cat sat on the mat. It was a big red mat. The dog ran to the cat.</p>", 8)
+	score, grade, ok := readability(parseHTML(t, "<html><body>"+easy+"</body></html>"))
+	if !ok || score < 90 || grade != "very easy" {
+		t.Errorf("Expected very easy text, got %.1f (%q, ok=%v)", score, grade, ok)
+	}
+
+	hard := strings.Repeat(`<p>Comprehensive organizational restructuring necessitates considerable
+		administrative deliberation, particularly regarding interdepartmental communication
+		responsibilities and the corresponding institutional accountability mechanisms.</p>`, 8)
+	score, grade, ok = readability(parseHTML(t, "<html><body>"+hard+"</body></html>"))
+	if !ok || score >= hardToReadScore || grade != "very difficult" {
+		t.Errorf("Expected very difficult text, got %.1f (%q, ok=%v)", score, grade, ok)
+	}
+
+	// Navigation and code aren't prose
+	var nav strings.Builder
+	for i := 0; i < 60; i++ {
+		nav.WriteString(`<a href="/">Products</a> <a href="/">Pricing</a> `)
+	}
+	page := "<html><body><nav>" + nav.String() + "</nav><pre><code>" + strings.Repeat("x := compute(y) ", 100) + "</code></pre></body></html>"
+	if _, grade, ok := readability(parseHTML(t, page)); ok || grade != "" {
+		t.Errorf("Expected navigation and code to be left unscored, got %q", grade)
+	}
+
+	// Unpunctuated text can't be split into sentences
+	run := "<div>" + strings.Repeat("word ", 150) + "</div>"
+	if _, _, ok := readability(parseHTML(t, "<html><body>"+run+"</body></html>")); ok {
+		t.Error("Expected text without sentence boundaries to be left unscored")
+	}
+}
@@ -0,0 +1,66 @@
+package analyzer
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// maxRedirects is how many redirects are followed before giving up, as with
+// the default http.Client
+const maxRedirects = 10
+
+// redirectRecorderKey is the context key of the *[]RedirectHop that
+// checkRedirect appends hops to
+type redirectRecorderKey struct{}
+
+// withRedirectRecorder returns a context whose requests record redirect hops
+// into hops
+func withRedirectRecorder(ctx context.Context, hops *[]RedirectHop) context.Context {
+	return context.WithValue(ctx, redirectRecorderKey{}, hops)
+}
+
+// checkRedirect is the http.Client CheckRedirect hook. It records each hop
+// for requests made with withRedirectRecorder.
+func checkRedirect(req *http.Request, via []*http.Request) error {
+	if len(via) >= maxRedirects {
+		return errors.New("stopped after 10 redirects")
+	}
+	if hops, ok := req.Context().Value(redirectRecorderKey{}).(*[]RedirectHop); ok && req.Response != nil {
+		*hops = append(*hops, RedirectHop{
+			URL:        via[len(via)-1].URL.String(),
+			StatusCode: req.Response.StatusCode,
+			Location:   req.URL.String(),
+		})
+	}
+	return nil
+}
+
+// analyzeRedirects summarizes the redirects taken from the requested URL to
+// finalURL
+func analyzeRedirects(hops []RedirectHop, requestedURL, finalURL string) RedirectAnalysis {
+	result := RedirectAnalysis{
+		Chain:     append([]RedirectHop{}, hops...),
+		Redirects: len(hops),
+		FinalURL:  finalURL,
+	}
+	for _, hop := range hops {
+		from, to := schemeOf(hop.URL), schemeOf(hop.Location)
+		if from == "https" && to == "http" {
+			result.DowngradesToHTTP = true
+		}
+	}
+	result.UpgradedToHTTPS = schemeOf(requestedURL) == "http" && schemeOf(finalURL) == "https"
+	return result
+}
+
+// schemeOf returns the lowercase scheme of rawURL, or "" if it has none
+func schemeOf(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	return strings.ToLower(u.Scheme)
+}
@@ -0,0 +1,68 @@
+package analyzer
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRedirectChain(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.Handle("/old", http.RedirectHandler("/older", http.StatusMovedPermanently))
+	mux.Handle("/older", http.RedirectHandler("/oldest", http.StatusFound))
+	mux.Handle("/oldest", http.RedirectHandler("/page", http.StatusMovedPermanently))
+	mux.HandleFunc("/page", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte("<html><head><title>Final</title></head><body></body></html>"))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	analyzer := newTestAnalyzer(t)
+
+	analysis, err := analyzer.Analyze(server.URL + "/old")
+	if err != nil {
+		t.Fatalf("Failed to analyze URL: %v", err)
+	}
+	redirects := analysis.Redirects
+	if redirects.Redirects != 3 || len(redirects.Chain) != 3 {
+		t.Fatalf("Expected 3 redirects, got %+v", redirects)
+	}
+	if hop := redirects.Chain[1]; hop.URL != server.URL+"/older" || hop.StatusCode != http.StatusFound || hop.Location != server.URL+"/oldest" {
+		t.Errorf("Unexpected second hop %+v", hop)
+	}
+	if redirects.FinalURL != server.URL+"/page" || redirects.UpgradedToHTTPS || redirects.DowngradesToHTTP {
+		t.Errorf("Unexpected redirect summary %+v", redirects)
+	}
+	found := false
+	for _, rec := range analysis.Recommendations {
+		found = found || strings.Contains(rec, "3 redirects")
+	}
+	if !found {
+		t.Errorf("Expected a redirect chain recommendation, got %v", analysis.Recommendations)
+	}
+
+	analysis, err = analyzer.Analyze(server.URL + "/page")
+	if err != nil {
+		t.Fatalf("Failed to analyze URL: %v", err)
+	}
+	if analysis.Redirects.Redirects != 0 || analysis.Redirects.Chain == nil {
+		t.Errorf("Expected an empty chain without redirects, got %+v", analysis.Redirects)
+	}
+
+	// Scheme changes between hops
+	summary := analyzeRedirects([]RedirectHop{
+		{URL: "http://example.com/", StatusCode: 301, Location: "https://example.com/"},
+		{URL: "https://example.com/", StatusCode: 302, Location: "http://example.com/login"},
+	}, "http://example.com/", "http://example.com/login")
+	if !summary.DowngradesToHTTP || summary.UpgradedToHTTPS {
+		t.Errorf("Expected an HTTPS to HTTP downgrade, got %+v", summary)
+	}
+	summary = analyzeRedirects([]RedirectHop{
+		{URL: "http://example.com/", StatusCode: 301, Location: "https://example.com/"},
+	}, "http://example.com/", "https://example.com/")
+	if !summary.UpgradedToHTTPS || summary.DowngradesToHTTP {
+		t.Errorf("Expected an HTTP to HTTPS upgrade, got %+v", summary)
+	}
+}
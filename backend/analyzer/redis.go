@@ -0,0 +1,317 @@
+package analyzer
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/seo-optimizer/backend/logging"
+)
+
+// redisKeyPrefix namespaces analysis entries in a shared Redis database
+const redisKeyPrefix = "seo-optimizer:analysis:"
+
+// redisPoolSize is how many idle connections are kept for reuse
+const redisPoolSize = 8
+
+// RedisCache is an AnalysisCache backed by Redis, so replicas behind a load
+// balancer share results. Analyses are stored as JSON under the existing
+// MD5 cache keys and expire through Redis TTLs. Redis errors are logged and
+// treated as cache misses.
+type RedisCache struct {
+	addr     string
+	password string
+	db       int
+	timeout  time.Duration
+	idle     chan *redisConn
+	closed   chan struct{}
+	once     sync.Once
+}
+
+// redisEntry is the stored form of an analysis. The analysis is stored
+// unrounded along with its output precision; InternalHrefs is excluded from
+// the API JSON but needed by site checks and crawls.
+type redisEntry struct {
+	Analysis       *plainAnalysis `json:"analysis"`
+	OutputDecimals *int           `json:"outputDecimals,omitempty"`
+	InternalHrefs  []string       `json:"internalHrefs,omitempty"`
+}
+
+// redisConn is one connection speaking RESP
+type redisConn struct {
+	conn   net.Conn
+	reader *bufio.Reader
+}
+
+// redisError is an error reply sent by the server
+type redisError string
+
+func (e redisError) Error() string { return "redis: " + string(e) }
+
+// NewRedisCache connects to the Redis server at rawURL
+// (redis://[:password@]host[:port][/db]) and checks it answers
+func NewRedisCache(rawURL string) (*RedisCache, error) {
+	parsed, err := url.Parse(rawURL)
+	if err != nil || parsed.Scheme != "redis" || parsed.Hostname() == "" {
+		return nil, fmt.Errorf("invalid redis URL %q", rawURL)
+	}
+	cache := &RedisCache{
+		addr:    parsed.Host,
+		timeout: 2 * time.Second,
+		idle:    make(chan *redisConn, redisPoolSize),
+		closed:  make(chan struct{}),
+	}
+	if parsed.Port() == "" {
+		cache.addr = net.JoinHostPort(parsed.Hostname(), "6379")
+	}
+	if parsed.User != nil {
+		if password, ok := parsed.User.Password(); ok {
+			cache.password = password
+		} else {
+			cache.password = parsed.User.Username()
+		}
+	}
+	if db := strings.Trim(parsed.Path, "/"); db != "" {
+		if cache.db, err = strconv.Atoi(db); err != nil {
+			return nil, fmt.Errorf("invalid redis database %q", db)
+		}
+	}
+
+	if _, err := cache.do("PING"); err != nil {
+		return nil, fmt.Errorf("failed to connect to redis at %s: %w", cache.addr, err)
+	}
+	return cache, nil
+}
+
+// Get returns the analysis stored under key
+func (c *RedisCache) Get(key string) (*SEOAnalysis, bool) {
+	reply, err := c.do("GET", redisKeyPrefix+key)
+	if err != nil {
+		logging.Warn("Redis cache read failed", "key", key, "error", err)
+		return nil, false
+	}
+	data, ok := reply.(string)
+	if !ok {
+		return nil, false
+	}
+	var entry redisEntry
+	if err := json.Unmarshal([]byte(data), &entry); err != nil || entry.Analysis == nil {
+		logging.Warn("Discarding unreadable redis cache entry", "key", key, "error", err)
+		return nil, false
+	}
+	analysis := (*SEOAnalysis)(entry.Analysis)
+	analysis.outputDecimals = entry.OutputDecimals
+	analysis.Links.InternalHrefs = entry.InternalHrefs
+	return analysis, true
+}
+
+// Set stores analysis under key for ttl
+func (c *RedisCache) Set(key string, analysis *SEOAnalysis, ttl time.Duration) {
+	if ttl <= 0 {
+		return
+	}
+	data, err := json.Marshal(redisEntry{
+		Analysis:       (*plainAnalysis)(analysis),
+		OutputDecimals: analysis.outputDecimals,
+		InternalHrefs:  analysis.Links.InternalHrefs,
+	})
+	if err != nil {
+		logging.Warn("Failed to encode analysis for redis", "key", key, "error", err)
+		return
+	}
+	ms := strconv.FormatInt(ttl.Milliseconds(), 10)
+	if _, err := c.do("SET", redisKeyPrefix+key, string(data), "PX", ms); err != nil {
+		logging.Warn("Redis cache write failed", "key", key, "error", err)
+	}
+}
+
+// Delete removes key from the cache
+func (c *RedisCache) Delete(key string) {
+	if _, err := c.do("DEL", redisKeyPrefix+key); err != nil {
+		logging.Warn("Redis cache delete failed", "key", key, "error", err)
+	}
+}
+
+// Clear removes every analysis entry, leaving other keys in the database
+func (c *RedisCache) Clear() {
+	cursor := "0"
+	for {
+		reply, err := c.do("SCAN", cursor, "MATCH", redisKeyPrefix+"*", "COUNT", "100")
+		if err != nil {
+			logging.Warn("Redis cache clear failed", "error", err)
+			return
+		}
+		page, ok := reply.([]interface{})
+		if !ok || len(page) != 2 {
+			logging.Warn("Unexpected redis SCAN reply")
+			return
+		}
+		cursor, _ = page[0].(string)
+		keys, _ := page[1].([]interface{})
+		if len(keys) > 0 {
+			args := []string{"DEL"}
+			for _, key := range keys {
+				if s, ok := key.(string); ok {
+					args = append(args, s)
+				}
+			}
+			if _, err := c.do(args...); err != nil {
+				logging.Warn("Redis cache clear failed", "error", err)
+				return
+			}
+		}
+		if cursor == "0" || cursor == "" {
+			return
+		}
+	}
+}
+
+// Close disconnects from Redis; the cached entries are kept
+func (c *RedisCache) Close() error {
+	c.once.Do(func() {
+		close(c.closed)
+		for {
+			select {
+			case conn := <-c.idle:
+				conn.conn.Close()
+			default:
+				return
+			}
+		}
+	})
+	return nil
+}
+
+// do sends one command and returns its reply: a string, int64, nil or
+// []interface{} of those
+func (c *RedisCache) do(args ...string) (interface{}, error) {
+	select {
+	case <-c.closed:
+		return nil, errors.New("redis: cache closed")
+	default:
+	}
+
+	conn, err := c.conn()
+	if err != nil {
+		return nil, err
+	}
+	reply, err := conn.roundTrip(c.timeout, args)
+	var replyErr redisError
+	if err != nil && !errors.As(err, &replyErr) {
+		// The connection state is unknown after an I/O error
+		conn.conn.Close()
+		return nil, err
+	}
+
+	select {
+	case c.idle <- conn:
+	default:
+		conn.conn.Close()
+	}
+	return reply, err
+}
+
+// conn returns an idle connection or dials, authenticates and selects the
+// database on a new one
+func (c *RedisCache) conn() (*redisConn, error) {
+	select {
+	case conn := <-c.idle:
+		return conn, nil
+	default:
+	}
+
+	netConn, err := net.DialTimeout("tcp", c.addr, c.timeout)
+	if err != nil {
+		return nil, err
+	}
+	conn := &redisConn{conn: netConn, reader: bufio.NewReader(netConn)}
+	if c.password != "" {
+		if _, err := conn.roundTrip(c.timeout, []string{"AUTH", c.password}); err != nil {
+			netConn.Close()
+			return nil, err
+		}
+	}
+	if c.db != 0 {
+		if _, err := conn.roundTrip(c.timeout, []string{"SELECT", strconv.Itoa(c.db)}); err != nil {
+			netConn.Close()
+			return nil, err
+		}
+	}
+	return conn, nil
+}
+
+// roundTrip writes args as a RESP array and reads the reply
+func (r *redisConn) roundTrip(timeout time.Duration, args []string) (interface{}, error) {
+	r.conn.SetDeadline(time.Now().Add(timeout))
+
+	var cmd strings.Builder
+	fmt.Fprintf(&cmd, "*%d\r\n", len(args))
+	for _, arg := range args {
+		fmt.Fprintf(&cmd, "$%d\r\n%s\r\n", len(arg), arg)
+	}
+	if _, err := io.WriteString(r.conn, cmd.String()); err != nil {
+		return nil, err
+	}
+	return readRESP(r.reader)
+}
+
+// readRESP reads one RESP reply
+func readRESP(reader *bufio.Reader) (interface{}, error) {
+	line, err := reader.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	line = strings.TrimSuffix(line, "\r\n")
+	if line == "" {
+		return nil, errors.New("redis: empty reply")
+	}
+
+	switch line[0] {
+	case '+':
+		return line[1:], nil
+	case '-':
+		return nil, redisError(line[1:])
+	case ':':
+		return strconv.ParseInt(line[1:], 10, 64)
+	case '$':
+		size, err := strconv.Atoi(line[1:])
+		if err != nil {
+			return nil, err
+		}
+		if size < 0 {
+			return nil, nil
+		}
+		data := make([]byte, size+2)
+		if _, err := io.ReadFull(reader, data); err != nil {
+			return nil, err
+		}
+		return string(data[:size]), nil
+	case '*':
+		count, err := strconv.Atoi(line[1:])
+		if err != nil {
+			return nil, err
+		}
+		if count < 0 {
+			return nil, nil
+		}
+		items := make([]interface{}, 0, count)
+		for i := 0; i < count; i++ {
+			item, err := readRESP(reader)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, item)
+		}
+		return items, nil
+	default:
+		return nil, fmt.Errorf("redis: unexpected reply %q", line)
+	}
+}
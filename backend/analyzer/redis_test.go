@@ -0,0 +1,229 @@
+package analyzer
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"net"
+	"path"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeRedis is an in-process server speaking enough RESP for RedisCache
+type fakeRedis struct {
+	mu      sync.Mutex
+	data    map[string]string
+	expires map[string]time.Time
+}
+
+// newFakeRedis starts a fake server and returns its redis:// URL
+func newFakeRedis(t *testing.T) (*fakeRedis, string) {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	fake := &fakeRedis{data: make(map[string]string), expires: make(map[string]time.Time)}
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go fake.serve(conn)
+		}
+	}()
+	return fake, "redis://" + listener.Addr().String()
+}
+
+func (f *fakeRedis) serve(conn net.Conn) {
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+	for {
+		reply, err := readRESP(reader)
+		if err != nil {
+			return
+		}
+		items, _ := reply.([]interface{})
+		args := make([]string, len(items))
+		for i, item := range items {
+			args[i], _ = item.(string)
+		}
+		fmt.Fprint(conn, f.handle(args))
+	}
+}
+
+func (f *fakeRedis) handle(args []string) string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	bulk := func(s string) string { return fmt.Sprintf("$%d\r\n%s\r\n", len(s), s) }
+
+	switch strings.ToUpper(args[0]) {
+	case "PING":
+		return "+PONG\r\n"
+	case "GET":
+		value, ok := f.data[args[1]]
+		if !ok || time.Now().After(f.expires[args[1]]) {
+			return "$-1\r\n"
+		}
+		return bulk(value)
+	case "SET":
+		var ms int
+		fmt.Sscan(args[4], &ms)
+		f.data[args[1]] = args[2]
+		f.expires[args[1]] = time.Now().Add(time.Duration(ms) * time.Millisecond)
+		return "+OK\r\n"
+	case "DEL":
+		for _, key := range args[1:] {
+			delete(f.data, key)
+		}
+		return fmt.Sprintf(":%d\r\n", len(args)-1)
+	case "SCAN":
+		var keys []string
+		for key := range f.data {
+			if ok, _ := path.Match(args[3], key); ok {
+				keys = append(keys, key)
+			}
+		}
+		reply := fmt.Sprintf("*2\r\n%s*%d\r\n", bulk("0"), len(keys))
+		for _, key := range keys {
+			reply += bulk(key)
+		}
+		return reply
+	default:
+		return "-ERR unknown command\r\n"
+	}
+}
+
+func TestRedisCache(t *testing.T) {
+	fake, redisURL := newFakeRedis(t)
+	cache, err := NewRedisCache(redisURL)
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	defer cache.Close()
+
+	analysis := &SEOAnalysis{URL: "https://example.com", Title: TitleAnalysis{Title: "Example"}}
+	analysis.Links.InternalHrefs = []string{"https://example.com/about"}
+	cache.Set("key", analysis, time.Minute)
+
+	got, ok := cache.Get("key")
+	if !ok {
+		t.Fatal("Expected a cache hit")
+	}
+	if got.URL != analysis.URL || got.Title.Title != "Example" {
+		t.Errorf("Expected the stored analysis back, got %+v", got)
+	}
+	if len(got.Links.InternalHrefs) != 1 {
+		t.Errorf("Expected InternalHrefs to survive the round trip, got %v", got.Links.InternalHrefs)
+	}
+	if _, ok := cache.Get("other"); ok {
+		t.Error("Expected a miss for an unknown key")
+	}
+
+	// Entries expire through their Redis TTL
+	cache.Set("short", analysis, 10*time.Millisecond)
+	time.Sleep(20 * time.Millisecond)
+	if _, ok := cache.Get("short"); ok {
+		t.Error("Expected the entry to expire")
+	}
+
+	cache.Delete("key")
+	if _, ok := cache.Get("key"); ok {
+		t.Error("Expected Delete to remove the entry")
+	}
+
+	// Clear only touches analysis keys
+	cache.Set("a", analysis, time.Minute)
+	fake.mu.Lock()
+	fake.data["unrelated"] = "keep"
+	fake.expires["unrelated"] = time.Now().Add(time.Minute)
+	fake.mu.Unlock()
+	cache.Clear()
+	if _, ok := cache.Get("a"); ok {
+		t.Error("Expected Clear to remove analysis entries")
+	}
+	fake.mu.Lock()
+	_, kept := fake.data["unrelated"]
+	fake.mu.Unlock()
+	if !kept {
+		t.Error("Expected Clear to leave unrelated keys alone")
+	}
+
+	if _, err := NewRedisCache("http://localhost"); err == nil {
+		t.Error("Expected a non-redis URL to be rejected")
+	}
+}
+
+func TestRedisCacheSharedBetweenAnalyzers(t *testing.T) {
+	_, redisURL := newFakeRedis(t)
+	site := newTestSite(t)
+
+	replicas := make([]*Analyzer, 2)
+	for i := range replicas {
+		cache, err := NewRedisCache(redisURL)
+		if err != nil {
+			t.Fatalf("Failed to connect: %v", err)
+		}
+		replicas[i] = newTestAnalyzer(t)
+		replicas[i].SetAnalysisCache(cache)
+	}
+
+	if _, err := replicas[0].Analyze(site.URL); err != nil {
+		t.Fatalf("Failed to analyze: %v", err)
+	}
+	if !replicas[1].IsCached(site.URL) {
+		t.Error("Expected the second replica to see the first replica's result")
+	}
+}
+
+func TestRedisCacheKeepsPrecision(t *testing.T) {
+	_, redisURL := newFakeRedis(t)
+	cache, err := NewRedisCache(redisURL)
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	analyzer := newTestAnalyzer(t)
+	analyzer.SetOutputPrecision(4)
+	analyzer.SetAnalysisCache(cache)
+
+	site := newSiteServer(t, map[string]string{"/": `<html><head><title>Precision</title></head><body>
+		<p>Seven words in this short paragraph here. Then three more.</p></body></html>`})
+	fresh, err := analyzer.Analyze(site.URL)
+	if err != nil {
+		t.Fatalf("Failed to analyze: %v", err)
+	}
+	// A score with more decimals than the output precision
+	fresh.Score = 250.0 / 3
+	fresh.Content.ReadabilityScore = 200.0 / 3
+	cache.Set("precision", fresh, time.Minute)
+
+	cached, ok := cache.Get("precision")
+	if !ok {
+		t.Fatal("Expected a cache hit")
+	}
+	if cached.Score != fresh.Score || cached.Content.ReadabilityScore != fresh.Content.ReadabilityScore {
+		t.Errorf("Expected full precision after the round trip, got score %v and readability %v",
+			cached.Score, cached.Content.ReadabilityScore)
+	}
+	for word, density := range fresh.Content.KeywordDensity {
+		if cached.Content.KeywordDensity[word] != density {
+			t.Errorf("Expected the density of %q to be %v, got %v", word, density, cached.Content.KeywordDensity[word])
+		}
+	}
+
+	// The configured precision still applies to the cached result
+	want, _ := json.Marshal(fresh)
+	got, _ := json.Marshal(cached)
+	if string(got) != string(want) {
+		t.Errorf("Expected the cached result to serialize like the fresh one\nwant %s\n got %s", want, got)
+	}
+	if !strings.Contains(string(got), `"score":83.3333`) {
+		t.Errorf("Expected the score at 4 decimals, got %s", got)
+	}
+}
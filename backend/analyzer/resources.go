@@ -0,0 +1,50 @@
+package analyzer
+
+import (
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// Counts past which a page is considered to load too many separate files or
+// to inline too much that could be cached
+const (
+	maxExternalStylesheets   = 10
+	maxExternalScripts       = 15
+	maxInlineStyleBlocks     = 5
+	maxInlineStyleAttributes = 50
+	maxInlineScripts         = 10
+)
+
+// countResources counts the page's external stylesheets and scripts and its
+// inline styles and scripts. Inline scripts only count when they hold
+// JavaScript, not data such as JSON-LD.
+func countResources(doc *goquery.Document, perf *Performance) {
+	doc.Find("link[rel][href]").Each(func(_ int, s *goquery.Selection) {
+		for _, token := range strings.Fields(s.AttrOr("rel", "")) {
+			if strings.EqualFold(token, "stylesheet") {
+				perf.ExternalStylesheets++
+				return
+			}
+		}
+	})
+	doc.Find("script").Each(func(_ int, s *goquery.Selection) {
+		if _, ok := s.Attr("src"); ok {
+			perf.ExternalScripts++
+		} else if isJavaScriptType(s.AttrOr("type", "")) && strings.TrimSpace(s.Text()) != "" {
+			perf.InlineScripts++
+		}
+	})
+	perf.InlineStyleBlocks = doc.Find("style").Length()
+	perf.InlineStyleAttributes = doc.Find("[style]").Length()
+}
+
+// isJavaScriptType reports whether a script type attribute denotes code
+// the browser runs
+func isJavaScriptType(scriptType string) bool {
+	switch strings.ToLower(strings.TrimSpace(scriptType)) {
+	case "", "text/javascript", "application/javascript", "module":
+		return true
+	}
+	return false
+}
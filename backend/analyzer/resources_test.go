@@ -0,0 +1,78 @@
+package analyzer
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestResourceCounts(t *testing.T) {
+	var heavy strings.Builder
+	heavy.WriteString("<html><head>")
+	for i := 0; i < 12; i++ {
+		fmt.Fprintf(&heavy, `<link rel="stylesheet" href="/css/%d.css">`, i)
+	}
+	for i := 0; i < 16; i++ {
+		fmt.Fprintf(&heavy, `<script src="/js/%d.js" defer></script>`, i)
+	}
+	heavy.WriteString(`<style>body { margin: 0 }</style>`)
+	heavy.WriteString(`<script type="application/ld+json">{"@type": "Organization"}</script>`)
+	heavy.WriteString("</head><body>")
+	for i := 0; i < 60; i++ {
+		heavy.WriteString(`<p style="color: red">Styled</p>`)
+	}
+	for i := 0; i < 11; i++ {
+		fmt.Fprintf(&heavy, `<script>track(%d);</script>`, i)
+	}
+	heavy.WriteString("</body></html>")
+
+	server := newSiteServer(t, map[string]string{
+		"/light": `<html><head>
+			<link rel="stylesheet" href="/main.css">
+			<link rel="preload" href="/font.woff2" as="font">
+			<style>h1 { color: navy }</style>
+			<script src="/app.js" defer></script>
+			<script>window.dataLayer = [];</script>
+			<script type="application/ld+json">{"@type": "Article"}</script>
+			</head><body><h1 style="margin: 0">Light</h1></body></html>`,
+		"/heavy": heavy.String(),
+	})
+	analyzer := newTestAnalyzer(t)
+
+	light, err := analyzer.Analyze(server.URL + "/light")
+	if err != nil {
+		t.Fatalf("Failed to analyze URL: %v", err)
+	}
+	perf := light.Performance
+	if perf.ExternalStylesheets != 1 || perf.ExternalScripts != 1 || perf.InlineStyleBlocks != 1 ||
+		perf.InlineStyleAttributes != 1 || perf.InlineScripts != 1 {
+		t.Errorf("Expected one of each resource, got %+v", perf)
+	}
+
+	heavyAnalysis, err := analyzer.Analyze(server.URL + "/heavy")
+	if err != nil {
+		t.Fatalf("Failed to analyze URL: %v", err)
+	}
+	perf = heavyAnalysis.Performance
+	if perf.ExternalStylesheets != 12 || perf.ExternalScripts != 16 || perf.InlineStyleBlocks != 1 ||
+		perf.InlineStyleAttributes != 60 || perf.InlineScripts != 11 {
+		t.Errorf("Unexpected resource counts: %+v", perf)
+	}
+
+	hasRecommendation := func(analysis *SEOAnalysis, text string) bool {
+		for _, rec := range analysis.Recommendations {
+			if strings.Contains(rec, text) {
+				return true
+			}
+		}
+		return false
+	}
+	for _, text := range []string{"Combine stylesheets", "Bundle scripts", "Move inline styles", "11 inline scripts"} {
+		if hasRecommendation(light, text) {
+			t.Errorf("Expected no %q recommendation for the light page", text)
+		}
+		if !hasRecommendation(heavyAnalysis, text) {
+			t.Errorf("Expected a %q recommendation for the heavy page, got %v", text, heavyAnalysis.Recommendations)
+		}
+	}
+}
@@ -0,0 +1,172 @@
+package analyzer
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// cacheHeaders reads the caching headers of a response received at now.
+// The freshness lifetime comes from max-age (s-maxage only applies to shared
+// caches), falling back to Expires relative to the Date header.
+func cacheHeaders(header http.Header, now time.Time) CacheHeaders {
+	cache := CacheHeaders{
+		CacheControl:    header.Get("Cache-Control"),
+		HasETag:         header.Get("ETag") != "",
+		HasLastModified: header.Get("Last-Modified") != "",
+	}
+	cache.HasCacheControl = cache.CacheControl != ""
+
+	hasMaxAge := false
+	for _, directive := range strings.Split(cache.CacheControl, ",") {
+		name, value, _ := strings.Cut(strings.TrimSpace(directive), "=")
+		switch strings.ToLower(name) {
+		case "no-store":
+			cache.NoStore = true
+		case "no-cache":
+			cache.NoCache = true
+		case "max-age":
+			if seconds, err := strconv.Atoi(strings.Trim(value, `"`)); err == nil && seconds >= 0 {
+				cache.MaxAge = seconds
+				hasMaxAge = true
+			}
+		}
+	}
+
+	if expires := header.Get("Expires"); expires != "" {
+		cache.HasExpires = true
+		if !hasMaxAge {
+			// Invalid dates such as "0" mean already expired
+			if expiresAt, err := http.ParseTime(expires); err == nil {
+				if date, err := http.ParseTime(header.Get("Date")); err == nil {
+					now = date
+				}
+				if lifetime := int(expiresAt.Sub(now).Seconds()); lifetime > 0 {
+					cache.MaxAge = lifetime
+				}
+			}
+		}
+	}
+
+	if cache.NoStore || cache.NoCache {
+		cache.MaxAge = 0
+	}
+	// Stored pages are reused while fresh or, with a validator, revalidated
+	// cheaply with a conditional request
+	cache.Cacheable = !cache.NoStore && (cache.MaxAge > 0 || cache.HasETag || cache.HasLastModified)
+	return cache
+}
+
+// minCompressionSavings is the estimated saving in bytes from which enabling
+// compression is recommended
+const minCompressionSavings = 1024
+
+// gzipWriterPool reuses the compressors used for savings estimates
+var gzipWriterPool = sync.Pool{
+	New: func() interface{} {
+		return gzip.NewWriter(io.Discard)
+	},
+}
+
+// countingWriter counts the bytes written to it
+type countingWriter struct {
+	n int
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	w.n += len(p)
+	return len(p), nil
+}
+
+// gzipSavings estimates how many bytes gzip would save on body
+func gzipSavings(body []byte) int {
+	var counter countingWriter
+	gz := gzipWriterPool.Get().(*gzip.Writer)
+	defer gzipWriterPool.Put(gz)
+	gz.Reset(&counter)
+	if _, err := gz.Write(body); err != nil {
+		return 0
+	}
+	if err := gz.Close(); err != nil {
+		return 0
+	}
+	if savings := len(body) - counter.n; savings > 0 {
+		return savings
+	}
+	return 0
+}
+
+// responseInfo summarizes resp. The transport transparently decompresses
+// gzip responses it asked for, removing Content-Encoding, so those are
+// recognized by resp.Uncompressed.
+// DefaultMaxPageBytes is the largest page body read for a full analysis
+const DefaultMaxPageBytes = 10 << 20
+
+// ErrPageTooLarge is returned for pages whose body exceeds the size limit
+var ErrPageTooLarge = errors.New("page body exceeds the size limit")
+
+// SetMaxPageBytes sets how much of a page body is read. Larger pages fail
+// with ErrPageTooLarge, or in best effort mode are analyzed from their first
+// n bytes.
+func (a *Analyzer) SetMaxPageBytes(n int64) {
+	if n <= 0 {
+		return
+	}
+	a.configMutex.Lock()
+	defer a.configMutex.Unlock()
+	a.maxPageBytes = n
+}
+
+// readBody copies at most limit bytes of r into buf, reporting whether the
+// body was longer than that
+func readBody(buf *bytes.Buffer, r io.Reader, limit int64) (bool, error) {
+	n, err := io.Copy(buf, io.LimitReader(r, limit+1))
+	if err != nil {
+		return false, err
+	}
+	if n > limit {
+		buf.Truncate(buf.Len() - int(n-limit))
+		return true, nil
+	}
+	return false, nil
+}
+
+// ErrNotHTML is returned for responses that aren't HTML pages, such as PDFs,
+// images or JSON
+var ErrNotHTML = errors.New("resource is not an HTML page")
+
+// isHTMLContentType reports whether a Content-Type header denotes HTML or
+// XHTML. A missing header gets the benefit of the doubt.
+func isHTMLContentType(contentType string) bool {
+	mediaType, _, _ := strings.Cut(contentType, ";")
+	switch strings.ToLower(strings.TrimSpace(mediaType)) {
+	case "", "text/html", "application/xhtml+xml":
+		return true
+	}
+	return false
+}
+
+func responseInfo(resp *http.Response) ResponseInfo {
+	info := ResponseInfo{
+		StatusCode:      resp.StatusCode,
+		ContentType:     resp.Header.Get("Content-Type"),
+		Server:          resp.Header.Get("Server"),
+		ContentEncoding: strings.ToLower(resp.Header.Get("Content-Encoding")),
+	}
+	if resp.Uncompressed {
+		info.ContentEncoding = "gzip"
+	}
+	for _, encoding := range strings.Split(info.ContentEncoding, ",") {
+		switch strings.TrimSpace(encoding) {
+		case "gzip", "br":
+			info.Compressed = true
+		}
+	}
+	return info
+}
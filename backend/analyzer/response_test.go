@@ -0,0 +1,271 @@
+package analyzer
+
+import (
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestResponseInfo(t *testing.T) {
+	page := `<html><head><title>Compressed</title></head><body>` + strings.Repeat("<p>Filler text.</p>", 100) + `</body></html>`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Header().Set("Server", "test-server/1.0")
+		if r.URL.Path == "/gzip" && strings.Contains(r.Header.Get("Accept-Encoding"), "gzip") {
+			w.Header().Set("Content-Encoding", "gzip")
+			gz := gzip.NewWriter(w)
+			defer gz.Close()
+			gz.Write([]byte(page))
+			return
+		}
+		w.Write([]byte(page))
+	}))
+	defer server.Close()
+
+	analyzer := newTestAnalyzer(t)
+
+	analysis, err := analyzer.Analyze(server.URL + "/gzip")
+	if err != nil {
+		t.Fatalf("Failed to analyze URL: %v", err)
+	}
+	want := ResponseInfo{StatusCode: 200, ContentType: "text/html; charset=utf-8", Server: "test-server/1.0",
+		ContentEncoding: "gzip", Compressed: true}
+	if analysis.Response != want {
+		t.Errorf("Expected %+v, got %+v", want, analysis.Response)
+	}
+	if analysis.Title.Title != "Compressed" {
+		t.Errorf("Expected the decompressed page to be analyzed, got title %q", analysis.Title.Title)
+	}
+	if !analysis.Performance.Compressed || analysis.Performance.EstimatedCompressionSavings != 0 {
+		t.Errorf("Expected a compressed page with no savings estimate, got %+v", analysis.Performance)
+	}
+
+	analysis, err = analyzer.Analyze(server.URL + "/plain")
+	if err != nil {
+		t.Fatalf("Failed to analyze URL: %v", err)
+	}
+	if analysis.Response.Compressed || analysis.Response.ContentEncoding != "" {
+		t.Errorf("Expected an uncompressed response, got %+v", analysis.Response)
+	}
+	// The repetitive page compresses to a fraction of its size
+	if savings := analysis.Performance.EstimatedCompressionSavings; savings < len(page)/2 || savings >= len(page) {
+		t.Errorf("Expected gzip to save over half of %d bytes, got %d", len(page), savings)
+	}
+	found := false
+	for _, rec := range analysis.Recommendations {
+		found = found || strings.Contains(rec, "Enable gzip or Brotli compression")
+	}
+	if !found {
+		t.Errorf("Expected a compression recommendation, got %v", analysis.Recommendations)
+	}
+}
+
+func TestGzipSavings(t *testing.T) {
+	if savings := gzipSavings([]byte(strings.Repeat("compressible ", 1000))); savings < 12000 {
+		t.Errorf("Expected large savings for repetitive text, got %d", savings)
+	}
+	// Tiny bodies grow when compressed; that is no saving
+	if savings := gzipSavings([]byte("x")); savings != 0 {
+		t.Errorf("Expected no savings for a tiny body, got %d", savings)
+	}
+}
+
+func TestCacheHeaders(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name    string
+		headers map[string]string
+		want    CacheHeaders
+	}{
+		{
+			name:    "none",
+			headers: map[string]string{},
+			want:    CacheHeaders{},
+		},
+		{
+			name:    "max-age",
+			headers: map[string]string{"Cache-Control": "public, max-age=3600", "ETag": `"v1"`},
+			want: CacheHeaders{HasCacheControl: true, CacheControl: "public, max-age=3600", HasETag: true,
+				MaxAge: 3600, Cacheable: true},
+		},
+		{
+			name: "expires relative to date",
+			headers: map[string]string{
+				"Date":    "Wed, 01 May 2024 11:00:00 GMT",
+				"Expires": "Wed, 01 May 2024 11:10:00 GMT",
+			},
+			want: CacheHeaders{HasExpires: true, MaxAge: 600, Cacheable: true},
+		},
+		{
+			name:    "max-age overrides expires",
+			headers: map[string]string{"Cache-Control": "max-age=60", "Expires": "Wed, 01 May 2024 13:00:00 GMT"},
+			want: CacheHeaders{HasCacheControl: true, CacheControl: "max-age=60", HasExpires: true,
+				MaxAge: 60, Cacheable: true},
+		},
+		{
+			name:    "invalid expires",
+			headers: map[string]string{"Expires": "0"},
+			want:    CacheHeaders{HasExpires: true},
+		},
+		{
+			name:    "no-cache with validator",
+			headers: map[string]string{"Cache-Control": "no-cache, max-age=600", "Last-Modified": "Wed, 01 May 2024 10:00:00 GMT"},
+			want: CacheHeaders{HasCacheControl: true, CacheControl: "no-cache, max-age=600", NoCache: true,
+				HasLastModified: true, Cacheable: true},
+		},
+		{
+			name:    "no-store",
+			headers: map[string]string{"Cache-Control": "no-store", "ETag": `"v1"`},
+			want:    CacheHeaders{HasCacheControl: true, CacheControl: "no-store", NoStore: true, HasETag: true},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header := http.Header{}
+			for name, value := range tt.headers {
+				header.Set(name, value)
+			}
+			if got := cacheHeaders(header, now); got != tt.want {
+				t.Errorf("Expected %+v, got %+v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestCacheHeaderRecommendation(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		if r.URL.Path == "/cached" {
+			w.Header().Set("Cache-Control", "max-age=300")
+		}
+		w.Write([]byte("<html><head><title>Cache</title></head><body></body></html>"))
+	}))
+	defer server.Close()
+	analyzer := newTestAnalyzer(t)
+
+	hasRecommendation := func(path string) bool {
+		analysis, err := analyzer.Analyze(server.URL + path)
+		if err != nil {
+			t.Fatalf("Failed to analyze URL: %v", err)
+		}
+		for _, rec := range analysis.Recommendations {
+			if strings.Contains(rec, "Add caching headers") {
+				return true
+			}
+		}
+		return false
+	}
+	if !hasRecommendation("/uncached") {
+		t.Error("Expected a caching recommendation for a page without caching headers")
+	}
+	if hasRecommendation("/cached") {
+		t.Error("Expected no caching recommendation for a page with Cache-Control")
+	}
+}
+
+func TestPageSizeLimit(t *testing.T) {
+	page := `<html><head><title>Huge</title></head><body>` + strings.Repeat("<p>Filler text.</p>", 10000) + `</body></html>`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		if r.URL.Path == "/chunked" {
+			// Flushing early leaves out Content-Length, so only the read catches it
+			w.(http.Flusher).Flush()
+		} else {
+			w.Header().Set("Content-Length", fmt.Sprint(len(page)))
+		}
+		fmt.Fprint(w, page)
+	}))
+	defer server.Close()
+
+	analyzer := newTestAnalyzer(t)
+	analyzer.SetMaxPageBytes(64 << 10)
+
+	for _, path := range []string{"/sized", "/chunked"} {
+		_, err := analyzer.AnalyzeWithOptions(server.URL+path, AnalyzeOptions{SkipLinkCheck: true})
+		var fetchErr *FetchError
+		if !errors.Is(err, ErrPageTooLarge) || !errors.As(err, &fetchErr) || fetchErr.Category != CategoryTooLarge {
+			t.Errorf("Expected %s to fail as too large, got %v", path, err)
+		}
+
+		analysis, err := analyzer.AnalyzeWithOptions(server.URL+path, AnalyzeOptions{Mode: FetchModeBestEffort, SkipLinkCheck: true})
+		if err != nil {
+			t.Fatalf("Expected best effort to analyze the start of %s, got %v", path, err)
+		}
+		if !analysis.Response.Truncated || analysis.Title.Title != "Huge" {
+			t.Errorf("Expected a truncated analysis of %s, got truncated=%v title=%q", path, analysis.Response.Truncated, analysis.Title.Title)
+		}
+		if len(analysis.Warnings) == 0 || !strings.Contains(analysis.Warnings[0], "only the start of it was analyzed") {
+			t.Errorf("Expected a truncation warning for %s, got %v", path, analysis.Warnings)
+		}
+	}
+
+	analyzer.SetMaxPageBytes(DefaultMaxPageBytes)
+	analysis, err := analyzer.AnalyzeWithOptions(server.URL+"/fits", AnalyzeOptions{SkipLinkCheck: true})
+	if err != nil || analysis.Response.Truncated {
+		t.Errorf("Expected the page to fit the default limit, got %v", err)
+	}
+}
+
+func TestNonHTMLResponses(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/report.pdf":
+			w.Header().Set("Content-Type", "application/pdf")
+			fmt.Fprint(w, "%PDF-1.4 <a href=\"/linked\">not a link</a>")
+		case "/page.xhtml":
+			w.Header().Set("Content-Type", "application/xhtml+xml; charset=utf-8")
+			fmt.Fprint(w, `<html xmlns="http://www.w3.org/1999/xhtml"><head><title>XHTML</title></head><body></body></html>`)
+		case "/linked":
+			t.Error("Expected links in a refused PDF not to be checked")
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+	analyzer := newTestAnalyzer(t)
+
+	_, err := analyzer.Analyze(server.URL + "/report.pdf")
+	var fetchErr *FetchError
+	if !errors.Is(err, ErrNotHTML) || !errors.As(err, &fetchErr) || fetchErr.Category != CategoryNotHTML {
+		t.Errorf("Expected a PDF to be refused as not HTML, got %v", err)
+	}
+	if _, err := analyzer.QuickAnalyze(context.Background(), server.URL+"/report.pdf"); !errors.Is(err, ErrNotHTML) {
+		t.Errorf("Expected the quick analysis to refuse a PDF, got %v", err)
+	}
+
+	// Best effort analyzes it anyway, with a warning; links were never checked
+	analysis, err := analyzer.AnalyzeWithOptions(server.URL+"/report.pdf", AnalyzeOptions{Mode: FetchModeBestEffort, SkipLinkCheck: true})
+	if err != nil {
+		t.Fatalf("Expected best effort to analyze the PDF, got %v", err)
+	}
+	if len(analysis.Warnings) == 0 || !strings.Contains(analysis.Warnings[0], "application/pdf") {
+		t.Errorf("Expected a not-HTML warning, got %v", analysis.Warnings)
+	}
+
+	if analysis, err := analyzer.Analyze(server.URL + "/page.xhtml"); err != nil || analysis.Title.Title != "XHTML" {
+		t.Errorf("Expected XHTML to be analyzed, got %v", err)
+	}
+}
+
+func TestIsHTMLContentType(t *testing.T) {
+	for contentType, want := range map[string]bool{
+		"":                             true,
+		"text/html":                    true,
+		"TEXT/HTML; charset=UTF-8":     true,
+		"application/xhtml+xml":        true,
+		"application/json":             false,
+		"image/png":                    false,
+		"text/plain":                   false,
+		"application/pdf; version=1.7": false,
+	} {
+		if got := isHTMLContentType(contentType); got != want {
+			t.Errorf("isHTMLContentType(%q) = %v, want %v", contentType, got, want)
+		}
+	}
+}
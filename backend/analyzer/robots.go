@@ -0,0 +1,274 @@
+package analyzer
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// robotsUserAgent is the product token matched against robots.txt groups
+const robotsUserAgent = "seoanalyzer"
+
+// Robots.txt fetching limits
+const (
+	// maxRobotsBytes is the most of a robots.txt that is parsed; Google
+	// ignores everything after 500KiB too
+	maxRobotsBytes = 500 << 10
+	robotsCacheTTL = time.Hour
+	// maxRobotsHosts bounds the per-host robots.txt cache
+	maxRobotsHosts = 1000
+)
+
+// robotsRule is one Allow or Disallow line
+type robotsRule struct {
+	allow   bool
+	path    string
+	pattern *regexp.Regexp
+}
+
+// robotsGroup is the rules that apply to a set of user agents
+type robotsGroup struct {
+	agents []string
+	rules  []robotsRule
+}
+
+// robotsFile is a parsed robots.txt
+type robotsFile struct {
+	groups   []robotsGroup
+	sitemaps []string
+}
+
+// parseRobots parses robots.txt content. Unknown lines are ignored.
+func parseRobots(r io.Reader) *robotsFile {
+	file := &robotsFile{}
+	var current *robotsGroup
+	lastWasAgent := false
+
+	scanner := bufio.NewScanner(io.LimitReader(r, maxRobotsBytes))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if i := strings.Index(line, "#"); i >= 0 {
+			line = line[:i]
+		}
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		value = strings.TrimSpace(value)
+
+		switch key {
+		case "user-agent":
+			// Consecutive user-agent lines share one group
+			if current == nil || !lastWasAgent {
+				file.groups = append(file.groups, robotsGroup{})
+				current = &file.groups[len(file.groups)-1]
+			}
+			current.agents = append(current.agents, strings.ToLower(value))
+			lastWasAgent = true
+		case "allow", "disallow":
+			lastWasAgent = false
+			// An empty Disallow allows everything, so it adds no rule
+			if current == nil || value == "" {
+				continue
+			}
+			current.rules = append(current.rules, robotsRule{
+				allow:   key == "allow",
+				path:    value,
+				pattern: robotsPattern(value),
+			})
+		case "sitemap":
+			lastWasAgent = false
+			if value != "" {
+				file.sitemaps = append(file.sitemaps, value)
+			}
+		default:
+			lastWasAgent = false
+		}
+	}
+
+	return file
+}
+
+// robotsPattern compiles a robots.txt path, where '*' matches any sequence
+// and a trailing '$' anchors the end
+func robotsPattern(path string) *regexp.Regexp {
+	anchored := strings.HasSuffix(path, "$")
+	path = strings.TrimSuffix(path, "$")
+	parts := strings.Split(path, "*")
+	for i, part := range parts {
+		parts[i] = regexp.QuoteMeta(part)
+	}
+	expr := "^" + strings.Join(parts, ".*")
+	if anchored {
+		expr += "$"
+	}
+	return regexp.MustCompile(expr)
+}
+
+// group returns the rules for userAgent: the group naming it, else the
+// "*" group, else nil
+func (f *robotsFile) group(userAgent string) *robotsGroup {
+	var wildcard *robotsGroup
+	for i := range f.groups {
+		for _, agent := range f.groups[i].agents {
+			if agent == "*" {
+				wildcard = &f.groups[i]
+			} else if agent != "" && strings.HasPrefix(userAgent, agent) {
+				return &f.groups[i]
+			}
+		}
+	}
+	return wildcard
+}
+
+// blocked reports whether path (with query) is disallowed for userAgent and
+// the rule that decided it. The longest matching rule wins; Allow wins ties.
+func (f *robotsFile) blocked(userAgent, path string) (bool, string) {
+	group := f.group(userAgent)
+	if group == nil {
+		return false, ""
+	}
+
+	var best *robotsRule
+	for i, rule := range group.rules {
+		if !rule.pattern.MatchString(path) {
+			continue
+		}
+		if best == nil || len(rule.path) > len(best.path) ||
+			(len(rule.path) == len(best.path) && rule.allow && !best.allow) {
+			best = &group.rules[i]
+		}
+	}
+	if best == nil {
+		return false, ""
+	}
+	if best.allow {
+		return false, "Allow: " + best.path
+	}
+	return true, "Disallow: " + best.path
+}
+
+// robotsCacheEntry is the robots.txt of one host, or why it couldn't be read
+type robotsCacheEntry struct {
+	file      *robotsFile // nil when the host has no robots.txt
+	err       error
+	timestamp time.Time
+}
+
+// robotsCache holds parsed robots.txt files per scheme and host
+type robotsCache struct {
+	mutex   sync.Mutex
+	entries map[string]robotsCacheEntry
+}
+
+func newRobotsCache() *robotsCache {
+	return &robotsCache{entries: make(map[string]robotsCacheEntry)}
+}
+
+func (c *robotsCache) get(origin string) (robotsCacheEntry, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	entry, ok := c.entries[origin]
+	if ok && time.Since(entry.timestamp) > robotsCacheTTL {
+		delete(c.entries, origin)
+		return robotsCacheEntry{}, false
+	}
+	return entry, ok
+}
+
+func (c *robotsCache) put(origin string, entry robotsCacheEntry) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if len(c.entries) >= maxRobotsHosts {
+		for key, old := range c.entries {
+			if time.Since(old.timestamp) > robotsCacheTTL {
+				delete(c.entries, key)
+			}
+		}
+		// Still full: drop an arbitrary entry rather than grow unbounded
+		for key := range c.entries {
+			if len(c.entries) < maxRobotsHosts {
+				break
+			}
+			delete(c.entries, key)
+		}
+	}
+	c.entries[origin] = entry
+}
+
+// fetchRobots downloads and parses origin's robots.txt. A 4xx response
+// means there is no robots.txt; other failures are returned as errors.
+func (a *Analyzer) fetchRobots(ctx context.Context, origin string) (*robotsFile, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", origin+"/robots.txt", nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", deviceProfiles[DeviceDesktop].UserAgent)
+
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode >= 500:
+		return nil, fmt.Errorf("robots.txt returned HTTP status %d", resp.StatusCode)
+	case resp.StatusCode >= 400:
+		return nil, nil
+	}
+	return parseRobots(resp.Body), nil
+}
+
+// analyzeRobots reports whether pageURL may be crawled according to its
+// host's robots.txt, which is fetched once per host and cached
+func (a *Analyzer) analyzeRobots(ctx context.Context, pageURL string) RobotsAnalysis {
+	result := RobotsAnalysis{SitemapURLs: []string{}}
+
+	u, err := url.Parse(pageURL)
+	if err != nil || u.Host == "" {
+		result.Error = "invalid URL"
+		return result
+	}
+	origin := u.Scheme + "://" + u.Host
+
+	entry, ok := a.robots.get(origin)
+	if !ok {
+		file, err := a.fetchRobots(ctx, origin)
+		entry = robotsCacheEntry{file: file, err: err, timestamp: time.Now()}
+		// Don't remember failures caused by the caller giving up
+		if ctx.Err() == nil {
+			a.robots.put(origin, entry)
+		}
+	}
+
+	if entry.err != nil {
+		result.Error = entry.err.Error()
+		return result
+	}
+	if entry.file == nil {
+		return result
+	}
+
+	result.HasRobotsTxt = true
+	result.SitemapURLs = append(result.SitemapURLs, entry.file.sitemaps...)
+
+	path := u.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	if u.RawQuery != "" {
+		path += "?" + u.RawQuery
+	}
+	result.IsBlocked, result.MatchedRule = entry.file.blocked(robotsUserAgent, path)
+
+	return result
+}
@@ -0,0 +1,119 @@
+package analyzer
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestParseRobots(t *testing.T) {
+	robots := parseRobots(strings.NewReader(`
+# Everyone
+User-agent: *
+Disallow: /private/
+Allow: /private/press/
+Disallow: /*.pdf$
+Disallow: /search?
+
+User-agent: OtherBot
+User-agent: SEOAnalyzer
+Disallow: /staging
+Allow: /staging/public
+
+Sitemap: https://example.com/sitemap.xml
+`))
+
+	tests := []struct {
+		agent   string
+		path    string
+		blocked bool
+	}{
+		{"googlebot", "/", false},
+		{"googlebot", "/private/page", true},
+		{"googlebot", "/private/press/release", false}, // longer Allow wins
+		{"googlebot", "/docs/guide.pdf", true},
+		{"googlebot", "/docs/guide.pdf?v=2", false}, // $ anchors the end
+		{"googlebot", "/search?q=seo", true},
+		{"googlebot", "/staging", false},
+		// A named group replaces the * group entirely
+		{robotsUserAgent, "/private/page", false},
+		{robotsUserAgent, "/staging/index.html", true},
+		{robotsUserAgent, "/staging/public/index.html", false},
+	}
+	for _, tt := range tests {
+		if blocked, rule := robots.blocked(tt.agent, tt.path); blocked != tt.blocked {
+			t.Errorf("%s %s: expected blocked=%v, got %v (rule %q)", tt.agent, tt.path, tt.blocked, blocked, rule)
+		}
+	}
+
+	if len(robots.sitemaps) != 1 || robots.sitemaps[0] != "https://example.com/sitemap.xml" {
+		t.Errorf("Expected the sitemap to be collected, got %v", robots.sitemaps)
+	}
+}
+
+func TestAnalyzeRobots(t *testing.T) {
+	var robotsFetches atomic.Int32
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/robots.txt" {
+			robotsFetches.Add(1)
+			fmt.Fprintf(w, "User-agent: *\nDisallow: /admin\n\nSitemap: %s/sitemap.xml\n", server.URL)
+			return
+		}
+		fmt.Fprint(w, `<html><head><title>Page</title></head><body>Page</body></html>`)
+	}))
+	defer server.Close()
+
+	analyzer := newTestAnalyzer(t)
+
+	blocked, err := analyzer.Analyze(server.URL + "/admin/users")
+	if err != nil {
+		t.Fatalf("Failed to analyze URL: %v", err)
+	}
+	if !blocked.Robots.HasRobotsTxt || !blocked.Robots.IsBlocked || blocked.Robots.MatchedRule != "Disallow: /admin" {
+		t.Errorf("Expected /admin/users to be blocked, got %+v", blocked.Robots)
+	}
+	if len(blocked.Robots.SitemapURLs) != 1 || blocked.Robots.SitemapURLs[0] != server.URL+"/sitemap.xml" {
+		t.Errorf("Expected the robots.txt sitemap, got %v", blocked.Robots.SitemapURLs)
+	}
+	found := false
+	for _, rec := range blocked.Recommendations {
+		if strings.Contains(rec, "robots.txt blocks crawlers") {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("Expected a blocked-by-robots recommendation, got %v", blocked.Recommendations)
+	}
+
+	allowed, err := analyzer.Analyze(server.URL + "/blog")
+	if err != nil {
+		t.Fatalf("Failed to analyze URL: %v", err)
+	}
+	if allowed.Robots.IsBlocked {
+		t.Errorf("Expected /blog to be crawlable, got %+v", allowed.Robots)
+	}
+
+	// robots.txt is fetched once per host
+	if got := robotsFetches.Load(); got != 1 {
+		t.Errorf("Expected robots.txt to be fetched once, got %d", got)
+	}
+}
+
+func TestAnalyzeRobotsMissing(t *testing.T) {
+	server := newSiteServer(t, map[string]string{
+		"/": `<html><head><title>Home</title></head><body>Home</body></html>`,
+	})
+	analyzer := newTestAnalyzer(t)
+
+	analysis, err := analyzer.Analyze(server.URL + "/")
+	if err != nil {
+		t.Fatalf("Failed to analyze URL: %v", err)
+	}
+	if analysis.Robots.HasRobotsTxt || analysis.Robots.IsBlocked || analysis.Robots.Error != "" {
+		t.Errorf("Expected a missing robots.txt to allow everything, got %+v", analysis.Robots)
+	}
+}
@@ -0,0 +1,70 @@
+package analyzer
+
+import (
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// robotsMeta collects every <meta name="robots"> tag and merges their
+// directives the way search engines do: when tags disagree, the most
+// restrictive directive wins. It returns the raw tag contents, the merged
+// directive string and whether the tags contradicted each other.
+func robotsMeta(doc *goquery.Document) (tags []string, effective string, conflict bool) {
+	tags = []string{}
+	index, noindex, follow, nofollow := false, false, false, false
+	var others []string
+	seen := make(map[string]bool)
+
+	doc.Find("meta[name]").Each(func(_ int, s *goquery.Selection) {
+		name, _ := s.Attr("name")
+		if !strings.EqualFold(strings.TrimSpace(name), "robots") {
+			return
+		}
+		content, _ := s.Attr("content")
+		tags = append(tags, content)
+
+		for _, directive := range strings.Split(content, ",") {
+			directive = strings.ToLower(strings.TrimSpace(directive))
+			switch directive {
+			case "":
+			case "index":
+				index = true
+			case "noindex":
+				noindex = true
+			case "follow":
+				follow = true
+			case "nofollow":
+				nofollow = true
+			case "all":
+				index, follow = true, true
+			case "none":
+				noindex, nofollow = true, true
+			default:
+				if !seen[directive] {
+					seen[directive] = true
+					others = append(others, directive)
+				}
+			}
+		}
+	})
+
+	conflict = (index && noindex) || (follow && nofollow)
+
+	var merged []string
+	switch {
+	case noindex:
+		merged = append(merged, "noindex")
+	case index:
+		merged = append(merged, "index")
+	}
+	switch {
+	case nofollow:
+		merged = append(merged, "nofollow")
+	case follow:
+		merged = append(merged, "follow")
+	}
+	merged = append(merged, others...)
+
+	return tags, strings.Join(merged, ", "), conflict
+}
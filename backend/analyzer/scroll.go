@@ -0,0 +1,57 @@
+package analyzer
+
+import (
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// thinListingWords is the word count below which a page with pagination
+// controls is considered to render most of its content client-side
+const thinListingWords = 150
+
+// infiniteScrollAttributes are data attributes used by common infinite
+// scroll and "load more" libraries
+var infiniteScrollAttributes = []string{
+	"data-infinite-scroll",
+	"data-infinite",
+	"data-load-more",
+	"data-next-page",
+	"data-next-url",
+	"data-page-url",
+}
+
+// paginationSelector matches typical pagination controls
+const paginationSelector = `.pagination, .pager, nav[aria-label*="pagination" i], [role="navigation"][aria-label*="page" i], a[rel~="next"], link[rel~="next"]`
+
+// detectInfiniteScroll heuristically decides whether the page loads its
+// listing content via infinite scroll or client-side pagination: either an
+// infinite scroll container is present, or the initial HTML is very short
+// while pagination or "load more" controls are present
+func detectInfiniteScroll(doc *goquery.Document, wordCount int) bool {
+	for _, attr := range infiniteScrollAttributes {
+		if doc.Find("["+attr+"]").Length() > 0 {
+			return true
+		}
+	}
+	if doc.Find(`[class*="infinite-scroll"], [class*="infinite_scroll"]`).Length() > 0 {
+		return true
+	}
+
+	if wordCount >= thinListingWords {
+		return false
+	}
+	if doc.Find(paginationSelector).Length() > 0 {
+		return true
+	}
+	loadMore := false
+	doc.Find("button, a").EachWithBreak(func(_ int, s *goquery.Selection) bool {
+		text := strings.ToLower(strings.TrimSpace(s.Text()))
+		if text == "load more" || text == "show more" || text == "more results" {
+			loadMore = true
+			return false
+		}
+		return true
+	})
+	return loadMore
+}
@@ -0,0 +1,59 @@
+package analyzer
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// sectionNames holds the top-level JSON field names of SEOAnalysis, which
+// can be requested individually
+var sectionNames = func() map[string]bool {
+	names := make(map[string]bool)
+	t := reflect.TypeOf(SEOAnalysis{})
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name := strings.Split(field.Tag.Get("json"), ",")[0]
+		if field.IsExported() && name != "" && name != "-" {
+			names[name] = true
+		}
+	}
+	return names
+}()
+
+// IsSection reports whether name is a section of the analysis output
+func IsSection(name string) bool {
+	return sectionNames[name]
+}
+
+// SectionNames returns the valid section names in sorted order
+func SectionNames() []string {
+	names := make([]string, 0, len(sectionNames))
+	for name := range sectionNames {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Section returns the JSON encoding of a single top-level section, with the
+// same rounding as the full analysis. Omitted sections encode as null.
+func (s *SEOAnalysis) Section(name string) (json.RawMessage, error) {
+	if !IsSection(name) {
+		return nil, fmt.Errorf("unknown section %q", name)
+	}
+	data, err := json.Marshal(s)
+	if err != nil {
+		return nil, err
+	}
+	var sections map[string]json.RawMessage
+	if err := json.Unmarshal(data, &sections); err != nil {
+		return nil, err
+	}
+	if section, ok := sections[name]; ok {
+		return section, nil
+	}
+	return json.RawMessage("null"), nil
+}
@@ -0,0 +1,122 @@
+package analyzer
+
+import (
+	"net/url"
+	"strings"
+	"unicode"
+)
+
+// Approximate search result layout: titles are cut around 600px at 20px
+// Arial, descriptions around 920px at 14px
+const (
+	serpTitleMaxPixels       = 600
+	serpTitleFontSize        = 20
+	serpDescriptionMaxPixels = 920
+	serpDescriptionFontSize  = 14
+	serpEllipsis             = "..."
+)
+
+// charWidth returns the approximate width of r in Arial, in ems
+func charWidth(r rune) float64 {
+	switch {
+	case strings.ContainsRune("iljI.,;:!|'", r):
+		return 0.25
+	case r == ' ' || strings.ContainsRune("ft()[]/-", r):
+		return 0.3
+	case strings.ContainsRune("r\"*", r):
+		return 0.35
+	case strings.ContainsRune("mMW", r):
+		return 0.85
+	case r == 'w':
+		return 0.72
+	case unicode.IsUpper(r):
+		return 0.68
+	case unicode.IsDigit(r), unicode.IsLower(r):
+		return 0.55
+	case r > unicode.MaxLatin1:
+		// CJK and other wide scripts
+		return 1
+	default:
+		return 0.6
+	}
+}
+
+// textPixelWidth estimates the rendered width of s at fontSize pixels
+func textPixelWidth(s string, fontSize float64) float64 {
+	width := 0.0
+	for _, r := range s {
+		width += charWidth(r) * fontSize
+	}
+	return width
+}
+
+// truncateToPixels cuts s at the last word boundary that fits maxWidth
+// together with an ellipsis, the way search results shorten snippets. It
+// reports whether s was cut.
+func truncateToPixels(s string, maxWidth, fontSize float64) (string, bool) {
+	if textPixelWidth(s, fontSize) <= maxWidth {
+		return s, false
+	}
+
+	limit := maxWidth - textPixelWidth(serpEllipsis, fontSize)
+	width := 0.0
+	cut, lastSpace := 0, -1
+	for i, r := range s {
+		width += charWidth(r) * fontSize
+		if width > limit {
+			break
+		}
+		if r == ' ' {
+			lastSpace = i
+		}
+		cut = i + len(string(r))
+	}
+	if lastSpace > 0 {
+		cut = lastSpace
+	}
+	return strings.TrimRight(s[:cut], " ,;:-") + serpEllipsis, true
+}
+
+// displayURL renders pageURL the way search results show it, e.g.
+// "example.com › blog › post"
+func displayURL(pageURL string) string {
+	u, err := url.Parse(pageURL)
+	if err != nil || u.Host == "" {
+		return pageURL
+	}
+	parts := []string{strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")}
+	for _, segment := range strings.Split(u.Path, "/") {
+		if segment != "" {
+			if unescaped, err := url.PathUnescape(segment); err == nil {
+				segment = unescaped
+			}
+			parts = append(parts, segment)
+		}
+	}
+	return strings.Join(parts, " › ")
+}
+
+// buildSERPPreview estimates how the page would appear in search results
+// from the title, meta description, URL and favicon analyses
+func buildSERPPreview(analysis *SEOAnalysis) SERPPreview {
+	preview := SERPPreview{
+		DisplayURL: displayURL(analysis.URL),
+		Favicon:    analysis.Icons.Favicon,
+	}
+	if preview.Favicon == "" {
+		// Browsers and search engines fall back to /favicon.ico
+		if u, err := url.Parse(analysis.URL); err == nil && u.Host != "" {
+			preview.Favicon = u.Scheme + "://" + u.Host + "/favicon.ico"
+		}
+	}
+
+	title := strings.TrimSpace(analysis.Title.Title)
+	preview.TitlePixelWidth = int(textPixelWidth(title, serpTitleFontSize))
+	preview.Title, preview.TitleTruncated = truncateToPixels(title, serpTitleMaxPixels, serpTitleFontSize)
+
+	description := strings.TrimSpace(analysis.Meta.Description)
+	preview.DescriptionPixelWidth = int(textPixelWidth(description, serpDescriptionFontSize))
+	preview.Description, preview.DescriptionTruncated = truncateToPixels(description, serpDescriptionMaxPixels, serpDescriptionFontSize)
+
+	return preview
+}
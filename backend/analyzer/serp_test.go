@@ -0,0 +1,67 @@
+package analyzer
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSERPPreview(t *testing.T) {
+	long := "WIDE MAGAZINE WORDS MAKE MEMORABLE HEADLINES WHEN WRITTEN"
+	server := newSiteServer(t, map[string]string{
+		"/blog/my post": `<html><head><title>` + long + `</title>
+			<meta name="description" content="A short summary.">
+			<link rel="icon" href="/img/icon.png"></head><body></body></html>`,
+		"/short": `<html><head><title>Short title</title></head><body></body></html>`,
+	})
+	analyzer := newTestAnalyzer(t)
+
+	analysis, err := analyzer.Analyze(server.URL + "/blog/my%20post")
+	if err != nil {
+		t.Fatalf("Failed to analyze URL: %v", err)
+	}
+	preview := analysis.SERPPreview
+	if len(long) > 60 || !preview.TitleTruncated {
+		t.Fatalf("Expected a %d-character uppercase title to be truncated by width, got %+v", len(long), preview)
+	}
+	if !strings.HasSuffix(preview.Title, "...") || !strings.HasPrefix(long, strings.TrimSuffix(preview.Title, "...")) {
+		t.Errorf("Expected a prefix of the title ending in an ellipsis, got %q", preview.Title)
+	}
+	if width := textPixelWidth(preview.Title, serpTitleFontSize); width > serpTitleMaxPixels {
+		t.Errorf("Expected the truncated title to fit %dpx, got %.0fpx", serpTitleMaxPixels, width)
+	}
+	// Cut at the last whole word that fits
+	next := long[len(strings.TrimSuffix(preview.Title, "...")):]
+	if !strings.HasPrefix(next, " ") {
+		t.Errorf("Expected the title to be cut at a word boundary, got %q", preview.Title)
+	}
+	if withNextWord := strings.TrimSuffix(preview.Title, "...") + " " + strings.Fields(next)[0] + "..."; textPixelWidth(withNextWord, serpTitleFontSize) <= serpTitleMaxPixels {
+		t.Errorf("Expected the next word not to fit, but %q does", withNextWord)
+	}
+	if preview.Description != "A short summary." || preview.DescriptionTruncated {
+		t.Errorf("Expected the description unchanged, got %q", preview.Description)
+	}
+	if preview.DisplayURL != "127.0.0.1 › blog › my post" {
+		t.Errorf("Unexpected display URL %q", preview.DisplayURL)
+	}
+	if preview.Favicon != server.URL+"/img/icon.png" {
+		t.Errorf("Expected the declared favicon, got %q", preview.Favicon)
+	}
+	found := false
+	for _, rec := range analysis.Recommendations {
+		found = found || strings.Contains(rec, "will be truncated in search results")
+	}
+	if !found {
+		t.Errorf("Expected a truncation recommendation, got %v", analysis.Recommendations)
+	}
+
+	analysis, err = analyzer.Analyze(server.URL + "/short")
+	if err != nil {
+		t.Fatalf("Failed to analyze URL: %v", err)
+	}
+	if analysis.SERPPreview.TitleTruncated || analysis.SERPPreview.Title != "Short title" {
+		t.Errorf("Expected a short title to be shown whole, got %+v", analysis.SERPPreview)
+	}
+	if analysis.SERPPreview.Favicon != server.URL+"/favicon.ico" {
+		t.Errorf("Expected the /favicon.ico fallback, got %q", analysis.SERPPreview.Favicon)
+	}
+}
@@ -0,0 +1,108 @@
+package analyzer
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// AnalyzeSite runs cross-page checks over a set of page analyses keyed by
+// page URL, such as the results of a batch or crawl
+func AnalyzeSite(pages map[string]*SEOAnalysis) SiteAnalysis {
+	return AnalyzeSiteWithSitemap(pages, nil)
+}
+
+// AnalyzeSiteWithSitemap is AnalyzeSite plus the checks that compare pages
+// against the URLs listed in the site's sitemap (see FetchSitemap)
+func AnalyzeSiteWithSitemap(pages map[string]*SEOAnalysis, sitemapURLs []string) SiteAnalysis {
+	site := SiteAnalysis{
+		Pages:                        len(pages),
+		TrailingSlashInconsistencies: findTrailingSlashInconsistencies(pages),
+		HreflangErrors:               findHreflangErrors(pages),
+		NoindexInSitemap:             findNoindexInSitemap(pages, sitemapURLs),
+		Recommendations:              []string{},
+	}
+	site.OrphanPages, site.UnlistedPages = findSitemapDiscrepancies(pages, sitemapURLs)
+
+	if n := len(site.TrailingSlashInconsistencies); n > 0 {
+		site.Recommendations = append(site.Recommendations, fmt.Sprintf(
+			"Standardize internal links on one trailing-slash form: %d URL(s) are linked both with and without a trailing slash", n))
+	}
+	if n := len(site.HreflangErrors); n > 0 {
+		site.Recommendations = append(site.Recommendations, fmt.Sprintf(
+			"Fix %d hreflang error(s): alternates must link back to each other and each language may map to only one URL", n))
+	}
+	if n := len(site.NoindexInSitemap); n > 0 {
+		site.Recommendations = append(site.Recommendations, fmt.Sprintf(
+			"%d page(s) are marked noindex but listed in the sitemap: remove them from the sitemap or drop the noindex directive", n))
+	}
+	if n := len(site.OrphanPages); n > 0 {
+		site.Recommendations = append(site.Recommendations, fmt.Sprintf(
+			"Link to %d orphan page(s) from your site: they are in the sitemap but no analyzed page links to them", n))
+	}
+	if n := len(site.UnlistedPages); n > 0 {
+		site.Recommendations = append(site.Recommendations, fmt.Sprintf(
+			"Add %d linked page(s) to the sitemap, or remove them from it intentionally", n))
+	}
+
+	return site
+}
+
+// findTrailingSlashInconsistencies compares the link forms used across pages
+// before normalization, reporting paths linked as both /path and /path/
+func findTrailingSlashInconsistencies(pages map[string]*SEOAnalysis) []TrailingSlashInconsistency {
+	type linkForms struct {
+		withSlash    map[string]bool
+		withoutSlash map[string]bool
+	}
+	forms := make(map[string]*linkForms)
+
+	for pageURL, page := range pages {
+		if page == nil {
+			continue
+		}
+		for _, href := range page.Links.InternalHrefs {
+			u, err := url.Parse(href)
+			if err != nil || u.Path == "" || u.Path == "/" {
+				continue
+			}
+			key := strings.ToLower(u.Host) + strings.TrimSuffix(u.Path, "/")
+			f, ok := forms[key]
+			if !ok {
+				f = &linkForms{withSlash: map[string]bool{}, withoutSlash: map[string]bool{}}
+				forms[key] = f
+			}
+			if strings.HasSuffix(u.Path, "/") {
+				f.withSlash[pageURL] = true
+			} else {
+				f.withoutSlash[pageURL] = true
+			}
+		}
+	}
+
+	issues := []TrailingSlashInconsistency{}
+	for key, f := range forms {
+		if len(f.withSlash) == 0 || len(f.withoutSlash) == 0 {
+			continue
+		}
+		issues = append(issues, TrailingSlashInconsistency{
+			Path:               key[strings.Index(key, "/"):],
+			LinkedWithSlash:    sortedKeys(f.withSlash),
+			LinkedWithoutSlash: sortedKeys(f.withoutSlash),
+		})
+	}
+	sort.Slice(issues, func(i, j int) bool { return issues[i].Path < issues[j].Path })
+
+	return issues
+}
+
+// sortedKeys returns the keys of a set in ascending order
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for k := range set {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
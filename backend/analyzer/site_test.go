@@ -0,0 +1,249 @@
+package analyzer
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// newSiteServer serves the given path -> HTML body map
+func newSiteServer(t *testing.T, pages map[string]string) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, ok := pages[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html")
+		fmt.Fprint(w, body)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// analyzePages analyzes each path on server and keys the results by URL
+func analyzePages(t *testing.T, a *Analyzer, server *httptest.Server, paths ...string) map[string]*SEOAnalysis {
+	t.Helper()
+	pages := make(map[string]*SEOAnalysis)
+	for _, path := range paths {
+		analysis, err := a.Analyze(server.URL + path)
+		if err != nil {
+			t.Fatalf("Failed to analyze %s: %v", path, err)
+		}
+		pages[server.URL+path] = analysis
+	}
+	return pages
+}
+
+func TestAnalyzeSiteTrailingSlashInconsistency(t *testing.T) {
+	server := newSiteServer(t, map[string]string{
+		"/":         `<html><body><a href="/products">Products</a><a href="/about/">About</a></body></html>`,
+		"/about/":   `<html><body><a href="/products/">Products</a><a href="/about/">About</a></body></html>`,
+		"/products": `<html><body><a href="/about/">About</a></body></html>`,
+	})
+	analyzer := newTestAnalyzer(t)
+	pages := analyzePages(t, analyzer, server, "/", "/about/", "/products")
+
+	site := AnalyzeSite(pages)
+
+	if site.Pages != 3 {
+		t.Errorf("Expected 3 pages, got %d", site.Pages)
+	}
+	if len(site.TrailingSlashInconsistencies) != 1 {
+		t.Fatalf("Expected 1 inconsistency, got %+v", site.TrailingSlashInconsistencies)
+	}
+	issue := site.TrailingSlashInconsistencies[0]
+	if issue.Path != "/products" {
+		t.Errorf("Expected /products to be reported, got %q", issue.Path)
+	}
+	if len(issue.LinkedWithSlash) != 1 || issue.LinkedWithSlash[0] != server.URL+"/about/" {
+		t.Errorf("Unexpected pages linking with slash: %v", issue.LinkedWithSlash)
+	}
+	if len(issue.LinkedWithoutSlash) != 1 || issue.LinkedWithoutSlash[0] != server.URL+"/" {
+		t.Errorf("Unexpected pages linking without slash: %v", issue.LinkedWithoutSlash)
+	}
+	if len(site.Recommendations) != 1 {
+		t.Errorf("Expected a standardization recommendation, got %v", site.Recommendations)
+	}
+}
+
+func TestAnalyzeSiteHreflangReturnTags(t *testing.T) {
+	alternates := `<link rel="alternate" hreflang="en" href="/en/">
+		<link rel="alternate" hreflang="de" href="/de/">
+		<link rel="alternate" hreflang="fr" href="/fr/">`
+	server := newSiteServer(t, map[string]string{
+		"/en/": `<html><head>` + alternates + `</head><body></body></html>`,
+		"/de/": `<html><head>` + alternates + `</head><body></body></html>`,
+		// The French page forgets the German alternate
+		"/fr/": `<html><head><link rel="alternate" hreflang="en" href="/en/">
+			<link rel="alternate" hreflang="fr" href="/fr/"></head><body></body></html>`,
+	})
+	analyzer := newTestAnalyzer(t)
+	pages := analyzePages(t, analyzer, server, "/en/", "/de/", "/fr/")
+
+	site := AnalyzeSite(pages)
+
+	if len(site.HreflangErrors) != 1 {
+		t.Fatalf("Expected 1 hreflang error, got %+v", site.HreflangErrors)
+	}
+	got := site.HreflangErrors[0]
+	if got.Page != server.URL+"/de/" || got.Target != server.URL+"/fr/" || got.Lang != "fr" {
+		t.Errorf("Expected /de/ -> /fr/ to lack a return tag, got %+v", got)
+	}
+	if !strings.Contains(got.Issue, "missing return tag") {
+		t.Errorf("Unexpected issue: %q", got.Issue)
+	}
+	if len(site.Recommendations) != 1 {
+		t.Errorf("Expected an hreflang recommendation, got %v", site.Recommendations)
+	}
+}
+
+func TestAnalyzeSiteHreflangConflict(t *testing.T) {
+	server := newSiteServer(t, map[string]string{
+		"/": `<html><head><link rel="alternate" hreflang="en" href="/">
+			<link rel="alternate" hreflang="en" href="/english"></head><body></body></html>`,
+	})
+	analyzer := newTestAnalyzer(t)
+	site := AnalyzeSite(analyzePages(t, analyzer, server, "/"))
+
+	if len(site.HreflangErrors) != 1 || !strings.Contains(site.HreflangErrors[0].Issue, "conflicting hreflang") {
+		t.Errorf("Expected a conflicting hreflang error, got %+v", site.HreflangErrors)
+	}
+}
+
+func TestAnalyzeSiteNoindexInSitemap(t *testing.T) {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/sitemap.xml":
+			w.Header().Set("Content-Type", "application/xml")
+			fmt.Fprintf(w, `<?xml version="1.0" encoding="UTF-8"?>
+<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+  <url><loc>%[1]s/</loc></url>
+  <url><loc>%[1]s/private</loc></url>
+</urlset>`, server.URL)
+		case "/":
+			fmt.Fprint(w, `<html><head><title>Home</title></head><body>Home</body></html>`)
+		case "/private":
+			fmt.Fprint(w, `<html><head><meta name="robots" content="noindex, follow"></head><body>Private</body></html>`)
+		case "/drafts":
+			fmt.Fprint(w, `<html><head><meta name="robots" content="noindex"></head><body>Drafts</body></html>`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	analyzer := newTestAnalyzer(t)
+	sitemap, err := analyzer.FetchSitemap(context.Background(), server.URL+"/sitemap.xml")
+	if err != nil {
+		t.Fatalf("Failed to fetch sitemap: %v", err)
+	}
+	if len(sitemap) != 2 {
+		t.Fatalf("Expected 2 sitemap URLs, got %v", sitemap)
+	}
+	pages := analyzePages(t, analyzer, server, "/", "/private", "/drafts")
+
+	site := AnalyzeSiteWithSitemap(pages, sitemap)
+
+	// /drafts is noindex too, but isn't in the sitemap
+	if len(site.NoindexInSitemap) != 1 || site.NoindexInSitemap[0] != server.URL+"/private" {
+		t.Errorf("Expected only /private to be reported, got %v", site.NoindexInSitemap)
+	}
+	found := false
+	for _, rec := range site.Recommendations {
+		if strings.Contains(rec, "noindex but listed in the sitemap") {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("Expected a noindex/sitemap recommendation, got %v", site.Recommendations)
+	}
+
+	if site := AnalyzeSite(pages); len(site.NoindexInSitemap) != 0 {
+		t.Errorf("Expected no sitemap findings without a sitemap, got %v", site.NoindexInSitemap)
+	}
+}
+
+func TestAnalyzeSiteSitemapDiscrepancies(t *testing.T) {
+	server := newSiteServer(t, map[string]string{
+		"/":      `<html><body><a href="/about">About</a> <a href="/blog">Blog</a> <a href="https://other.example/">Partner</a></body></html>`,
+		"/about": `<html><body><a href="/">Home</a> <a href="/team">Team</a></body></html>`,
+		"/blog":  `<html><body><a href="/">Home</a></body></html>`,
+	})
+	sitemap := []string{
+		server.URL + "/",
+		server.URL + "/about",
+		server.URL + "/blog",
+		server.URL + "/landing",              // orphan
+		"https://other.example/sitemap-page", // different host, ignored
+	}
+	analyzer := newTestAnalyzer(t)
+	pages := analyzePages(t, analyzer, server, "/", "/about", "/blog")
+
+	site := AnalyzeSiteWithSitemap(pages, sitemap)
+
+	if len(site.OrphanPages) != 1 || site.OrphanPages[0] != server.URL+"/landing" {
+		t.Errorf("Expected /landing to be the only orphan, got %v", site.OrphanPages)
+	}
+	if len(site.UnlistedPages) != 1 || site.UnlistedPages[0] != server.URL+"/team" {
+		t.Errorf("Expected /team to be the only unlisted page, got %v", site.UnlistedPages)
+	}
+
+	if site := AnalyzeSite(pages); len(site.OrphanPages) != 0 || len(site.UnlistedPages) != 0 {
+		t.Errorf("Expected no sitemap discrepancies without a sitemap, got %+v", site)
+	}
+}
+
+func TestHreflangValidation(t *testing.T) {
+	server := newSiteServer(t, map[string]string{
+		"/en/": `<html><head>
+			<link rel="alternate" hreflang="en-GB" href="/en/">
+			<link rel="alternate" hreflang="de" href="/de/">
+			<link rel="alternate" hreflang="de" href="/de/">
+			<link rel="alternate" hreflang="fr_FR" href="/fr/">
+			<link rel="alternate" hreflang="x-default" href="/">
+			</head><body></body></html>`,
+		"/de/": `<html><head><link rel="alternate" hreflang="en" href="/en/"></head><body></body></html>`,
+		"/":    `<html></html>`,
+	})
+	analyzer := newTestAnalyzer(t)
+
+	analysis, err := analyzer.Analyze(server.URL + "/en/")
+	if err != nil {
+		t.Fatalf("Failed to analyze URL: %v", err)
+	}
+	hreflang := analysis.Hreflang
+	if len(hreflang.Links) != 5 || !hreflang.HasSelfReference || !hreflang.HasXDefault {
+		t.Errorf("Expected 5 alternates with a self-reference and x-default, got %+v", hreflang)
+	}
+	if len(hreflang.InvalidCodes) != 1 || hreflang.InvalidCodes[0] != "fr_fr" {
+		t.Errorf("Expected fr_fr to be flagged as malformed, got %v", hreflang.InvalidCodes)
+	}
+	if len(hreflang.Duplicates) != 1 || hreflang.Duplicates[0] != "de" {
+		t.Errorf("Expected de to be flagged as duplicate, got %v", hreflang.Duplicates)
+	}
+	if len(hreflang.Broken) != 1 || hreflang.Broken[0] != server.URL+"/fr/" {
+		t.Errorf("Expected only /fr/ to be broken, got %v", hreflang.Broken)
+	}
+
+	// Neither a self-reference nor x-default
+	analysis, err = analyzer.Analyze(server.URL + "/de/")
+	if err != nil {
+		t.Fatalf("Failed to analyze URL: %v", err)
+	}
+	if analysis.Hreflang.HasSelfReference || analysis.Hreflang.HasXDefault {
+		t.Errorf("Expected no self-reference, got %+v", analysis.Hreflang)
+	}
+	found := false
+	for _, rec := range analysis.Recommendations {
+		found = found || strings.Contains(rec, "self-referencing hreflang")
+	}
+	if !found {
+		t.Errorf("Expected a self-reference recommendation, got %v", analysis.Recommendations)
+	}
+}
@@ -0,0 +1,151 @@
+package analyzer
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// maxSitemapBytes is the largest sitemap file read; the sitemap protocol
+// caps uncompressed sitemaps at 50MB
+const maxSitemapBytes = 50 << 20
+
+// maxSitemapFiles bounds how many child sitemaps of an index are fetched
+const maxSitemapFiles = 50
+
+// sitemapDocument covers both <urlset> and <sitemapindex> documents
+type sitemapDocument struct {
+	XMLName  xml.Name
+	URLs     []string `xml:"url>loc"`
+	Sitemaps []string `xml:"sitemap>loc"`
+}
+
+// FetchSitemap downloads the sitemap at sitemapURL and returns the page URLs
+// it lists. A sitemap index is followed one level deep.
+func (a *Analyzer) FetchSitemap(ctx context.Context, sitemapURL string) ([]string, error) {
+	doc, err := a.fetchSitemapDocument(ctx, sitemapURL)
+	if err != nil {
+		return nil, err
+	}
+	urls := doc.URLs
+	for i, child := range doc.Sitemaps {
+		if i >= maxSitemapFiles {
+			break
+		}
+		childDoc, err := a.fetchSitemapDocument(ctx, strings.TrimSpace(child))
+		if err != nil {
+			return nil, err
+		}
+		urls = append(urls, childDoc.URLs...)
+	}
+
+	for i := range urls {
+		urls[i] = strings.TrimSpace(urls[i])
+	}
+	return urls, nil
+}
+
+func (a *Analyzer) fetchSitemapDocument(ctx context.Context, sitemapURL string) (*sitemapDocument, error) {
+	if a.MaintenanceMode() {
+		return nil, ErrMaintenance
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", sitemapURL, nil)
+	if err != nil {
+		return nil, &FetchError{URL: sitemapURL, Category: CategoryInvalidURL, Err: err}
+	}
+	req.Header.Set("User-Agent", deviceProfiles[DeviceDesktop].UserAgent)
+
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return nil, newFetchError(sitemapURL, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		return nil, &FetchError{URL: sitemapURL, Category: CategoryHTTPStatus,
+			Err: fmt.Errorf("sitemap returned HTTP status %d", resp.StatusCode)}
+	}
+
+	var doc sitemapDocument
+	if err := xml.NewDecoder(io.LimitReader(resp.Body, maxSitemapBytes)).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("invalid sitemap %s: %w", sitemapURL, err)
+	}
+	return &doc, nil
+}
+
+// findNoindexInSitemap returns the analyzed pages that are listed in the
+// sitemap while their robots meta tells search engines not to index them
+func findNoindexInSitemap(pages map[string]*SEOAnalysis, sitemapURLs []string) []string {
+	listed := make(map[string]bool, len(sitemapURLs))
+	for _, u := range sitemapURLs {
+		listed[normalizeURL(u)] = true
+	}
+
+	contradictions := []string{}
+	for _, pageURL := range sortedPageURLs(pages) {
+		page := pages[pageURL]
+		if page == nil || !listed[normalizeURL(pageURL)] {
+			continue
+		}
+		if strings.Contains(strings.ToLower(page.Meta.Robots), "noindex") {
+			contradictions = append(contradictions, pageURL)
+		}
+	}
+	return contradictions
+}
+
+// findSitemapDiscrepancies compares the sitemap against the internal link
+// graph of the analyzed pages. Orphans are listed in the sitemap but not
+// linked from any analyzed page; unlisted pages are linked but missing from
+// the sitemap. Only URLs on the analyzed pages' hosts are compared.
+func findSitemapDiscrepancies(pages map[string]*SEOAnalysis, sitemapURLs []string) (orphans, unlisted []string) {
+	orphans, unlisted = []string{}, []string{}
+	if len(sitemapURLs) == 0 {
+		return orphans, unlisted
+	}
+
+	hosts := make(map[string]bool)
+	linked := make(map[string]bool)
+	for pageURL, page := range pages {
+		if u, err := url.Parse(pageURL); err == nil {
+			hosts[strings.ToLower(u.Host)] = true
+		}
+		if page == nil {
+			continue
+		}
+		for _, href := range page.Links.InternalHrefs {
+			linked[normalizeURL(href)] = true
+		}
+	}
+	onSite := func(raw string) bool {
+		u, err := url.Parse(raw)
+		return err == nil && hosts[strings.ToLower(u.Host)]
+	}
+
+	listed := make(map[string]bool, len(sitemapURLs))
+	for _, sitemapURL := range sitemapURLs {
+		normalized := normalizeURL(sitemapURL)
+		if listed[normalized] || !onSite(sitemapURL) {
+			continue
+		}
+		listed[normalized] = true
+		if !linked[normalized] {
+			orphans = append(orphans, normalized)
+		}
+	}
+	for href := range linked {
+		if !listed[href] && onSite(href) {
+			unlisted = append(unlisted, href)
+		}
+	}
+
+	sort.Strings(orphans)
+	sort.Strings(unlisted)
+	return orphans, unlisted
+}
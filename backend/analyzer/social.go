@@ -0,0 +1,90 @@
+package analyzer
+
+import (
+	"context"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// minOGImageDimension is the smallest og:image size platforms will render
+const minOGImageDimension = 200
+
+// Tags social platforms need for a full share preview
+var (
+	requiredOpenGraphTags   = []string{"og:title", "og:description", "og:image", "og:url", "og:type"}
+	requiredTwitterCardTags = []string{"twitter:card", "twitter:title", "twitter:image"}
+)
+
+// analyzeSocialTags extracts Open Graph and Twitter Card metadata. When
+// verifyImage is set the og:image is checked with a HEAD request through the
+// link cache.
+func (a *Analyzer) analyzeSocialTags(ctx context.Context, doc *goquery.Document, pageURL string, verifyImage bool) SocialAnalysis {
+	social := SocialAnalysis{
+		OGTitle:            metaProperty(doc, "og:title"),
+		OGDescription:      metaProperty(doc, "og:description"),
+		OGType:             metaProperty(doc, "og:type"),
+		TwitterCard:        metaProperty(doc, "twitter:card"),
+		TwitterTitle:       metaProperty(doc, "twitter:title"),
+		TwitterDescription: metaProperty(doc, "twitter:description"),
+		TwitterImage:       metaProperty(doc, "twitter:image"),
+		MissingOpenGraph:   missingMetaProperties(doc, requiredOpenGraphTags),
+		MissingTwitterCard: missingMetaProperties(doc, requiredTwitterCardTags),
+	}
+
+	if ogURL := metaProperty(doc, "og:url"); ogURL != "" {
+		social.OGURL = resolveURL(pageURL, ogURL)
+	}
+	social.OGImage = metaProperty(doc, "og:image")
+	social.OGImageWidth, _ = strconv.Atoi(metaProperty(doc, "og:image:width"))
+	social.OGImageHeight, _ = strconv.Atoi(metaProperty(doc, "og:image:height"))
+	social.HasImageDimensions = social.OGImageWidth > 0 && social.OGImageHeight > 0
+
+	if social.OGImage != "" && verifyImage {
+		reachable := a.isLinkAccessibleWithContext(ctx, resolveURL(pageURL, social.OGImage))
+		social.OGImageReachable = &reachable
+	}
+
+	return social
+}
+
+// checkOGURLCanonical flags an og:url that names a different page than the
+// canonical link, which makes social shares and search results point at
+// different URLs. Nothing is flagged unless both are declared.
+func checkOGURLCanonical(social *SocialAnalysis, canonical string) {
+	social.OGURLCanonicalMismatch = social.OGURL != "" && canonical != "" && !sameURL(social.OGURL, canonical)
+}
+
+// missingMetaProperties returns the properties that have no non-empty tag
+func missingMetaProperties(doc *goquery.Document, properties []string) []string {
+	missing := []string{}
+	for _, property := range properties {
+		if metaProperty(doc, property) == "" {
+			missing = append(missing, property)
+		}
+	}
+	return missing
+}
+
+// metaProperty returns the trimmed content of the first meta tag whose
+// property (or name, which some sites use instead) matches
+func metaProperty(doc *goquery.Document, property string) string {
+	content, _ := doc.Find(`meta[property="` + property + `"], meta[name="` + property + `"]`).First().Attr("content")
+	return strings.TrimSpace(content)
+}
+
+// resolveURL resolves ref against base, returning ref unchanged if either
+// fails to parse
+func resolveURL(base, ref string) string {
+	baseURL, err := url.Parse(base)
+	if err != nil {
+		return ref
+	}
+	refURL, err := url.Parse(strings.TrimSpace(ref))
+	if err != nil {
+		return ref
+	}
+	return baseURL.ResolveReference(refURL).String()
+}
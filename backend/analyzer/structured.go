@@ -0,0 +1,93 @@
+package analyzer
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// analyzeStructuredData parses every JSON-LD block on the page. Blocks that
+// fail to parse are recorded with their error rather than failing the
+// analysis.
+func analyzeStructuredData(doc *goquery.Document) StructuredDataAnalysis {
+	var result StructuredDataAnalysis
+	all := make(map[string]bool)
+
+	doc.Find(`script[type="application/ld+json"]`).Each(func(_ int, s *goquery.Selection) {
+		block := JSONLDBlock{Valid: true}
+		var data interface{}
+		if err := json.Unmarshal([]byte(strings.TrimSpace(s.Text())), &data); err != nil {
+			block.Valid = false
+			block.Error = err.Error()
+			result.InvalidBlocks++
+		} else {
+			block.Types = sortedKeys(schemaTypes(data))
+			for _, t := range block.Types {
+				all[t] = true
+			}
+		}
+		result.Blocks = append(result.Blocks, block)
+	})
+
+	result.Types = sortedKeys(all)
+	return result
+}
+
+// jsonLDTypes returns every schema.org @type declared in the page's JSON-LD
+// blocks, including nested objects and @graph entries. Invalid blocks are
+// skipped.
+func jsonLDTypes(doc *goquery.Document) map[string]bool {
+	types := make(map[string]bool)
+	for _, t := range analyzeStructuredData(doc).Types {
+		types[t] = true
+	}
+	return types
+}
+
+// schemaTypes collects the @type values of a parsed JSON-LD value
+func schemaTypes(data interface{}) map[string]bool {
+	types := make(map[string]bool)
+
+	var walk func(v interface{})
+	walk = func(v interface{}) {
+		switch node := v.(type) {
+		case map[string]interface{}:
+			switch t := node["@type"].(type) {
+			case string:
+				types[t] = true
+			case []interface{}:
+				for _, item := range t {
+					if s, ok := item.(string); ok {
+						types[s] = true
+					}
+				}
+			}
+			for _, child := range node {
+				walk(child)
+			}
+		case []interface{}:
+			for _, child := range node {
+				walk(child)
+			}
+		}
+	}
+
+	walk(data)
+	return types
+}
+
+// breadcrumbSelector matches visible breadcrumb trails by their usual class
+// names and ARIA labels
+const breadcrumbSelector = `nav[aria-label*="breadcrumb" i], [class*="breadcrumb" i], [id*="breadcrumb" i]`
+
+// analyzeBreadcrumbs cross-checks a visible breadcrumb trail against
+// BreadcrumbList structured data (JSON-LD or microdata)
+func (a *Analyzer) analyzeBreadcrumbs(doc *goquery.Document) BreadcrumbAnalysis {
+	result := BreadcrumbAnalysis{
+		HasVisible:    doc.Find(breadcrumbSelector).Length() > 0,
+		HasStructured: jsonLDTypes(doc)["BreadcrumbList"] || doc.Find(`[itemtype*="BreadcrumbList"]`).Length() > 0,
+	}
+	result.Mismatch = result.HasVisible != result.HasStructured
+	return result
+}
@@ -0,0 +1,142 @@
+package analyzer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"syscall"
+)
+
+// ErrInvalidURL is returned for page URLs that can't be analyzed
+var ErrInvalidURL = errors.New("invalid URL")
+
+// ParseTargetURL validates a page URL submitted for analysis and returns its
+// normalized form: lowercase scheme and host, "/" for an empty path and no
+// fragment. A URL without a scheme, such as "example.com/page", is taken
+// to be https when its host looks like a domain name or IP address. Only
+// http(s) URLs with a host and no credentials are accepted.
+func ParseTargetURL(raw string) (string, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return "", fmt.Errorf("%w: URL is empty", ErrInvalidURL)
+	}
+	if !strings.Contains(raw, "://") {
+		// "javascript:alert(1)" has a scheme, "localhost:8080" has a port
+		if u, err := url.Parse(raw); err == nil && u.Scheme != "" &&
+			(u.Opaque == "" || u.Opaque[0] < '0' || u.Opaque[0] > '9') {
+			return "", fmt.Errorf("%w: scheme %q is not supported, use http or https", ErrInvalidURL, u.Scheme)
+		}
+		u, err := url.Parse("https://" + raw)
+		if err != nil || !looksLikeHost(u.Hostname()) {
+			return "", fmt.Errorf("%w: %q has no http(s) scheme and is not a domain name", ErrInvalidURL, raw)
+		}
+		raw = "https://" + raw
+	}
+
+	u, err := url.Parse(raw)
+	if err != nil {
+		return "", fmt.Errorf("%w: %s", ErrInvalidURL, strings.TrimPrefix(err.Error(), "parse "))
+	}
+	if scheme := strings.ToLower(u.Scheme); scheme != "http" && scheme != "https" {
+		return "", fmt.Errorf("%w: scheme %q is not supported, use http or https", ErrInvalidURL, u.Scheme)
+	}
+	if u.Hostname() == "" {
+		return "", fmt.Errorf("%w: URL has no host", ErrInvalidURL)
+	}
+	if u.User != nil {
+		return "", fmt.Errorf("%w: URL must not contain credentials", ErrInvalidURL)
+	}
+	return normalizeURL(u.String()), nil
+}
+
+// looksLikeHost reports whether host is an IP address, localhost or a
+// dotted domain name, as opposed to a stray word
+func looksLikeHost(host string) bool {
+	return net.ParseIP(host) != nil || strings.EqualFold(host, "localhost") ||
+		strings.Contains(strings.Trim(host, "."), ".")
+}
+
+// SetAllowPrivateTargets allows analyzing pages on loopback and private
+// addresses, for trusted deployments that audit internal sites. By default
+// such pages are refused with ErrPrivateAddress.
+func (a *Analyzer) SetAllowPrivateTargets(enabled bool) {
+	a.configMutex.Lock()
+	defer a.configMutex.Unlock()
+	a.privateTargets = enabled
+}
+
+// SetTrustedNetworks allows connections to the given internal ranges while
+// other private addresses stay blocked, e.g. for a staging server
+func (a *Analyzer) SetTrustedNetworks(nets []*net.IPNet) {
+	a.configMutex.Lock()
+	defer a.configMutex.Unlock()
+	a.trustedNetworks = nets
+}
+
+// targetIPAllowed reports whether the analyzer may connect to ip
+func (a *Analyzer) targetIPAllowed(ip net.IP) bool {
+	a.configMutex.RLock()
+	defer a.configMutex.RUnlock()
+	if a.privateTargets || isPublicIP(ip) {
+		return true
+	}
+	for _, trusted := range a.trustedNetworks {
+		if trusted.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// dialControl refuses connections to addresses targetIPAllowed rejects.
+// It runs on the resolved address of every dial, so redirects, links and
+// DNS names that change between the check and the fetch are covered too.
+func (a *Analyzer) dialControl(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	if ip := net.ParseIP(host); ip == nil || !a.targetIPAllowed(ip) {
+		return fmt.Errorf("connection to %s: %w", host, ErrPrivateAddress)
+	}
+	return nil
+}
+
+// checkTargetAllowed returns ErrPrivateAddress when rawURL's host is, or
+// resolves to, an address the analyzer may not connect to, so the request
+// fails before it is queued. Hosts that don't resolve are left for the fetch
+// to report.
+func (a *Analyzer) checkTargetAllowed(ctx context.Context, rawURL string) error {
+	a.configMutex.RLock()
+	allowPrivate := a.privateTargets
+	a.configMutex.RUnlock()
+	if allowPrivate {
+		return nil
+	}
+
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidURL, err)
+	}
+	host := parsed.Hostname()
+	if ip := net.ParseIP(host); ip != nil {
+		if !a.targetIPAllowed(ip) {
+			return fmt.Errorf("%s: %w", host, ErrPrivateAddress)
+		}
+		return nil
+	}
+
+	addrs, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		return nil
+	}
+	for _, addr := range addrs {
+		if !a.targetIPAllowed(addr.IP) {
+			return fmt.Errorf("%s resolves to %s: %w", host, addr.IP, ErrPrivateAddress)
+		}
+	}
+	return nil
+}
@@ -3,14 +3,43 @@ package analyzer
 // SEOAnalysis represents the complete analysis of a webpage
 type SEOAnalysis struct {
 	URL           string         `json:"url"`
+	Device        DeviceProfile  `json:"device"`
+	URLAnalysis   URLAnalysis    `json:"urlAnalysis"`
 	Title         TitleAnalysis  `json:"title"`
 	Meta          MetaAnalysis   `json:"meta"`
 	Headers       HeaderAnalysis `json:"headers"`
 	Content       ContentAnalysis `json:"content"`
 	Performance   Performance    `json:"performance"`
 	Links         LinkAnalysis   `json:"links"`
+	HeadOrder     HeadOrderAnalysis `json:"headOrder"`
+	Document      DocumentInfo   `json:"document"`
+	Social        SocialAnalysis `json:"social"`
+	Breadcrumbs   BreadcrumbAnalysis `json:"breadcrumbs"`
+	StructuredData StructuredDataAnalysis `json:"structuredData"`
+	Icons         IconAnalysis   `json:"icons"`
+	PWA           PWAAnalysis    `json:"pwa"`
+	SERPPreview   SERPPreview    `json:"serpPreview"`
+	Hreflang      HreflangAnalysis `json:"hreflang"`
+	ResourceHints ResourceHintAnalysis `json:"resourceHints"`
+	// MixedContent lists http:// resources loaded by an https:// page
+	MixedContent  []string       `json:"mixedContent"`
+	Robots        RobotsAnalysis `json:"robots"`
+	Redirects     RedirectAnalysis `json:"redirects"`
+	Response      ResponseInfo   `json:"response"`
+	CacheHeaders  CacheHeaders   `json:"cacheHeaders"`
 	Score         float64       `json:"score"`
+	Grade         string        `json:"grade"` // letter grade of Score, A to F
 	Recommendations []string     `json:"recommendations"`
+	Warnings      []string       `json:"warnings,omitempty"`
+	// Truncated is set when memory caps cut the analysis short
+	Truncated     bool           `json:"truncated"`
+	Iframes       *IframeAnalysis `json:"iframes,omitempty"`
+	AMP           *AMPAnalysis    `json:"amp,omitempty"`
+	// Deprecated markup is only checked with the thorough profile
+	DeprecatedMarkup *DeprecatedMarkupAnalysis `json:"deprecatedMarkup,omitempty"`
+
+	// outputDecimals is the precision float fields are rounded to in JSON
+	outputDecimals *int
 }
 
 type TitleAnalysis struct {
@@ -26,8 +55,12 @@ type MetaAnalysis struct {
 	HasDescription  bool   `json:"hasDescription"`
 	Keywords        string `json:"keywords"`
 	HasKeywords     bool   `json:"hasKeywords"`
-	Robots          string `json:"robots"`
+	Robots          string `json:"robots"` // effective directives merged from all robots tags
+	RobotsTags      []string `json:"robotsTags"`
+	RobotsConflict  bool   `json:"robotsConflict"` // tags contradict each other
 	Viewport        string `json:"viewport"`
+	Canonical       string `json:"canonical"` // absolute rel=canonical URL
+	AMPHTML         string `json:"ampHtml"`   // absolute rel=amphtml URL
 	Score           int    `json:"score"`
 }
 
@@ -35,16 +68,57 @@ type HeaderAnalysis struct {
 	H1Count int      `json:"h1Count"`
 	H2Count int      `json:"h2Count"`
 	H3Count int      `json:"h3Count"`
+	H4Count int      `json:"h4Count"`
+	H5Count int      `json:"h5Count"`
+	H6Count int      `json:"h6Count"`
 	H1Text  []string `json:"h1Text"`
+	// HasProperHierarchy is false when HierarchyIssues lists any problem
+	HasProperHierarchy bool           `json:"hasProperHierarchy"`
+	HierarchyIssues    []HeadingIssue `json:"hierarchyIssues"`
 	Score   int      `json:"score"`
 }
 
+// HeadingIssue is one problem with the order or text of the page's headings
+type HeadingIssue struct {
+	// Type is "skippedLevel" (e.g. an H4 right after an H1),
+	// "missingParent" (e.g. an H3 before any H2) or "duplicateText" (a
+	// heading repeating an earlier one of the same level)
+	Type     string `json:"type"`
+	Level    int    `json:"level"`
+	Previous int    `json:"previous,omitempty"` // level of the heading before, for skippedLevel
+	Text     string `json:"text"`
+}
+
 type ContentAnalysis struct {
 	WordCount        int               `json:"wordCount"`
 	KeywordDensity   map[string]float64 `json:"keywordDensity"`
+	// TopKeywords lists the same terms as KeywordDensity, most dense first
+	TopKeywords      []KeywordFrequency `json:"topKeywords"`
 	HasImages        bool              `json:"hasImages"`
 	ImagesWithAlt    int               `json:"imagesWithAlt"`
 	TotalImages      int               `json:"totalImages"`
+	// Images with loading="lazy" and with a srcset for responsive sizes
+	LazyLoadedImages int               `json:"lazyLoadedImages"`
+	ResponsiveImages int               `json:"responsiveImages"`
+	// Image weight from HEAD requests: ImagesProbed images reported a size,
+	// adding up to TotalImageBytes; LargeImages are over the size threshold
+	ImagesProbed     int               `json:"imagesProbed"`
+	TotalImageBytes  int               `json:"totalImageBytes"`
+	LargeImages      []string          `json:"largeImages"`
+	// BrokenImages counts unique image URLs that fail to load
+	BrokenImages     int               `json:"brokenImages"`
+	// LikelyInfiniteScroll flags listing pages whose content is probably
+	// loaded client-side (infinite scroll or thin pages with pagination)
+	LikelyInfiniteScroll bool          `json:"likelyInfiniteScroll"`
+	// HeadingPoor flags long content with too few H2/H3 subheadings
+	HeadingPoor      bool              `json:"headingPoor"`
+	// Flesch Reading Ease (0-100, higher is easier) of the page's prose and
+	// its band; the grade is empty when the page has too little prose to
+	// score
+	ReadabilityScore float64           `json:"readabilityScore"`
+	ReadabilityGrade string            `json:"readabilityGrade"`
+	// TextToHTMLRatio is the visible text's share of the HTML in percent
+	TextToHTMLRatio  float64           `json:"textToHtmlRatio"`
 	Score            int               `json:"score"`
 }
 
@@ -55,11 +129,345 @@ type Performance struct {
 	Score           int    `json:"score"`
 	PageSizeSeverity string `json:"pageSizeSeverity"`
 	LoadTimeSeverity string `json:"loadTimeSeverity"`
+	MaxDomDepth      int    `json:"maxDomDepth"` // deepest element nesting level
+	// Stylesheets and synchronous external scripts in the head that delay
+	// the first render
+	RenderBlockingCSS int   `json:"renderBlockingCss"`
+	RenderBlockingJS  int   `json:"renderBlockingJs"`
+	// Compressed is set when the page was served gzip or br encoded;
+	// otherwise EstimatedCompressionSavings is how many bytes gzip would
+	// have saved
+	Compressed                  bool `json:"compressed"`
+	EstimatedCompressionSavings int  `json:"estimatedCompressionSavings"`
+	// Separate files the page loads, and styles and scripts inlined in it
+	// where browsers can't cache them
+	ExternalStylesheets   int `json:"externalStylesheets"`
+	ExternalScripts       int `json:"externalScripts"`
+	InlineStyleBlocks     int `json:"inlineStyleBlocks"`
+	InlineStyleAttributes int `json:"inlineStyleAttributes"`
+	InlineScripts         int `json:"inlineScripts"`
 }
 
 type LinkAnalysis struct {
 	InternalLinks int    `json:"internalLinks"`
 	ExternalLinks int    `json:"externalLinks"`
 	BrokenLinks   int    `json:"brokenLinks"`
+	LinksChecked  bool   `json:"linksChecked"` // false when link checks were skipped
+	SelfLinks     int    `json:"selfLinks"` // links whose target is the page itself
+	// Anchor text of internal links; KeywordAnchors is only set when a
+	// target keyword was requested
+	TargetKeyword  string `json:"targetKeyword,omitempty"`
+	KeywordAnchors int    `json:"keywordAnchors"`
+	GenericAnchors int    `json:"genericAnchors"` // "click here", "read more", ...
+	// Links qualified with rel="nofollow", "sponsored" or "ugc"; a link can
+	// carry several
+	NofollowLinks  int    `json:"nofollowLinks"`
+	SponsoredLinks int    `json:"sponsoredLinks"`
+	UGCLinks       int    `json:"ugcLinks"`
+	// AnchorTextIssues lists links, internal or external, with empty or
+	// generic anchor text
+	AnchorTextIssues []string `json:"anchorTextIssues"`
+	Truncated     bool   `json:"truncated,omitempty"` // stopped at the per-page link cap
 	Score         int    `json:"score"`
-} 
\ No newline at end of file
+	// InternalHrefs keeps internal link targets as written (resolved to
+	// absolute URLs) for cross-page checks in AnalyzeSite
+	InternalHrefs []string `json:"-"`
+} 
+
+// HeadOrderAnalysis reports whether critical head tags appear early enough
+// for browsers to pick them up quickly
+type HeadOrderAnalysis struct {
+	CharsetPosition        int      `json:"charsetPosition"`
+	CharsetEarly           bool     `json:"charsetEarly"`
+	HasTitle               bool     `json:"hasTitle"`
+	TitleBeforeScripts     bool     `json:"titleBeforeScripts"`
+	HasViewport            bool     `json:"hasViewport"`
+	ViewportBeforeBlocking bool     `json:"viewportBeforeBlocking"`
+	Violations             []string `json:"violations"`
+}
+
+// DocumentInfo reports the declared character encoding and the doctype
+type DocumentInfo struct {
+	Charset       string `json:"charset"` // lowercased, e.g. "utf-8"
+	CharsetSource string `json:"charsetSource,omitempty"` // "meta charset" or "http-equiv"
+	HasCharset    bool   `json:"hasCharset"`
+	Doctype       string `json:"doctype,omitempty"` // as written
+	HasDoctype    bool   `json:"hasDoctype"`
+	// HTML5Doctype is set for <!DOCTYPE html>; without any doctype
+	// browsers render in quirks mode
+	HTML5Doctype  bool   `json:"html5Doctype"`
+}
+
+// SocialAnalysis reports the metadata used to render social sharing previews
+type SocialAnalysis struct {
+	OGTitle            string `json:"ogTitle"`
+	OGDescription      string `json:"ogDescription"`
+	OGType             string `json:"ogType"`
+	TwitterCard        string `json:"twitterCard"`
+	TwitterTitle       string `json:"twitterTitle"`
+	TwitterDescription string `json:"twitterDescription"`
+	TwitterImage       string `json:"twitterImage"`
+	// Required tags that are absent or empty
+	MissingOpenGraph   []string `json:"missingOpenGraph"`
+	MissingTwitterCard []string `json:"missingTwitterCard"`
+
+	OGURL string `json:"ogUrl"`
+	// OGURLCanonicalMismatch is set when og:url and rel=canonical differ
+	OGURLCanonicalMismatch bool   `json:"ogUrlCanonicalMismatch"`
+	OGImage                string `json:"ogImage"`
+	OGImageWidth           int    `json:"ogImageWidth"`
+	OGImageHeight          int    `json:"ogImageHeight"`
+	HasImageDimensions     bool   `json:"hasImageDimensions"`
+	OGImageReachable       *bool  `json:"ogImageReachable,omitempty"` // nil when not verified
+}
+
+// IframeAnalysis reports content embedded through iframes, kept separate
+// from the top-level page analysis
+type IframeAnalysis struct {
+	Frames             []FrameAnalysis `json:"frames"`
+	SkippedCrossOrigin int             `json:"skippedCrossOrigin"`
+}
+
+// FrameAnalysis summarizes a single same-origin iframe
+type FrameAnalysis struct {
+	URL       string   `json:"url"`
+	WordCount int      `json:"wordCount"`
+	Headings  []string `json:"headings"`
+	Error     string   `json:"error,omitempty"`
+}
+
+// RobotsAnalysis reports whether robots.txt lets crawlers fetch the page
+type RobotsAnalysis struct {
+	HasRobotsTxt bool     `json:"hasRobotsTxt"`
+	IsBlocked    bool     `json:"isBlocked"`
+	MatchedRule  string   `json:"matchedRule,omitempty"` // rule that decided IsBlocked
+	SitemapURLs  []string `json:"sitemapUrls"`
+	Error        string   `json:"error,omitempty"` // robots.txt could not be read
+}
+
+// ResourceHintAnalysis compares the third-party origins a page loads from
+// with its preconnect/dns-prefetch hints
+type ResourceHintAnalysis struct {
+	ThirdPartyOrigins []OriginUsage `json:"thirdPartyOrigins"` // most requested first
+	Hinted            []string      `json:"hinted"`            // origins with a preconnect or dns-prefetch hint
+	MissingHints      []string      `json:"missingHints"`      // third-party origins without a hint
+}
+
+// OriginUsage counts the subresources requested from one origin
+type OriginUsage struct {
+	Origin   string `json:"origin"`
+	Requests int    `json:"requests"`
+}
+
+// SiteAnalysis reports issues that only show up when comparing several
+// pages of the same site
+type SiteAnalysis struct {
+	Pages                        int                          `json:"pages"`
+	TrailingSlashInconsistencies []TrailingSlashInconsistency `json:"trailingSlashInconsistencies"`
+	HreflangErrors               []HreflangError              `json:"hreflangErrors"`
+	NoindexInSitemap             []string                     `json:"noindexInSitemap"` // noindex pages listed in the sitemap
+	OrphanPages                  []string                     `json:"orphanPages"`      // in the sitemap but not linked
+	UnlistedPages                []string                     `json:"unlistedPages"`    // linked but not in the sitemap
+	Recommendations              []string                     `json:"recommendations"`
+}
+
+// HreflangError describes a broken hreflang relationship between two pages
+type HreflangError struct {
+	Page   string `json:"page"`   // page declaring the hreflang link
+	Target string `json:"target"` // alternate it points to
+	Lang   string `json:"lang"`
+	Issue  string `json:"issue"`
+}
+
+// TrailingSlashInconsistency describes an internal URL linked both with and
+// without a trailing slash
+type TrailingSlashInconsistency struct {
+	Path               string   `json:"path"`
+	LinkedWithSlash    []string `json:"linkedWithSlash"`    // pages using /path/
+	LinkedWithoutSlash []string `json:"linkedWithoutSlash"` // pages using /path
+}
+
+// AMPAnalysis verifies the canonical relationship between a page and its
+// AMP variant
+type AMPAnalysis struct {
+	AMPURL string `json:"ampUrl"`
+	// ExpectedCanonical is the main page's canonical URL (or the page URL
+	// when it declares none); the AMP page must point back to it
+	ExpectedCanonical string   `json:"expectedCanonical"`
+	AMPCanonical      string   `json:"ampCanonical"`
+	RoundTrip         bool     `json:"roundTrip"`
+	Issues            []string `json:"issues"`
+	Error             string   `json:"error,omitempty"`
+}
+
+// DeprecatedMarkupAnalysis counts obsolete HTML elements and presentational
+// attributes, keyed by tag or attribute name
+type DeprecatedMarkupAnalysis struct {
+	Elements   map[string]int `json:"elements"`
+	Attributes map[string]int `json:"attributes"`
+	Total      int            `json:"total"`
+}
+
+// URLAnalysis scores the hygiene of the analyzed URL string itself
+type URLAnalysis struct {
+	Length         int      `json:"length"`
+	Depth          int      `json:"depth"` // number of path segments
+	HasUnderscores bool     `json:"hasUnderscores"`
+	HasUppercase   bool     `json:"hasUppercase"`
+	QueryLength    int      `json:"queryLength"`
+	QueryParams    int      `json:"queryParams"`
+	StopWords      []string `json:"stopWords"`
+	Score          int      `json:"score"`
+}
+
+// BreadcrumbAnalysis compares the visible breadcrumb trail with
+// BreadcrumbList structured data
+type BreadcrumbAnalysis struct {
+	HasVisible    bool `json:"hasVisible"`
+	HasStructured bool `json:"hasStructured"`
+	Mismatch      bool `json:"mismatch"`
+}
+
+// ResponseInfo describes the HTTP response the page was served with
+type ResponseInfo struct {
+	StatusCode      int    `json:"statusCode"`
+	ContentType     string `json:"contentType"`
+	Server          string `json:"server,omitempty"`
+	ContentEncoding string `json:"contentEncoding,omitempty"`
+	// Compressed is set for gzip or br encoded responses
+	Compressed bool `json:"compressed"`
+	// Truncated is set when the body exceeded the page size limit and only
+	// its start was analyzed (best effort mode)
+	Truncated bool `json:"truncated"`
+}
+
+// CacheHeaders describes how browsers may cache the page response
+type CacheHeaders struct {
+	HasCacheControl bool   `json:"hasCacheControl"`
+	CacheControl    string `json:"cacheControl,omitempty"`
+	NoStore         bool   `json:"noStore"`
+	NoCache         bool   `json:"noCache"` // stored, but revalidated on every use
+	HasExpires      bool   `json:"hasExpires"`
+	HasETag         bool   `json:"hasETag"`
+	HasLastModified bool   `json:"hasLastModified"`
+	// MaxAge is how many seconds the page stays fresh, from max-age or
+	// Expires
+	MaxAge int `json:"maxAge"`
+	// Cacheable is set when the page may be reused, either while fresh or
+	// after revalidating it with its ETag or Last-Modified
+	Cacheable bool `json:"cacheable"`
+}
+
+// RedirectAnalysis lists the redirects followed to reach the analyzed page
+type RedirectAnalysis struct {
+	Chain            []RedirectHop `json:"chain"`
+	Redirects        int           `json:"redirects"`
+	FinalURL         string        `json:"finalUrl"`
+	UpgradedToHTTPS  bool          `json:"upgradedToHttps"`  // http:// URL ended on https://
+	DowngradesToHTTP bool          `json:"downgradesToHttp"` // a hop went from https:// to http://
+}
+
+// RedirectHop is one redirect response
+type RedirectHop struct {
+	URL        string `json:"url"`
+	StatusCode int    `json:"statusCode"`
+	Location   string `json:"location"`
+}
+
+// SERPPreview estimates how the page appears in search results. Widths are
+// pixel estimates; truncated strings end in "...".
+type SERPPreview struct {
+	Title                 string `json:"title"`
+	TitleTruncated        bool   `json:"titleTruncated"`
+	TitlePixelWidth       int    `json:"titlePixelWidth"`
+	DisplayURL            string `json:"displayUrl"`
+	Description           string `json:"description"`
+	DescriptionTruncated  bool   `json:"descriptionTruncated"`
+	DescriptionPixelWidth int    `json:"descriptionPixelWidth"`
+	Favicon               string `json:"favicon"`
+}
+
+// IconAnalysis lists the icon and manifest URLs the page declares
+type IconAnalysis struct {
+	Favicon        string `json:"favicon,omitempty"`
+	AppleTouchIcon string `json:"appleTouchIcon,omitempty"`
+	Manifest       string `json:"manifest,omitempty"`
+	// HasFavicon is set for a declared favicon that isn't known to be
+	// broken, or when /favicon.ico loads for a page that declares none
+	// (FaviconFallback)
+	HasFavicon        bool `json:"hasFavicon"`
+	FaviconFallback   bool `json:"faviconFallback"`
+	HasAppleTouchIcon bool `json:"hasAppleTouchIcon"`
+	// Verified is set when the declared URLs were requested (VERIFY_ICONS)
+	Verified bool     `json:"verified"`
+	Broken   []string `json:"broken"`
+}
+
+// PWAAnalysis reports the page's web app manifest and service worker
+// registration, which browsers need before offering to install a site
+type PWAAnalysis struct {
+	HasManifest bool   `json:"hasManifest"`
+	ManifestURL string `json:"manifestUrl,omitempty"`
+	// ManifestValid is set when the manifest loaded and parsed as JSON;
+	// otherwise ManifestError says why not
+	ManifestValid bool   `json:"manifestValid"`
+	ManifestError string `json:"manifestError,omitempty"`
+	HasName       bool   `json:"hasName"` // name or short_name
+	HasIcons      bool   `json:"hasIcons"`
+	HasStartURL   bool   `json:"hasStartUrl"`
+	// ServiceWorker is set when an inline script calls
+	// navigator.serviceWorker.register()
+	ServiceWorker    bool   `json:"serviceWorker"`
+	ServiceWorkerURL string `json:"serviceWorkerUrl,omitempty"`
+	Installable      bool   `json:"installable"`
+}
+
+// StructuredDataAnalysis summarizes the page's JSON-LD blocks
+type StructuredDataAnalysis struct {
+	Blocks        []JSONLDBlock `json:"blocks"`
+	Types         []string      `json:"types"`
+	InvalidBlocks int           `json:"invalidBlocks"`
+}
+
+// JSONLDBlock is one <script type="application/ld+json"> block
+type JSONLDBlock struct {
+	Valid bool     `json:"valid"`
+	Types []string `json:"types,omitempty"`
+	Error string   `json:"error,omitempty"`
+}
+
+// QuickAnalysis holds the head-only checks run by QuickAnalyze
+type QuickAnalysis struct {
+	URL       string            `json:"url"`
+	Title     TitleAnalysis     `json:"title"`
+	Meta      MetaAnalysis      `json:"meta"`
+	HeadOrder HeadOrderAnalysis `json:"headOrder"`
+	Social    SocialAnalysis    `json:"social"`
+	BytesRead int               `json:"bytesRead"`
+	// HeadOnly is false when no </head> was found within the read cap and
+	// the whole body had to be downloaded
+	HeadOnly bool `json:"headOnly"`
+	LoadTime int  `json:"loadTime"` // milliseconds
+}
+
+// HreflangAnalysis lists the page's hreflang alternates and the mistakes
+// found in them. The checks only apply to pages declaring alternates.
+type HreflangAnalysis struct {
+	Links []HreflangLink `json:"links"`
+	// InvalidCodes are values that aren't a language code with an optional
+	// script and region, such as "en_us" or "english"
+	InvalidCodes []string `json:"invalidCodes"`
+	// Duplicates are languages declared more than once
+	Duplicates []string `json:"duplicates"`
+	// HasSelfReference is set when one alternate points to the page itself
+	HasSelfReference bool `json:"hasSelfReference"`
+	HasXDefault      bool `json:"hasXDefault"`
+	// Broken are alternate URLs that fail to load
+	Broken []string `json:"broken"`
+}
+
+// HreflangLink is one <link rel="alternate" hreflang="..."> declaration
+type HreflangLink struct {
+	Lang string `json:"lang"`
+	URL  string `json:"url"` // absolute
+}
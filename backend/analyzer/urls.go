@@ -0,0 +1,149 @@
+package analyzer
+
+import (
+	"net/url"
+	"strings"
+)
+
+// sameURL compares two absolute URLs ignoring scheme/host case and
+// fragments; an empty path is the same as "/"
+func sameURL(a, b string) bool {
+	return normalizeURL(a) == normalizeURL(b)
+}
+
+// normalizeURL returns a comparable form of an absolute URL: lowercase
+// scheme and host, "/" for an empty path and no fragment. Unparseable URLs
+// are returned unchanged.
+func normalizeURL(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return raw
+	}
+	normalized := strings.ToLower(u.Scheme) + "://" + strings.ToLower(u.Host) + pathOrRoot(u)
+	if u.RawQuery != "" {
+		normalized += "?" + u.RawQuery
+	}
+	return normalized
+}
+
+// resolveLink resolves href against the page URL base. It reports false for unparseable links and for schemes other
+// than http(s), such as mailto: or javascript:.
+func resolveLink(base, href string) (string, bool) {
+	baseURL, err := url.Parse(base)
+	if err != nil {
+		return "", false
+	}
+	ref, err := url.Parse(strings.TrimSpace(href))
+	if err != nil {
+		return "", false
+	}
+	resolved := baseURL.ResolveReference(ref)
+	if resolved.Scheme != "http" && resolved.Scheme != "https" {
+		return "", false
+	}
+	return resolved.String(), true
+}
+
+// sameSite reports whether two absolute http(s) URLs are on the same site:
+// their hosts match ignoring case, a "www." prefix, the scheme and default
+// ports
+func sameSite(a, b string) bool {
+	keyA, okA := siteKey(a)
+	keyB, okB := siteKey(b)
+	return okA && okB && keyA == keyB
+}
+
+// siteKey returns the normalized host of an absolute http(s) URL, with its
+// port only when it isn't 80 or 443
+func siteKey(raw string) (string, bool) {
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Hostname() == "" {
+		return "", false
+	}
+	host := strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+	if port := u.Port(); port != "" && port != "80" && port != "443" {
+		host += ":" + port
+	}
+	return host, true
+}
+
+// pathOrRoot returns the escaped path of u, or "/" when it is empty
+func pathOrRoot(u *url.URL) string {
+	if p := u.EscapedPath(); p != "" {
+		return p
+	}
+	return "/"
+}
+
+// URL hygiene thresholds
+const (
+	maxURLLength    = 100
+	longURLLength   = 75
+	maxURLPathDepth = 4
+	maxQueryLength  = 50
+)
+
+// urlStopWords are filler words that add length to a slug without
+// carrying meaning
+var urlStopWords = map[string]bool{
+	"a": true, "an": true, "and": true, "are": true, "as": true, "at": true,
+	"by": true, "for": true, "from": true, "in": true, "is": true, "of": true,
+	"on": true, "or": true, "the": true, "to": true, "with": true,
+}
+
+// analyzeURLString scores the hygiene of the URL itself; no fetch needed
+func analyzeURLString(raw string) URLAnalysis {
+	result := URLAnalysis{Length: len(raw), StopWords: []string{}}
+
+	u, err := url.Parse(raw)
+	if err != nil {
+		return result
+	}
+
+	for _, segment := range strings.Split(strings.Trim(u.Path, "/"), "/") {
+		if segment == "" {
+			continue
+		}
+		result.Depth++
+		for _, word := range strings.FieldsFunc(strings.ToLower(segment), func(r rune) bool {
+			return r == '-' || r == '_' || r == '.'
+		}) {
+			if urlStopWords[word] {
+				result.StopWords = append(result.StopWords, word)
+			}
+		}
+	}
+	result.HasUnderscores = strings.Contains(u.Path, "_")
+	result.HasUppercase = u.Path != strings.ToLower(u.Path)
+	result.QueryLength = len(u.RawQuery)
+	result.QueryParams = len(u.Query())
+
+	score := 100
+	switch {
+	case result.Length > maxURLLength:
+		score -= 25
+	case result.Length > longURLLength:
+		score -= 10
+	}
+	if result.Depth > maxURLPathDepth {
+		score -= 15
+	}
+	if result.HasUnderscores {
+		score -= 15
+	}
+	if result.HasUppercase {
+		score -= 10
+	}
+	if result.QueryParams > 0 {
+		score -= 10
+		if result.QueryLength > maxQueryLength {
+			score -= 10
+		}
+	}
+	if len(result.StopWords) > 0 {
+		score -= 5
+	}
+	result.Score = score
+
+	return result
+}
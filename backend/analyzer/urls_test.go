@@ -0,0 +1,145 @@
+package analyzer
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestAnalyzeURLString(t *testing.T) {
+	tests := []struct {
+		name      string
+		url       string
+		want      URLAnalysis
+		wantScore int
+	}{
+		{
+			name:      "clean",
+			url:       "https://example.com/blog/seo-tips",
+			want:      URLAnalysis{Depth: 2, StopWords: []string{}},
+			wantScore: 100,
+		},
+		{
+			name:      "underscores and uppercase",
+			url:       "https://example.com/Blog/SEO_Tips",
+			want:      URLAnalysis{Depth: 2, HasUnderscores: true, HasUppercase: true, StopWords: []string{}},
+			wantScore: 75,
+		},
+		{
+			name:      "query string",
+			url:       "https://example.com/products?id=42&sort=price",
+			want:      URLAnalysis{Depth: 1, QueryLength: 16, QueryParams: 2, StopWords: []string{}},
+			wantScore: 90,
+		},
+		{
+			name:      "deep with stop words",
+			url:       "https://example.com/w/x/y/z/the-best-of-the-web",
+			want:      URLAnalysis{Depth: 5, StopWords: []string{"the", "of", "the"}},
+			wantScore: 80,
+		},
+		{
+			name: "very long",
+			url:  "https://example.com/" + strings.Repeat("x", 100),
+			want: URLAnalysis{Depth: 1, StopWords: []string{}},
+			// over 100 characters
+			wantScore: 75,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := analyzeURLString(tt.url)
+			if got.Score != tt.wantScore {
+				t.Errorf("Expected score %d, got %d", tt.wantScore, got.Score)
+			}
+			tt.want.Length = len(tt.url)
+			tt.want.Score = got.Score
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Expected %+v, got %+v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestURLRecommendations(t *testing.T) {
+	server := newTestSite(t)
+	analyzer := newTestAnalyzer(t)
+
+	analysis, err := analyzer.Analyze(server.URL + "/My_Page?ref=home")
+	if err != nil {
+		t.Fatalf("Failed to analyze URL: %v", err)
+	}
+	for _, want := range []string{"prefer hyphens", "uppercase letters", "query parameters"} {
+		found := false
+		for _, rec := range analysis.Recommendations {
+			if strings.Contains(rec, want) {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("Expected a recommendation containing %q, got %v", want, analysis.Recommendations)
+		}
+	}
+}
+
+func TestIsInternalHref(t *testing.T) {
+	const base = "https://example.com/blog/post"
+	tests := []struct {
+		href string
+		want bool
+	}{
+		{"/about", true},
+		{"https://example.com/pricing", true},
+		{"http://example.com/pricing", true},
+		{"https://www.example.com/pricing", true},
+		{"https://EXAMPLE.com:443/", true},
+		{"//example.com/img.png", true},
+		{"//www.example.com/", true},
+		{"other-post", true},
+		{"https://example.com.evil.test/", false},
+		{"https://blog.example.com/", false},
+		{"https://example.com:8443/", false},
+		{"//cdn.example.net/lib.js", false},
+		{"mailto:team@example.com", false},
+	}
+	for _, tt := range tests {
+		if got := isInternalHref(base, tt.href); got != tt.want {
+			t.Errorf("isInternalHref(%q) = %v, want %v", tt.href, got, tt.want)
+		}
+	}
+
+	// A www base treats the apex domain as the same site
+	if !isInternalHref("http://www.example.com/", "https://example.com/contact") {
+		t.Error("Expected the apex domain to be internal to a www base URL")
+	}
+}
+
+func TestResolveLink(t *testing.T) {
+	const base = "https://site.com/blog/post?ref=home"
+	tests := []struct {
+		href string
+		want string
+	}{
+		{"/about", "https://site.com/about"},
+		{"next", "https://site.com/blog/next"},
+		{"./next", "https://site.com/blog/next"},
+		{"../page.html", "https://site.com/page.html"},
+		{"../../../page.html", "https://site.com/page.html"},
+		{"?page=2", "https://site.com/blog/post?page=2"},
+		{"//cdn.site.com/a.js", "https://cdn.site.com/a.js"},
+		{"http://other.com/x", "http://other.com/x"},
+		{" /spaced ", "https://site.com/spaced"},
+	}
+	for _, tt := range tests {
+		got, ok := resolveLink(base, tt.href)
+		if !ok || got != tt.want {
+			t.Errorf("resolveLink(%q) = %q, %v; want %q", tt.href, got, ok, tt.want)
+		}
+	}
+
+	for _, href := range []string{"mailto:team@site.com", "javascript:void(0)", "tel:+123", "http://[::1"} {
+		if got, ok := resolveLink(base, href); ok {
+			t.Errorf("Expected %q to be skipped, got %q", href, got)
+		}
+	}
+}
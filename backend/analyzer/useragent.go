@@ -0,0 +1,47 @@
+package analyzer
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// defaultLinkUserAgent is sent by link checks unless the analysis
+// overrides the User-Agent
+const defaultLinkUserAgent = "SEOAnalyzer/1.0"
+
+// maxUserAgentLength bounds the userAgent option
+const maxUserAgentLength = 512
+
+// userAgentKey is the context key of a custom User-Agent for the requests
+// made on behalf of one analysis
+type userAgentKey struct{}
+
+// withUserAgent returns a context whose link checks send userAgent; an
+// empty userAgent leaves ctx unchanged
+func withUserAgent(ctx context.Context, userAgent string) context.Context {
+	if userAgent == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, userAgentKey{}, userAgent)
+}
+
+// linkUserAgent returns the User-Agent link checks made under ctx send
+func linkUserAgent(ctx context.Context) string {
+	if userAgent, ok := ctx.Value(userAgentKey{}).(string); ok {
+		return userAgent
+	}
+	return defaultLinkUserAgent
+}
+
+// validateUserAgent rejects User-Agents that are too long or could not be
+// sent as a header value
+func validateUserAgent(userAgent string) error {
+	if len(userAgent) > maxUserAgentLength {
+		return fmt.Errorf("user agent is longer than %d characters", maxUserAgentLength)
+	}
+	if strings.ContainsAny(userAgent, "\r\n\x00") {
+		return fmt.Errorf("user agent contains control characters")
+	}
+	return nil
+}
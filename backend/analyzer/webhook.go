@@ -0,0 +1,159 @@
+package analyzer
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"syscall"
+	"time"
+
+	"github.com/seo-optimizer/backend/logging"
+)
+
+// SignatureHeader carries the hex HMAC-SHA256 of a callback body, keyed with
+// the webhook secret, as "sha256=<hex>"
+const SignatureHeader = "X-Signature-256"
+
+// defaultCallbackDelays are the waits before the second and third delivery
+// attempts of a job callback
+var defaultCallbackDelays = []time.Duration{time.Second, 4 * time.Second}
+
+// ErrPrivateAddress is returned for callback URLs that resolve to loopback,
+// private or otherwise internal addresses
+var ErrPrivateAddress = errors.New("address is not publicly routable")
+
+// callbackIPAllowed reports whether callbacks may connect to ip; tests
+// replace it to reach local servers
+var callbackIPAllowed = isPublicIP
+
+// isPublicIP reports whether ip is a globally routable unicast address
+func isPublicIP(ip net.IP) bool {
+	return !(ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() || ip.IsMulticast() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast())
+}
+
+// validateCallbackURL checks that rawURL is an absolute http(s) URL whose
+// host resolves only to public addresses
+func validateCallbackURL(ctx context.Context, rawURL string) error {
+	parsed, err := url.Parse(rawURL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Hostname() == "" {
+		return fmt.Errorf("callback URL must be an absolute http(s) URL")
+	}
+	if ip := net.ParseIP(parsed.Hostname()); ip != nil {
+		if !callbackIPAllowed(ip) {
+			return fmt.Errorf("callback URL: %w", ErrPrivateAddress)
+		}
+		return nil
+	}
+
+	addrs, err := net.DefaultResolver.LookupIPAddr(ctx, parsed.Hostname())
+	if err != nil {
+		return fmt.Errorf("callback URL host does not resolve: %w", err)
+	}
+	for _, addr := range addrs {
+		if !callbackIPAllowed(addr.IP) {
+			return fmt.Errorf("callback URL: %w", ErrPrivateAddress)
+		}
+	}
+	return nil
+}
+
+// newCallbackClient returns a client that refuses to connect to internal
+// addresses at dial time, so DNS changes and redirects can't reach them
+func newCallbackClient() *http.Client {
+	dialer := &net.Dialer{
+		Timeout: 5 * time.Second,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if ip := net.ParseIP(host); ip == nil || !callbackIPAllowed(ip) {
+				return fmt.Errorf("callback to %s: %w", host, ErrPrivateAddress)
+			}
+			return nil
+		},
+	}
+	return &http.Client{
+		Timeout:   10 * time.Second,
+		Transport: &http.Transport{DialContext: dialer.DialContext},
+	}
+}
+
+// SetWebhookSecret sets the key job callbacks are signed with; empty
+// disables signing
+func (a *Analyzer) SetWebhookSecret(secret string) {
+	a.configMutex.Lock()
+	defer a.configMutex.Unlock()
+	a.webhookSecret = secret
+}
+
+// signPayload returns the SignatureHeader value for body
+func signPayload(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// deliverCallback POSTs the finished job to its callback URL, retrying
+// network errors, 429 and 5xx responses with backoff. It reports whether a
+// delivery attempt succeeded.
+func (a *Analyzer) deliverCallback(callbackURL string, job Job) bool {
+	body, err := json.Marshal(job)
+	if err != nil {
+		logging.Error("Failed to encode job callback", "id", job.ID, "error", err)
+		return false
+	}
+
+	a.configMutex.RLock()
+	secret := a.webhookSecret
+	delays := a.callbackDelays
+	a.configMutex.RUnlock()
+
+	for attempt := 0; ; attempt++ {
+		retry, err := a.postCallback(callbackURL, job.ID, body, secret)
+		if err == nil {
+			logging.Debug("Delivered job callback", "id", job.ID, "attempt", attempt+1)
+			return true
+		}
+		if !retry || attempt >= len(delays) {
+			logging.Warn("Job callback failed", "id", job.ID, "url", callbackURL, "attempts", attempt+1, "error", err)
+			return false
+		}
+		time.Sleep(delays[attempt])
+	}
+}
+
+// postCallback makes one delivery attempt, reporting whether a failure is
+// worth retrying
+func (a *Analyzer) postCallback(callbackURL, jobID string, body []byte, secret string) (bool, error) {
+	req, err := http.NewRequest("POST", callbackURL, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", defaultLinkUserAgent)
+	req.Header.Set("X-Job-ID", jobID)
+	if secret != "" {
+		req.Header.Set(SignatureHeader, signPayload(secret, body))
+	}
+
+	resp, err := a.callbackClient.Do(req)
+	if err != nil {
+		return !errors.Is(err, ErrPrivateAddress), err
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return false, nil
+	}
+	retry := resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+	return retry, fmt.Errorf("callback returned status %d", resp.StatusCode)
+}
@@ -0,0 +1,117 @@
+package analyzer
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+// allowLocalCallbacks lets callbacks reach httptest servers for one test
+func allowLocalCallbacks(t *testing.T) {
+	t.Helper()
+	callbackIPAllowed = func(net.IP) bool { return true }
+	t.Cleanup(func() { callbackIPAllowed = isPublicIP })
+}
+
+func TestValidateCallbackURL(t *testing.T) {
+	for _, rawURL := range []string{
+		"http://127.0.0.1/hook",
+		"http://10.1.2.3/hook",
+		"http://169.254.169.254/latest/meta-data",
+		"http://[::1]:8080/hook",
+		"http://localhost/hook",
+	} {
+		if err := validateCallbackURL(context.Background(), rawURL); !errors.Is(err, ErrPrivateAddress) {
+			t.Errorf("Expected %s to be rejected as internal, got %v", rawURL, err)
+		}
+	}
+	if err := validateCallbackURL(context.Background(), "ftp://example.com/hook"); err == nil {
+		t.Error("Expected a non-http scheme to be rejected")
+	}
+	if err := validateCallbackURL(context.Background(), "https://93.184.216.34/hook"); err != nil {
+		t.Errorf("Expected a public address to be accepted, got %v", err)
+	}
+}
+
+func TestJobCallback(t *testing.T) {
+	allowLocalCallbacks(t)
+	site := newSiteServer(t, map[string]string{"/": `<html><head><title>Home</title></head></html>`})
+
+	var mu sync.Mutex
+	var attempts int
+	var body []byte
+	var signature string
+	delivered := make(chan struct{})
+	hook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		attempts++
+		if attempts == 1 {
+			// Temporarily unavailable: the delivery is retried
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		body, _ = io.ReadAll(r.Body)
+		signature = r.Header.Get(SignatureHeader)
+		close(delivered)
+	}))
+	defer hook.Close()
+
+	analyzer := newTestAnalyzer(t)
+	analyzer.SetWebhookSecret("s3cret")
+	analyzer.callbackDelays = []time.Duration{time.Millisecond}
+
+	job, err := analyzer.SubmitJob(JobRequest{URL: site.URL + "/", CallbackURL: hook.URL})
+	if err != nil {
+		t.Fatalf("Failed to submit job: %v", err)
+	}
+	if job.Callback != "pending" {
+		t.Errorf("Expected the callback to be pending, got %q", job.Callback)
+	}
+	select {
+	case <-delivered:
+	case <-time.After(10 * time.Second):
+		t.Fatal("Timed out waiting for the callback")
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if attempts != 2 {
+		t.Errorf("Expected 2 delivery attempts, got %d", attempts)
+	}
+	if want := signPayload("s3cret", body); signature != want {
+		t.Errorf("Expected signature %s, got %s", want, signature)
+	}
+	var payload struct {
+		ID     string    `json:"id"`
+		Status JobStatus `json:"status"`
+		Result struct {
+			URL string `json:"url"`
+		} `json:"result"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		t.Fatalf("Failed to parse callback body: %v", err)
+	}
+	if payload.ID != job.ID || payload.Status != JobDone || payload.Result.URL == "" {
+		t.Errorf("Expected the finished job in the callback, got %+v", payload)
+	}
+}
+
+func TestCallbackClientRefusesInternalAddresses(t *testing.T) {
+	hook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer hook.Close()
+
+	// Even if validation is bypassed (DNS rebinding), the dialer refuses
+	analyzer := newTestAnalyzer(t)
+	retry, err := analyzer.postCallback(hook.URL, "job", []byte("{}"), "")
+	if !errors.Is(err, ErrPrivateAddress) || retry {
+		t.Errorf("Expected a non-retried ErrPrivateAddress, got retry=%v err=%v", retry, err)
+	}
+}
@@ -0,0 +1,103 @@
+package analyzer
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// defaultScoreWeights is how much each section contributes to the overall
+// score
+var defaultScoreWeights = map[string]float64{
+	"title":       0.2,
+	"meta":        0.2,
+	"headers":     0.15,
+	"content":     0.2,
+	"performance": 0.15,
+	"links":       0.1,
+}
+
+// validateWeights checks that weight overrides name known sections and are
+// not negative
+func validateWeights(weights map[string]float64) error {
+	for section, weight := range weights {
+		if _, ok := defaultScoreWeights[section]; !ok {
+			return fmt.Errorf("unknown score weight %q", section)
+		}
+		if weight < 0 {
+			return fmt.Errorf("score weight %q must not be negative", section)
+		}
+	}
+	return nil
+}
+
+// weightSumTolerance is how far configured default weights may sum from 1
+const weightSumTolerance = 0.01
+
+// SetScoreWeights replaces the default section weights of the overall
+// score. Sections left out keep their built-in weight; the resulting weights
+// must sum to 1 (within 0.01). Cached analyses are dropped since their scores
+// used the old weights.
+func (a *Analyzer) SetScoreWeights(weights map[string]float64) error {
+	if err := validateWeights(weights); err != nil {
+		return err
+	}
+	merged := mergeWeights(defaultScoreWeights, weights)
+	total := 0.0
+	for _, weight := range merged {
+		total += weight
+	}
+	if math.Abs(total-1) > weightSumTolerance {
+		return fmt.Errorf("score weights must sum to 1, got %g", total)
+	}
+
+	a.configMutex.Lock()
+	a.scoreWeights = merged
+	a.configMutex.Unlock()
+	a.ClearCache()
+	return nil
+}
+
+// ParseScoreWeights parses weights written as "title=0.3,meta=0.1"
+func ParseScoreWeights(s string) (map[string]float64, error) {
+	weights := make(map[string]float64)
+	for _, pair := range strings.Split(s, ",") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+		section, value, ok := strings.Cut(pair, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid score weight %q, expected section=weight", pair)
+		}
+		weight, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid score weight %q: %w", pair, err)
+		}
+		weights[strings.ToLower(strings.TrimSpace(section))] = weight
+	}
+	return weights, nil
+}
+
+// mergeWeights returns base with the sections in overrides replaced
+func mergeWeights(base, overrides map[string]float64) map[string]float64 {
+	merged := make(map[string]float64, len(base))
+	for section, weight := range base {
+		merged[section] = weight
+	}
+	for section, weight := range overrides {
+		merged[section] = weight
+	}
+	return merged
+}
+
+// weightsKey is a stable string form of weight overrides for cache keys
+func weightsKey(weights map[string]float64) string {
+	pairs := make([]string, 0, len(weights))
+	for section, weight := range weights {
+		pairs = append(pairs, section+"="+strconv.FormatFloat(weight, 'g', -1, 64))
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
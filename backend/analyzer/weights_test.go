@@ -0,0 +1,63 @@
+package analyzer
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSetScoreWeights(t *testing.T) {
+	server := newSiteServer(t, map[string]string{
+		"/": `<html><head><title>Short</title></head><body><h1>Heading</h1></body></html>`,
+	})
+	analyzer := newTestAnalyzer(t)
+
+	before, err := analyzer.Analyze(server.URL + "/")
+	if err != nil {
+		t.Fatalf("Failed to analyze URL: %v", err)
+	}
+
+	for _, tc := range []struct {
+		weights map[string]float64
+		want    string
+	}{
+		{map[string]float64{"bogus": 0.2}, "unknown score weight"},
+		{map[string]float64{"title": -0.2, "meta": 0.6}, "must not be negative"},
+		{map[string]float64{"title": 0.5}, "must sum to 1"},
+	} {
+		if err := analyzer.SetScoreWeights(tc.weights); err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("SetScoreWeights(%v) = %v, want an error containing %q", tc.weights, err, tc.want)
+		}
+	}
+
+	// Move all weight to the headers section
+	headersOnly := map[string]float64{"title": 0, "meta": 0, "headers": 1, "content": 0, "performance": 0, "links": 0}
+	if err := analyzer.SetScoreWeights(headersOnly); err != nil {
+		t.Fatalf("Failed to set weights: %v", err)
+	}
+	after, err := analyzer.Analyze(server.URL + "/")
+	if err != nil {
+		t.Fatalf("Failed to analyze URL: %v", err)
+	}
+	if after == before {
+		t.Fatal("Expected cached analyses to be dropped when the weights change")
+	}
+	if after.Score != float64(after.Headers.Score) {
+		t.Errorf("Expected the score to equal the headers score %d, got %v", after.Headers.Score, after.Score)
+	}
+
+	// Request overrides still merge over the configured weights
+	overridden, err := analyzer.AnalyzeWithOptions(server.URL+"/", AnalyzeOptions{Weights: map[string]float64{"headers": 0, "title": 1}})
+	if err != nil {
+		t.Fatalf("Failed to analyze URL: %v", err)
+	}
+	if overridden.Score != float64(overridden.Title.Score) {
+		t.Errorf("Expected the score to equal the title score %d, got %v", overridden.Title.Score, overridden.Score)
+	}
+
+	if weights, err := ParseScoreWeights("title=0.3, Meta=0.1"); err != nil || weights["title"] != 0.3 || weights["meta"] != 0.1 {
+		t.Errorf("Unexpected parse result %v (%v)", weights, err)
+	}
+	if _, err := ParseScoreWeights("title"); err == nil {
+		t.Error("Expected an error for a weight without a value")
+	}
+}
@@ -0,0 +1,128 @@
+package geoip
+
+import (
+	"bytes"
+	"encoding/binary"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// Minimal encoders for the data section format
+func encString(s string) []byte { return append([]byte{2<<5 | byte(len(s))}, s...) }
+
+func encUint(kind byte, size int, v uint32) []byte {
+	b := make([]byte, 4)
+	binary.BigEndian.PutUint32(b, v)
+	return append([]byte{kind<<5 | byte(size)}, b[4-size:]...)
+}
+
+func encMap(pairs ...[]byte) []byte {
+	out := []byte{7<<5 | byte(len(pairs)/2)}
+	for _, p := range pairs {
+		out = append(out, p...)
+	}
+	return out
+}
+
+func countryRecord(key, code string) []byte {
+	return encMap(encString(key), encMap(encString("iso_code"), encString(code)))
+}
+
+// buildDB assembles a MaxMind DB from its search tree nodes and data section
+func buildDB(recordSize, ipVersion int, nodes [][2]uint, data []byte) []byte {
+	var buf bytes.Buffer
+	for _, n := range nodes {
+		l, r := n[0], n[1]
+		switch recordSize {
+		case 24:
+			buf.Write([]byte{byte(l >> 16), byte(l >> 8), byte(l), byte(r >> 16), byte(r >> 8), byte(r)})
+		case 28:
+			buf.Write([]byte{byte(l >> 16), byte(l >> 8), byte(l),
+				byte(l>>24)<<4 | byte(r>>24)&0x0F, byte(r >> 16), byte(r >> 8), byte(r)})
+		}
+	}
+	buf.Write(make([]byte, dataSectionSeparator))
+	buf.Write(data)
+	buf.Write(metadataMarker)
+	buf.Write(encMap(
+		encString("node_count"), encUint(6, 4, uint32(len(nodes))),
+		encString("record_size"), encUint(5, 2, uint32(recordSize)),
+		encString("ip_version"), encUint(5, 2, uint32(ipVersion)),
+	))
+	return buf.Bytes()
+}
+
+func TestCountryIPv4(t *testing.T) {
+	us := countryRecord("country", "US")
+	de := countryRecord("registered_country", "DE")
+	data := append(append([]byte{}, us...), de...)
+	// 0.0.0.0/2 is US, 64.0.0.0/2 is DE, 128.0.0.0/1 is unknown
+	const nodeCount = 2
+	pointer := func(offset int) uint { return uint(nodeCount + dataSectionSeparator + offset) }
+	db := buildDB(24, 4, [][2]uint{{1, nodeCount}, {pointer(0), pointer(len(us))}}, data)
+
+	path := filepath.Join(t.TempDir(), "test.mmdb")
+	if err := os.WriteFile(path, db, 0644); err != nil {
+		t.Fatal(err)
+	}
+	r, err := Open(path)
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	for ip, want := range map[string]string{
+		"8.8.8.8":     "US",
+		"93.184.0.1":  "DE",
+		"203.0.113.1": "",
+		"2001:db8::1": "",
+	} {
+		if got := r.Country(net.ParseIP(ip)); got != want {
+			t.Errorf("Country(%s) = %q, want %q", ip, got, want)
+		}
+	}
+}
+
+func TestCountryIPv6(t *testing.T) {
+	// Nodes 0-95 lead to the IPv4 subtree at ::/96, whose node 96 sends
+	// 0.0.0.0/1 to FR through a data pointer; everything else is unknown
+	const nodeCount = 97
+	fr := countryRecord("country", "FR")
+	// The inner {"iso_code": "FR"} map starts after the map and key bytes
+	viaPointer := encMap(encString("country"), []byte{1 << 5, byte(1 + len(encString("country")))})
+	data := append(append([]byte{}, fr...), viaPointer...)
+	nodes := make([][2]uint, nodeCount)
+	for i := 0; i < 96; i++ {
+		nodes[i] = [2]uint{uint(i + 1), nodeCount}
+	}
+	nodes[96] = [2]uint{uint(nodeCount + dataSectionSeparator + len(fr)), nodeCount}
+
+	r, err := New(buildDB(28, 6, nodes, data))
+	if err != nil {
+		t.Fatalf("Failed to parse database: %v", err)
+	}
+	for ip, want := range map[string]string{
+		"10.1.2.3":    "FR",
+		"192.0.2.1":   "",
+		"2001:db8::1": "",
+	} {
+		if got := r.Country(net.ParseIP(ip)); got != want {
+			t.Errorf("Country(%s) = %q, want %q", ip, got, want)
+		}
+	}
+}
+
+func TestNewRejectsInvalidDatabases(t *testing.T) {
+	if _, err := New([]byte("not a database")); err == nil {
+		t.Error("Expected an error for data without metadata")
+	}
+	if _, err := New(buildDB(24, 5, nil, nil)); err == nil {
+		t.Error("Expected an error for an unsupported IP version")
+	}
+	// The metadata of 100 nodes after a one node tree
+	full := buildDB(24, 4, make([][2]uint, 100), nil)
+	truncated := append(make([]byte, 6+dataSectionSeparator), full[600+dataSectionSeparator:]...)
+	if _, err := New(truncated); err == nil {
+		t.Error("Expected an error for a tree larger than the file")
+	}
+}
@@ -0,0 +1,88 @@
+package logging
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"sync/atomic"
+)
+
+// Log levels, from most to least verbose
+const (
+	LevelDebug = slog.LevelDebug
+	LevelInfo  = slog.LevelInfo
+	LevelWarn  = slog.LevelWarn
+	LevelError = slog.LevelError
+)
+
+// level is the minimum level written; INFO unless LOG_LEVEL says otherwise
+var level = new(slog.LevelVar)
+
+// logger writes JSON lines with "ts", "level" and "msg" plus the fields
+// passed as key-value pairs
+var logger atomic.Pointer[slog.Logger]
+
+func init() {
+	SetOutput(os.Stderr)
+}
+
+// SetOutput sends log lines to w
+func SetOutput(w io.Writer) {
+	logger.Store(slog.New(slog.NewJSONHandler(w, &slog.HandlerOptions{
+		Level: level,
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if len(groups) == 0 && a.Key == slog.TimeKey {
+				a.Key = "ts"
+			}
+			return a
+		},
+	})))
+}
+
+// SetLevel sets the minimum level that is written
+func SetLevel(l slog.Level) {
+	level.Set(l)
+}
+
+// ParseLevel parses a LOG_LEVEL value: debug, info, warn or error
+func ParseLevel(s string) (slog.Level, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "debug":
+		return LevelDebug, nil
+	case "info":
+		return LevelInfo, nil
+	case "warn", "warning":
+		return LevelWarn, nil
+	case "error":
+		return LevelError, nil
+	}
+	return LevelInfo, fmt.Errorf("unknown log level %q", s)
+}
+
+// DebugEnabled reports whether DEBUG lines are written, so callers can skip
+// building expensive fields
+func DebugEnabled() bool {
+	return level.Level() <= LevelDebug
+}
+
+// Debug logs msg with key-value fields at DEBUG level
+func Debug(msg string, fields ...any) {
+	logger.Load().Debug(msg, fields...)
+}
+
+// Info logs msg with key-value fields at INFO level
+func Info(msg string, fields ...any) {
+	logger.Load().Info(msg, fields...)
+}
+
+// Warn logs msg with key-value fields at WARN level
+func Warn(msg string, fields ...any) {
+	logger.Load().Warn(msg, fields...)
+}
+
+// Error logs msg with key-value fields at ERROR level
+func Error(msg string, fields ...any) {
+	logger.Load().Error(msg, fields...)
+}
@@ -0,0 +1,49 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestLeveledJSONLogging(t *testing.T) {
+	var buf bytes.Buffer
+	SetOutput(&buf)
+	defer SetOutput(os.Stderr)
+	defer SetLevel(LevelInfo)
+
+	level, err := ParseLevel("WARN")
+	if err != nil {
+		t.Fatalf("Failed to parse level: %v", err)
+	}
+	SetLevel(level)
+
+	Debug("hidden debug")
+	Info("hidden info")
+	Warn("disk almost full", "path", "/data", "freeBytes", 1024)
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 1 {
+		t.Fatalf("Expected only the WARN line, got %q", buf.String())
+	}
+	var entry map[string]interface{}
+	if err := json.Unmarshal([]byte(lines[0]), &entry); err != nil {
+		t.Fatalf("Expected a JSON line, got %q", lines[0])
+	}
+	if entry["level"] != "WARN" || entry["msg"] != "disk almost full" || entry["path"] != "/data" || entry["freeBytes"] != float64(1024) {
+		t.Errorf("Unexpected log entry %v", entry)
+	}
+	if _, ok := entry["ts"]; !ok {
+		t.Errorf("Expected a ts field, got %v", entry)
+	}
+
+	SetLevel(LevelDebug)
+	if !DebugEnabled() {
+		t.Error("Expected DEBUG to be enabled")
+	}
+	if _, err := ParseLevel("verbose"); err == nil {
+		t.Error("Expected an error for an unknown level")
+	}
+}
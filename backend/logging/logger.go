@@ -3,10 +3,10 @@ package logging
 import (
 	"encoding/json"
 	"fmt"
-	"log"
 	"net/url"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 	"sync"
 	"time"
@@ -39,7 +39,7 @@ var (
 // Initialize creates or loads the statistics
 func Initialize() *Statistics {
 	once.Do(func() {
-		log.Println("Initializing statistics...")
+		Debug("Initializing statistics")
 		stats = &Statistics{
 			UniqueVisitors:   make(map[string]time.Time),
 			PopularURLs:      make(map[string]int),
@@ -48,12 +48,12 @@ func Initialize() *Statistics {
 		
 		// Try to load existing statistics
 		if err := stats.Load(); err != nil {
-			log.Printf("Could not load existing statistics: %v\n", err)
+			Warn("Could not load existing statistics", "error", err)
 		}
 
 		// Log current mode and statistics state
-		log.Printf("DEV_MODE: %s\n", os.Getenv(ENV_DEV_MODE))
-		log.Printf("Initial stats state: %+v\n", stats.GetStatistics())
+		Debug("Statistics mode", "devMode", os.Getenv(ENV_DEV_MODE))
+		Debug("Initial stats state", "stats", stats.GetStatistics())
 	})
 	return stats
 }
@@ -104,7 +104,7 @@ func (s *Statistics) TrackAnalysis(url string, loadTime float64, hasError bool)
 	// Only track non-empty URLs (those that passed our filtering)
 	if cleanedURL != "" {
 		s.PopularURLs[cleanedURL]++
-		log.Printf("Tracked URL: %s (Count: %d)\n", cleanedURL, s.PopularURLs[cleanedURL])
+		Debug("Tracked URL", "url", RedactURL(cleanedURL), "count", s.PopularURLs[cleanedURL])
 	}
 	
 	if hasError {
@@ -119,7 +119,7 @@ func (s *Statistics) TrackAnalysis(url string, loadTime float64, hasError bool)
 	// Save statistics periodically
 	if s.AnalysisRequests%10 == 0 { // Save every 10 requests
 		if err := s.Save(); err != nil {
-			log.Printf("Error saving statistics: %v\n", err)
+			Error("Failed to save statistics", "error", err)
 		}
 	}
 }
@@ -146,15 +146,21 @@ func (s *Statistics) GetPopularURLs(n int) map[string]int {
 	s.mutex.RLock()
 	defer s.mutex.RUnlock()
 	
-	result := make(map[string]int)
-	count := 0
-	
-	// Simple implementation - for production, use a heap or sorted data structure
-	for url, freq := range s.PopularURLs {
-		if count < n {
-			result[url] = freq
-			count++
+	// Most analyzed first, ties in URL order so the cut is stable
+	urls := make([]string, 0, len(s.PopularURLs))
+	for url := range s.PopularURLs {
+		urls = append(urls, url)
+	}
+	sort.Slice(urls, func(i, j int) bool {
+		if s.PopularURLs[urls[i]] != s.PopularURLs[urls[j]] {
+			return s.PopularURLs[urls[i]] > s.PopularURLs[urls[j]]
 		}
+		return urls[i] < urls[j]
+	})
+	
+	result := make(map[string]int)
+	for i := 0; i < n && i < len(urls); i++ {
+		result[urls[i]] = s.PopularURLs[urls[i]]
 	}
 	
 	return result
@@ -240,12 +246,12 @@ func (s *Statistics) GetStatistics() map[string]interface{} {
 
 	// Add popular URLs in development mode
 	if os.Getenv(ENV_DEV_MODE) == "true" {
-		log.Println("Development mode: Including popular URLs in statistics")
+		Debug("Development mode: including popular URLs in statistics")
 		popularURLs := s.GetPopularURLs(5)
 		if len(popularURLs) > 0 {
 			stats["popularUrls"] = popularURLs
 		} else {
-			log.Println("No popular URLs available")
+			Debug("No popular URLs available")
 		}
 	}
 
@@ -0,0 +1,22 @@
+package logging
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestGetPopularURLs(t *testing.T) {
+	s := &Statistics{PopularURLs: make(map[string]int)}
+	for i := 1; i <= 20; i++ {
+		s.PopularURLs[fmt.Sprintf("https://example.com/%02d", i)] = i
+	}
+
+	want := map[string]int{"https://example.com/20": 20, "https://example.com/19": 19, "https://example.com/18": 18}
+	if got := s.GetPopularURLs(3); !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected the 3 most analyzed URLs %v, got %v", want, got)
+	}
+	if got := s.GetPopularURLs(50); len(got) != 20 {
+		t.Errorf("Expected all 20 URLs, got %d", len(got))
+	}
+}
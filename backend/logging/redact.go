@@ -0,0 +1,128 @@
+package logging
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+// Redacted replaces the value of every sensitive field in logged data
+const Redacted = "[REDACTED]"
+
+// defaultSensitiveFields are always redacted. Names are compared
+// case-insensitively with '-' and '_' ignored, so "apiKey", "api_key" and
+// "X-API-Key" are covered by "apikey"/"xapikey".
+var defaultSensitiveFields = []string{
+	"password", "passwd", "secret", "clientsecret", "token", "accesstoken",
+	"refreshtoken", "apikey", "xapikey", "authorization", "proxyauthorization",
+	"cookie", "setcookie", "credentials",
+}
+
+var (
+	sensitiveMutex  sync.RWMutex
+	sensitiveFields = fieldSet(defaultSensitiveFields)
+)
+
+// normalizeField folds a field or header name to its comparable form
+func normalizeField(name string) string {
+	return strings.NewReplacer("-", "", "_", "").Replace(strings.ToLower(strings.TrimSpace(name)))
+}
+
+func fieldSet(names []string) map[string]bool {
+	set := make(map[string]bool, len(names))
+	for _, name := range names {
+		if name = normalizeField(name); name != "" {
+			set[name] = true
+		}
+	}
+	return set
+}
+
+// SetSensitiveFields adds field names to redact on top of the defaults
+func SetSensitiveFields(fields []string) {
+	set := fieldSet(append(append([]string{}, defaultSensitiveFields...), fields...))
+	sensitiveMutex.Lock()
+	defer sensitiveMutex.Unlock()
+	sensitiveFields = set
+}
+
+// IsSensitive reports whether values of the named field must not be logged
+func IsSensitive(name string) bool {
+	sensitiveMutex.RLock()
+	defer sensitiveMutex.RUnlock()
+	return sensitiveFields[normalizeField(name)]
+}
+
+// RedactHeaders returns a copy of h with sensitive header values replaced
+func RedactHeaders(h http.Header) http.Header {
+	redacted := make(http.Header, len(h))
+	for name, values := range h {
+		if IsSensitive(name) {
+			redacted[name] = []string{Redacted}
+			continue
+		}
+		redacted[name] = append([]string(nil), values...)
+	}
+	return redacted
+}
+
+// RedactURL strips user info and sensitive query parameters from a URL
+func RedactURL(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return raw
+	}
+	if u.User != nil {
+		u.User = url.User(Redacted)
+	}
+	if u.RawQuery != "" {
+		query := u.Query()
+		for name := range query {
+			if IsSensitive(name) {
+				query[name] = []string{Redacted}
+			}
+		}
+		u.RawQuery = query.Encode()
+	}
+	return u.String()
+}
+
+// RedactJSON scrubs sensitive fields at any depth of a JSON document.
+// Bodies that aren't valid JSON are replaced entirely, since there is no
+// way to tell which parts are safe to log.
+func RedactJSON(body []byte) []byte {
+	var data interface{}
+	if err := json.Unmarshal(body, &data); err != nil {
+		return []byte(Redacted)
+	}
+	redacted, err := json.Marshal(redactValue(data))
+	if err != nil {
+		return []byte(Redacted)
+	}
+	return redacted
+}
+
+func redactValue(v interface{}) interface{} {
+	switch value := v.(type) {
+	case map[string]interface{}:
+		for key, field := range value {
+			if IsSensitive(key) {
+				value[key] = Redacted
+			} else {
+				value[key] = redactValue(field)
+			}
+		}
+	case []interface{}:
+		for i, item := range value {
+			value[i] = redactValue(item)
+		}
+	case string:
+		// URLs may carry credentials in user info or the query string
+		if strings.Contains(value, "://") {
+			return RedactURL(value)
+		}
+	}
+	return v
+}
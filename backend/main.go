@@ -1,13 +1,17 @@
 package main
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"fmt"
-	"log"
+	"io"
 	"net/http"
+	"net/url"
 	"os"
 	"os/signal"
 	"strconv"
+	"strings"
 	"syscall"
 	"time"
 
@@ -15,9 +19,18 @@ import (
 	"github.com/joho/godotenv"
 
 	"github.com/seo-optimizer/backend/analyzer"
+	"github.com/seo-optimizer/backend/geoip"
+	"github.com/seo-optimizer/backend/logging"
 	"github.com/seo-optimizer/backend/middleware"
+	"github.com/seo-optimizer/backend/publisher"
+	"github.com/seo-optimizer/backend/report"
+	"github.com/seo-optimizer/backend/stats"
 )
 
+// popularURLsLimit is how many of the most analyzed URLs the statistics
+// endpoint lists
+const popularURLsLimit = 10
+
 var (
 	seoAnalyzer  *analyzer.Analyzer
 	rateLimiter  *middleware.RateLimiter
@@ -28,11 +41,25 @@ func loadEnv() {
 	if err := godotenv.Load(".env.development"); err != nil {
 		// If .env.development doesn't exist, try regular .env
 		if err := godotenv.Load(); err != nil {
-			log.Println("No .env file found, using environment variables")
+			logging.Info("No .env file found, using environment variables")
 		}
 	}
 }
 
+// setupLogLevel applies LOG_LEVEL (debug, info, warn or error; default info)
+func setupLogLevel() {
+	levelStr := os.Getenv("LOG_LEVEL")
+	if levelStr == "" {
+		return
+	}
+	level, err := logging.ParseLevel(levelStr)
+	if err != nil {
+		logging.Warn("Ignoring LOG_LEVEL", "error", err)
+		return
+	}
+	logging.SetLevel(level)
+}
+
 func setupGinMode() {
 	// Set Gin mode based on environment variable
 	mode := os.Getenv("GIN_MODE")
@@ -73,6 +100,52 @@ func securityHeaders() gin.HandlerFunc {
 	}
 }
 
+// getAllowedOrigins returns the set of origins from the comma-separated
+// CORS_ALLOWED_ORIGINS, where "*" allows any origin. Unset, it allows any
+// origin in development and only the hosted frontend in release mode.
+func getAllowedOrigins() map[string]bool {
+	list := os.Getenv("CORS_ALLOWED_ORIGINS")
+	if list == "" {
+		list = "*"
+		if os.Getenv("GIN_MODE") == "release" {
+			list = "https://seo-optimizer.elvynprise.xyz"
+		}
+	}
+
+	origins := make(map[string]bool)
+	for _, origin := range strings.Split(list, ",") {
+		if origin = strings.TrimRight(strings.TrimSpace(origin), "/"); origin != "" {
+			origins[origin] = true
+		}
+	}
+	return origins
+}
+
+// corsMiddleware echoes the request's Origin back when it is in allowed.
+// With "*" any origin may call the API, but without credentials, which
+// browsers refuse to combine with a wildcard.
+func corsMiddleware(allowed map[string]bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		header := c.Writer.Header()
+		header.Add("Vary", "Origin")
+		if origin := c.GetHeader("Origin"); allowed["*"] {
+			header.Set("Access-Control-Allow-Origin", "*")
+		} else if origin != "" && allowed[origin] {
+			header.Set("Access-Control-Allow-Origin", origin)
+			header.Set("Access-Control-Allow-Credentials", "true")
+		}
+		header.Set("Access-Control-Allow-Methods", "GET, POST, PUT, OPTIONS")
+		header.Set("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, Authorization, X-API-Key")
+		header.Set("Access-Control-Max-Age", "86400") // 24 hours
+
+		if c.Request.Method == "OPTIONS" {
+			c.AbortWithStatus(http.StatusNoContent)
+			return
+		}
+		c.Next()
+	}
+}
+
 func getRateLimitConfig() (int, int) {
 	requestsStr := os.Getenv("RATE_LIMIT_REQUESTS")
 	durationStr := os.Getenv("RATE_LIMIT_DURATION")
@@ -90,6 +163,32 @@ func getRateLimitConfig() (int, int) {
 	return requests, duration
 }
 
+// getRateLimitIdleTTL returns how long a client may be idle before the rate
+// limiter forgets it
+func getRateLimitIdleTTL() time.Duration {
+	seconds, err := strconv.Atoi(os.Getenv("RATE_LIMIT_IDLE_TTL"))
+	if err != nil || seconds <= 0 {
+		return 10 * time.Minute
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// configureIPLists applies the RATE_LIMIT_ALLOWLIST and RATE_LIMIT_DENYLIST
+// CIDR ranges (comma-separated) to the rate limiter
+func configureIPLists(rl *middleware.RateLimiter) {
+	allow, err := middleware.ParseCIDRs(os.Getenv("RATE_LIMIT_ALLOWLIST"))
+	if err != nil {
+		logging.Warn("Ignoring RATE_LIMIT_ALLOWLIST", "error", err)
+		allow = nil
+	}
+	deny, err := middleware.ParseCIDRs(os.Getenv("RATE_LIMIT_DENYLIST"))
+	if err != nil {
+		logging.Warn("Ignoring RATE_LIMIT_DENYLIST", "error", err)
+		deny = nil
+	}
+	rl.SetIPLists(allow, deny)
+}
+
 func initializeAnalyzer() (*analyzer.Analyzer, error) {
 	// Get data directory from environment variable
 	dataDir := os.Getenv("DATA_DIR")
@@ -110,14 +209,256 @@ func initializeAnalyzer() (*analyzer.Analyzer, error) {
 	}
 
 	// Log the data directory being used
-	log.Printf("Using data directory: %s", dataDir)
+	logging.Info("Using data directory", "dataDir", dataDir)
+
+	// Statistics go to stats.json unless another backend is selected
+	statsBackend, err := stats.Open(os.Getenv("STATS_BACKEND"), dataDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize stats storage: %w", err)
+	}
+
+	// Count visitors per country when a MaxMind DB is configured
+	if path := os.Getenv("GEOIP_DB_PATH"); path != "" {
+		if reader, err := geoip.Open(path); err != nil {
+			logging.Error("Visitor countries disabled", "error", err)
+		} else {
+			statsBackend.SetCountryLookup(reader)
+			logging.Info("Counting visitors per country", "database", path)
+		}
+	}
 
 	// Create analyzer instance
-	analyzerInstance, err := analyzer.New(dataDir)
+	analyzerInstance, err := analyzer.NewWithStatsBackend(dataDir, statsBackend)
 	if err != nil {
+		statsBackend.Shutdown()
 		return nil, err
 	}
 
+	// Cache fetch failures briefly (in seconds, 0 disables)
+	if ttlStr := os.Getenv("NEGATIVE_CACHE_TTL"); ttlStr != "" {
+		if ttl, err := strconv.Atoi(ttlStr); err == nil && ttl >= 0 {
+			analyzerInstance.SetNegativeCacheTTL(time.Duration(ttl) * time.Second)
+		}
+	}
+
+	// Optionally include same-origin iframe content in analyses
+	if os.Getenv("ANALYZE_IFRAMES") == "true" {
+		analyzerInstance.SetAnalyzeIframes(true)
+	}
+
+	// Start in maintenance mode (no outbound fetching) if requested
+	if os.Getenv("MAINTENANCE_MODE") == "true" {
+		analyzerInstance.SetMaintenanceMode(true)
+		logging.Warn("Maintenance mode enabled: outbound fetching is disabled")
+	}
+
+	// Optionally verify that the og:image loads
+	if os.Getenv("VERIFY_OG_IMAGE") == "true" {
+		analyzerInstance.SetVerifyOGImage(true)
+	}
+
+	// Default section weights of the overall score, e.g. "title=0.3,meta=0.1"
+	if weightsStr := os.Getenv("SCORE_WEIGHTS"); weightsStr != "" {
+		weights, err := analyzer.ParseScoreWeights(weightsStr)
+		if err == nil {
+			err = analyzerInstance.SetScoreWeights(weights)
+		}
+		if err != nil {
+			logging.Warn("Ignoring SCORE_WEIGHTS", "error", err)
+		}
+	}
+
+	// Allow analyzing pages on loopback and private addresses, either all of
+	// them or only the trusted CIDR ranges (comma-separated)
+	if os.Getenv("ALLOW_PRIVATE_TARGETS") == "true" {
+		analyzerInstance.SetAllowPrivateTargets(true)
+	}
+	if trusted := os.Getenv("TRUSTED_INTERNAL_NETWORKS"); trusted != "" {
+		nets, err := middleware.ParseCIDRs(trusted)
+		if err != nil {
+			logging.Warn("Ignoring TRUSTED_INTERNAL_NETWORKS", "error", err)
+		} else {
+			analyzerInstance.SetTrustedNetworks(nets)
+		}
+	}
+
+	// Only analyze pages on these domains (comma-separated)
+	if domains := os.Getenv("ALLOWED_DOMAINS"); domains != "" {
+		analyzerInstance.SetAllowedDomains(strings.Split(domains, ","))
+	}
+
+	// Check that declared icons and the manifest load
+	if os.Getenv("VERIFY_ICONS") == "true" {
+		analyzerInstance.SetVerifyIcons(true)
+	}
+
+	// Limit concurrent analyses (0 = unlimited) and optionally rotate free
+	// slots between clients instead of serving them first-come-first-served
+	if maxStr := os.Getenv("MAX_CONCURRENT_ANALYSES"); maxStr != "" {
+		if max, err := strconv.Atoi(maxStr); err == nil && max >= 0 {
+			analyzerInstance.SetMaxConcurrentAnalyses(max)
+		}
+	}
+	if os.Getenv("FAIR_SCHEDULING") == "true" {
+		analyzerInstance.SetFairScheduling(true)
+	}
+	if os.Getenv("QUEUE_PRIORITIES") == "true" {
+		analyzerInstance.SetQueuePriorities(true)
+	}
+
+	// Memory guards for pathological pages (0 = unlimited)
+	if nodesStr := os.Getenv("MAX_DOM_NODES"); nodesStr != "" {
+		if nodes, err := strconv.Atoi(nodesStr); err == nil && nodes >= 0 {
+			analyzerInstance.SetMaxDOMNodes(nodes)
+		}
+	}
+	if linksStr := os.Getenv("MAX_LINKS_PER_PAGE"); linksStr != "" {
+		if links, err := strconv.Atoi(linksStr); err == nil && links >= 0 {
+			analyzerInstance.SetMaxLinksPerPage(links)
+		}
+	}
+
+	// Image weight checks: size threshold in KB and images probed per page
+	if kbStr := os.Getenv("LARGE_IMAGE_KB"); kbStr != "" {
+		if kb, err := strconv.Atoi(kbStr); err == nil && kb > 0 {
+			analyzerInstance.SetLargeImageThreshold(int64(kb) << 10)
+		}
+	}
+	if probesStr := os.Getenv("MAX_IMAGE_PROBES"); probesStr != "" {
+		if probes, err := strconv.Atoi(probesStr); err == nil && probes >= 0 {
+			analyzerInstance.SetMaxImageProbes(probes)
+		}
+	}
+
+	// Bytes the quick analysis reads looking for </head> before reading
+	// the whole page
+	if headStr := os.Getenv("MAX_HEAD_BYTES"); headStr != "" {
+		if head, err := strconv.Atoi(headStr); err == nil && head > 0 {
+			analyzerInstance.SetMaxHeadBytes(head)
+		}
+	}
+
+	// Largest page body read for a full analysis
+	if pageStr := os.Getenv("MAX_PAGE_BYTES"); pageStr != "" {
+		if page, err := strconv.ParseInt(pageStr, 10, 64); err == nil && page > 0 {
+			analyzerInstance.SetMaxPageBytes(page)
+		}
+	}
+
+	// Per-host circuit breaker: fail fast after repeated failures
+	if thresholdStr := os.Getenv("CIRCUIT_BREAKER_THRESHOLD"); thresholdStr != "" {
+		if threshold, err := strconv.Atoi(thresholdStr); err == nil && threshold >= 0 {
+			cooldown := 30
+			if cooldownStr := os.Getenv("CIRCUIT_BREAKER_COOLDOWN"); cooldownStr != "" {
+				if c, err := strconv.Atoi(cooldownStr); err == nil && c > 0 {
+					cooldown = c
+				}
+			}
+			analyzerInstance.SetCircuitBreaker(threshold, time.Minute, time.Duration(cooldown)*time.Second)
+		}
+	}
+
+	// Seconds Shutdown waits for in-flight analyses before giving up
+	if timeoutStr := os.Getenv("ANALYZER_SHUTDOWN_TIMEOUT"); timeoutStr != "" {
+		if timeout, err := strconv.Atoi(timeoutStr); err == nil && timeout > 0 {
+			analyzerInstance.SetShutdownTimeout(time.Duration(timeout) * time.Second)
+		}
+	}
+
+	// Months kept in stats.json; older months move to stats-YYYY-MM.json
+	if monthsStr := os.Getenv("STATS_HOT_MONTHS"); monthsStr != "" {
+		if months, err := strconv.Atoi(monthsStr); err == nil && months > 0 {
+			if storage, ok := analyzerInstance.GetStats().(*stats.Storage); ok {
+				if err := storage.SetHotMonths(months); err != nil {
+					logging.Error("Failed to archive statistics", "error", err)
+				}
+			}
+		}
+	}
+
+	// Days of per-day statistics kept by the nightly cleanup
+	if daysStr := os.Getenv("STATS_DAILY_RETENTION"); daysStr != "" {
+		if days, err := strconv.Atoi(daysStr); err == nil && days >= 0 {
+			if stats := analyzerInstance.GetStats(); stats != nil {
+				stats.SetDailyRetention(days)
+			}
+		}
+	}
+
+	// URLs of one /api/analyze-batch request analyzed at the same time
+	if workersStr := os.Getenv("BATCH_WORKERS"); workersStr != "" {
+		if workers, err := strconv.Atoi(workersStr); err == nil && workers > 0 {
+			analyzerInstance.SetBatchWorkers(workers)
+		}
+	}
+
+	// Async jobs kept at once, and seconds finished jobs can still be polled
+	maxJobs, _ := strconv.Atoi(os.Getenv("MAX_JOBS"))
+	jobTTL, _ := strconv.Atoi(os.Getenv("JOB_TTL"))
+	analyzerInstance.SetJobLimits(maxJobs, time.Duration(jobTTL)*time.Second)
+
+	// Key job callbacks are signed with
+	if secret := os.Getenv("WEBHOOK_SECRET"); secret != "" {
+		analyzerInstance.SetWebhookSecret(secret)
+	}
+
+	// Words of long content one H2/H3 subheading may cover
+	if wordsStr := os.Getenv("WORDS_PER_SUBHEADING"); wordsStr != "" {
+		if words, err := strconv.Atoi(wordsStr); err == nil && words > 0 {
+			analyzerInstance.SetWordsPerSubheading(words)
+		}
+	}
+
+	// Publish cache activity on /api/cache/events
+	if os.Getenv("CACHE_EVENTS") == "true" {
+		analyzerInstance.SetCacheEvents(true)
+	}
+
+	// Share analysis results between replicas through Redis
+	switch cacheBackend := os.Getenv("CACHE_BACKEND"); cacheBackend {
+	case "", "memory":
+	case "redis":
+		redisURL := os.Getenv("REDIS_URL")
+		if redisURL == "" {
+			redisURL = "redis://localhost:6379/0"
+		}
+		redisCache, err := analyzer.NewRedisCache(redisURL)
+		if err != nil {
+			logging.Warn("Redis cache unavailable; using the in-memory cache", "error", err)
+		} else {
+			analyzerInstance.SetAnalysisCache(redisCache)
+			logging.Info("Caching analyses in Redis", "url", redisURL)
+		}
+	default:
+		logging.Warn("Unknown CACHE_BACKEND; using the in-memory cache", "backend", cacheBackend)
+	}
+
+	// Publish every completed analysis to a message queue
+	switch publisherType := os.Getenv("RESULT_PUBLISHER"); publisherType {
+	case "", "none":
+	case "nats":
+		subject := os.Getenv("NATS_SUBJECT")
+		if subject == "" {
+			subject = "seo.analysis.results"
+		}
+		natsPublisher, err := publisher.NewNATSPublisher(os.Getenv("NATS_URL"), subject)
+		if err != nil {
+			logging.Warn("Result publishing disabled", "error", err)
+		} else {
+			analyzerInstance.SetResultPublisher(natsPublisher)
+			logging.Info("Publishing analysis results to NATS", "subject", subject)
+		}
+	default:
+		logging.Warn("Unknown RESULT_PUBLISHER; result publishing disabled", "publisher", publisherType)
+	}
+
+	// Decimal places for scores and other floats in JSON output
+	if precisionStr := os.Getenv("SCORE_PRECISION"); precisionStr != "" {
+		if precision, err := strconv.Atoi(precisionStr); err == nil && precision >= 0 {
+			analyzerInstance.SetOutputPrecision(precision)
+		}
+	}
+
 	// Start periodic cleanup in background
 	go func() {
 		// Calculate duration until next midnight
@@ -136,7 +477,7 @@ func initializeAnalyzer() (*analyzer.Analyzer, error) {
 			if stats := analyzerInstance.GetStats(); stats != nil {
 				// Keep only current month and previous month
 				stats.Cleanup(1) // 1 means keep current month plus 1 previous month
-				log.Printf("Statistics cleanup completed at %v", time.Now().Format("2006-01-02 15:04:05"))
+				logging.Info("Statistics cleanup completed")
 			}
 		}
 
@@ -152,59 +493,42 @@ func initializeAnalyzer() (*analyzer.Analyzer, error) {
 	return analyzerInstance, nil
 }
 
-func main() {
-	// Load environment configuration
-	loadEnv()
-	
-	// Set up Gin mode
-	setupGinMode()
-
-	// Initialize services
-	var err error
-	seoAnalyzer, err = initializeAnalyzer()
-	if err != nil {
-		log.Fatalf("Failed to initialize analyzer: %v", err)
-	}
-
-	requests, duration := getRateLimitConfig()
-	rateLimiter = middleware.NewRateLimiter(float64(requests), float64(duration * 5)) // Convert to float64
-
+// setupRouter creates the Gin engine with all middleware and API routes
+func setupRouter() *gin.Engine {
 	// Initialize Gin router
 	r := gin.Default()
 
 	// Set up trusted proxies
 	if err := setupTrustedProxies(r); err != nil {
-		log.Printf("Warning: Failed to set trusted proxies: %v\n", err)
+		logging.Warn("Failed to set trusted proxies", "error", err)
 	}
 
 	// Add security headers
 	r.Use(securityHeaders())
+
+	// Compress responses of at least GZIP_MIN_SIZE bytes (default 1KB)
+	gzipMinSize := 1024
+	if sizeStr := os.Getenv("GZIP_MIN_SIZE"); sizeStr != "" {
+		if size, err := strconv.Atoi(sizeStr); err == nil && size >= 0 {
+			gzipMinSize = size
+		}
+	}
+	r.Use(middleware.Gzip(gzipMinSize))
+
+	// Log request headers and bodies in debug mode, with credentials redacted
+	if fields := os.Getenv("LOG_REDACT_FIELDS"); fields != "" {
+		logging.SetSensitiveFields(strings.Split(fields, ","))
+	}
+	if os.Getenv("GIN_MODE") == "debug" || os.Getenv("LOG_REQUESTS") == "true" {
+		r.Use(middleware.RequestLogger())
+	}
 	
+	// CORS runs before the rate limiter so browsers can read 429 responses
+	r.Use(corsMiddleware(getAllowedOrigins()))
+
 	// Add middlewares
 	r.Use(middleware.ErrorHandler())
 	r.Use(rateLimiter.RateLimit())
-	
-	// CORS middleware with more restrictive settings
-	r.Use(func(c *gin.Context) {
-		// In development, allow all origins
-		origin := "*"
-		if os.Getenv("GIN_MODE") == "release" {
-			// In production, restrict to your domain
-			origin = "https://seo-optimizer.elvynprise.xyz"
-		}
-		
-		c.Writer.Header().Set("Access-Control-Allow-Origin", origin)
-		c.Writer.Header().Set("Access-Control-Allow-Credentials", "true")
-		c.Writer.Header().Set("Access-Control-Allow-Methods", "GET, POST, OPTIONS")
-		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, Authorization")
-		c.Writer.Header().Set("Access-Control-Max-Age", "86400") // 24 hours
-
-		if c.Request.Method == "OPTIONS" {
-			c.AbortWithStatus(http.StatusNoContent)
-			return
-		}
-		c.Next()
-	})
 
 	// Convert standard middleware to Gin middleware
 	r.Use(func(c *gin.Context) {
@@ -224,59 +548,78 @@ func main() {
 	{
 		// Health check
 		api.GET("/health", func(c *gin.Context) {
-			log.Printf("Health check request received from: %s\n", c.ClientIP())
+			logging.Debug("Health check request received", "ip", c.ClientIP(), "headers", logging.RedactHeaders(c.Request.Header))
 			c.JSON(http.StatusOK, gin.H{
-				"status": "ok",
+				"status":      "ok",
+				"maintenance": seoAnalyzer.MaintenanceMode(),
 			})
 		})
 
+		// Admin endpoints, protected by ADMIN_API_KEY
+		adminAuth := middleware.AdminAuth(os.Getenv("ADMIN_API_KEY"))
+		admin := api.Group("/admin", adminAuth)
+		{
+			admin.POST("/maintenance", setMaintenanceMode)
+		}
+		api.POST("/statistics/reset", adminAuth, resetStatistics)
+
 		// SEO analysis endpoints
 		api.POST("/analyze", analyzeURL)
+		api.GET("/analyze/section/:name", analyzeSection)
+		api.POST("/analyze/quick", quickAnalyzeURL)
+		api.POST("/analyze-batch", analyzeBatch)
+		api.POST("/analyze-html", analyzeHTML)
+
+		// Side-by-side comparison of two pages
+		api.POST("/compare", compareURLs)
+
+		// Shareable PDF or HTML report of an analysis
+		api.GET("/report", getReport)
+
+		// Async analyses: submit, then poll until done
+		api.POST("/jobs", createJob)
+		api.GET("/jobs/:id", getJob)
+
+		// Default analysis settings for the API key sent in X-API-Key
+		api.GET("/profile", getKeyProfile)
+		api.PUT("/profile", setKeyProfile)
 		
 		// Cache status endpoint
 		api.GET("/cache-status", getCacheStatus)
+
+		// Live cache activity (server-sent events), enabled by CACHE_EVENTS
+		api.GET("/cache/events", streamCacheEvents)
 		
 		// Statistics endpoint
 		api.GET("/statistics", func(c *gin.Context) {
 			if stats := seoAnalyzer.GetStats(); stats != nil {
 				currentStats := stats.GetCurrentStats()
 				
-				// Filter out /api/analyze from popularUrls and adjust counters
-				filteredUrls := make(map[string]int)
-				apiCallCount := 0
-				if currentStats.PopularUrls != nil {
-					for url, count := range currentStats.PopularUrls {
-						if url != "/api/analyze" {
-							filteredUrls[url] = count
-						} else {
-							apiCallCount = count
-						}
-					}
-				}
-
-				// Adjust total requests to exclude API calls
-				adjustedRequests := currentStats.TotalRequests - apiCallCount
-				if adjustedRequests < 0 {
-					adjustedRequests = 0
-				}
-				
 				// Calculate average load time based on actual analyses
 				var avgLoadTime float64
-				if adjustedRequests > 0 {
-					avgLoadTime = currentStats.TotalLoadTime / float64(adjustedRequests)
+				if currentStats.TotalRequests > 0 {
+					avgLoadTime = currentStats.TotalLoadTime / float64(currentStats.TotalRequests)
 				}
 				
 				// Prepare response with all numerical stats
 				response := gin.H{
 					"uniqueVisitors24h": len(currentStats.UniqueVisitors),
-					"totalRequests":     adjustedRequests,
-					"errorRate":         float64(currentStats.ErrorCount) / float64(adjustedRequests+1) * 100,
+					"totalRequests":     currentStats.TotalRequests,
+					"errorRate":         errorRate(currentStats.ErrorCount, currentStats.TotalRequests),
 					"averageLoadTime":   avgLoadTime,
 				}
 				
 				// Include popular URLs only in development mode
 				if os.Getenv("GIN_MODE") != "release" {
-					response["popularUrls"] = filteredUrls
+					popular := make(map[string]int)
+					for _, entry := range stats.GetTopURLs(popularURLsLimit) {
+						popular[entry.URL] = entry.Count
+					}
+					response["popularUrls"] = popular
+					// Only with GEOIP_DB_PATH
+					if len(currentStats.Countries) > 0 {
+						response["countries"] = currentStats.Countries
+					}
 				}
 				
 				c.JSON(http.StatusOK, response)
@@ -286,6 +629,33 @@ func main() {
 		})
 	}
 
+	return r
+}
+
+func main() {
+	// Load environment configuration
+	loadEnv()
+	setupLogLevel()
+	
+	// Set up Gin mode
+	setupGinMode()
+
+	// Initialize services
+	var err error
+	seoAnalyzer, err = initializeAnalyzer()
+	if err != nil {
+		logging.Error("Failed to initialize analyzer", "error", err)
+		os.Exit(1)
+	}
+
+	requests, duration := getRateLimitConfig()
+	rateLimiter = middleware.NewRateLimiterWithCleanup(float64(requests), float64(duration * 5), // Convert to float64
+		time.Minute, getRateLimitIdleTTL())
+	defer rateLimiter.Stop()
+	configureIPLists(rateLimiter)
+
+	r := setupRouter()
+
 	// Get port from environment variable or use default
 	port := os.Getenv("PORT")
 	if port == "" {
@@ -300,9 +670,10 @@ func main() {
 
 	// Start server in a goroutine
 	go func() {
-		log.Printf("Server starting on port %s", port)
+		logging.Info("Server starting", "port", port)
 		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			log.Fatalf("Failed to start server: %v", err)
+			logging.Error("Failed to start server", "error", err)
+			os.Exit(1)
 		}
 	}()
 
@@ -311,7 +682,7 @@ func main() {
 	signal.Notify(quit, os.Interrupt, syscall.SIGTERM)
 	<-quit
 
-	log.Println("Shutting down server...")
+	logging.Info("Shutting down server")
 
 	// Create a deadline for graceful shutdown
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
@@ -319,23 +690,50 @@ func main() {
 
 	// Shutdown the server
 	if err := srv.Shutdown(ctx); err != nil {
-		log.Printf("Server forced to shutdown: %v", err)
+		logging.Error("Server forced to shutdown", "error", err)
 	}
 
 	// Shutdown the analyzer (which will save stats)
 	if err := seoAnalyzer.Shutdown(); err != nil {
-		log.Printf("Error during analyzer shutdown: %v", err)
+		logging.Error("Analyzer shutdown failed", "error", err)
 	}
 
-	log.Println("Server exited")
+	logging.Info("Server exited")
+}
+
+// clientKey identifies the caller for fair scheduling
+func clientKey(c *gin.Context) string {
+	if key := c.GetHeader("X-API-Key"); key != "" {
+		return "key:" + key
+	}
+	return "ip:" + c.ClientIP()
 }
 
 func analyzeURL(c *gin.Context) {
 	start := time.Now()
-	log.Printf("Analyze request received from: %s\n", c.ClientIP())
+	logging.Debug("Analyze request received", "ip", c.ClientIP())
 	var request struct {
-		URL   string `json:"url" binding:"required,url"`
+		URL   string `json:"url" binding:"required"`
 		Track bool   `json:"track"`
+		Mode  string `json:"mode"`
+		// Profile selects optional checks ("standard" or "thorough")
+		Profile string `json:"profile"`
+		// Device is "desktop" (default) or "mobile"
+		Device string `json:"device"`
+		// CheckAMP verifies the canonical round trip with the page's AMP variant
+		CheckAMP bool `json:"checkAmp"`
+		// Weights overrides the overall score weight of individual sections
+		Weights map[string]float64 `json:"weights"`
+		// TargetKeyword counts internal links using it in their anchor text
+		TargetKeyword string `json:"targetKeyword"`
+		// Refresh skips the cache and replaces the cached result
+		Refresh bool `json:"refresh"`
+		// UserAgent replaces the User-Agent of the page fetch and link checks
+		UserAgent string `json:"userAgent"`
+		// TimeoutSeconds overrides the analysis timeout, clamped to 5-60s
+		TimeoutSeconds int `json:"timeoutSeconds"`
+		// CheckLinks false skips the broken link checks (default true)
+		CheckLinks *bool `json:"checkLinks"`
 	}
 
 	if err := c.ShouldBindJSON(&request); err != nil {
@@ -344,30 +742,664 @@ func analyzeURL(c *gin.Context) {
 		})
 		return
 	}
+	target, err := analyzer.ParseTargetURL(request.URL)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	request.URL = target
 
-	analysis, err := seoAnalyzer.Analyze(request.URL)
+	// Clamp before converting so huge values can't overflow the duration
+	if max := int(analyzer.MaxAnalysisTimeout / time.Second); request.TimeoutSeconds > max {
+		request.TimeoutSeconds = max
+	}
+	opts := analyzer.AnalyzeOptions{
+		BypassCache:   request.Refresh,
+		Mode:          analyzer.FetchMode(request.Mode),
+		Profile:       analyzer.Profile(request.Profile),
+		Device:        analyzer.Device(request.Device),
+		CheckAMP:      request.CheckAMP,
+		Weights:       request.Weights,
+		TargetKeyword: strings.TrimSpace(request.TargetKeyword),
+		UserAgent:     strings.TrimSpace(request.UserAgent),
+		Timeout:       time.Duration(request.TimeoutSeconds) * time.Second,
+		SkipLinkCheck: request.CheckLinks != nil && !*request.CheckLinks,
+		// Fair scheduling keys on the API key when one is sent, else the IP
+		ClientKey: clientKey(c),
+	}
+	// Fill anything the request didn't set from the key's stored profile
+	if key := c.GetHeader("X-API-Key"); key != "" {
+		if profile, ok := seoAnalyzer.KeyProfile(key); ok {
+			opts = profile.Apply(opts)
+		}
+	}
+	if err := opts.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid options: " + err.Error(),
+		})
+		return
+	}
+
+	c.Header("X-Analysis-Timeout", strconv.Itoa(int(opts.EffectiveTimeout().Seconds())))
+
+	// Analyze under the request's context so a client that disconnects stops
+	// the fetch and link checks
+	analysis, cacheStatus, err := seoAnalyzer.AnalyzeWithCacheStatus(c.Request.Context(), request.URL, opts)
+	setCacheHeader(c, cacheStatus)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
+		if c.Request.Context().Err() != nil {
+			// Nobody is left to read a response
+			logging.Debug("Client disconnected during analysis", "url", logging.RedactURL(request.URL))
+			c.Abort()
+			return
+		}
+		trackAnalysis(request.URL, start, err)
+		writeAnalyzeError(c, err)
+		return
+	}
+
+	// Track the actual analyzed URL, not the API endpoint
+	trackAnalysis(request.URL, start, nil)
+
+	c.JSON(http.StatusOK, analysis)
+}
+
+// errorRate returns errors as a percentage of requests, the analysis
+// attempts including failed ones, or 0 when there were no requests
+func errorRate(errors, requests int) float64 {
+	if requests <= 0 {
+		return 0
+	}
+	return float64(errors) / float64(requests) * 100
+}
+
+// writeAnalyzeError responds with the status matching an analysis error:
+// 503 while the analyzer or the target's host can't take requests, 400 for
+// private targets, 403 outside the domain allowlist, 422 for non-HTML pages
+// and 500 for anything else
+func writeAnalyzeError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, analyzer.ErrMaintenance), errors.Is(err, analyzer.ErrCircuitOpen),
+		errors.Is(err, analyzer.ErrShuttingDown):
+		c.JSON(http.StatusServiceUnavailable, gin.H{
+			"error": err.Error(),
+		})
+	case errors.Is(err, analyzer.ErrPrivateAddress):
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+	case errors.Is(err, analyzer.ErrDomainNotAllowed):
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": err.Error(),
+		})
+	case errors.Is(err, analyzer.ErrNotHTML):
+		c.JSON(http.StatusUnprocessableEntity, gin.H{
+			"error": err.Error(),
+		})
+	default:
+		response := gin.H{
 			"error": "Failed to analyze URL: " + err.Error(),
+		}
+		// Report the page's status; mode "bestEffort" analyzes error pages
+		var fetchErr *analyzer.FetchError
+		if errors.As(err, &fetchErr) && fetchErr.StatusCode != 0 {
+			response["statusCode"] = fetchErr.StatusCode
+		}
+		c.JSON(http.StatusInternalServerError, response)
+	}
+}
+
+// setCacheHeader reports in X-Cache how the cache served the analysis;
+// requests refused before the cache was consulted get no header
+func setCacheHeader(c *gin.Context, status analyzer.CacheStatus) {
+	if status != "" {
+		c.Header("X-Cache", string(status))
+	}
+}
+
+// trackAnalysis records the analysis of url started at start, counting it
+// as an error when err is set. Requests refused before anything was fetched,
+// e.g. in maintenance mode or outside the domain allowlist, aren't tracked.
+func trackAnalysis(url string, start time.Time, err error) {
+	if errors.Is(err, analyzer.ErrMaintenance) || errors.Is(err, analyzer.ErrShuttingDown) ||
+		errors.Is(err, analyzer.ErrDomainNotAllowed) || errors.Is(err, analyzer.ErrPrivateAddress) {
+		return
+	}
+	stats := seoAnalyzer.GetStats()
+	if stats == nil {
+		return
+	}
+	stats.TrackAnalysis(url, float64(time.Since(start).Milliseconds()), err != nil)
+	logging.Debug("Tracked analysis", "url", logging.RedactURL(url), "failed", err != nil)
+}
+
+// analyzeBatch analyzes up to analyzer.MaxBatchSize URLs in one call,
+// returning per-URL results and errors
+func analyzeBatch(c *gin.Context) {
+	logging.Debug("Batch analyze request received", "ip", c.ClientIP())
+	var request struct {
+		URLs    []string `json:"urls" binding:"required"`
+		Mode    string   `json:"mode"`
+		Profile string   `json:"profile"`
+		Device  string   `json:"device"`
+	}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid URL list provided",
+		})
+		return
+	}
+	for i, raw := range request.URLs {
+		target, err := analyzer.ParseTargetURL(raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": fmt.Sprintf("urls[%d]: %v", i, err),
+			})
+			return
+		}
+		request.URLs[i] = target
+	}
+	if seoAnalyzer.MaintenanceMode() {
+		c.JSON(http.StatusServiceUnavailable, gin.H{
+			"error": analyzer.ErrMaintenance.Error(),
 		})
 		return
 	}
 
-	// Track the actual analyzed URL, not the API endpoint
-	loadTime := float64(time.Since(start).Milliseconds())
-	if stats := seoAnalyzer.GetStats(); stats != nil {
-		// Only track if it's a valid URL
-		if request.URL != "" && request.URL != "/api/analyze" {
-			stats.TrackAnalysis(request.URL, loadTime, false)
-			log.Printf("Tracked analysis for URL: %s", request.URL)
+	opts := analyzer.AnalyzeOptions{
+		Mode:      analyzer.FetchMode(request.Mode),
+		Profile:   analyzer.Profile(request.Profile),
+		Device:    analyzer.Device(request.Device),
+		ClientKey: clientKey(c),
+	}
+	if key := c.GetHeader("X-API-Key"); key != "" {
+		if profile, ok := seoAnalyzer.KeyProfile(key); ok {
+			opts = profile.Apply(opts)
 		}
 	}
 
+	batch, err := seoAnalyzer.AnalyzeBatch(request.URLs, opts)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	for _, result := range batch.Results {
+		trackAnalysis(result.URL, result.Started(), result.Err())
+	}
+
+	c.JSON(http.StatusOK, batch)
+}
+
+// analyzeHTML analyzes markup sent in the request body without fetching it,
+// resolving relative links against baseURL
+func analyzeHTML(c *gin.Context) {
+	logging.Debug("HTML analyze request received", "ip", c.ClientIP())
+	var request struct {
+		HTML    string `json:"html" binding:"required"`
+		BaseURL string `json:"baseURL" binding:"required,url"`
+		Profile string `json:"profile"`
+		Device  string `json:"device"`
+		// TargetKeyword counts internal links using it in their anchor text
+		TargetKeyword string `json:"targetKeyword"`
+	}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "HTML and a valid baseURL are required",
+		})
+		return
+	}
+
+	opts := analyzer.AnalyzeOptions{
+		Profile:       analyzer.Profile(request.Profile),
+		Device:        analyzer.Device(request.Device),
+		TargetKeyword: strings.TrimSpace(request.TargetKeyword),
+		ClientKey:     clientKey(c),
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 30*time.Second)
+	defer cancel()
+
+	analysis, err := seoAnalyzer.AnalyzeHTML(ctx, []byte(request.HTML), request.BaseURL, opts)
+	if err != nil {
+		if errors.Is(err, analyzer.ErrMaintenance) || errors.Is(err, analyzer.ErrShuttingDown) {
+			c.JSON(http.StatusServiceUnavailable, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Failed to analyze HTML: " + err.Error(),
+		})
+		return
+	}
+
 	c.JSON(http.StatusOK, analysis)
 }
 
+// compareURLs analyzes two pages concurrently and reports how their scores,
+// recommendations and metrics differ
+func compareURLs(c *gin.Context) {
+	logging.Debug("Compare request received", "ip", c.ClientIP())
+	var request struct {
+		URLA    string `json:"urlA" binding:"required"`
+		URLB    string `json:"urlB" binding:"required"`
+		Mode    string `json:"mode"`
+		Profile string `json:"profile"`
+		Device  string `json:"device"`
+	}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid URLs provided; expected urlA and urlB",
+		})
+		return
+	}
+	for _, target := range []struct {
+		name string
+		url  *string
+	}{{"urlA", &request.URLA}, {"urlB", &request.URLB}} {
+		parsed, err := analyzer.ParseTargetURL(*target.url)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": target.name + ": " + err.Error(),
+			})
+			return
+		}
+		*target.url = parsed
+	}
+
+	opts := analyzer.AnalyzeOptions{
+		Mode:      analyzer.FetchMode(request.Mode),
+		Profile:   analyzer.Profile(request.Profile),
+		Device:    analyzer.Device(request.Device),
+		ClientKey: clientKey(c),
+	}
+	if key := c.GetHeader("X-API-Key"); key != "" {
+		if profile, ok := seoAnalyzer.KeyProfile(key); ok {
+			opts = profile.Apply(opts)
+		}
+	}
+	if err := opts.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid options: " + err.Error(),
+		})
+		return
+	}
+
+	start := time.Now()
+	comparison, err := seoAnalyzer.Compare(request.URLA, request.URLB, opts)
+	// Count both analyses, unless the comparison failed before running them
+	var compareErr *analyzer.CompareError
+	if err == nil || errors.As(err, &compareErr) {
+		var errA, errB error
+		if compareErr != nil {
+			errA, errB = compareErr.ErrA, compareErr.ErrB
+		}
+		trackAnalysis(request.URLA, start, errA)
+		trackAnalysis(request.URLB, start, errB)
+	}
+	if err != nil {
+		status := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, analyzer.ErrMaintenance), errors.Is(err, analyzer.ErrCircuitOpen),
+			errors.Is(err, analyzer.ErrShuttingDown):
+			status = http.StatusServiceUnavailable
+		case errors.Is(err, analyzer.ErrDomainNotAllowed):
+			status = http.StatusForbidden
+		case errors.Is(err, analyzer.ErrPrivateAddress):
+			status = http.StatusBadRequest
+		}
+		c.JSON(status, gin.H{
+			"error": "Failed to compare URLs: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, comparison)
+}
+
+// createJob starts an analysis, batch or crawl in the background and
+// returns its job ID right away, so the work can outlast the request
+func createJob(c *gin.Context) {
+	logging.Debug("Job request received", "ip", c.ClientIP())
+	var request struct {
+		// Type is "analyze" (default), "batch" or "crawl"
+		Type     string   `json:"type"`
+		URL      string   `json:"url"`
+		URLs     []string `json:"urls"`
+		MaxDepth int      `json:"maxDepth"`
+		MaxPages int      `json:"maxPages"`
+		Mode     string   `json:"mode"`
+		Profile  string   `json:"profile"`
+		Device   string   `json:"device"`
+		// CallbackURL receives the finished job as a signed POST
+		CallbackURL string `json:"callbackURL"`
+	}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid job request",
+		})
+		return
+	}
+
+	opts := analyzer.AnalyzeOptions{
+		Mode:      analyzer.FetchMode(request.Mode),
+		Profile:   analyzer.Profile(request.Profile),
+		Device:    analyzer.Device(request.Device),
+		ClientKey: clientKey(c),
+	}
+	if key := c.GetHeader("X-API-Key"); key != "" {
+		if profile, ok := seoAnalyzer.KeyProfile(key); ok {
+			opts = profile.Apply(opts)
+		}
+	}
+
+	job, err := seoAnalyzer.SubmitJob(analyzer.JobRequest{
+		Kind:        analyzer.JobKind(request.Type),
+		URL:         request.URL,
+		URLs:        request.URLs,
+		MaxDepth:    request.MaxDepth,
+		MaxPages:    request.MaxPages,
+		Options:     opts,
+		CallbackURL: request.CallbackURL,
+	})
+	if err != nil {
+		status := http.StatusBadRequest
+		switch {
+		case errors.Is(err, analyzer.ErrMaintenance), errors.Is(err, analyzer.ErrShuttingDown):
+			status = http.StatusServiceUnavailable
+		case errors.Is(err, analyzer.ErrTooManyJobs):
+			status = http.StatusTooManyRequests
+		case errors.Is(err, analyzer.ErrDomainNotAllowed):
+			status = http.StatusForbidden
+		}
+		c.JSON(status, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	c.Header("Location", "/api/jobs/"+job.ID)
+	c.JSON(http.StatusAccepted, job)
+}
+
+// getJob reports a job's status, with its result once it is done
+func getJob(c *gin.Context) {
+	job, ok := seoAnalyzer.GetJob(c.Param("id"))
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Job not found or expired",
+		})
+		return
+	}
+	c.JSON(http.StatusOK, job)
+}
+
+// quickAnalyzeURL runs the head-only checks, downloading only as much of the
+// page as needed to see the complete head
+func quickAnalyzeURL(c *gin.Context) {
+	var request struct {
+		URL string `json:"url" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid URL provided",
+		})
+		return
+	}
+	target, err := analyzer.ParseTargetURL(request.URL)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 15*time.Second)
+	defer cancel()
+
+	quick, err := seoAnalyzer.QuickAnalyze(ctx, target)
+	if err != nil {
+		writeAnalyzeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, quick)
+}
+
+// analyzeSection returns a single section of the analysis of the url query
+// parameter, e.g. GET /api/analyze/section/links?url=https://example.com
+func analyzeSection(c *gin.Context) {
+	name := c.Param("name")
+	if !analyzer.IsSection(name) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":    "Unknown section: " + name,
+			"sections": analyzer.SectionNames(),
+		})
+		return
+	}
+
+	target, err := analyzer.ParseTargetURL(c.Query("url"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	analysis, cacheStatus, err := seoAnalyzer.AnalyzeWithCacheStatus(context.Background(), target,
+		analyzer.AnalyzeOptions{ClientKey: clientKey(c)})
+	setCacheHeader(c, cacheStatus)
+	if err != nil {
+		writeAnalyzeError(c, err)
+		return
+	}
+
+	section, err := analysis.Section(name)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to encode section: " + err.Error(),
+		})
+		return
+	}
+
+	c.Data(http.StatusOK, "application/json; charset=utf-8", section)
+}
+
+// getReport renders the analysis of the url query parameter as a PDF
+// (format=pdf, the default) or HTML (format=html) report, reusing a cached
+// analysis when available
+func getReport(c *gin.Context) {
+	format := c.DefaultQuery("format", "pdf")
+	if format != "pdf" && format != "html" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Unknown report format: " + format + " (expected pdf or html)",
+		})
+		return
+	}
+
+	target, err := analyzer.ParseTargetURL(c.Query("url"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	// Name the PDF after the host; target is already validated
+	parsed, _ := url.Parse(target)
+
+	analysis, cacheStatus, err := seoAnalyzer.AnalyzeWithCacheStatus(context.Background(), target,
+		analyzer.AnalyzeOptions{ClientKey: clientKey(c)})
+	setCacheHeader(c, cacheStatus)
+	if err != nil {
+		writeAnalyzeError(c, err)
+		return
+	}
+
+	// Render into a buffer so a failure can still be reported as JSON
+	var body bytes.Buffer
+	doc := report.New(analysis)
+	if format == "html" {
+		err = doc.WriteHTML(&body)
+	} else {
+		err = doc.WritePDF(&body)
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to render report: " + err.Error(),
+		})
+		return
+	}
+
+	if format == "html" {
+		c.Data(http.StatusOK, "text/html; charset=utf-8", body.Bytes())
+		return
+	}
+	c.Header("Content-Disposition", fmt.Sprintf(`inline; filename="seo-report-%s.pdf"`, parsed.Hostname()))
+	c.Data(http.StatusOK, "application/pdf", body.Bytes())
+}
+
+// streamCacheEvents streams cache additions, hits and evictions as
+// server-sent events until the client disconnects
+func streamCacheEvents(c *gin.Context) {
+	if !seoAnalyzer.CacheEventsEnabled() {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Cache events are disabled",
+		})
+		return
+	}
+
+	events, unsubscribe := seoAnalyzer.SubscribeCacheEvents()
+	defer unsubscribe()
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Status(http.StatusOK)
+	c.Writer.Flush()
+
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case event := <-events:
+			c.SSEvent(string(event.Type), event)
+			return true
+		case <-c.Request.Context().Done():
+			return false
+		}
+	})
+}
+
+func getKeyProfile(c *gin.Context) {
+	key := c.GetHeader("X-API-Key")
+	if key == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "An X-API-Key header is required",
+		})
+		return
+	}
+
+	profile, _ := seoAnalyzer.KeyProfile(key)
+	c.JSON(http.StatusOK, profile)
+}
+
+func setKeyProfile(c *gin.Context) {
+	key := c.GetHeader("X-API-Key")
+	if key == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "An X-API-Key header is required",
+		})
+		return
+	}
+
+	var profile analyzer.KeyProfile
+	if err := c.ShouldBindJSON(&profile); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid profile: " + err.Error(),
+		})
+		return
+	}
+	if err := profile.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid profile: " + err.Error(),
+		})
+		return
+	}
+	if err := seoAnalyzer.SetKeyProfile(key, profile); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to save profile: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, profile)
+}
+
+func setMaintenanceMode(c *gin.Context) {
+	var request struct {
+		Enabled *bool `json:"enabled" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Request must include \"enabled\": true|false",
+		})
+		return
+	}
+
+	seoAnalyzer.SetMaintenanceMode(*request.Enabled)
+	logging.Warn("Maintenance mode changed", "enabled", *request.Enabled, "ip", c.ClientIP())
+
+	c.JSON(http.StatusOK, gin.H{
+		"maintenance": seoAnalyzer.MaintenanceMode(),
+	})
+}
+
+// resetStatistics wipes the statistics of the month given as {"month":
+// "YYYY-MM"}, or of every month when the body is empty
+func resetStatistics(c *gin.Context) {
+	var request struct {
+		Month string `json:"month"`
+	}
+	if err := c.ShouldBindJSON(&request); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Request body must be empty or {\"month\": \"YYYY-MM\"}",
+		})
+		return
+	}
+
+	backend := seoAnalyzer.GetStats()
+	if backend == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Statistics not available"})
+		return
+	}
+	if err := backend.Reset(request.Month); err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, stats.ErrInvalidMonth) {
+			status = http.StatusBadRequest
+		}
+		c.JSON(status, gin.H{
+			"error": "Failed to reset statistics: " + err.Error(),
+		})
+		return
+	}
+	logging.Warn("Statistics reset", "month", request.Month, "ip", c.ClientIP())
+
+	month := request.Month
+	if month == "" {
+		month = "all"
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"reset": month,
+	})
+}
+
 func getCacheStatus(c *gin.Context) {
-	log.Printf("Cache status request received from: %s\n", c.ClientIP())
+	logging.Debug("Cache status request received", "ip", c.ClientIP())
 	
 	// Get cache statistics
 	stats := seoAnalyzer.GetCacheStats()
@@ -0,0 +1,777 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/seo-optimizer/backend/analyzer"
+	"github.com/seo-optimizer/backend/logging"
+	"github.com/seo-optimizer/backend/middleware"
+)
+
+// setupTestServer initializes the package-level services against a temporary
+// data directory and returns the router
+func setupTestServer(t *testing.T) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	var err error
+	seoAnalyzer, err = analyzer.New(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to create analyzer: %v", err)
+	}
+	t.Cleanup(func() { seoAnalyzer.Shutdown() })
+	// Test sites run on loopback
+	seoAnalyzer.SetAllowPrivateTargets(true)
+
+	rateLimiter = middleware.NewRateLimiter(1000, 1000)
+	return setupRouter()
+}
+
+// performRequest sends a request with an optional JSON body through the router
+func performRequest(r http.Handler, method, path string, body interface{}, headers map[string]string) *httptest.ResponseRecorder {
+	var buf bytes.Buffer
+	if body != nil {
+		json.NewEncoder(&buf).Encode(body)
+	}
+	req := httptest.NewRequest(method, path, &buf)
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+// newTestSite serves a minimal HTML page for every path
+func newTestSite(t *testing.T) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		fmt.Fprint(w, `<html><head><title>Test page</title></head><body><h1>Hello</h1></body></html>`)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestMaintenanceMode(t *testing.T) {
+	t.Setenv("ADMIN_API_KEY", "secret")
+	r := setupTestServer(t)
+	site := newTestSite(t)
+	auth := map[string]string{"Authorization": "Bearer secret"}
+
+	// Toggling requires the admin key
+	w := performRequest(r, "POST", "/api/admin/maintenance", gin.H{"enabled": true}, nil)
+	if w.Code != http.StatusUnauthorized {
+		t.Fatalf("Expected 401 without admin key, got %d", w.Code)
+	}
+
+	w = performRequest(r, "POST", "/api/admin/maintenance", gin.H{"enabled": true}, auth)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected 200 enabling maintenance, got %d: %s", w.Code, w.Body)
+	}
+
+	w = performRequest(r, "POST", "/api/analyze", gin.H{"url": site.URL}, nil)
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("Expected 503 while in maintenance, got %d: %s", w.Code, w.Body)
+	}
+
+	w = performRequest(r, "GET", "/api/health", nil, nil)
+	var health struct {
+		Status      string `json:"status"`
+		Maintenance bool   `json:"maintenance"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &health)
+	if w.Code != http.StatusOK || health.Status != "ok" || !health.Maintenance {
+		t.Errorf("Expected healthy status reporting maintenance, got %d: %s", w.Code, w.Body)
+	}
+
+	w = performRequest(r, "POST", "/api/admin/maintenance", gin.H{"enabled": false}, auth)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected 200 disabling maintenance, got %d", w.Code)
+	}
+
+	w = performRequest(r, "POST", "/api/analyze", gin.H{"url": site.URL}, nil)
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected 200 after leaving maintenance, got %d: %s", w.Code, w.Body)
+	}
+}
+
+func TestAnalyzeSection(t *testing.T) {
+	r := setupTestServer(t)
+	site := newTestSite(t)
+
+	w := performRequest(r, "GET", "/api/analyze/section/title?url="+site.URL, nil, nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected 200 for a valid section, got %d: %s", w.Code, w.Body)
+	}
+	var title analyzer.TitleAnalysis
+	if err := json.Unmarshal(w.Body.Bytes(), &title); err != nil {
+		t.Fatalf("Expected the bare title section, got %s", w.Body)
+	}
+	if title.Title != "Test page" || !title.HasTitle {
+		t.Errorf("Unexpected title section: %+v", title)
+	}
+
+	w = performRequest(r, "GET", "/api/analyze/section/links?url="+site.URL, nil, nil)
+	if w.Code != http.StatusOK || w.Header().Get("X-Cache") != "HIT" {
+		t.Errorf("Expected the links section from the cached analysis, got %d (X-Cache %q)", w.Code, w.Header().Get("X-Cache"))
+	}
+
+	w = performRequest(r, "GET", "/api/analyze/section/bogus?url="+site.URL, nil, nil)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for an unknown section, got %d: %s", w.Code, w.Body)
+	}
+
+	w = performRequest(r, "GET", "/api/analyze/section/title?url=not-a-url", nil, nil)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for an invalid URL, got %d: %s", w.Code, w.Body)
+	}
+}
+
+func TestReport(t *testing.T) {
+	r := setupTestServer(t)
+	site := newTestSite(t)
+
+	w := performRequest(r, "GET", "/api/report?url="+site.URL, nil, nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected 200 for a PDF report, got %d: %s", w.Code, w.Body)
+	}
+	if w.Header().Get("Content-Type") != "application/pdf" || !bytes.HasPrefix(w.Body.Bytes(), []byte("%PDF-")) {
+		t.Errorf("Expected a PDF, got %q", w.Header().Get("Content-Type"))
+	}
+
+	w = performRequest(r, "GET", "/api/report?format=html&url="+site.URL, nil, nil)
+	if w.Code != http.StatusOK || w.Header().Get("X-Cache") != "HIT" {
+		t.Fatalf("Expected an HTML report from the cached analysis, got %d (X-Cache %q)", w.Code, w.Header().Get("X-Cache"))
+	}
+	if !strings.HasPrefix(w.Header().Get("Content-Type"), "text/html") || !strings.Contains(w.Body.String(), site.URL) {
+		t.Errorf("Expected an HTML report for %s, got %s", site.URL, w.Body)
+	}
+
+	w = performRequest(r, "GET", "/api/report?format=docx&url="+site.URL, nil, nil)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for an unknown format, got %d: %s", w.Code, w.Body)
+	}
+}
+
+func TestCacheEventsStream(t *testing.T) {
+	r := setupTestServer(t)
+	site := newTestSite(t)
+
+	w := performRequest(r, "GET", "/api/cache/events", nil, nil)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 while cache events are disabled, got %d", w.Code)
+	}
+
+	seoAnalyzer.SetCacheEvents(true)
+	server := httptest.NewServer(r)
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, "GET", server.URL+"/api/cache/events", nil)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Failed to subscribe: %v", err)
+	}
+	defer resp.Body.Close()
+	if ct := resp.Header.Get("Content-Type"); !strings.HasPrefix(ct, "text/event-stream") {
+		t.Fatalf("Expected an event stream, got %q", ct)
+	}
+
+	w = performRequest(r, "POST", "/api/analyze", gin.H{"url": site.URL}, nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected 200 from analyze, got %d: %s", w.Code, w.Body)
+	}
+
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		if scanner.Text() == "event:added" {
+			if !scanner.Scan() || !strings.Contains(scanner.Text(), site.URL) {
+				t.Errorf("Expected the added event to carry the URL, got %q", scanner.Text())
+			}
+			return
+		}
+	}
+	t.Fatalf("Stream ended without an added event: %v", scanner.Err())
+}
+
+func TestGzipCompression(t *testing.T) {
+	r := setupTestServer(t)
+	site := newTestSite(t)
+	acceptGzip := map[string]string{"Accept-Encoding": "gzip"}
+
+	w := performRequest(r, "POST", "/api/analyze", gin.H{"url": site.URL}, acceptGzip)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d", w.Code)
+	}
+	if w.Header().Get("Content-Encoding") != "gzip" {
+		t.Fatalf("Expected a large analysis response to be gzipped (%d bytes)", w.Body.Len())
+	}
+	gz, err := gzip.NewReader(w.Body)
+	if err != nil {
+		t.Fatalf("Invalid gzip body: %v", err)
+	}
+	// The submitted URL is normalized with a "/" path
+	var analysis analyzer.SEOAnalysis
+	if err := json.NewDecoder(gz).Decode(&analysis); err != nil || analysis.URL != site.URL+"/" {
+		t.Errorf("Expected the decompressed analysis for %s/, got %v (%v)", site.URL, analysis.URL, err)
+	}
+
+	w = performRequest(r, "GET", "/api/health", nil, acceptGzip)
+	if w.Header().Get("Content-Encoding") != "" {
+		t.Error("Expected a small response not to be compressed")
+	}
+	if !strings.Contains(w.Body.String(), `"status":"ok"`) {
+		t.Errorf("Expected the plain health response, got %q", w.Body)
+	}
+
+	w = performRequest(r, "POST", "/api/analyze", gin.H{"url": site.URL}, nil)
+	if w.Header().Get("Content-Encoding") != "" {
+		t.Error("Expected no compression without Accept-Encoding: gzip")
+	}
+}
+
+func TestRequestLoggingRedactsCredentials(t *testing.T) {
+	t.Setenv("LOG_REQUESTS", "true")
+	r := setupTestServer(t)
+	site := newTestSite(t)
+
+	var logs bytes.Buffer
+	logging.SetOutput(&logs)
+	defer logging.SetOutput(os.Stderr)
+
+	body := gin.H{
+		"url":     site.URL,
+		"auth":    gin.H{"username": "admin", "password": "hunter2"},
+		"headers": gin.H{"Authorization": "Bearer s3cr3t"},
+	}
+	w := performRequest(r, "POST", "/api/analyze", body, map[string]string{"X-API-Key": "key-123"})
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected the logged request to still be handled, got %d", w.Code)
+	}
+
+	logged := logs.String()
+	if !strings.Contains(logged, "/api/analyze") || !strings.Contains(logged, "admin") {
+		t.Fatalf("Expected the request to be logged, got %q", logged)
+	}
+	for _, secret := range []string{"hunter2", "s3cr3t", "key-123"} {
+		if strings.Contains(logged, secret) {
+			t.Errorf("Expected %q to be redacted from logs, got %q", secret, logged)
+		}
+	}
+}
+
+func TestKeyProfileDefaults(t *testing.T) {
+	r := setupTestServer(t)
+	site := newTestSite(t)
+	partner := map[string]string{"X-API-Key": "partner-key"}
+
+	w := performRequest(r, "PUT", "/api/profile", gin.H{"weights": gin.H{"title": 2}}, nil)
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("Expected 401 without an API key, got %d", w.Code)
+	}
+	w = performRequest(r, "PUT", "/api/profile", gin.H{"weights": gin.H{"bogus": 1}}, partner)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for an unknown weight, got %d", w.Code)
+	}
+
+	// Score on the title alone
+	titleOnly := gin.H{"title": 1, "meta": 0, "headers": 0, "content": 0, "performance": 0, "links": 0}
+	w = performRequest(r, "PUT", "/api/profile", gin.H{"weights": titleOnly}, partner)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected the profile to be saved, got %d: %s", w.Code, w.Body)
+	}
+	w = performRequest(r, "GET", "/api/profile", nil, partner)
+	if !strings.Contains(w.Body.String(), `"title":1`) {
+		t.Errorf("Expected the stored profile, got %s", w.Body)
+	}
+
+	analyze := func(body gin.H, headers map[string]string) analyzer.SEOAnalysis {
+		t.Helper()
+		w := performRequest(r, "POST", "/api/analyze", body, headers)
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected 200, got %d: %s", w.Code, w.Body)
+		}
+		var analysis analyzer.SEOAnalysis
+		if err := json.Unmarshal(w.Body.Bytes(), &analysis); err != nil {
+			t.Fatalf("Invalid response: %v", err)
+		}
+		return analysis
+	}
+
+	withProfile := analyze(gin.H{"url": site.URL}, partner)
+	if withProfile.Score != float64(withProfile.Title.Score) {
+		t.Errorf("Expected the key's weights to score on the title alone (%d), got %v",
+			withProfile.Title.Score, withProfile.Score)
+	}
+
+	// Request weights win over the profile
+	overridden := analyze(gin.H{"url": site.URL, "weights": gin.H{"title": 0, "meta": 1}}, partner)
+	if overridden.Score != float64(overridden.Meta.Score) {
+		t.Errorf("Expected request weights to win (%d), got %v", overridden.Meta.Score, overridden.Score)
+	}
+
+	withoutKey := analyze(gin.H{"url": site.URL}, nil)
+	if withoutKey.Score == withProfile.Score {
+		t.Errorf("Expected requests without the key to use the default weights, got %v", withoutKey.Score)
+	}
+}
+
+func TestAnalyzeTimeoutOption(t *testing.T) {
+	r := setupTestServer(t)
+	site := newTestSite(t)
+
+	tests := []struct {
+		timeoutSeconds int
+		want           string
+	}{
+		{0, "30"},
+		{45, "45"},
+		{1, "5"},
+		{1 << 62, "60"},
+	}
+	for _, tt := range tests {
+		w := performRequest(r, "POST", "/api/analyze", gin.H{"url": site.URL, "timeoutSeconds": tt.timeoutSeconds}, nil)
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected 200 for timeoutSeconds %d, got %d: %s", tt.timeoutSeconds, w.Code, w.Body)
+		}
+		if got := w.Header().Get("X-Analysis-Timeout"); got != tt.want {
+			t.Errorf("Expected an effective timeout of %ss for timeoutSeconds %d, got %q", tt.want, tt.timeoutSeconds, got)
+		}
+	}
+
+	w := performRequest(r, "POST", "/api/analyze", gin.H{"url": site.URL, "timeoutSeconds": -5}, nil)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for a negative timeout, got %d: %s", w.Code, w.Body)
+	}
+}
+
+func TestAnalyzeCheckLinksOption(t *testing.T) {
+	r := setupTestServer(t)
+	site := newTestSite(t)
+
+	for _, tt := range []struct {
+		body    gin.H
+		checked bool
+	}{
+		{gin.H{"url": site.URL + "/skip", "checkLinks": false}, false},
+		{gin.H{"url": site.URL + "/check", "checkLinks": true}, true},
+		{gin.H{"url": site.URL + "/default"}, true},
+	} {
+		w := performRequest(r, "POST", "/api/analyze", tt.body, nil)
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected 200, got %d: %s", w.Code, w.Body)
+		}
+		var analysis struct {
+			Links struct {
+				LinksChecked bool `json:"linksChecked"`
+			} `json:"links"`
+		}
+		json.Unmarshal(w.Body.Bytes(), &analysis)
+		if analysis.Links.LinksChecked != tt.checked {
+			t.Errorf("Expected linksChecked %v for %v, got %v", tt.checked, tt.body, analysis.Links.LinksChecked)
+		}
+	}
+}
+
+func TestAnalyzeClientDisconnect(t *testing.T) {
+	r := setupTestServer(t)
+	site := newTestSite(t)
+
+	// A request whose client has already gone away gets no error body
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	body, _ := json.Marshal(gin.H{"url": site.URL})
+	req := httptest.NewRequest("POST", "/api/analyze", bytes.NewReader(body)).WithContext(ctx)
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Body.Len() != 0 {
+		t.Errorf("Expected no response for a disconnected client, got %d: %s", w.Code, w.Body)
+	}
+
+	// The cancellation isn't cached against the URL
+	if w := performRequest(r, "POST", "/api/analyze", gin.H{"url": site.URL}, nil); w.Code != http.StatusOK {
+		t.Errorf("Expected 200 after the disconnect, got %d: %s", w.Code, w.Body)
+	}
+}
+
+func TestCORSAllowedOrigins(t *testing.T) {
+	t.Setenv("CORS_ALLOWED_ORIGINS", "https://app.example.com, https://admin.example.com/")
+	setupTestServer(t)
+	// One request per client, so the second is rate limited
+	rateLimiter = middleware.NewRateLimiter(0, 1)
+	r := setupRouter()
+
+	w := performRequest(r, "GET", "/api/health", nil, map[string]string{"Origin": "https://admin.example.com"})
+	if got := w.Header().Get("Access-Control-Allow-Origin"); got != "https://admin.example.com" {
+		t.Errorf("Expected the allowed origin to be echoed, got %q", got)
+	}
+	if got := w.Header().Get("Access-Control-Allow-Credentials"); got != "true" {
+		t.Errorf("Expected credentials for an allowed origin, got %q", got)
+	}
+
+	// Rate limited responses still carry CORS headers
+	w = performRequest(r, "GET", "/api/health", nil, map[string]string{"Origin": "https://app.example.com"})
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("Expected 429, got %d", w.Code)
+	}
+	if got := w.Header().Get("Access-Control-Allow-Origin"); got != "https://app.example.com" {
+		t.Errorf("Expected CORS headers on the 429, got %q", got)
+	}
+
+	w = performRequest(r, "OPTIONS", "/api/analyze", nil, map[string]string{"Origin": "https://evil.example.com"})
+	if w.Code != http.StatusNoContent {
+		t.Errorf("Expected 204 for a preflight, got %d", w.Code)
+	}
+	if got := w.Header().Get("Access-Control-Allow-Origin"); got != "" {
+		t.Errorf("Expected no allowed origin for an unlisted origin, got %q", got)
+	}
+
+	t.Setenv("CORS_ALLOWED_ORIGINS", "*")
+	rateLimiter = middleware.NewRateLimiter(1000, 1000)
+	w = performRequest(setupRouter(), "GET", "/api/health", nil, map[string]string{"Origin": "http://localhost:3000"})
+	if got := w.Header().Get("Access-Control-Allow-Origin"); got != "*" {
+		t.Errorf("Expected any origin to be allowed with *, got %q", got)
+	}
+	if got := w.Header().Get("Access-Control-Allow-Credentials"); got != "" {
+		t.Errorf("Expected no credentials with a wildcard origin, got %q", got)
+	}
+}
+
+func TestAnalyzeURLValidation(t *testing.T) {
+	r := setupTestServer(t)
+	site := newTestSite(t)
+
+	w := performRequest(r, "POST", "/api/analyze", gin.H{"url": "javascript:alert(1)"}, nil)
+	if w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), `scheme \"javascript\" is not supported`) {
+		t.Errorf("Expected 400 naming the scheme, got %d: %s", w.Code, w.Body)
+	}
+
+	seoAnalyzer.SetAllowPrivateTargets(false)
+	w = performRequest(r, "POST", "/api/analyze", gin.H{"url": site.URL}, nil)
+	if w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), "not publicly routable") {
+		t.Errorf("Expected 400 for a loopback target, got %d: %s", w.Code, w.Body)
+	}
+}
+
+func TestAnalyzeNonHTML(t *testing.T) {
+	r := setupTestServer(t)
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"status": "ok"}`)
+	}))
+	defer api.Close()
+
+	target := api.URL + "/status.json"
+	requests := []struct {
+		method, path string
+		body         interface{}
+	}{
+		{"POST", "/api/analyze", gin.H{"url": target}},
+		{"POST", "/api/analyze/quick", gin.H{"url": target}},
+		{"GET", "/api/analyze/section/title?url=" + target, nil},
+		{"GET", "/api/report?format=html&url=" + target, nil},
+	}
+	for _, req := range requests {
+		w := performRequest(r, req.method, req.path, req.body, nil)
+		if w.Code != http.StatusUnprocessableEntity || !strings.Contains(w.Body.String(), "not an HTML page") {
+			t.Errorf("%s %s: expected 422 for a JSON endpoint, got %d: %s", req.method, req.path, w.Code, w.Body)
+		}
+	}
+
+	// Every analysis endpoint maps errors the same way
+	seoAnalyzer.SetMaintenanceMode(true)
+	defer seoAnalyzer.SetMaintenanceMode(false)
+	for _, req := range requests {
+		if w := performRequest(r, req.method, req.path, req.body, nil); w.Code != http.StatusServiceUnavailable {
+			t.Errorf("%s %s: expected 503 in maintenance mode, got %d: %s", req.method, req.path, w.Code, w.Body)
+		}
+	}
+}
+
+func TestResetStatistics(t *testing.T) {
+	t.Setenv("ADMIN_API_KEY", "secret")
+	r := setupTestServer(t)
+	site := newTestSite(t)
+	auth := map[string]string{"Authorization": "Bearer secret"}
+
+	if w := performRequest(r, "POST", "/api/analyze", gin.H{"url": site.URL}, nil); w.Code != http.StatusOK {
+		t.Fatalf("Expected 200 from analyze, got %d: %s", w.Code, w.Body)
+	}
+	if seoAnalyzer.GetStats().GetCurrentStats().AnalysisRequests == 0 {
+		t.Fatal("Expected the analysis to be tracked")
+	}
+
+	if w := performRequest(r, "POST", "/api/statistics/reset", nil, nil); w.Code != http.StatusUnauthorized {
+		t.Errorf("Expected 401 without admin key, got %d", w.Code)
+	}
+	if w := performRequest(r, "POST", "/api/statistics/reset", gin.H{"month": "last month"}, auth); w.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for an invalid month, got %d: %s", w.Code, w.Body)
+	}
+
+	w := performRequest(r, "POST", "/api/statistics/reset", nil, auth)
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"reset":"all"`) {
+		t.Fatalf("Expected 200 confirming the reset, got %d: %s", w.Code, w.Body)
+	}
+	if stats := seoAnalyzer.GetStats().GetCurrentStats(); stats.AnalysisRequests != 0 {
+		t.Errorf("Expected statistics to be cleared, got %+v", stats)
+	}
+
+	month := time.Now().Format("2006-01")
+	w = performRequest(r, "POST", "/api/statistics/reset", gin.H{"month": month}, auth)
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), month) {
+		t.Errorf("Expected 200 confirming the month reset, got %d: %s", w.Code, w.Body)
+	}
+}
+
+func TestAnalyzeFailuresTracked(t *testing.T) {
+	r := setupTestServer(t)
+	seoAnalyzer.SetNegativeCacheTTL(0)
+	site := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/missing":
+			http.NotFound(w, r)
+		case "/data.json":
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprint(w, `{}`)
+		default:
+			w.Header().Set("Content-Type", "text/html")
+			fmt.Fprint(w, `<html><head><title>OK</title></head><body></body></html>`)
+		}
+	}))
+	defer site.Close()
+	closed := httptest.NewServer(http.NotFoundHandler())
+	closed.Close()
+
+	for _, target := range []string{site.URL + "/missing", site.URL + "/data.json", closed.URL, site.URL + "/"} {
+		performRequest(r, "POST", "/api/analyze", gin.H{"url": target}, nil)
+	}
+	// Refused requests aren't analyses
+	seoAnalyzer.SetAllowedDomains([]string{"example.com"})
+	performRequest(r, "POST", "/api/analyze", gin.H{"url": site.URL + "/"}, nil)
+
+	stats := seoAnalyzer.GetStats().GetCurrentStats()
+	if stats.AnalysisRequests != 4 || stats.ErrorCount != 3 {
+		t.Errorf("Expected 4 analyses with 3 errors, got %d with %d errors", stats.AnalysisRequests, stats.ErrorCount)
+	}
+}
+
+func TestErrorRate(t *testing.T) {
+	for _, tt := range []struct {
+		errors, requests int
+		want             float64
+	}{
+		{0, 0, 0},
+		{3, 0, 0},
+		{0, 4, 0},
+		{1, 4, 25},
+		{5, 5, 100},
+	} {
+		if got := errorRate(tt.errors, tt.requests); got != tt.want {
+			t.Errorf("errorRate(%d, %d) = %v, want %v", tt.errors, tt.requests, got, tt.want)
+		}
+	}
+
+	r := setupTestServer(t)
+	w := performRequest(r, "GET", "/api/statistics", nil, nil)
+	var response struct {
+		ErrorRate float64 `json:"errorRate"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil || response.ErrorRate != 0 {
+		t.Errorf("Expected a 0%% error rate without requests, got %s", w.Body.String())
+	}
+
+	seoAnalyzer.SetNegativeCacheTTL(0)
+	closed := httptest.NewServer(http.NotFoundHandler())
+	closed.Close()
+	for i := 0; i < 2; i++ {
+		performRequest(r, "POST", "/api/analyze", gin.H{"url": closed.URL}, nil)
+	}
+	w = performRequest(r, "GET", "/api/statistics", nil, nil)
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil || response.ErrorRate != 100 {
+		t.Errorf("Expected a 100%% error rate when every analysis failed, got %s", w.Body.String())
+	}
+}
+
+// countryOf is a stats.CountryLookup placing every address in one country
+type countryOf string
+
+func (c countryOf) Country(net.IP) string { return string(c) }
+
+func TestStatisticsCountries(t *testing.T) {
+	r := setupTestServer(t)
+	var response map[string]interface{}
+	w := performRequest(r, "GET", "/api/statistics", nil, nil)
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to parse response: %v", err)
+	}
+	if _, ok := response["countries"]; ok {
+		t.Errorf("Expected no countries without GeoIP, got %s", w.Body.String())
+	}
+
+	// Visitors already counted this month aren't placed in a country
+	seoAnalyzer.GetStats().SetCountryLookup(countryOf("NL"))
+	req := httptest.NewRequest("GET", "/api/health", nil)
+	req.RemoteAddr = "203.0.113.7:4321"
+	r.ServeHTTP(httptest.NewRecorder(), req)
+	w = performRequest(r, "GET", "/api/statistics", nil, nil)
+	response = nil
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to parse response: %v", err)
+	}
+	if countries, _ := response["countries"].(map[string]interface{}); len(countries) != 1 || countries["NL"] != 1.0 {
+		t.Errorf("Expected one visitor from NL, got %s", w.Body.String())
+	}
+}
+
+func TestAnalyzeTrackedOnce(t *testing.T) {
+	r := setupTestServer(t)
+	site := newTestSite(t)
+
+	if w := performRequest(r, "POST", "/api/analyze", gin.H{"url": site.URL}, nil); w.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	current := seoAnalyzer.GetStats().GetCurrentStats()
+	if _, ok := current.PopularUrls["/api/analyze"]; ok {
+		t.Errorf("Expected the API endpoint not to be tracked, got %v", current.PopularUrls)
+	}
+	if current.PopularUrls[site.URL+"/"] != 1 || current.TotalRequests != 1 {
+		t.Errorf("Expected one analysis of the page, got %d requests and %v", current.TotalRequests, current.PopularUrls)
+	}
+
+	w := performRequest(r, "GET", "/api/statistics", nil, nil)
+	var response struct {
+		TotalRequests int            `json:"totalRequests"`
+		PopularUrls   map[string]int `json:"popularUrls"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to parse response: %v", err)
+	}
+	if _, ok := response.PopularUrls["/api/analyze"]; ok || response.TotalRequests != 1 {
+		t.Errorf("Expected one analysis and no API endpoint, got %s", w.Body.String())
+	}
+}
+
+func TestStatisticsPopularURLsLimit(t *testing.T) {
+	r := setupTestServer(t)
+	backend := seoAnalyzer.GetStats()
+	for i := 0; i < popularURLsLimit+5; i++ {
+		for j := 0; j <= i; j++ {
+			backend.TrackAnalysis(fmt.Sprintf("https://example.com/%d", i), 10, false)
+		}
+	}
+
+	w := performRequest(r, "GET", "/api/statistics", nil, nil)
+	var response struct {
+		PopularUrls map[string]int `json:"popularUrls"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to parse response: %v", err)
+	}
+	if len(response.PopularUrls) != popularURLsLimit {
+		t.Fatalf("Expected %d popular URLs, got %d", popularURLsLimit, len(response.PopularUrls))
+	}
+	// URL i was analyzed i+1 times, so the first five are left out
+	for i := 0; i < 5; i++ {
+		if _, ok := response.PopularUrls[fmt.Sprintf("https://example.com/%d", i)]; ok {
+			t.Errorf("Expected https://example.com/%d not to be among the most analyzed", i)
+		}
+	}
+}
+
+func TestAnalyzeCacheHeaderFollowsOptions(t *testing.T) {
+	r := setupTestServer(t)
+	site := newTestSite(t)
+
+	for _, tt := range []struct {
+		body gin.H
+		want string
+	}{
+		{gin.H{"url": site.URL}, "MISS"},
+		{gin.H{"url": site.URL}, "HIT"},
+		// Another device is another cache entry
+		{gin.H{"url": site.URL, "device": "mobile"}, "MISS"},
+		{gin.H{"url": site.URL, "device": "mobile"}, "HIT"},
+		{gin.H{"url": site.URL, "device": "mobile", "refresh": true}, "BYPASS"},
+	} {
+		w := performRequest(r, "POST", "/api/analyze", tt.body, nil)
+		if w.Code != http.StatusOK || w.Header().Get("X-Cache") != tt.want {
+			t.Errorf("%v: expected 200 with X-Cache %s, got %d with %q", tt.body, tt.want, w.Code, w.Header().Get("X-Cache"))
+		}
+	}
+
+	// A cold entry for the section's options isn't reported as a hit
+	w := performRequest(r, "GET", "/api/analyze/section/title?url="+site.URL+"/other", nil, nil)
+	if w.Header().Get("X-Cache") != "MISS" {
+		t.Errorf("Expected a miss for an unanalyzed page, got %q", w.Header().Get("X-Cache"))
+	}
+}
+
+func TestBatchAndCompareTracked(t *testing.T) {
+	r := setupTestServer(t)
+	seoAnalyzer.SetNegativeCacheTTL(0)
+	site := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html")
+		fmt.Fprint(w, `<html><head><title>OK</title></head><body></body></html>`)
+	}))
+	defer site.Close()
+
+	w := performRequest(r, "POST", "/api/analyze-batch", gin.H{"urls": []string{site.URL + "/", site.URL + "/missing"}}, nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected 200 for the batch, got %d: %s", w.Code, w.Body)
+	}
+	current := seoAnalyzer.GetStats().GetCurrentStats()
+	if current.TotalRequests != 2 || current.ErrorCount != 1 {
+		t.Errorf("Expected the batch to count 2 analyses with 1 error, got %d with %d", current.TotalRequests, current.ErrorCount)
+	}
+
+	// Both sides of a failed comparison are counted
+	w = performRequest(r, "POST", "/api/compare", gin.H{"urlA": site.URL + "/", "urlB": site.URL + "/missing"}, nil)
+	if w.Code == http.StatusOK {
+		t.Fatalf("Expected the comparison to fail, got %s", w.Body)
+	}
+	current = seoAnalyzer.GetStats().GetCurrentStats()
+	if current.TotalRequests != 4 || current.ErrorCount != 2 {
+		t.Errorf("Expected 4 analyses with 2 errors after the comparison, got %d with %d", current.TotalRequests, current.ErrorCount)
+	}
+}
+
+func TestBatchAndCompareValidateURLs(t *testing.T) {
+	r := setupTestServer(t)
+
+	w := performRequest(r, "POST", "/api/analyze-batch", gin.H{"urls": []string{"https://example.com/", "ftp://example.com/"}}, nil)
+	if w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), "urls[1]: invalid URL: scheme") {
+		t.Errorf("Expected a 400 naming the ftp URL, got %d: %s", w.Code, w.Body)
+	}
+
+	w = performRequest(r, "POST", "/api/compare", gin.H{"urlA": "https://example.com/", "urlB": "javascript:alert(1)"}, nil)
+	if w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), "urlB: invalid URL: scheme") {
+		t.Errorf("Expected a 400 naming urlB, got %d: %s", w.Code, w.Body)
+	}
+
+	w = performRequest(r, "POST", "/api/jobs", gin.H{"url": "not a url"}, nil)
+	if w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), "url: invalid URL") {
+		t.Errorf("Expected a 400 for the job URL, got %d: %s", w.Code, w.Body)
+	}
+}
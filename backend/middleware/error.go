@@ -1,11 +1,12 @@
 package middleware
 
 import (
-	"log"
+	"fmt"
 	"net/http"
 	"runtime/debug"
 
 	"github.com/gin-gonic/gin"
+	"github.com/seo-optimizer/backend/logging"
 )
 
 // ErrorHandler middleware recovers from any panics and handles errors
@@ -14,7 +15,7 @@ func ErrorHandler() gin.HandlerFunc {
 		defer func() {
 			if err := recover(); err != nil {
 				// Log the error and stack trace
-				log.Printf("Panic recovered: %v\nStack trace:\n%s", err, debug.Stack())
+				logging.Error("Panic recovered", "error", fmt.Sprint(err), "stack", string(debug.Stack()))
 
 				// Return a 500 error to the client
 				c.JSON(http.StatusInternalServerError, gin.H{
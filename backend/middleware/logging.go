@@ -0,0 +1,44 @@
+package middleware
+
+import (
+	"bytes"
+	"io"
+
+	"github.com/gin-gonic/gin"
+	"github.com/seo-optimizer/backend/logging"
+)
+
+// maxLoggedBody bounds how much of a request body is logged
+const maxLoggedBody = 4 << 10
+
+// RequestLogger logs each request's headers and JSON body for debugging.
+// Sensitive headers and fields are redacted before anything is written.
+func RequestLogger() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var body []byte
+		if c.Request.Body != nil {
+			data, err := io.ReadAll(io.LimitReader(c.Request.Body, maxLoggedBody+1))
+			if err == nil {
+				// Restore the body for the handlers
+				c.Request.Body = struct {
+					io.Reader
+					io.Closer
+				}{io.MultiReader(bytes.NewReader(data), c.Request.Body), c.Request.Body}
+				body = data
+			}
+		}
+
+		logged := "-"
+		if len(body) > maxLoggedBody {
+			logged = "[body too large to log]"
+		} else if len(body) > 0 {
+			logged = string(logging.RedactJSON(body))
+		}
+
+		logging.Info("Request",
+			"method", c.Request.Method, "url", logging.RedactURL(c.Request.URL.String()), "ip", c.ClientIP(),
+			"headers", logging.RedactHeaders(c.Request.Header), "body", logged)
+
+		c.Next()
+	}
+}
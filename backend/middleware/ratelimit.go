@@ -1,7 +1,12 @@
 package middleware
 
 import (
+	"fmt"
+	"math"
+	"net"
 	"net/http"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 
@@ -15,6 +20,12 @@ type RateLimiter struct {
 	rate           float64  // tokens per second
 	bucketSize     float64  // maximum tokens
 	refillInterval time.Duration
+	idleTTL        time.Duration // clients idle this long are forgotten
+	now            func() time.Time
+	stop           chan struct{}
+	stopOnce       sync.Once
+	allowlist      []*net.IPNet // never rate limited
+	denylist       []*net.IPNet // always refused
 }
 
 func NewRateLimiter(rate float64, bucketSize float64) *RateLimiter {
@@ -24,15 +35,131 @@ func NewRateLimiter(rate float64, bucketSize float64) *RateLimiter {
 		rate:           rate,
 		bucketSize:     bucketSize,
 		refillInterval: time.Second,
+		now:            time.Now,
+		stop:           make(chan struct{}),
 	}
 }
 
+// NewRateLimiterWithCleanup returns a rate limiter that every interval
+// forgets clients that have not made a request for idleTTL, so the number of
+// tracked IPs doesn't grow forever. A forgotten client starts again with a
+// full bucket, so idleTTL should be at least as long as a bucket takes to
+// refill. Call Stop to end the cleanup.
+func NewRateLimiterWithCleanup(rate float64, bucketSize float64, interval, idleTTL time.Duration) *RateLimiter {
+	rl := NewRateLimiter(rate, bucketSize)
+	rl.idleTTL = idleTTL
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				rl.evictIdle()
+			case <-rl.stop:
+				return
+			}
+		}
+	}()
+	return rl
+}
+
+// Stop ends the background cleanup started by NewRateLimiterWithCleanup
+func (rl *RateLimiter) Stop() {
+	rl.stopOnce.Do(func() { close(rl.stop) })
+}
+
+// evictIdle removes clients whose last request is older than idleTTL
+func (rl *RateLimiter) evictIdle() {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	cutoff := rl.now().Add(-rl.idleTTL)
+	for ip, last := range rl.lastRefill {
+		if last.Before(cutoff) {
+			delete(rl.lastRefill, ip)
+			delete(rl.tokens, ip)
+		}
+	}
+}
+
+// SetIPLists sets the client ranges that skip rate limiting entirely and
+// those that are refused with 403. The denylist wins when a client is in both.
+func (rl *RateLimiter) SetIPLists(allow, deny []*net.IPNet) {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+	rl.allowlist = allow
+	rl.denylist = deny
+}
+
+// ParseCIDRs parses a comma-separated list of CIDR ranges such as
+// "10.0.0.0/8,2001:db8::/32". A bare IP address is taken as a single host.
+func ParseCIDRs(list string) ([]*net.IPNet, error) {
+	var nets []*net.IPNet
+	for _, entry := range strings.Split(list, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		if !strings.Contains(entry, "/") {
+			ip := net.ParseIP(entry)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid IP address %q", entry)
+			}
+			bits := 8 * net.IPv6len
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 8*net.IPv4len
+			}
+			nets = append(nets, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+		_, ipNet, err := net.ParseCIDR(entry)
+		if err != nil {
+			return nil, fmt.Errorf("invalid CIDR range %q", entry)
+		}
+		nets = append(nets, ipNet)
+	}
+	return nets, nil
+}
+
+// containsIP reports whether any of nets contains ip
+func containsIP(nets []*net.IPNet, ip net.IP) bool {
+	for _, n := range nets {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// clients returns how many clients are currently tracked
+func (rl *RateLimiter) clients() int {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+	return len(rl.lastRefill)
+}
+
 func (rl *RateLimiter) RateLimit() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		ip := c.ClientIP()
 
 		rl.mu.Lock()
-		now := time.Now()
+		if addr := net.ParseIP(ip); addr != nil {
+			if containsIP(rl.denylist, addr) {
+				rl.mu.Unlock()
+				c.JSON(http.StatusForbidden, gin.H{
+					"error": "Access denied",
+				})
+				c.Abort()
+				return
+			}
+			if containsIP(rl.allowlist, addr) {
+				rl.mu.Unlock()
+				c.Next()
+				return
+			}
+		}
+		now := rl.now()
 
 		// Initialize if first request
 		if _, exists := rl.lastRefill[ip]; !exists {
@@ -48,9 +175,12 @@ func (rl *RateLimiter) RateLimit() gin.HandlerFunc {
 
 		// Check if we have enough tokens
 		if rl.tokens[ip] < 1 {
+			retryAfter := rl.retryAfter(rl.tokens[ip])
 			rl.mu.Unlock()
+			c.Header("Retry-After", strconv.Itoa(retryAfter))
 			c.JSON(http.StatusTooManyRequests, gin.H{
-				"error": "Rate limit exceeded. Please try again later.",
+				"error":      "Rate limit exceeded. Please try again later.",
+				"retryAfter": retryAfter,
 			})
 			c.Abort()
 			return
@@ -64,6 +194,20 @@ func (rl *RateLimiter) RateLimit() gin.HandlerFunc {
 	}
 }
 
+// retryAfter returns the whole seconds until a bucket holding tokens has
+// refilled to one token
+func (rl *RateLimiter) retryAfter(tokens float64) int {
+	if rl.rate <= 0 {
+		return int(rl.refillInterval.Seconds())
+	}
+	wait := time.Duration((1 - tokens) / rl.rate * float64(rl.refillInterval))
+	seconds := int(math.Ceil(wait.Seconds()))
+	if seconds < 1 {
+		seconds = 1
+	}
+	return seconds
+}
+
 func min(a, b float64) float64 {
 	if a < b {
 		return a
@@ -0,0 +1,142 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestRateLimiterEvictsIdleClients(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	clock := time.Now()
+	rl := NewRateLimiterWithCleanup(10, 10, time.Hour, 10*time.Minute)
+	defer rl.Stop()
+	rl.now = func() time.Time { return clock }
+
+	r := gin.New()
+	r.Use(rl.RateLimit())
+	r.GET("/", func(c *gin.Context) { c.Status(http.StatusOK) })
+	request := func(ip string) {
+		req := httptest.NewRequest("GET", "/", nil)
+		req.RemoteAddr = ip + ":1234"
+		r.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	for i := 0; i < 500; i++ {
+		request(fmt.Sprintf("10.0.%d.%d", i/256, i%256))
+	}
+	clock = clock.Add(5 * time.Minute)
+	request("192.0.2.1")
+	if n := rl.clients(); n != 501 {
+		t.Fatalf("Expected 501 tracked clients, got %d", n)
+	}
+
+	// Only the client seen in the last 10 minutes survives
+	clock = clock.Add(6 * time.Minute)
+	rl.evictIdle()
+	if n := rl.clients(); n != 1 {
+		t.Errorf("Expected idle clients to be evicted, %d remain", n)
+	}
+}
+
+func TestRateLimiterRetryAfter(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	clock := time.Now()
+	// Half a token per second: an empty bucket refills one token in 2s
+	rl := NewRateLimiter(0.5, 1)
+	rl.now = func() time.Time { return clock }
+
+	r := gin.New()
+	r.Use(rl.RateLimit())
+	r.GET("/", func(c *gin.Context) { c.Status(http.StatusOK) })
+	request := func() *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+		return w
+	}
+
+	if w := request(); w.Code != http.StatusOK {
+		t.Fatalf("Expected the first request to pass, got %d", w.Code)
+	}
+	w := request()
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("Expected 429, got %d", w.Code)
+	}
+	if got := w.Header().Get("Retry-After"); got != "2" {
+		t.Errorf("Expected Retry-After: 2, got %q", got)
+	}
+	if !strings.Contains(w.Body.String(), `"retryAfter":2`) {
+		t.Errorf("Expected retryAfter in the body, got %s", w.Body)
+	}
+
+	// Half a second later a token is 1.5s away, rounded up
+	clock = clock.Add(500 * time.Millisecond)
+	if got := request().Header().Get("Retry-After"); got != "2" {
+		t.Errorf("Expected Retry-After to round up to 2, got %q", got)
+	}
+	clock = clock.Add(1200 * time.Millisecond)
+	if got := request().Header().Get("Retry-After"); got != "1" {
+		t.Errorf("Expected Retry-After: 1, got %q", got)
+	}
+}
+
+func TestRateLimiterIPLists(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	// One request per client, never refilled
+	rl := NewRateLimiter(0, 1)
+	allow, err := ParseCIDRs("192.0.2.0/24, 2001:db8:1::/48")
+	if err != nil {
+		t.Fatalf("Failed to parse allowlist: %v", err)
+	}
+	deny, err := ParseCIDRs("198.51.100.7,2001:db8:bad::/48")
+	if err != nil {
+		t.Fatalf("Failed to parse denylist: %v", err)
+	}
+	rl.SetIPLists(allow, deny)
+
+	r := gin.New()
+	r.Use(rl.RateLimit())
+	r.GET("/", func(c *gin.Context) { c.Status(http.StatusOK) })
+	request := func(remoteAddr string) int {
+		req := httptest.NewRequest("GET", "/", nil)
+		req.RemoteAddr = remoteAddr
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	for _, tt := range []struct {
+		remoteAddr string
+		want       []int
+	}{
+		{"192.0.2.10:1234", []int{http.StatusOK, http.StatusOK, http.StatusOK}},
+		{"[2001:db8:1::5]:1234", []int{http.StatusOK, http.StatusOK, http.StatusOK}},
+		{"198.51.100.7:1234", []int{http.StatusForbidden, http.StatusForbidden}},
+		{"[2001:db8:bad::1]:1234", []int{http.StatusForbidden, http.StatusForbidden}},
+		{"198.51.100.8:1234", []int{http.StatusOK, http.StatusTooManyRequests}},
+		{"[2001:db8:2::1]:1234", []int{http.StatusOK, http.StatusTooManyRequests}},
+	} {
+		for i, want := range tt.want {
+			if got := request(tt.remoteAddr); got != want {
+				t.Errorf("%s request %d: expected %d, got %d", tt.remoteAddr, i+1, want, got)
+			}
+		}
+	}
+}
+
+func TestParseCIDRs(t *testing.T) {
+	nets, err := ParseCIDRs("")
+	if err != nil || len(nets) != 0 {
+		t.Errorf("Expected no ranges for an empty list, got %v, %v", nets, err)
+	}
+	for _, bad := range []string{"10.0.0.0/33", "not-an-ip", "2001:db8::/129"} {
+		if _, err := ParseCIDRs(bad); err == nil {
+			t.Errorf("Expected %q to be rejected", bad)
+		}
+	}
+}
@@ -3,17 +3,15 @@ package middleware
 import (
 	"net/http"
 	"strings"
-	"time"
 
 	"github.com/seo-optimizer/backend/logging"
 )
 
-// StatsMiddleware tracks various statistics about requests
+// StatsMiddleware tracks the visitor of every request. Analyses are tracked
+// by the handler that runs them, with the analyzed URL.
 func StatsMiddleware(stats *logging.Statistics) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			start := time.Now()
-
 			// Get the real IP address
 			ip := r.Header.Get("X-Real-IP")
 			if ip == "" {
@@ -28,17 +26,6 @@ func StatsMiddleware(stats *logging.Statistics) func(http.Handler) http.Handler
 
 			// Call the next handler
 			next.ServeHTTP(w, r)
-
-			// Only track analysis requests
-			if r.URL.Path == "/api/analyze" && r.Method == "POST" {
-				loadTime := float64(time.Since(start).Milliseconds())
-				stats.TrackAnalysis(r.URL.String(), loadTime, false) // We could add error tracking here
-			}
-
-			// Periodically save statistics (every 100 requests or every hour)
-			if stats.GetStatistics()["totalRequests"].(int)%100 == 0 {
-				go stats.Save() // Save asynchronously to not block the request
-			}
 		})
 	}
 } 
\ No newline at end of file
@@ -0,0 +1,125 @@
+package publisher
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/seo-optimizer/backend/analyzer"
+)
+
+// fakeNATSServer accepts one connection, greets it and returns the
+// CONNECT options and the first published message
+func fakeNATSServer(t *testing.T) (string, <-chan string, <-chan []byte) {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	connects := make(chan string, 1)
+	messages := make(chan []byte, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		fmt.Fprint(conn, "INFO {\"server_id\":\"test\"}\r\n")
+
+		reader := bufio.NewReader(conn)
+		for {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				return
+			}
+			switch {
+			case strings.HasPrefix(line, "CONNECT "):
+				connects <- strings.TrimSpace(strings.TrimPrefix(line, "CONNECT "))
+				// Make sure the client answers keep-alive pings
+				fmt.Fprint(conn, "PING\r\n")
+			case strings.HasPrefix(line, "PUB "):
+				var subject string
+				var size int
+				fmt.Sscanf(line, "PUB %s %d", &subject, &size)
+				payload := make([]byte, size+2)
+				if _, err := io.ReadFull(reader, payload); err != nil {
+					return
+				}
+				messages <- append([]byte(subject+" "), payload[:size]...)
+			}
+		}
+	}()
+
+	return "nats://analyzer:secret@" + listener.Addr().String(), connects, messages
+}
+
+func TestNATSPublisher(t *testing.T) {
+	serverURL, connects, messages := fakeNATSServer(t)
+
+	p, err := NewNATSPublisher(serverURL, "seo.results")
+	if err != nil {
+		t.Fatalf("Failed to create publisher: %v", err)
+	}
+	defer p.Close()
+
+	msg := analyzer.ResultMessage{
+		URL:         "https://example.com",
+		CompletedAt: time.Now(),
+		Analysis:    &analyzer.SEOAnalysis{URL: "https://example.com", Score: 87.5},
+	}
+	if err := p.Publish(context.Background(), msg); err != nil {
+		t.Fatalf("Failed to publish: %v", err)
+	}
+
+	select {
+	case options := <-connects:
+		if !strings.Contains(options, `"user":"analyzer"`) || !strings.Contains(options, `"pass":"secret"`) {
+			t.Errorf("Expected credentials in CONNECT, got %s", options)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Expected a CONNECT")
+	}
+
+	select {
+	case raw := <-messages:
+		subject, payload, _ := strings.Cut(string(raw), " ")
+		if subject != "seo.results" {
+			t.Errorf("Expected subject seo.results, got %q", subject)
+		}
+		var received struct {
+			URL      string `json:"url"`
+			Analysis struct {
+				Score float64 `json:"score"`
+			} `json:"analysis"`
+		}
+		if err := json.Unmarshal([]byte(payload), &received); err != nil {
+			t.Fatalf("Invalid payload %q: %v", payload, err)
+		}
+		if received.URL != "https://example.com" || received.Analysis.Score != 87.5 {
+			t.Errorf("Expected the full result in the message, got %+v", received)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Expected a published message")
+	}
+}
+
+func TestNewNATSPublisherValidation(t *testing.T) {
+	if _, err := NewNATSPublisher("http://localhost:4222", "subject"); err == nil {
+		t.Error("Expected a non-nats URL to be rejected")
+	}
+	if _, err := NewNATSPublisher("nats://localhost", "bad subject"); err == nil {
+		t.Error("Expected a subject with spaces to be rejected")
+	}
+	p, err := NewNATSPublisher("nats://localhost", "subject")
+	if err != nil || p.addr != "localhost:4222" {
+		t.Errorf("Expected the default NATS port, got %+v (%v)", p, err)
+	}
+}
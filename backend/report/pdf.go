@@ -0,0 +1,297 @@
+package report
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+)
+
+// A4 page geometry in points
+const (
+	pageWidth    = 595.0
+	pageHeight   = 842.0
+	pageMargin   = 50.0
+	footerHeight = 20.0
+	contentWidth = pageWidth - 2*pageMargin
+	// listIndent is where the text of a list item starts after its label
+	listIndent = 20.0
+)
+
+// pdfFont is one of the standard Type 1 fonts every PDF reader has, so no
+// font data needs to be embedded
+type pdfFont int
+
+const (
+	fontRegular pdfFont = iota
+	fontBold
+)
+
+func (f pdfFont) name() string {
+	if f == fontBold {
+		return "/F2"
+	}
+	return "/F1"
+}
+
+// Glyph widths of printable ASCII (32 to 126) in thousandths of the font
+// size, from the Helvetica and Helvetica-Bold metrics
+var (
+	helveticaWidths = [95]int{
+		278, 278, 355, 556, 556, 889, 667, 191, 333, 333, 389, 584, 278, 333, 278, 278,
+		556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 278, 278, 584, 584, 584, 556,
+		1015, 667, 667, 722, 722, 667, 611, 778, 722, 278, 500, 667, 556, 833, 722, 778,
+		667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 278, 278, 278, 469, 556,
+		333, 556, 556, 500, 556, 556, 278, 556, 556, 222, 222, 500, 222, 833, 556, 556,
+		556, 556, 333, 500, 278, 556, 500, 722, 500, 500, 500, 334, 260, 334, 584,
+	}
+	helveticaBoldWidths = [95]int{
+		278, 333, 474, 556, 556, 889, 722, 238, 333, 333, 389, 584, 278, 333, 278, 278,
+		556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 333, 333, 584, 584, 584, 611,
+		975, 722, 722, 722, 722, 667, 611, 778, 722, 278, 556, 722, 611, 833, 722, 778,
+		667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 333, 278, 333, 584, 556,
+		333, 556, 611, 556, 611, 556, 333, 611, 611, 278, 278, 556, 278, 889, 611, 611,
+		611, 611, 389, 556, 333, 611, 556, 778, 556, 556, 500, 389, 280, 389, 584,
+	}
+)
+
+// textWidth returns the width of WinAnsi-encoded text in points
+func textWidth(font pdfFont, size float64, text []byte) float64 {
+	widths := &helveticaWidths
+	if font == fontBold {
+		widths = &helveticaBoldWidths
+	}
+	total := 0
+	for _, c := range text {
+		if c >= 32 && c <= 126 {
+			total += widths[c-32]
+		} else {
+			total += 556
+		}
+	}
+	return float64(total) * size / 1000
+}
+
+// winAnsiPunctuation maps typographic characters outside Latin-1 to their
+// WinAnsiEncoding codes
+var winAnsiPunctuation = map[rune]byte{
+	'€': 0x80, '…': 0x85, '‘': 0x91, '’': 0x92, '“': 0x93, '”': 0x94,
+	'•': 0x95, '–': 0x96, '—': 0x97, '™': 0x99,
+}
+
+// encodeWinAnsi converts s to the encoding of the standard fonts, replacing
+// characters they can't show with '?'
+func encodeWinAnsi(s string) []byte {
+	encoded := make([]byte, 0, len(s))
+	for _, r := range s {
+		switch {
+		case r == '\t' || r == '\n' || r == '\r':
+			encoded = append(encoded, ' ')
+		case r >= 32 && r <= 126, r >= 160 && r <= 255:
+			encoded = append(encoded, byte(r))
+		default:
+			if c, ok := winAnsiPunctuation[r]; ok {
+				encoded = append(encoded, c)
+			} else {
+				encoded = append(encoded, '?')
+			}
+		}
+	}
+	return encoded
+}
+
+// wrapText breaks text into lines no wider than width, splitting words that
+// are too long on their own (such as URLs)
+func wrapText(font pdfFont, size, width float64, text []byte) [][]byte {
+	var lines [][]byte
+	var line []byte
+	for _, word := range bytes.Fields(text) {
+		candidate := word
+		if len(line) > 0 {
+			candidate = append(append(append([]byte{}, line...), ' '), word...)
+		}
+		if textWidth(font, size, candidate) <= width {
+			line = candidate
+			continue
+		}
+		if len(line) > 0 {
+			lines = append(lines, line)
+			line = nil
+		}
+		for textWidth(font, size, word) > width {
+			cut := 1
+			for cut < len(word) && textWidth(font, size, word[:cut+1]) <= width {
+				cut++
+			}
+			lines = append(lines, word[:cut])
+			word = word[cut:]
+		}
+		line = word
+	}
+	if len(line) > 0 || len(lines) == 0 {
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// escapePDFString escapes text for a PDF literal string
+func escapePDFString(text []byte) string {
+	var escaped bytes.Buffer
+	for _, c := range text {
+		if c == '\\' || c == '(' || c == ')' {
+			escaped.WriteByte('\\')
+		}
+		escaped.WriteByte(c)
+	}
+	return escaped.String()
+}
+
+// pdfDocument lays out text top to bottom, starting a new page whenever the
+// current one is full
+type pdfDocument struct {
+	pages []*bytes.Buffer
+	y     float64
+}
+
+func newPDFDocument() *pdfDocument {
+	doc := &pdfDocument{}
+	doc.newPage()
+	return doc
+}
+
+func (d *pdfDocument) newPage() {
+	d.pages = append(d.pages, &bytes.Buffer{})
+	d.y = pageHeight - pageMargin
+}
+
+func (d *pdfDocument) page() *bytes.Buffer {
+	return d.pages[len(d.pages)-1]
+}
+
+// ensure starts a new page unless height points fit on the current one
+func (d *pdfDocument) ensure(height float64) {
+	if d.y-height < pageMargin+footerHeight {
+		d.newPage()
+	}
+}
+
+// space adds vertical space, which is dropped at the top of a page
+func (d *pdfDocument) space(height float64) {
+	if d.y < pageHeight-pageMargin {
+		d.y -= height
+	}
+}
+
+// lineHeight is the distance between baselines of text of the given size
+func lineHeight(size float64) float64 {
+	return size * 1.3
+}
+
+// drawText writes one line with its baseline at the current position
+func (d *pdfDocument) drawText(font pdfFont, size, x float64, text []byte) {
+	fmt.Fprintf(d.page(), "BT %s %s Tf %s %s Td (%s) Tj ET\n",
+		font.name(), formatNumber(size), formatNumber(x), formatNumber(d.y), escapePDFString(text))
+}
+
+// text writes a wrapped paragraph
+func (d *pdfDocument) text(font pdfFont, size float64, text string) {
+	height := lineHeight(size)
+	for _, line := range wrapText(font, size, contentWidth, encodeWinAnsi(text)) {
+		d.ensure(height)
+		d.y -= height
+		d.drawText(font, size, pageMargin, line)
+	}
+}
+
+// listItem writes a labelled entry with a hanging indent. Items that fit on
+// a page are not split across pages.
+func (d *pdfDocument) listItem(label, text string) {
+	const size = 11.0
+	height := lineHeight(size)
+	lines := wrapText(fontRegular, size, contentWidth-listIndent, encodeWinAnsi(text))
+	if total := height*float64(len(lines)) + 4; total <= pageHeight-2*pageMargin-footerHeight {
+		d.ensure(total)
+	}
+	for i, line := range lines {
+		d.ensure(height)
+		d.y -= height
+		if i == 0 {
+			d.drawText(fontRegular, size, pageMargin, encodeWinAnsi(label))
+		}
+		d.drawText(fontRegular, size, pageMargin+listIndent, line)
+	}
+	d.y -= 4
+}
+
+// scoreRow writes a category name with its 0-100 score as a bar and number
+func (d *pdfDocument) scoreRow(name string, score int) {
+	const size = 11.0
+	const barX, barWidth, barHeight = pageMargin + 150, 250.0, 8.0
+	height := lineHeight(size) + 4
+	d.ensure(height)
+	d.y -= height
+	if score < 0 {
+		score = 0
+	} else if score > 100 {
+		score = 100
+	}
+
+	d.drawText(fontRegular, size, pageMargin, encodeWinAnsi(name))
+	fmt.Fprintf(d.page(), "0.93 g %s %s %s %s re f\n",
+		formatNumber(barX), formatNumber(d.y), formatNumber(barWidth), formatNumber(barHeight))
+	fmt.Fprintf(d.page(), "0.16 0.48 0.89 rg %s %s %s %s re f 0 g\n",
+		formatNumber(barX), formatNumber(d.y), formatNumber(barWidth*float64(score)/100), formatNumber(barHeight))
+	value := []byte(strconv.Itoa(score))
+	d.drawText(fontBold, size, pageWidth-pageMargin-textWidth(fontBold, size, value), value)
+}
+
+// formatNumber formats a coordinate with at most two decimals
+func formatNumber(n float64) string {
+	return strconv.FormatFloat(math.Round(n*100)/100, 'f', -1, 64)
+}
+
+// write numbers the pages and writes the document
+func (d *pdfDocument) write(w io.Writer) error {
+	out := bufio.NewWriter(w)
+	var offset int
+	var offsets []int
+	emit := func(format string, args ...interface{}) {
+		n, _ := fmt.Fprintf(out, format, args...)
+		offset += n
+	}
+	object := func(body string) {
+		offsets = append(offsets, offset)
+		emit("%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	emit("%%PDF-1.4\n%%\xe2\xe3\xcf\xd3\n")
+	// Objects 1 to 4 are fixed; each page then takes a page and a content
+	// object
+	var kids bytes.Buffer
+	for i := range d.pages {
+		fmt.Fprintf(&kids, "%d 0 R ", 5+2*i)
+	}
+	object("<< /Type /Catalog /Pages 2 0 R >>")
+	object(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", bytes.TrimSpace(kids.Bytes()), len(d.pages)))
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>")
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>")
+	for i, content := range d.pages {
+		footer := []byte(fmt.Sprintf("Page %d of %d", i+1, len(d.pages)))
+		fmt.Fprintf(content, "0.4 g BT /F1 9 Tf %s %s Td (%s) Tj ET 0 g\n",
+			formatNumber(pageWidth-pageMargin-textWidth(fontRegular, 9, footer)), formatNumber(pageMargin), footer)
+
+		object(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %s %s] /Resources << /Font << /F1 3 0 R /F2 4 0 R >> >> /Contents %d 0 R >>",
+			formatNumber(pageWidth), formatNumber(pageHeight), 6+2*i))
+		object(fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", content.Len(), content.Bytes()))
+	}
+
+	xref := offset
+	emit("xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, o := range offsets {
+		emit("%010d 00000 n \n", o)
+	}
+	emit("trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+	return out.Flush()
+}
@@ -0,0 +1,153 @@
+// Package report renders analyses as shareable HTML and PDF reports.
+package report
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"time"
+
+	"github.com/seo-optimizer/backend/analyzer"
+)
+
+// Category is the score of one section of the analysis
+type Category struct {
+	Name  string
+	Score int
+}
+
+// Report is the data shown in a report, shared by the HTML and PDF output
+type Report struct {
+	URL             string
+	Score           float64
+	Grade           string
+	Generated       time.Time
+	Categories      []Category
+	Recommendations []string
+	Warnings        []string
+}
+
+// New builds the report of an analysis
+func New(analysis *analyzer.SEOAnalysis) Report {
+	return Report{
+		URL:       analysis.URL,
+		Score:     analysis.Score,
+		Grade:     analysis.Grade,
+		Generated: time.Now().UTC(),
+		// The sections that make up the overall score
+		Categories: []Category{
+			{"Title", analysis.Title.Score},
+			{"Meta tags", analysis.Meta.Score},
+			{"Headings", analysis.Headers.Score},
+			{"Content", analysis.Content.Score},
+			{"Performance", analysis.Performance.Score},
+			{"Links", analysis.Links.Score},
+		},
+		Recommendations: analysis.Recommendations,
+		Warnings:        analysis.Warnings,
+	}
+}
+
+// ScoreText formats the overall score with its grade, e.g. "87.5 (B)"
+func (r Report) ScoreText() string {
+	if r.Grade == "" {
+		return fmt.Sprintf("%.1f", r.Score)
+	}
+	return fmt.Sprintf("%.1f (%s)", r.Score, r.Grade)
+}
+
+var htmlTemplate = template.Must(template.New("report").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>SEO report for {{.URL}}</title>
+<style>
+body { font-family: Helvetica, Arial, sans-serif; max-width: 800px; margin: 2em auto; padding: 0 1em; color: #222; }
+h1 { margin-bottom: 0.2em; }
+.meta { color: #666; word-break: break-all; }
+.score { font-size: 2em; font-weight: bold; }
+table { border-collapse: collapse; width: 100%; }
+td { padding: 0.4em 0; }
+td.value { text-align: right; width: 4em; }
+.bar { background: #eee; height: 0.8em; }
+.bar div { background: #2a7ae2; height: 100%; }
+li { margin-bottom: 0.4em; }
+@media print { body { margin: 0; } li, tr { page-break-inside: avoid; } }
+</style>
+</head>
+<body>
+<h1>SEO report</h1>
+<p class="meta">{{.URL}}<br>Generated {{.Generated.Format "2006-01-02 15:04 MST"}}</p>
+<p class="score">{{.ScoreText}}</p>
+<h2>Categories</h2>
+<table>
+{{- range .Categories}}
+<tr><td>{{.Name}}</td><td><div class="bar"><div style="width: {{.Score}}%"></div></div></td><td class="value">{{.Score}}</td></tr>
+{{- end}}
+</table>
+<h2>Recommendations</h2>
+{{- if .Recommendations}}
+<ol>
+{{- range .Recommendations}}
+<li>{{.}}</li>
+{{- end}}
+</ol>
+{{- else}}
+<p>No recommendations.</p>
+{{- end}}
+{{- if .Warnings}}
+<h2>Warnings</h2>
+<ul>
+{{- range .Warnings}}
+<li>{{.}}</li>
+{{- end}}
+</ul>
+{{- end}}
+</body>
+</html>
+`))
+
+// WriteHTML writes the report as a standalone web page
+func (r Report) WriteHTML(w io.Writer) error {
+	return htmlTemplate.Execute(w, r)
+}
+
+// WritePDF writes the report as an A4 PDF, continuing long recommendation
+// lists on further pages
+func (r Report) WritePDF(w io.Writer) error {
+	doc := newPDFDocument()
+	doc.text(fontBold, 22, "SEO report")
+	doc.space(4)
+	doc.text(fontRegular, 10, r.URL)
+	doc.text(fontRegular, 10, "Generated "+r.Generated.Format("2006-01-02 15:04 MST"))
+	doc.space(12)
+	doc.text(fontBold, 28, r.ScoreText())
+
+	doc.space(16)
+	doc.text(fontBold, 16, "Categories")
+	doc.space(4)
+	for _, category := range r.Categories {
+		doc.scoreRow(category.Name, category.Score)
+	}
+
+	doc.space(16)
+	doc.text(fontBold, 16, "Recommendations")
+	doc.space(4)
+	if len(r.Recommendations) == 0 {
+		doc.text(fontRegular, 11, "No recommendations.")
+	}
+	for i, recommendation := range r.Recommendations {
+		doc.listItem(fmt.Sprintf("%d.", i+1), recommendation)
+	}
+
+	if len(r.Warnings) > 0 {
+		doc.space(16)
+		doc.text(fontBold, 16, "Warnings")
+		doc.space(4)
+		for _, warning := range r.Warnings {
+			doc.listItem("-", warning)
+		}
+	}
+	return doc.write(w)
+}
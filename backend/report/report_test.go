@@ -0,0 +1,117 @@
+package report
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/seo-optimizer/backend/analyzer"
+)
+
+func testAnalysis(recommendations int) *analyzer.SEOAnalysis {
+	analysis := &analyzer.SEOAnalysis{URL: "https://example.com/", Score: 72.5, Grade: "C"}
+	analysis.Title.Score = 80
+	analysis.Links.Score = 40
+	for i := 0; i < recommendations; i++ {
+		analysis.Recommendations = append(analysis.Recommendations,
+			fmt.Sprintf("Recommendation %d: add a <meta> description that summarizes the page (roughly 150 characters) so search results show useful text", i+1))
+	}
+	return analysis
+}
+
+func TestWritePDF(t *testing.T) {
+	var buf bytes.Buffer
+	if err := New(testAnalysis(3)).WritePDF(&buf); err != nil {
+		t.Fatalf("Failed to write PDF: %v", err)
+	}
+	pdf := buf.Bytes()
+	if !bytes.HasPrefix(pdf, []byte("%PDF-1.4")) || !bytes.HasSuffix(pdf, []byte("%%EOF\n")) {
+		t.Fatal("Expected a complete PDF file")
+	}
+
+	// The xref table must point at each object
+	match := regexp.MustCompile(`startxref\n(\d+)`).FindSubmatch(pdf)
+	if match == nil {
+		t.Fatal("Expected a startxref entry")
+	}
+	xref, _ := strconv.Atoi(string(match[1]))
+	if !bytes.HasPrefix(pdf[xref:], []byte("xref\n")) {
+		t.Fatalf("startxref points at %q", pdf[xref:xref+10])
+	}
+	for i, entry := range regexp.MustCompile(`(\d{10}) 00000 n`).FindAllSubmatch(pdf[xref:], -1) {
+		offset, _ := strconv.Atoi(string(entry[1]))
+		if want := fmt.Sprintf("%d 0 obj", i+1); !bytes.HasPrefix(pdf[offset:], []byte(want)) {
+			t.Errorf("xref entry %d points at %q", i+1, pdf[offset:offset+10])
+		}
+	}
+
+	for _, want := range []string{"(72.5 \\(C\\)) Tj", "(Links) Tj", "(Page 1 of 1) Tj"} {
+		if !bytes.Contains(pdf, []byte(want)) {
+			t.Errorf("Expected the PDF to contain %s", want)
+		}
+	}
+}
+
+func TestWritePDFPaginatesLongRecommendations(t *testing.T) {
+	var buf bytes.Buffer
+	if err := New(testAnalysis(80)).WritePDF(&buf); err != nil {
+		t.Fatalf("Failed to write PDF: %v", err)
+	}
+	pdf := buf.String()
+
+	pages := strings.Count(pdf, "/Type /Page ")
+	if pages < 3 {
+		t.Fatalf("Expected the recommendations to span several pages, got %d", pages)
+	}
+	if !strings.Contains(pdf, fmt.Sprintf("/Count %d", pages)) || !strings.Contains(pdf, fmt.Sprintf("(Page %d of %d) Tj", pages, pages)) {
+		t.Errorf("Expected page count and numbering for %d pages", pages)
+	}
+
+	// Every line stays between the margins
+	for _, match := range regexp.MustCompile(`Td \(`).FindAllStringIndex(pdf, -1) {
+		line := pdf[strings.LastIndex(pdf[:match[0]], "BT"):match[0]]
+		var font string
+		var size, x, y float64
+		fmt.Sscanf(line, "BT %s %g Tf %g %g", &font, &size, &x, &y)
+		if y < pageMargin || y > pageHeight-pageMargin {
+			t.Fatalf("Text outside the page margins: %q", line)
+		}
+	}
+	if !strings.Contains(pdf, "(80.) Tj") {
+		t.Error("Expected the last recommendation to be written")
+	}
+}
+
+func TestWrapText(t *testing.T) {
+	lines := wrapText(fontRegular, 11, 100, encodeWinAnsi("short words that wrap https://example.com/a/very/long/path/that/cannot/fit/on/one/line"))
+	if len(lines) < 3 {
+		t.Fatalf("Expected several lines, got %q", lines)
+	}
+	for _, line := range lines {
+		if textWidth(fontRegular, 11, line) > 100 {
+			t.Errorf("Line %q is wider than the limit", line)
+		}
+	}
+	if got := string(encodeWinAnsi("“Café” – ok ☃")); got != "\x93Caf\xe9\x94 \x96 ok ?" {
+		t.Errorf("Unexpected WinAnsi encoding %q", got)
+	}
+}
+
+func TestWriteHTML(t *testing.T) {
+	var buf bytes.Buffer
+	if err := New(testAnalysis(2)).WriteHTML(&buf); err != nil {
+		t.Fatalf("Failed to write HTML: %v", err)
+	}
+	page := buf.String()
+	for _, want := range []string{"<title>SEO report for https://example.com/</title>", "72.5 (C)", "&lt;meta&gt; description", "width: 40%"} {
+		if !strings.Contains(page, want) {
+			t.Errorf("Expected the report to contain %q", want)
+		}
+	}
+	if strings.Contains(page, "<meta> description") {
+		t.Error("Expected recommendations to be escaped")
+	}
+}
@@ -0,0 +1,7 @@
+//go:build sqlite
+
+package main
+
+// Links the SQLite driver for STATS_BACKEND=sqlite. It needs cgo; builds
+// without the tag refuse the sqlite backend at startup.
+import _ "github.com/mattn/go-sqlite3"
@@ -0,0 +1,110 @@
+package stats
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"time"
+
+	"github.com/seo-optimizer/backend/logging"
+)
+
+// archiveFilePattern matches the per-month archive files in the data dir
+var archiveFilePattern = regexp.MustCompile(`^stats-(\d{4}-\d{2})\.json$`)
+
+// archivePath returns the archive file of a YYYY-MM month
+func (s *Storage) archivePath(month string) string {
+	return filepath.Join(s.dataDir, "stats-"+month+".json")
+}
+
+// SetHotMonths enables archival: only the newest hotMonths months (the
+// current one included) stay in stats.json, older months are moved to
+// stats-YYYY-MM.json files and loaded on demand. Existing history in the
+// hot file is archived right away. Zero disables archival.
+func (s *Storage) SetHotMonths(hotMonths int) error {
+	if hotMonths < 0 {
+		return fmt.Errorf("hot months must not be negative")
+	}
+	s.mutex.Lock()
+	s.hotMonths = hotMonths
+	s.mutex.Unlock()
+
+	if hotMonths == 0 {
+		return nil
+	}
+	return s.save()
+}
+
+// archiveOldMonths moves months outside the hot window from memory to their
+// archive files. It must be called with s.mutex held for writing.
+func (s *Storage) archiveOldMonths() error {
+	if s.hotMonths == 0 {
+		return nil
+	}
+	oldest := time.Now().AddDate(0, -(s.hotMonths - 1), 0).Format("2006-01")
+
+	for month, stats := range s.stats {
+		if month >= oldest {
+			continue
+		}
+		data, err := json.Marshal(stats)
+		if err != nil {
+			return fmt.Errorf("failed to marshal stats for %s: %w", month, err)
+		}
+		path := s.archivePath(month)
+		if err := os.WriteFile(path+".tmp", data, 0644); err != nil {
+			return fmt.Errorf("failed to write archive for %s: %w", month, err)
+		}
+		if err := os.Rename(path+".tmp", path); err != nil {
+			os.Remove(path + ".tmp")
+			return fmt.Errorf("failed to write archive for %s: %w", month, err)
+		}
+		delete(s.stats, month)
+		s.archived[month] = stats
+		s.version++
+		logging.Info("Archived statistics", "month", month, "path", path)
+	}
+	return nil
+}
+
+// loadArchivedMonth returns an archived month, reading its file the first
+// time it is requested
+func (s *Storage) loadArchivedMonth(month string) (*MonthlyStats, bool) {
+	s.archiveMu.Lock()
+	defer s.archiveMu.Unlock()
+
+	if stats, ok := s.archived[month]; ok {
+		return stats, true
+	}
+	data, err := os.ReadFile(s.archivePath(month))
+	if err != nil {
+		if !os.IsNotExist(err) {
+			logging.Error("Failed to read archived stats", "month", month, "error", err)
+		}
+		return nil, false
+	}
+	stats := NewMonthlyStats()
+	if err := json.Unmarshal(data, stats); err != nil {
+		logging.Error("Failed to parse archived stats", "month", month, "error", err)
+		return nil, false
+	}
+	s.archived[month] = stats
+	return stats, true
+}
+
+// archivedMonths lists the months that have an archive file
+func (s *Storage) archivedMonths() []string {
+	entries, err := os.ReadDir(s.dataDir)
+	if err != nil {
+		return nil
+	}
+	var months []string
+	for _, entry := range entries {
+		if match := archiveFilePattern.FindStringSubmatch(entry.Name()); match != nil {
+			months = append(months, match[1])
+		}
+	}
+	return months
+}
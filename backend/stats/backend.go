@@ -0,0 +1,108 @@
+package stats
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// StatsBackend is a statistics store. Storage (stats.json) is the default;
+// SQLStorage keeps the same data in SQLite.
+type StatsBackend interface {
+	TrackVisitor(ip string)
+	TrackAnalysis(url string, loadTime float64, isError bool)
+	IncrementStats(analysisHits, analysisMisses, linkHits, linkMisses int)
+	GetCurrentStats() MonthlyStats
+	GetMonthlyStats(yearMonth string) (MonthlyStats, bool)
+	GetAllMonths() []string
+	GetDailyStats(date string) (DailyStats, bool)
+	GetDaysInMonth(yearMonth string) []string
+	GetTopURLs(n int) []URLCount
+	SetDailyRetention(days int) error
+	SetCountryLookup(lookup CountryLookup)
+	Cleanup(retainMonths int)
+	Reset(month string) error
+	Shutdown() error
+}
+
+// URLCount is how many times a URL was analyzed
+type URLCount struct {
+	URL   string `json:"url"`
+	Count int    `json:"count"`
+}
+
+// topURLs returns the n most counted URLs, most counted first and ties in
+// URL order
+func topURLs(counts map[string]int, n int) []URLCount {
+	top := make([]URLCount, 0, len(counts))
+	for url, count := range counts {
+		top = append(top, URLCount{URL: url, Count: count})
+	}
+	sort.Slice(top, func(i, j int) bool {
+		if top[i].Count != top[j].Count {
+			return top[i].Count > top[j].Count
+		}
+		return top[i].URL < top[j].URL
+	})
+	if n < 0 {
+		n = 0
+	}
+	if len(top) > n {
+		top = top[:n]
+	}
+	return top
+}
+
+// CountryLookup maps a visitor IP address to an ISO country code, or ""
+// when it isn't known. *geoip.Reader implements it.
+type CountryLookup interface {
+	Country(ip net.IP) string
+}
+
+// lookupCountry returns the country of ip, or "" without a lookup
+func lookupCountry(lookup CountryLookup, ip string) string {
+	if lookup == nil {
+		return ""
+	}
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return ""
+	}
+	return lookup.Country(parsed)
+}
+
+// ErrInvalidMonth is returned for months not in YYYY-MM form
+var ErrInvalidMonth = errors.New("invalid month")
+
+// checkMonth returns ErrInvalidMonth unless month is empty or YYYY-MM
+func checkMonth(month string) error {
+	if month == "" {
+		return nil
+	}
+	if _, err := time.Parse("2006-01", month); err != nil {
+		return fmt.Errorf("%w %q (want YYYY-MM)", ErrInvalidMonth, month)
+	}
+	return nil
+}
+
+var (
+	_ StatsBackend = (*Storage)(nil)
+	_ StatsBackend = (*SQLStorage)(nil)
+)
+
+// Open creates the statistics backend named by kind ("json" or "sqlite")
+// in dataDir; an empty kind selects json
+func Open(kind, dataDir string) (StatsBackend, error) {
+	switch strings.ToLower(strings.TrimSpace(kind)) {
+	case "", "json":
+		return NewStorage(dataDir)
+	case "sqlite":
+		return NewSQLiteStorage(filepath.Join(dataDir, "stats.db"))
+	default:
+		return nil, fmt.Errorf("unknown stats backend %q (want json or sqlite)", kind)
+	}
+}
@@ -0,0 +1,30 @@
+package stats
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestOpenBackend(t *testing.T) {
+	backend, err := Open("", t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to open default backend: %v", err)
+	}
+	defer backend.Shutdown()
+	if _, ok := backend.(*Storage); !ok {
+		t.Errorf("Expected the JSON storage by default, got %T", backend)
+	}
+
+	if _, err := Open("mongo", t.TempDir()); err == nil {
+		t.Error("Expected an unknown backend to be rejected")
+	}
+
+	// Without the driver linked in, sqlite fails with a hint instead of
+	// silently falling back to JSON
+	if sqliteLinked() {
+		t.Skip("SQLite driver linked in; covered by sqlite_test.go")
+	}
+	if _, err := Open("sqlite", t.TempDir()); !errors.Is(err, ErrSQLiteUnavailable) {
+		t.Errorf("Expected a missing-driver error, got %v", err)
+	}
+}
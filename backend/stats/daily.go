@@ -0,0 +1,141 @@
+package stats
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// defaultDailyRetention is how many days of per-day statistics are kept
+const defaultDailyRetention = 90
+
+// DailyStats represents statistics for a single day. Days are stored under
+// the month they belong to, so they are archived and cleaned up with it.
+type DailyStats struct {
+	AnalysisCacheHits   int                  `json:"analysis_hits"`
+	AnalysisCacheMisses int                  `json:"analysis_misses"`
+	LinkCacheHits       int                  `json:"link_hits"`
+	LinkCacheMisses     int                  `json:"link_misses"`
+	UniqueVisitors      map[string]time.Time `json:"unique_visitors"`
+	AnalysisRequests    int                  `json:"analysis_requests"`
+	ErrorCount          int                  `json:"error_count"`
+	TotalLoadTime       float64              `json:"total_load_time"`
+	TotalRequests       int                  `json:"total_requests"`
+	LastUpdated         time.Time            `json:"last_updated"`
+}
+
+// getCurrentDay returns the current day key in YYYY-MM-DD format
+func getCurrentDay() string {
+	return time.Now().Format("2006-01-02")
+}
+
+// dayStats returns the bucket for day within a month, creating it if needed.
+// It must be called with s.mutex held for writing.
+func dayStats(month *MonthlyStats, day string) *DailyStats {
+	if month.Daily == nil {
+		month.Daily = make(map[string]*DailyStats)
+	}
+	daily, ok := month.Daily[day]
+	if !ok {
+		daily = &DailyStats{UniqueVisitors: make(map[string]time.Time)}
+		month.Daily[day] = daily
+	}
+	if daily.UniqueVisitors == nil {
+		daily.UniqueVisitors = make(map[string]time.Time)
+	}
+	return daily
+}
+
+// copyDaily returns a deep copy of a month's day buckets
+func copyDaily(daily map[string]*DailyStats) map[string]*DailyStats {
+	copied := make(map[string]*DailyStats, len(daily))
+	for day, stats := range daily {
+		dayCopy := *stats
+		dayCopy.UniqueVisitors = make(map[string]time.Time, len(stats.UniqueVisitors))
+		for ip, seen := range stats.UniqueVisitors {
+			dayCopy.UniqueVisitors[ip] = seen
+		}
+		copied[day] = &dayCopy
+	}
+	return copied
+}
+
+// SetDailyRetention sets how many days of per-day statistics Cleanup keeps;
+// zero keeps them for as long as their month is kept
+func (s *Storage) SetDailyRetention(days int) error {
+	if days < 0 {
+		return fmt.Errorf("daily retention must not be negative")
+	}
+	s.mutex.Lock()
+	s.dailyRetention = days
+	s.mutex.Unlock()
+	return nil
+}
+
+// pruneDaily drops day buckets older than the retention window. It must be
+// called with s.mutex held for writing.
+func (s *Storage) pruneDaily() {
+	if s.dailyRetention == 0 {
+		return
+	}
+	oldest := time.Now().AddDate(0, 0, -(s.dailyRetention - 1)).Format("2006-01-02")
+	for _, stats := range s.stats {
+		for day := range stats.Daily {
+			if day < oldest {
+				delete(stats.Daily, day)
+				s.version++
+			}
+		}
+	}
+}
+
+// GetDailyStats returns statistics for a specific YYYY-MM-DD day
+func (s *Storage) GetDailyStats(date string) (DailyStats, bool) {
+	day, err := time.Parse("2006-01-02", date)
+	if err != nil {
+		return DailyStats{}, false
+	}
+	month := day.Format("2006-01")
+
+	s.mutex.RLock()
+	if stats, ok := s.stats[month]; ok {
+		defer s.mutex.RUnlock()
+		if daily, ok := stats.Daily[date]; ok {
+			return *daily, true
+		}
+		return DailyStats{}, false
+	}
+	s.mutex.RUnlock()
+
+	if stats, ok := s.loadArchivedMonth(month); ok {
+		if daily, ok := stats.Daily[date]; ok {
+			return *daily, true
+		}
+	}
+	return DailyStats{}, false
+}
+
+// GetDaysInMonth returns the days of a YYYY-MM month that have statistics,
+// sorted oldest first
+func (s *Storage) GetDaysInMonth(yearMonth string) []string {
+	s.mutex.RLock()
+	stats, ok := s.stats[yearMonth]
+	days := make([]string, 0)
+	if ok {
+		for day := range stats.Daily {
+			days = append(days, day)
+		}
+	}
+	s.mutex.RUnlock()
+
+	if !ok {
+		if stats, ok := s.loadArchivedMonth(yearMonth); ok {
+			for day := range stats.Daily {
+				days = append(days, day)
+			}
+		}
+	}
+
+	sort.Strings(days)
+	return days
+}
@@ -0,0 +1,458 @@
+package stats
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/seo-optimizer/backend/logging"
+)
+
+// SQLiteDriver is the database/sql driver name used for the sqlite backend.
+// The driver itself is linked in by building with -tags sqlite.
+var SQLiteDriver = "sqlite3"
+
+// ErrSQLiteUnavailable is returned for the sqlite backend when the binary
+// was built without the driver
+var ErrSQLiteUnavailable = errors.New("stats backend sqlite: built without sqlite support (rebuild with -tags sqlite and cgo)")
+
+// sqliteLinked reports whether the SQLite driver is registered
+func sqliteLinked() bool {
+	for _, driver := range sql.Drivers() {
+		if driver == SQLiteDriver {
+			return true
+		}
+	}
+	return false
+}
+
+// sqliteSchema stores monthly and daily rollups side by side: counters and
+// visitors are keyed by period, either "YYYY-MM" or "YYYY-MM-DD"
+const sqliteSchema = `
+CREATE TABLE IF NOT EXISTS counters (
+	period            TEXT PRIMARY KEY,
+	analysis_hits     INTEGER NOT NULL DEFAULT 0,
+	analysis_misses   INTEGER NOT NULL DEFAULT 0,
+	link_hits         INTEGER NOT NULL DEFAULT 0,
+	link_misses       INTEGER NOT NULL DEFAULT 0,
+	analysis_requests INTEGER NOT NULL DEFAULT 0,
+	error_count       INTEGER NOT NULL DEFAULT 0,
+	total_load_time   REAL    NOT NULL DEFAULT 0,
+	total_requests    INTEGER NOT NULL DEFAULT 0,
+	last_updated      TEXT    NOT NULL
+);
+CREATE TABLE IF NOT EXISTS visitors (
+	period    TEXT NOT NULL,
+	ip        TEXT NOT NULL,
+	last_seen TEXT NOT NULL,
+	PRIMARY KEY (period, ip)
+);
+CREATE TABLE IF NOT EXISTS popular_urls (
+	month TEXT    NOT NULL,
+	url   TEXT    NOT NULL,
+	count INTEGER NOT NULL DEFAULT 0,
+	PRIMARY KEY (month, url)
+);
+CREATE TABLE IF NOT EXISTS visitor_countries (
+	month   TEXT NOT NULL,
+	ip      TEXT NOT NULL,
+	country TEXT NOT NULL,
+	PRIMARY KEY (month, ip)
+);`
+
+// counterDelta is one update applied to a month and its current day
+type counterDelta struct {
+	analysisHits, analysisMisses, linkHits, linkMisses int
+	analysisRequests, errorCount, totalRequests        int
+	totalLoadTime                                      float64
+}
+
+// SQLStorage keeps statistics in SQLite. Every update is a small upsert,
+// so nothing is rewritten wholesale and a crash loses at most one update.
+type SQLStorage struct {
+	db             *sql.DB
+	mutex          sync.RWMutex
+	dailyRetention int
+	countries      CountryLookup // nil unless GeoIP is configured
+}
+
+// NewSQLiteStorage opens (creating if needed) the SQLite database at path
+func NewSQLiteStorage(path string) (*SQLStorage, error) {
+	if !sqliteLinked() {
+		return nil, ErrSQLiteUnavailable
+	}
+	logging.Info("Initializing SQLite statistics storage", "path", path)
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create data directory: %w", err)
+	}
+	db, err := sql.Open(SQLiteDriver, path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open stats database: %w", err)
+	}
+	// SQLite allows one writer; a single connection avoids "database is locked"
+	db.SetMaxOpenConns(1)
+	if _, err := db.Exec(sqliteSchema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to create stats schema: %w", err)
+	}
+
+	return &SQLStorage{db: db, dailyRetention: defaultDailyRetention}, nil
+}
+
+// apply adds delta to the current month and day and records the visitor,
+// their country and the URL, if any, in one transaction
+func (s *SQLStorage) apply(delta counterDelta, ip, country, url string) error {
+	now := time.Now()
+	stamp := now.Format(time.RFC3339Nano)
+	month, day := now.Format("2006-01"), now.Format("2006-01-02")
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, period := range []string{month, day} {
+		if _, err := tx.Exec(`INSERT INTO counters (period, analysis_hits, analysis_misses, link_hits, link_misses,
+				analysis_requests, error_count, total_load_time, total_requests, last_updated)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+			ON CONFLICT (period) DO UPDATE SET
+				analysis_hits     = analysis_hits + excluded.analysis_hits,
+				analysis_misses   = analysis_misses + excluded.analysis_misses,
+				link_hits         = link_hits + excluded.link_hits,
+				link_misses       = link_misses + excluded.link_misses,
+				analysis_requests = analysis_requests + excluded.analysis_requests,
+				error_count       = error_count + excluded.error_count,
+				total_load_time   = total_load_time + excluded.total_load_time,
+				total_requests    = total_requests + excluded.total_requests,
+				last_updated      = excluded.last_updated`,
+			period, delta.analysisHits, delta.analysisMisses, delta.linkHits, delta.linkMisses,
+			delta.analysisRequests, delta.errorCount, delta.totalLoadTime, delta.totalRequests, stamp); err != nil {
+			return err
+		}
+		if ip != "" {
+			if _, err := tx.Exec(`INSERT INTO visitors (period, ip, last_seen) VALUES (?, ?, ?)
+				ON CONFLICT (period, ip) DO UPDATE SET last_seen = excluded.last_seen`,
+				period, ip, stamp); err != nil {
+				return err
+			}
+		}
+	}
+	if ip != "" && country != "" {
+		// A visitor counts once a month, in the country first seen
+		if _, err := tx.Exec(`INSERT INTO visitor_countries (month, ip, country) VALUES (?, ?, ?)
+			ON CONFLICT (month, ip) DO NOTHING`, month, ip, country); err != nil {
+			return err
+		}
+	}
+	if url != "" {
+		if _, err := tx.Exec(`INSERT INTO popular_urls (month, url, count) VALUES (?, ?, 1)
+			ON CONFLICT (month, url) DO UPDATE SET count = count + 1`, month, url); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// TrackVisitor records a unique visitor
+func (s *SQLStorage) TrackVisitor(ip string) {
+	if ip == "" {
+		logging.Warn("Empty IP address in TrackVisitor")
+		return
+	}
+	s.mutex.RLock()
+	country := lookupCountry(s.countries, ip)
+	s.mutex.RUnlock()
+	if err := s.apply(counterDelta{}, ip, country, ""); err != nil {
+		logging.Error("Failed to track visitor", "error", err)
+	}
+}
+
+// TrackAnalysis records an analysis request
+func (s *SQLStorage) TrackAnalysis(url string, loadTime float64, isError bool) {
+	delta := counterDelta{analysisRequests: 1, totalRequests: 1, totalLoadTime: loadTime}
+	if isError {
+		delta.errorCount = 1
+	}
+	if err := s.apply(delta, "", "", url); err != nil {
+		logging.Error("Failed to track analysis", "url", url, "error", err)
+	}
+}
+
+// IncrementStats increments the specified statistics
+func (s *SQLStorage) IncrementStats(analysisHits, analysisMisses, linkHits, linkMisses int) {
+	delta := counterDelta{analysisHits: analysisHits, analysisMisses: analysisMisses,
+		linkHits: linkHits, linkMisses: linkMisses}
+	if err := s.apply(delta, "", "", ""); err != nil {
+		logging.Error("Failed to update cache stats", "error", err)
+	}
+}
+
+// loadPeriod reads the counters and visitors of one period
+func (s *SQLStorage) loadPeriod(period string) (DailyStats, bool, error) {
+	var stats DailyStats
+	var updated string
+	err := s.db.QueryRow(`SELECT analysis_hits, analysis_misses, link_hits, link_misses,
+			analysis_requests, error_count, total_load_time, total_requests, last_updated
+		FROM counters WHERE period = ?`, period).Scan(
+		&stats.AnalysisCacheHits, &stats.AnalysisCacheMisses, &stats.LinkCacheHits, &stats.LinkCacheMisses,
+		&stats.AnalysisRequests, &stats.ErrorCount, &stats.TotalLoadTime, &stats.TotalRequests, &updated)
+	if err == sql.ErrNoRows {
+		return DailyStats{}, false, nil
+	}
+	if err != nil {
+		return DailyStats{}, false, err
+	}
+	stats.LastUpdated, _ = time.Parse(time.RFC3339Nano, updated)
+
+	rows, err := s.db.Query(`SELECT ip, last_seen FROM visitors WHERE period = ?`, period)
+	if err != nil {
+		return DailyStats{}, false, err
+	}
+	defer rows.Close()
+	stats.UniqueVisitors = make(map[string]time.Time)
+	for rows.Next() {
+		var ip, seen string
+		if err := rows.Scan(&ip, &seen); err != nil {
+			return DailyStats{}, false, err
+		}
+		stats.UniqueVisitors[ip], _ = time.Parse(time.RFC3339Nano, seen)
+	}
+	return stats, true, rows.Err()
+}
+
+// GetCurrentStats returns statistics for the current month
+func (s *SQLStorage) GetCurrentStats() MonthlyStats {
+	if stats, ok := s.GetMonthlyStats(getCurrentMonth()); ok {
+		return stats
+	}
+	return *NewMonthlyStats()
+}
+
+// GetMonthlyStats returns statistics for a specific month
+func (s *SQLStorage) GetMonthlyStats(yearMonth string) (MonthlyStats, bool) {
+	period, ok, err := s.loadPeriod(yearMonth)
+	if err != nil {
+		logging.Error("Failed to read monthly stats", "month", yearMonth, "error", err)
+		return MonthlyStats{}, false
+	}
+	if !ok {
+		return MonthlyStats{}, false
+	}
+
+	stats := NewMonthlyStats()
+	stats.AnalysisCacheHits = period.AnalysisCacheHits
+	stats.AnalysisCacheMisses = period.AnalysisCacheMisses
+	stats.LinkCacheHits = period.LinkCacheHits
+	stats.LinkCacheMisses = period.LinkCacheMisses
+	stats.AnalysisRequests = period.AnalysisRequests
+	stats.ErrorCount = period.ErrorCount
+	stats.TotalLoadTime = period.TotalLoadTime
+	stats.TotalRequests = period.TotalRequests
+	stats.LastUpdated = period.LastUpdated
+	stats.UniqueVisitors = period.UniqueVisitors
+
+	rows, err := s.db.Query(`SELECT url, count FROM popular_urls WHERE month = ?`, yearMonth)
+	if err != nil {
+		logging.Error("Failed to read popular URLs", "month", yearMonth, "error", err)
+		return *stats, true
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var url string
+		var count int
+		if err := rows.Scan(&url, &count); err != nil {
+			logging.Error("Failed to read popular URLs", "month", yearMonth, "error", err)
+			break
+		}
+		stats.PopularUrls[url] = count
+	}
+
+	countries, err := s.db.Query(`SELECT country, COUNT(*) FROM visitor_countries
+		WHERE month = ? GROUP BY country`, yearMonth)
+	if err != nil {
+		logging.Error("Failed to read visitor countries", "month", yearMonth, "error", err)
+		return *stats, true
+	}
+	defer countries.Close()
+	for countries.Next() {
+		var country string
+		var count int
+		if err := countries.Scan(&country, &count); err != nil {
+			logging.Error("Failed to read visitor countries", "month", yearMonth, "error", err)
+			break
+		}
+		if stats.Countries == nil {
+			stats.Countries = make(map[string]int)
+		}
+		stats.Countries[country] = count
+	}
+	return *stats, true
+}
+
+// periods lists the periods matching pattern (a LIKE pattern) of the given
+// key length, in the given order
+func (s *SQLStorage) periods(pattern string, length int, order string) []string {
+	rows, err := s.db.Query(`SELECT period FROM counters
+		WHERE period LIKE ? AND length(period) = ? ORDER BY period `+order, pattern, length)
+	if err != nil {
+		logging.Error("Failed to list stats periods", "error", err)
+		return []string{}
+	}
+	defer rows.Close()
+
+	periods := make([]string, 0)
+	for rows.Next() {
+		var period string
+		if err := rows.Scan(&period); err != nil {
+			logging.Error("Failed to list stats periods", "error", err)
+			break
+		}
+		periods = append(periods, period)
+	}
+	return periods
+}
+
+// GetAllMonths returns all months that have statistics, newest first
+func (s *SQLStorage) GetAllMonths() []string {
+	return s.periods("%", len("2006-01"), "DESC")
+}
+
+// GetDailyStats returns statistics for a specific YYYY-MM-DD day
+func (s *SQLStorage) GetDailyStats(date string) (DailyStats, bool) {
+	if _, err := time.Parse("2006-01-02", date); err != nil {
+		return DailyStats{}, false
+	}
+	stats, ok, err := s.loadPeriod(date)
+	if err != nil {
+		logging.Error("Failed to read daily stats", "date", date, "error", err)
+		return DailyStats{}, false
+	}
+	return stats, ok
+}
+
+// GetDaysInMonth returns the days of a YYYY-MM month that have statistics,
+// sorted oldest first
+func (s *SQLStorage) GetDaysInMonth(yearMonth string) []string {
+	return s.periods(yearMonth+"-%", len("2006-01-02"), "ASC")
+}
+
+// SetDailyRetention sets how many days of per-day statistics Cleanup keeps;
+// zero keeps them for as long as their month is kept
+func (s *SQLStorage) SetDailyRetention(days int) error {
+	if days < 0 {
+		return fmt.Errorf("daily retention must not be negative")
+	}
+	s.mutex.Lock()
+	s.dailyRetention = days
+	s.mutex.Unlock()
+	return nil
+}
+
+// GetTopURLs returns the n URLs analyzed most often this month, most
+// analyzed first
+func (s *SQLStorage) GetTopURLs(n int) []URLCount {
+	top := make([]URLCount, 0)
+	if n <= 0 {
+		return top
+	}
+	rows, err := s.db.Query(`SELECT url, count FROM popular_urls WHERE month = ?
+		ORDER BY count DESC, url ASC LIMIT ?`, getCurrentMonth(), n)
+	if err != nil {
+		logging.Error("Failed to read popular URLs", "error", err)
+		return top
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var entry URLCount
+		if err := rows.Scan(&entry.URL, &entry.Count); err != nil {
+			logging.Error("Failed to read popular URLs", "error", err)
+			break
+		}
+		top = append(top, entry)
+	}
+	return top
+}
+
+// SetCountryLookup enables counting new visitors per country; nil disables it
+func (s *SQLStorage) SetCountryLookup(lookup CountryLookup) {
+	s.mutex.Lock()
+	s.countries = lookup
+	s.mutex.Unlock()
+}
+
+// Cleanup removes months older than the current month plus retainMonths
+// previous ones, and days outside the daily retention window
+func (s *SQLStorage) Cleanup(retainMonths int) {
+	s.mutex.RLock()
+	retention := s.dailyRetention
+	s.mutex.RUnlock()
+
+	type deletion struct{ query, cutoff string }
+	oldestMonth := time.Now().AddDate(0, -retainMonths, 0).Format("2006-01")
+	deletions := []deletion{
+		{`DELETE FROM counters WHERE substr(period, 1, 7) < ?`, oldestMonth},
+		{`DELETE FROM visitors WHERE substr(period, 1, 7) < ?`, oldestMonth},
+		{`DELETE FROM popular_urls WHERE month < ?`, oldestMonth},
+		{`DELETE FROM visitor_countries WHERE month < ?`, oldestMonth},
+	}
+	if retention > 0 {
+		oldestDay := time.Now().AddDate(0, 0, -(retention - 1)).Format("2006-01-02")
+		deletions = append(deletions,
+			deletion{`DELETE FROM counters WHERE length(period) = 10 AND period < ?`, oldestDay},
+			deletion{`DELETE FROM visitors WHERE length(period) = 10 AND period < ?`, oldestDay})
+	}
+
+	for _, d := range deletions {
+		if _, err := s.db.Exec(d.query, d.cutoff); err != nil {
+			logging.Error("Failed to clean up statistics", "error", err)
+			return
+		}
+	}
+	logging.Info("Cleaned up statistics", "oldestMonth", oldestMonth)
+}
+
+// Reset deletes the statistics of a YYYY-MM month and its days, or of every
+// period when month is empty
+func (s *SQLStorage) Reset(month string) error {
+	if err := checkMonth(month); err != nil {
+		return err
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// An empty month matches every period
+	for _, query := range []string{
+		`DELETE FROM counters WHERE ? = '' OR substr(period, 1, 7) = ?`,
+		`DELETE FROM visitors WHERE ? = '' OR substr(period, 1, 7) = ?`,
+		`DELETE FROM popular_urls WHERE ? = '' OR month = ?`,
+		`DELETE FROM visitor_countries WHERE ? = '' OR month = ?`,
+	} {
+		if _, err := tx.Exec(query, month, month); err != nil {
+			return fmt.Errorf("failed to reset statistics: %w", err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to reset statistics: %w", err)
+	}
+	logging.Warn("Reset statistics", "month", month)
+	return nil
+}
+
+// Shutdown closes the database
+func (s *SQLStorage) Shutdown() error {
+	if s == nil {
+		return nil
+	}
+	logging.Info("Shutting down statistics storage")
+	return s.db.Close()
+}
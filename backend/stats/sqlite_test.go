@@ -0,0 +1,145 @@
+//go:build sqlite
+
+package stats
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func TestSQLStorage(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "stats.db")
+	storage, err := NewSQLiteStorage(path)
+	if err != nil {
+		t.Fatalf("Failed to open SQLite storage: %v", err)
+	}
+
+	storage.TrackVisitor("192.0.2.1")
+	storage.TrackVisitor("192.0.2.1")
+	storage.TrackAnalysis("https://example.com", 100, false)
+	storage.TrackAnalysis("https://example.com", 50, true)
+	storage.IncrementStats(1, 2, 3, 4)
+
+	// Data survives reopening the database
+	if err := storage.Shutdown(); err != nil {
+		t.Fatalf("Failed to close SQLite storage: %v", err)
+	}
+	storage, err = NewSQLiteStorage(path)
+	if err != nil {
+		t.Fatalf("Failed to reopen SQLite storage: %v", err)
+	}
+	defer storage.Shutdown()
+
+	stats := storage.GetCurrentStats()
+	if stats.AnalysisRequests != 2 || stats.ErrorCount != 1 || stats.TotalLoadTime != 150 {
+		t.Errorf("Unexpected monthly counters: %+v", stats)
+	}
+	if stats.AnalysisCacheHits != 1 || stats.LinkCacheMisses != 4 {
+		t.Errorf("Unexpected cache counters: %+v", stats)
+	}
+	if len(stats.UniqueVisitors) != 1 || stats.PopularUrls["https://example.com"] != 2 {
+		t.Errorf("Unexpected visitors or popular URLs: %+v", stats)
+	}
+
+	today := time.Now().Format("2006-01-02")
+	if daily, ok := storage.GetDailyStats(today); !ok || daily.AnalysisRequests != 2 {
+		t.Errorf("Expected 2 requests today, got %+v (found %v)", daily, ok)
+	}
+	if days := storage.GetDaysInMonth(time.Now().Format("2006-01")); len(days) != 1 || days[0] != today {
+		t.Errorf("Expected only %s in GetDaysInMonth, got %v", today, days)
+	}
+	if months := storage.GetAllMonths(); len(months) != 1 {
+		t.Errorf("Expected one month, got %v", months)
+	}
+
+	// Cleanup drops old months and days outside the retention window
+	if _, err := storage.db.Exec(`INSERT INTO counters (period, last_updated) VALUES ('2001-01', ''), ('2001-01-01', '')`); err != nil {
+		t.Fatalf("Failed to insert old rows: %v", err)
+	}
+	storage.Cleanup(1)
+	if _, ok := storage.GetMonthlyStats("2001-01"); ok {
+		t.Error("Expected Cleanup to remove old months")
+	}
+	if _, ok := storage.GetDailyStats("2001-01-01"); ok {
+		t.Error("Expected Cleanup to remove old days")
+	}
+	if _, ok := storage.GetDailyStats(today); !ok {
+		t.Error("Expected Cleanup to keep today")
+	}
+}
+
+func TestSQLStorageReset(t *testing.T) {
+	storage, err := NewSQLiteStorage(filepath.Join(t.TempDir(), "stats.db"))
+	if err != nil {
+		t.Fatalf("Failed to open SQLite storage: %v", err)
+	}
+	defer storage.Shutdown()
+
+	storage.TrackVisitor("192.0.2.1")
+	storage.TrackAnalysis("https://example.com", 100, false)
+	if err := storage.Reset("not-a-month"); err == nil {
+		t.Error("Expected an invalid month to be rejected")
+	}
+	if err := storage.Reset(""); err != nil {
+		t.Fatalf("Failed to reset statistics: %v", err)
+	}
+
+	stats := storage.GetCurrentStats()
+	if stats.AnalysisRequests != 0 || len(stats.UniqueVisitors) != 0 || len(stats.PopularUrls) != 0 {
+		t.Errorf("Expected empty stats after a reset, got %+v", stats)
+	}
+	if days := storage.GetDaysInMonth(time.Now().Format("2006-01")); len(days) != 0 {
+		t.Errorf("Expected no daily stats after a reset, got %v", days)
+	}
+}
+
+func TestSQLStorageCountries(t *testing.T) {
+	storage, err := NewSQLiteStorage(filepath.Join(t.TempDir(), "stats.db"))
+	if err != nil {
+		t.Fatalf("Failed to open SQLite storage: %v", err)
+	}
+	defer storage.Shutdown()
+
+	storage.TrackVisitor("203.0.113.1")
+	if stats := storage.GetCurrentStats(); stats.Countries != nil {
+		t.Errorf("Expected no countries without a lookup, got %v", stats.Countries)
+	}
+
+	storage.SetCountryLookup(testCountries)
+	for _, ip := range []string{"192.0.2.1", "192.0.2.1", "192.0.2.2", "198.51.100.1", "203.0.113.9"} {
+		storage.TrackVisitor(ip)
+	}
+	stats := storage.GetCurrentStats()
+	if len(stats.Countries) != 2 || stats.Countries["US"] != 2 || stats.Countries["DE"] != 1 {
+		t.Errorf("Expected 2 US and 1 DE visitors, got %v", stats.Countries)
+	}
+
+	if err := storage.Reset(""); err != nil {
+		t.Fatalf("Failed to reset statistics: %v", err)
+	}
+	if stats := storage.GetCurrentStats(); stats.Countries != nil {
+		t.Errorf("Expected countries to be reset, got %v", stats.Countries)
+	}
+}
+
+func TestSQLStorageGetTopURLs(t *testing.T) {
+	storage, err := NewSQLiteStorage(filepath.Join(t.TempDir(), "stats.db"))
+	if err != nil {
+		t.Fatalf("Failed to open SQLite storage: %v", err)
+	}
+	defer storage.Shutdown()
+
+	for i := 1; i <= 6; i++ {
+		for j := 0; j < i%4; j++ {
+			storage.TrackAnalysis(fmt.Sprintf("https://example.com/%d", i), 10, false)
+		}
+	}
+	want := []URLCount{{"https://example.com/3", 3}, {"https://example.com/2", 2}, {"https://example.com/6", 2}}
+	if top := storage.GetTopURLs(3); fmt.Sprint(top) != fmt.Sprint(want) {
+		t.Errorf("Expected %v, got %v", want, top)
+	}
+}
@@ -3,12 +3,13 @@ package stats
 import (
 	"encoding/json"
 	"fmt"
-	"log"
 	"os"
 	"path/filepath"
 	"sort"
 	"sync"
 	"time"
+
+	"github.com/seo-optimizer/backend/logging"
 )
 
 // MonthlyStats represents statistics for a specific month
@@ -24,35 +25,64 @@ type MonthlyStats struct {
 	AnalysisRequests    int                  `json:"analysis_requests"`
 	ErrorCount          int                  `json:"error_count"`
 	PopularUrls         map[string]int       `json:"popular_urls"`
+	Countries           map[string]int       `json:"countries,omitempty"` // unique visitors per country, with GeoIP
 	TotalLoadTime       float64              `json:"total_load_time"`
 	TotalRequests       int                  `json:"total_requests"`
+
+	// Per-day breakdown, keyed by "YYYY-MM-DD"
+	Daily               map[string]*DailyStats `json:"daily,omitempty"`
 	
 	// Metadata
 	LastUpdated         time.Time            `json:"last_updated"`
 }
 
+// legacyAnalyzeEndpoint is the API path older versions tracked as a popular
+// URL next to the analyzed page
+const legacyAnalyzeEndpoint = "/api/analyze"
+
 // NewMonthlyStats creates a new MonthlyStats instance with initialized maps
 func NewMonthlyStats() *MonthlyStats {
 	return &MonthlyStats{
 		UniqueVisitors: make(map[string]time.Time),
 		PopularUrls:    make(map[string]int),
+		Daily:          make(map[string]*DailyStats),
 		LastUpdated:    time.Now(),
 	}
 }
 
+// statsSnapshot is a memoized copy of one month's statistics, valid only
+// while the storage version it was taken at is current
+type statsSnapshot struct {
+	month   string
+	version uint64
+	taken   time.Time
+	stats   MonthlyStats
+}
+
 // Storage handles persistent storage of statistics
 type Storage struct {
 	mutex       sync.RWMutex
 	stats       map[string]*MonthlyStats // key: "YYYY-MM"
 	filePath    string
+	dataDir     string
+	hotMonths   int                      // months kept in filePath; 0 disables archival
+	dailyRetention int                   // days of per-day stats kept; 0 keeps them with their month
+	archived    map[string]*MonthlyStats // archived months loaded so far
+	archiveMu   sync.Mutex               // guards archived
 	lastWrite   time.Time
 	writeBuffer chan struct{}
+	version     uint64 // Incremented on every change to stats
+	snapshot    *statsSnapshot
+	snapshotMu  sync.Mutex
+	snapshotTTL time.Duration
+	countries   CountryLookup // nil unless GeoIP is configured
 	done        chan struct{} // Channel to signal shutdown
+	stopped     chan struct{} // Closed once the background writer has exited
 }
 
 // NewStorage creates a new statistics storage instance
 func NewStorage(dataDir string) (*Storage, error) {
-	log.Printf("Initializing storage with data directory: %s", dataDir)
+	logging.Info("Initializing statistics storage", "dataDir", dataDir)
 	
 	// Ensure data directory exists
 	if err := os.MkdirAll(dataDir, 0755); err != nil {
@@ -63,34 +93,39 @@ func NewStorage(dataDir string) (*Storage, error) {
 	s := &Storage{
 		stats:       make(map[string]*MonthlyStats),
 		filePath:    filePath,
+		dataDir:     dataDir,
+		archived:    make(map[string]*MonthlyStats),
 		writeBuffer: make(chan struct{}, 1),
 		done:        make(chan struct{}),
+		stopped:     make(chan struct{}),
+		snapshotTTL: 2 * time.Second,
+		dailyRetention: defaultDailyRetention,
 	}
 
 	// Initialize current month's stats
 	currentMonth := getCurrentMonth()
 	s.stats[currentMonth] = NewMonthlyStats()
-	log.Printf("Initialized current month stats: %s", currentMonth)
+	logging.Debug("Initialized current month stats", "month", currentMonth)
 
 	// Load existing stats if file exists
 	if err := s.load(); err != nil {
 		if !os.IsNotExist(err) {
-			log.Printf("Error loading existing stats: %v", err)
+			logging.Error("Failed to load existing stats", "error", err)
 			return nil, fmt.Errorf("failed to load stats: %w", err)
 		}
-		log.Printf("No existing stats file found, starting fresh")
+		logging.Info("No existing stats file found, starting fresh")
 	} else {
-		log.Printf("Successfully loaded existing stats")
+		logging.Info("Loaded existing stats")
 	}
 
 	// Try to migrate old statistics
 	if err := s.migrateOldStats(dataDir); err != nil {
-		log.Printf("Warning: Failed to migrate old statistics: %v", err)
+		logging.Warn("Failed to migrate old statistics", "error", err)
 	}
 
 	// Force an immediate save to ensure everything is written
 	if err := s.save(); err != nil {
-		log.Printf("Warning: Failed to perform initial stats save: %v", err)
+		logging.Warn("Failed to perform initial stats save", "error", err)
 	}
 
 	// Start background writer
@@ -142,6 +177,7 @@ func (s *Storage) migrateOldStats(dataDir string) error {
 	month := getCurrentMonth()
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
+	s.version++
 
 	stats, exists := s.stats[month]
 	if !exists {
@@ -198,11 +234,11 @@ func (s *Storage) migrateOldStats(dataDir string) error {
 // TrackVisitor records a unique visitor
 func (s *Storage) TrackVisitor(ip string) {
 	if s == nil {
-		log.Printf("ERROR: Storage is nil in TrackVisitor")
+		logging.Error("Storage is nil in TrackVisitor")
 		return
 	}
 	if ip == "" {
-		log.Printf("WARNING: Empty IP address in TrackVisitor")
+		logging.Warn("Empty IP address in TrackVisitor")
 		return
 	}
 
@@ -220,10 +256,22 @@ func (s *Storage) TrackVisitor(ip string) {
 		s.mutex.Unlock()
 	}
 
-	// Update visitor under write lock
+	// Update visitor under write lock, counting new ones per country
 	s.mutex.Lock()
+	if _, seen := stats.UniqueVisitors[ip]; !seen {
+		if country := lookupCountry(s.countries, ip); country != "" {
+			if stats.Countries == nil {
+				stats.Countries = make(map[string]int)
+			}
+			stats.Countries[country]++
+		}
+	}
 	stats.UniqueVisitors[ip] = time.Now()
 	stats.LastUpdated = time.Now()
+	daily := dayStats(stats, getCurrentDay())
+	daily.UniqueVisitors[ip] = stats.LastUpdated
+	daily.LastUpdated = stats.LastUpdated
+	s.version++
 	s.mutex.Unlock()
 
 	// Get count under read lock
@@ -231,7 +279,7 @@ func (s *Storage) TrackVisitor(ip string) {
 	visitorCount := len(stats.UniqueVisitors)
 	s.mutex.RUnlock()
 
-	log.Printf("Tracked visitor IP: %s, total unique visitors: %d", ip, visitorCount)
+	logging.Debug("Tracked visitor", "ip", ip, "uniqueVisitors", visitorCount)
 
 	// Check write timing under read lock
 	s.mutex.RLock()
@@ -249,7 +297,7 @@ func (s *Storage) TrackVisitor(ip string) {
 // TrackAnalysis records an analysis request
 func (s *Storage) TrackAnalysis(url string, loadTime float64, isError bool) {
 	if s == nil {
-		log.Printf("ERROR: Storage is nil in TrackAnalysis")
+		logging.Error("Storage is nil in TrackAnalysis")
 		return
 	}
 
@@ -279,10 +327,19 @@ func (s *Storage) TrackAnalysis(url string, loadTime float64, isError bool) {
 		stats.PopularUrls[url]++
 	}
 	stats.LastUpdated = time.Now()
+	daily := dayStats(stats, getCurrentDay())
+	daily.AnalysisRequests++
+	daily.TotalRequests++
+	daily.TotalLoadTime += loadTime
+	if isError {
+		daily.ErrorCount++
+	}
+	daily.LastUpdated = stats.LastUpdated
+	s.version++
 	s.mutex.Unlock()
 
-	log.Printf("Updated stats after analysis for %s: requests=%d, total=%d, errors=%d", 
-		url, stats.AnalysisRequests, stats.TotalRequests, stats.ErrorCount)
+	logging.Debug("Updated stats after analysis", "url", url, "requests", stats.AnalysisRequests,
+		"total", stats.TotalRequests, "errors", stats.ErrorCount)
 
 	// Check write timing under a short lock
 	s.mutex.RLock()
@@ -301,23 +358,24 @@ func (s *Storage) TrackAnalysis(url string, loadTime float64, isError bool) {
 func (s *Storage) load() error {
 	data, err := os.ReadFile(s.filePath)
 	if err != nil {
-		log.Printf("Error reading stats file: %v", err)
+		logging.Debug("Could not read stats file", "error", err)
 		return err
 	}
 
-	log.Printf("Loading stats from file: %s", string(data))
+	logging.Debug("Loading stats from file", "path", s.filePath, "bytes", len(data))
 
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
+	s.version++
 
 	// Create temporary map for loading
 	tempStats := make(map[string]*MonthlyStats)
 	if err := json.Unmarshal(data, &tempStats); err != nil {
-		log.Printf("Error unmarshaling stats: %v", err)
+		logging.Error("Failed to parse stats file", "error", err)
 		return err
 	}
 
-	log.Printf("Loaded stats before initialization: %+v", tempStats)
+	logging.Debug("Loaded stats before initialization", "months", len(tempStats))
 
 	// Ensure all maps are properly initialized
 	for month, stats := range tempStats {
@@ -327,12 +385,23 @@ func (s *Storage) load() error {
 		if stats.PopularUrls == nil {
 			stats.PopularUrls = make(map[string]int)
 		}
+		if stats.Daily == nil {
+			stats.Daily = make(map[string]*DailyStats)
+		}
+		// Older versions also counted the analyze endpoint as an analysis
+		if count, ok := stats.PopularUrls[legacyAnalyzeEndpoint]; ok {
+			delete(stats.PopularUrls, legacyAnalyzeEndpoint)
+			stats.TotalRequests -= count
+			if stats.TotalRequests < 0 {
+				stats.TotalRequests = 0
+			}
+		}
 
-		log.Printf("Processing month %s, stats before merge: %+v", month, stats)
+		logging.Debug("Processing loaded month", "month", month)
 
 		// Preserve any existing data by merging
 		if existingStats, exists := s.stats[month]; exists {
-			log.Printf("Found existing stats for month %s: %+v", month, existingStats)
+			logging.Debug("Merging with existing month stats", "month", month)
 			
 			// Merge unique visitors
 			for ip, timestamp := range existingStats.UniqueVisitors {
@@ -344,6 +413,19 @@ func (s *Storage) load() error {
 			for url, count := range existingStats.PopularUrls {
 				stats.PopularUrls[url] += count
 			}
+			// Merge visitor countries
+			for country, count := range existingStats.Countries {
+				if stats.Countries == nil {
+					stats.Countries = make(map[string]int)
+				}
+				stats.Countries[country] += count
+			}
+			// Merge days not present on disk
+			for day, daily := range existingStats.Daily {
+				if _, ok := stats.Daily[day]; !ok {
+					stats.Daily[day] = daily
+				}
+			}
 			// Add counters
 			stats.AnalysisRequests += existingStats.AnalysisRequests
 			stats.ErrorCount += existingStats.ErrorCount
@@ -359,18 +441,30 @@ func (s *Storage) load() error {
 				stats.LastUpdated = existingStats.LastUpdated
 			}
 
-			log.Printf("Stats after merge for month %s: %+v", month, stats)
+			logging.Debug("Merged month stats", "month", month, "requests", stats.AnalysisRequests)
 		}
 	}
 
 	// Replace the storage's stats with the merged data
 	s.stats = tempStats
-	log.Printf("Final loaded stats: %+v", s.stats)
+	logging.Debug("Loaded stats", "months", len(s.stats))
 	return nil
 }
 
 // save writes statistics to file
 func (s *Storage) save() error {
+	// Move months outside the hot window to their archive files first, so
+	// they are never lost between the two writes
+	s.mutex.Lock()
+	s.archiveMu.Lock()
+	err := s.archiveOldMonths()
+	s.archiveMu.Unlock()
+	s.mutex.Unlock()
+	if err != nil {
+		logging.Error("Failed to archive stats", "error", err)
+		return err
+	}
+
 	// Create a copy of stats under read lock
 	s.mutex.RLock()
 	statsCopy := make(map[string]*MonthlyStats)
@@ -387,6 +481,7 @@ func (s *Storage) save() error {
 			LastUpdated:         stats.LastUpdated,
 			UniqueVisitors:      make(map[string]time.Time),
 			PopularUrls:         make(map[string]int),
+			Daily:               copyDaily(stats.Daily),
 		}
 		for k, v := range stats.UniqueVisitors {
 			statsCopy[month].UniqueVisitors[k] = v
@@ -394,31 +489,32 @@ func (s *Storage) save() error {
 		for k, v := range stats.PopularUrls {
 			statsCopy[month].PopularUrls[k] = v
 		}
+		statsCopy[month].Countries = copyCounts(stats.Countries)
 	}
 	s.mutex.RUnlock()
 
 	// Marshal the copy
 	data, err := json.Marshal(statsCopy)
 	if err != nil {
-		log.Printf("Error marshaling stats: %v", err)
+		logging.Error("Failed to marshal stats", "error", err)
 		return fmt.Errorf("failed to marshal stats: %w", err)
 	}
 
 	// Write to temporary file first
 	tempFile := s.filePath + ".tmp"
 	if err := os.WriteFile(tempFile, data, 0644); err != nil {
-		log.Printf("Error writing temp file: %v", err)
+		logging.Error("Failed to write temporary stats file", "error", err)
 		return fmt.Errorf("failed to write temporary file: %w", err)
 	}
 
 	// Rename temporary file to actual file (atomic operation)
 	if err := os.Rename(tempFile, s.filePath); err != nil {
 		os.Remove(tempFile) // Clean up temp file if rename fails
-		log.Printf("Error renaming temp file: %v", err)
+		logging.Error("Failed to rename temporary stats file", "error", err)
 		return fmt.Errorf("failed to rename temporary file: %w", err)
 	}
 
-	log.Printf("Successfully saved stats to %s", s.filePath)
+	logging.Debug("Saved stats", "path", s.filePath)
 	return nil
 }
 
@@ -426,24 +522,25 @@ func (s *Storage) save() error {
 func (s *Storage) backgroundWriter() {
 	ticker := time.NewTicker(1 * time.Minute)
 	defer ticker.Stop()
+	defer close(s.stopped)
 
 	for {
 		select {
 		case <-s.writeBuffer:
 			// Immediate write requested
 			if err := s.save(); err != nil {
-				log.Printf("Error during immediate stats write: %v", err)
+				logging.Error("Immediate stats write failed", "error", err)
 			}
 		case <-ticker.C:
 			// Periodic write
 			if err := s.save(); err != nil {
-				log.Printf("Error during periodic stats write: %v", err)
+				logging.Error("Periodic stats write failed", "error", err)
 			}
 		case <-s.done:
 			// Final write before shutdown
-			log.Printf("Performing final stats write before shutdown")
+			logging.Debug("Performing final stats write before shutdown")
 			if err := s.save(); err != nil {
-				log.Printf("Error during final stats write: %v", err)
+				logging.Error("Final stats write failed", "error", err)
 			}
 			return
 		}
@@ -459,15 +556,15 @@ func getCurrentMonth() string {
 func (s *Storage) requestWrite() {
 	// Try to write immediately first
 	if err := s.save(); err != nil {
-		log.Printf("Error during direct stats write: %v", err)
+		logging.Error("Direct stats write failed", "error", err)
 		// Fall back to buffered write if immediate write fails
 		select {
 		case s.writeBuffer <- struct{}{}:
-			log.Printf("Queued stats write after failed direct write")
+			logging.Debug("Queued stats write after failed direct write")
 		default:
 			// Try an immediate write again if buffer is full
 			if err := s.save(); err != nil {
-				log.Printf("Error during retry stats write: %v", err)
+				logging.Error("Retried stats write failed", "error", err)
 			}
 		}
 	}
@@ -476,7 +573,7 @@ func (s *Storage) requestWrite() {
 // IncrementStats increments the specified statistics
 func (s *Storage) IncrementStats(analysisHits, analysisMisses, linkHits, linkMisses int) {
 	if s == nil {
-		log.Printf("ERROR: Storage is nil in IncrementStats")
+		logging.Error("Storage is nil in IncrementStats")
 		return
 	}
 
@@ -501,11 +598,18 @@ func (s *Storage) IncrementStats(analysisHits, analysisMisses, linkHits, linkMis
 	stats.LinkCacheHits += linkHits
 	stats.LinkCacheMisses += linkMisses
 	stats.LastUpdated = time.Now()
+	daily := dayStats(stats, getCurrentDay())
+	daily.AnalysisCacheHits += analysisHits
+	daily.AnalysisCacheMisses += analysisMisses
+	daily.LinkCacheHits += linkHits
+	daily.LinkCacheMisses += linkMisses
+	daily.LastUpdated = stats.LastUpdated
+	s.version++
 	s.mutex.Unlock()
 
-	log.Printf("Updated cache stats: hits=%d/%d, misses=%d/%d", 
-		stats.AnalysisCacheHits, stats.LinkCacheHits,
-		stats.AnalysisCacheMisses, stats.LinkCacheMisses)
+	logging.Debug("Updated cache stats",
+		"analysisHits", stats.AnalysisCacheHits, "linkHits", stats.LinkCacheHits,
+		"analysisMisses", stats.AnalysisCacheMisses, "linkMisses", stats.LinkCacheMisses)
 
 	// Check write timing under read lock
 	s.mutex.RLock()
@@ -520,25 +624,46 @@ func (s *Storage) IncrementStats(analysisHits, analysisMisses, linkHits, linkMis
 	}
 }
 
-// GetCurrentStats returns statistics for the current month
+// SetSnapshotTTL sets how long GetCurrentStats may reuse a memoized copy
+// while no writes occur; zero disables memoization
+func (s *Storage) SetSnapshotTTL(ttl time.Duration) {
+	s.snapshotMu.Lock()
+	defer s.snapshotMu.Unlock()
+	s.snapshotTTL = ttl
+	s.snapshot = nil
+}
+
+// GetCurrentStats returns statistics for the current month. Repeated reads
+// with no intervening writes may share maps, so callers must not modify them.
 func (s *Storage) GetCurrentStats() MonthlyStats {
 	if s == nil {
-		log.Printf("ERROR: Storage is nil in GetCurrentStats")
+		logging.Error("Storage is nil in GetCurrentStats")
 		return *NewMonthlyStats()
 	}
 
 	month := getCurrentMonth()
-	
+
+	// Reuse a recent snapshot if nothing has been written since it was taken
 	s.mutex.RLock()
-	stats, exists := s.stats[month]
+	version := s.version
 	s.mutex.RUnlock()
 
-	if !exists {
-		return *NewMonthlyStats()
+	s.snapshotMu.Lock()
+	if snap := s.snapshot; snap != nil && snap.month == month && snap.version == version &&
+		time.Since(snap.taken) < s.snapshotTTL {
+		s.snapshotMu.Unlock()
+		return snap.stats
 	}
+	s.snapshotMu.Unlock()
 
-	// Make a copy under a short-lived read lock
+	// Copy under a single read lock so the snapshot matches its version
 	s.mutex.RLock()
+	stats, exists := s.stats[month]
+	if !exists {
+		s.mutex.RUnlock()
+		return *NewMonthlyStats()
+	}
+	version = s.version
 	statsCopy := MonthlyStats{
 		AnalysisCacheHits:   stats.AnalysisCacheHits,
 		AnalysisCacheMisses: stats.AnalysisCacheMisses,
@@ -552,26 +677,38 @@ func (s *Storage) GetCurrentStats() MonthlyStats {
 		UniqueVisitors:      make(map[string]time.Time, len(stats.UniqueVisitors)),
 		PopularUrls:         make(map[string]int, len(stats.PopularUrls)),
 	}
-	s.mutex.RUnlock()
-
-	// Copy maps under separate short-lived locks to minimize contention
-	s.mutex.RLock()
 	for k, v := range stats.UniqueVisitors {
 		statsCopy.UniqueVisitors[k] = v
 	}
-	s.mutex.RUnlock()
-
-	s.mutex.RLock()
 	for k, v := range stats.PopularUrls {
 		statsCopy.PopularUrls[k] = v
 	}
+	statsCopy.Countries = copyCounts(stats.Countries)
 	s.mutex.RUnlock()
 
+	s.snapshotMu.Lock()
+	if s.snapshotTTL > 0 {
+		s.snapshot = &statsSnapshot{month: month, version: version, taken: time.Now(), stats: statsCopy}
+	}
+	s.snapshotMu.Unlock()
+
 	return statsCopy
 }
 
 // Cleanup removes statistics older than the specified number of months
 func (s *Storage) Cleanup(retainMonths int) {
+	// Per-day buckets have their own, shorter retention window
+	s.mutex.Lock()
+	s.pruneDaily()
+	archiving := s.hotMonths > 0
+	s.mutex.Unlock()
+
+	// With archival enabled old months are archived, never deleted
+	if archiving {
+		s.requestWrite()
+		return
+	}
+
 	currentTime := time.Now()
 	currentMonth := currentTime.Format("2006-01")
 	
@@ -579,28 +716,100 @@ func (s *Storage) Cleanup(retainMonths int) {
 	previousMonth := currentTime.AddDate(0, -1, 0).Format("2006-01")
 
 	s.mutex.Lock()
-	defer s.mutex.Unlock()
-
 	// Only keep current and previous month
 	for key := range s.stats {
 		if key != currentMonth && key != previousMonth {
 			delete(s.stats, key)
 		}
 	}
+	s.version++
+	s.mutex.Unlock()
 
-	// Request a write to persist changes
+	// Request a write to persist changes (save takes its own read lock)
 	s.requestWrite()
 	
 	// Log retained months for debugging
-	log.Printf("Retained statistics for months: %s, %s", currentMonth, previousMonth)
+	logging.Info("Cleaned up statistics", "retainedMonths", []string{currentMonth, previousMonth})
+}
+
+// Reset discards the statistics of a YYYY-MM month, archived or not, or of
+// every month when month is empty, and persists the result right away. The
+// current month starts again from zero.
+func (s *Storage) Reset(month string) error {
+	if err := checkMonth(month); err != nil {
+		return err
+	}
+
+	s.mutex.Lock()
+	s.archiveMu.Lock()
+	months := []string{month}
+	if month == "" {
+		months = s.archivedMonths()
+		s.stats = make(map[string]*MonthlyStats)
+		s.archived = make(map[string]*MonthlyStats)
+	} else {
+		delete(s.stats, month)
+		delete(s.archived, month)
+	}
+	for _, m := range months {
+		if err := os.Remove(s.archivePath(m)); err != nil && !os.IsNotExist(err) {
+			logging.Error("Failed to remove archived stats", "month", m, "error", err)
+		}
+	}
+	if _, ok := s.stats[getCurrentMonth()]; !ok {
+		s.stats[getCurrentMonth()] = NewMonthlyStats()
+	}
+	s.version++
+	s.archiveMu.Unlock()
+	s.mutex.Unlock()
+
+	logging.Warn("Reset statistics", "month", month)
+	return s.save()
+}
+
+// GetTopURLs returns the n URLs analyzed most often this month, most
+// analyzed first
+func (s *Storage) GetTopURLs(n int) []URLCount {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	stats, exists := s.stats[getCurrentMonth()]
+	if !exists {
+		return []URLCount{}
+	}
+	return topURLs(stats.PopularUrls, n)
+}
+
+// SetCountryLookup enables counting new visitors per country; nil disables it
+func (s *Storage) SetCountryLookup(lookup CountryLookup) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.countries = lookup
+}
+
+// copyCounts returns a copy of counts, or nil when it is empty
+func copyCounts(counts map[string]int) map[string]int {
+	if len(counts) == 0 {
+		return nil
+	}
+	copied := make(map[string]int, len(counts))
+	for k, v := range counts {
+		copied[k] = v
+	}
+	return copied
 }
 
 // GetMonthlyStats returns statistics for a specific month
 func (s *Storage) GetMonthlyStats(yearMonth string) (MonthlyStats, bool) {
 	s.mutex.RLock()
-	defer s.mutex.RUnlock()
+	stats, exists := s.stats[yearMonth]
+	if exists {
+		defer s.mutex.RUnlock()
+		return *stats, true
+	}
+	s.mutex.RUnlock()
 
-	if stats, exists := s.stats[yearMonth]; exists {
+	// Older months may have been archived to their own file
+	if stats, ok := s.loadArchivedMonth(yearMonth); ok {
 		return *stats, true
 	}
 	return MonthlyStats{}, false
@@ -615,6 +824,11 @@ func (s *Storage) GetAllMonths() []string {
 	for month := range s.stats {
 		months = append(months, month)
 	}
+	for _, month := range s.archivedMonths() {
+		if _, hot := s.stats[month]; !hot {
+			months = append(months, month)
+		}
+	}
 	
 	// Sort months in descending order (newest first)
 	sort.Sort(sort.Reverse(sort.StringSlice(months)))
@@ -628,16 +842,17 @@ func (s *Storage) Shutdown() error {
 		return nil
 	}
 
-	log.Printf("Shutting down statistics storage")
+	logging.Info("Shutting down statistics storage")
 	
-	// Signal the background writer to stop and perform final write
+	// Signal the background writer to stop and wait for its final write
 	close(s.done)
+	<-s.stopped
 
 	// Perform one final write directly
 	if err := s.save(); err != nil {
 		return fmt.Errorf("failed to save stats during shutdown: %w", err)
 	}
 
-	log.Printf("Statistics storage shutdown complete")
+	logging.Info("Statistics storage shutdown complete")
 	return nil
 } 
\ No newline at end of file
@@ -1,6 +1,10 @@
 package stats
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
 	"os"
 	"path/filepath"
 	"testing"
@@ -96,6 +100,10 @@ func TestStorage(t *testing.T) {
 
 	// Test concurrent access
 	t.Run("ConcurrentAccess", func(t *testing.T) {
+		// Earlier subtests share this storage, so count relative to its current state
+		initial := storage.GetCurrentStats()
+		initialHits := initial.AnalysisCacheHits + initial.LinkCacheHits
+
 		done := make(chan bool)
 		for i := 0; i < 10; i++ {
 			go func() {
@@ -115,9 +123,362 @@ func TestStorage(t *testing.T) {
 		// Verify final counts
 		stats := storage.GetCurrentStats()
 		expectedCount := 1000 // 10 goroutines * 100 iterations
-		totalHits := stats.AnalysisCacheHits + stats.LinkCacheHits
+		totalHits := stats.AnalysisCacheHits + stats.LinkCacheHits - initialHits
 		if totalHits != expectedCount*2 {
 			t.Errorf("Expected %d total hits, got %d", expectedCount*2, totalHits)
 		}
 	})
-} 
\ No newline at end of file
+} 
+
+func TestStorageArchivesOldMonths(t *testing.T) {
+	tempDir := t.TempDir()
+	oldMonth := time.Now().AddDate(0, -3, 0).Format("2006-01")
+
+	// Existing single-file history is migrated on first run
+	storage, err := NewStorage(tempDir)
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	storage.mutex.Lock()
+	storage.stats[oldMonth] = &MonthlyStats{AnalysisRequests: 42, LastUpdated: time.Now().AddDate(0, -3, 0)}
+	storage.mutex.Unlock()
+	storage.IncrementStats(1, 0, 0, 0)
+	if err := storage.Shutdown(); err != nil {
+		t.Fatalf("Failed to shut down storage: %v", err)
+	}
+
+	storage, err = NewStorage(tempDir)
+	if err != nil {
+		t.Fatalf("Failed to reopen storage: %v", err)
+	}
+	if err := storage.SetHotMonths(2); err != nil {
+		t.Fatalf("Failed to enable archival: %v", err)
+	}
+
+	hot, err := os.ReadFile(filepath.Join(tempDir, "stats.json"))
+	if err != nil {
+		t.Fatalf("Failed to read hot file: %v", err)
+	}
+	var hotStats map[string]*MonthlyStats
+	if err := json.Unmarshal(hot, &hotStats); err != nil {
+		t.Fatalf("Failed to parse hot file: %v", err)
+	}
+	if _, ok := hotStats[oldMonth]; ok {
+		t.Errorf("Expected %s to be archived out of stats.json", oldMonth)
+	}
+	if _, ok := hotStats[time.Now().Format("2006-01")]; !ok {
+		t.Error("Expected the current month to stay in stats.json")
+	}
+	if _, err := os.Stat(filepath.Join(tempDir, "stats-"+oldMonth+".json")); err != nil {
+		t.Errorf("Expected an archive file for %s: %v", oldMonth, err)
+	}
+
+	// Archived months remain queryable, loaded from their own file
+	storage, err = reopenStorage(t, storage, tempDir, 2)
+	if err != nil {
+		t.Fatalf("Failed to reopen storage: %v", err)
+	}
+	stats, ok := storage.GetMonthlyStats(oldMonth)
+	if !ok || stats.AnalysisRequests != 42 {
+		t.Errorf("Expected archived month with 42 requests, got %v (found %v)", stats.AnalysisRequests, ok)
+	}
+	months := storage.GetAllMonths()
+	found := false
+	for _, month := range months {
+		found = found || month == oldMonth
+	}
+	if !found {
+		t.Errorf("Expected GetAllMonths to include %s, got %v", oldMonth, months)
+	}
+
+	// Cleanup archives rather than deletes
+	storage.Cleanup(1)
+	if _, ok := storage.GetMonthlyStats(oldMonth); !ok {
+		t.Error("Expected Cleanup to keep archived months")
+	}
+}
+
+func TestStorageDailyStats(t *testing.T) {
+	dataDir := t.TempDir()
+	storage, err := NewStorage(dataDir)
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+
+	today := time.Now().Format("2006-01-02")
+	storage.TrackVisitor("192.0.2.1")
+	storage.TrackAnalysis("https://example.com", 100, false)
+	storage.TrackAnalysis("https://example.com", 50, true)
+	storage.IncrementStats(1, 0, 0, 2)
+
+	daily, ok := storage.GetDailyStats(today)
+	if !ok {
+		t.Fatalf("Expected stats for %s", today)
+	}
+	if daily.AnalysisRequests != 2 || daily.ErrorCount != 1 || daily.TotalLoadTime != 150 {
+		t.Errorf("Unexpected daily counters: %+v", daily)
+	}
+	if daily.AnalysisCacheHits != 1 || daily.LinkCacheMisses != 2 || len(daily.UniqueVisitors) != 1 {
+		t.Errorf("Unexpected daily cache or visitor counts: %+v", daily)
+	}
+	if _, ok := storage.GetDailyStats("not-a-date"); ok {
+		t.Error("Expected an invalid date to have no stats")
+	}
+
+	// Days survive a restart, nested under their month in stats.json
+	if err := storage.Shutdown(); err != nil {
+		t.Fatalf("Failed to shut down storage: %v", err)
+	}
+	storage, err = NewStorage(dataDir)
+	if err != nil {
+		t.Fatalf("Failed to reopen storage: %v", err)
+	}
+	defer storage.Shutdown()
+	if daily, ok := storage.GetDailyStats(today); !ok || daily.AnalysisRequests != 2 {
+		t.Errorf("Expected 2 requests for %s after reload, got %+v (found %v)", today, daily, ok)
+	}
+
+	// Cleanup prunes days outside the retention window but keeps the month
+	old := time.Now().AddDate(0, 0, -5)
+	oldMonth := old.Format("2006-01")
+	storage.mutex.Lock()
+	if _, ok := storage.stats[oldMonth]; !ok {
+		storage.stats[oldMonth] = NewMonthlyStats()
+	}
+	dayStats(storage.stats[oldMonth], old.Format("2006-01-02")).AnalysisRequests = 7
+	storage.mutex.Unlock()
+
+	days := storage.GetDaysInMonth(oldMonth)
+	if len(days) == 0 || days[0] != old.Format("2006-01-02") {
+		t.Errorf("Expected %s first in GetDaysInMonth, got %v", old.Format("2006-01-02"), days)
+	}
+
+	if err := storage.SetDailyRetention(3); err != nil {
+		t.Fatalf("Failed to set daily retention: %v", err)
+	}
+	storage.Cleanup(1)
+	if _, ok := storage.GetDailyStats(old.Format("2006-01-02")); ok {
+		t.Error("Expected Cleanup to prune days outside the retention window")
+	}
+	if _, ok := storage.GetDailyStats(today); !ok {
+		t.Error("Expected Cleanup to keep today's stats")
+	}
+}
+
+// reopenStorage shuts storage down and opens the data dir again with
+// archival enabled
+func reopenStorage(t *testing.T, storage *Storage, dataDir string, hotMonths int) (*Storage, error) {
+	t.Helper()
+	if err := storage.Shutdown(); err != nil {
+		return nil, err
+	}
+	reopened, err := NewStorage(dataDir)
+	if err != nil {
+		return nil, err
+	}
+	t.Cleanup(func() { reopened.Shutdown() })
+	return reopened, reopened.SetHotMonths(hotMonths)
+}
+
+func TestGetCurrentStatsSnapshotFreshAfterWrite(t *testing.T) {
+	storage, err := NewStorage(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	defer storage.Shutdown()
+	storage.SetSnapshotTTL(time.Minute)
+
+	storage.TrackAnalysis("https://example.com", 100, false)
+	first := storage.GetCurrentStats()
+	if first.AnalysisRequests != 1 {
+		t.Fatalf("Expected 1 analysis request, got %d", first.AnalysisRequests)
+	}
+
+	// A read right after a write must not return the memoized snapshot
+	storage.TrackAnalysis("https://example.com", 100, false)
+	second := storage.GetCurrentStats()
+	if second.AnalysisRequests != 2 {
+		t.Errorf("Expected 2 analysis requests after write, got %d", second.AnalysisRequests)
+	}
+	if second.PopularUrls["https://example.com"] != 2 {
+		t.Errorf("Expected popular URL count 2, got %d", second.PopularUrls["https://example.com"])
+	}
+}
+
+// BenchmarkGetCurrentStats compares frequent reads of a month with large maps
+// with and without the memoized snapshot
+func BenchmarkGetCurrentStats(b *testing.B) {
+	for _, tc := range []struct {
+		name string
+		ttl  time.Duration
+	}{
+		{"NoSnapshot", 0},
+		{"Snapshot", time.Minute},
+	} {
+		b.Run(tc.name, func(b *testing.B) {
+			storage, err := NewStorage(b.TempDir())
+			if err != nil {
+				b.Fatalf("Failed to create storage: %v", err)
+			}
+			defer storage.Shutdown()
+			storage.SetSnapshotTTL(tc.ttl)
+
+			stats := storage.stats[getCurrentMonth()]
+			for i := 0; i < 10000; i++ {
+				stats.UniqueVisitors[fmt.Sprintf("10.0.%d.%d", i/256, i%256)] = time.Now()
+				stats.PopularUrls[fmt.Sprintf("https://example.com/page/%d", i)] = i
+			}
+
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				storage.GetCurrentStats()
+			}
+		})
+	}
+}
+
+func TestStorageReset(t *testing.T) {
+	tempDir := t.TempDir()
+	current := getCurrentMonth()
+	previous := time.Now().AddDate(0, -1, 0).Format("2006-01")
+	archived := time.Now().AddDate(0, -3, 0).Format("2006-01")
+
+	storage, err := NewStorage(tempDir)
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	storage.mutex.Lock()
+	storage.stats[previous] = &MonthlyStats{AnalysisRequests: 7, LastUpdated: time.Now()}
+	storage.stats[archived] = &MonthlyStats{AnalysisRequests: 42, LastUpdated: time.Now()}
+	storage.mutex.Unlock()
+	storage.TrackAnalysis("https://example.com", 100, false)
+	if err := storage.SetHotMonths(2); err != nil {
+		t.Fatalf("Failed to enable archival: %v", err)
+	}
+	if _, err := os.Stat(storage.archivePath(archived)); err != nil {
+		t.Fatalf("Expected %s to be archived: %v", archived, err)
+	}
+
+	if err := storage.Reset("2024-13"); !errors.Is(err, ErrInvalidMonth) {
+		t.Errorf("Expected an invalid month to be rejected, got %v", err)
+	}
+
+	// Resetting one month leaves the others alone
+	if err := storage.Reset(previous); err != nil {
+		t.Fatalf("Failed to reset %s: %v", previous, err)
+	}
+	if _, ok := storage.GetMonthlyStats(previous); ok {
+		t.Errorf("Expected %s to be gone", previous)
+	}
+	if stats := storage.GetCurrentStats(); stats.AnalysisRequests != 1 {
+		t.Errorf("Expected the current month to be kept, got %d requests", stats.AnalysisRequests)
+	}
+
+	// Resetting everything removes archives too and survives a restart
+	if err := storage.Reset(""); err != nil {
+		t.Fatalf("Failed to reset all statistics: %v", err)
+	}
+	if _, err := os.Stat(storage.archivePath(archived)); !os.IsNotExist(err) {
+		t.Errorf("Expected the archive of %s to be removed: %v", archived, err)
+	}
+	if storage, err = reopenStorage(t, storage, tempDir, 2); err != nil {
+		t.Fatalf("Failed to reopen storage: %v", err)
+	}
+	if months := storage.GetAllMonths(); len(months) != 1 || months[0] != current {
+		t.Errorf("Expected only an empty current month, got %v", months)
+	}
+	if stats := storage.GetCurrentStats(); stats.AnalysisRequests != 0 || len(stats.PopularUrls) != 0 {
+		t.Errorf("Expected empty current stats, got %+v", stats)
+	}
+}
+
+// fakeCountries is a CountryLookup keyed by IP address
+type fakeCountries map[string]string
+
+func (f fakeCountries) Country(ip net.IP) string { return f[ip.String()] }
+
+var testCountries = fakeCountries{"192.0.2.1": "US", "192.0.2.2": "US", "198.51.100.1": "DE"}
+
+func TestStorageCountries(t *testing.T) {
+	tempDir := t.TempDir()
+	storage, err := NewStorage(tempDir)
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+
+	// Without GeoIP no countries are recorded
+	storage.TrackVisitor("203.0.113.1")
+	if stats := storage.GetCurrentStats(); stats.Countries != nil {
+		t.Errorf("Expected no countries without a lookup, got %v", stats.Countries)
+	}
+
+	storage.SetCountryLookup(testCountries)
+	for _, ip := range []string{"192.0.2.1", "192.0.2.1", "192.0.2.2", "198.51.100.1", "203.0.113.9"} {
+		storage.TrackVisitor(ip)
+	}
+	want := map[string]int{"US": 2, "DE": 1}
+	if stats := storage.GetCurrentStats(); fmt.Sprint(stats.Countries) != fmt.Sprint(want) {
+		t.Errorf("Expected countries %v, got %v", want, stats.Countries)
+	}
+
+	if storage, err = reopenStorage(t, storage, tempDir, 0); err != nil {
+		t.Fatalf("Failed to reopen storage: %v", err)
+	}
+	if stats := storage.GetCurrentStats(); fmt.Sprint(stats.Countries) != fmt.Sprint(want) {
+		t.Errorf("Expected countries %v after a restart, got %v", want, stats.Countries)
+	}
+}
+
+func TestStorageDropsLegacyEndpointCounts(t *testing.T) {
+	tempDir := t.TempDir()
+	month := getCurrentMonth()
+	legacy := map[string]*MonthlyStats{month: {
+		AnalysisRequests: 5,
+		TotalRequests:    5,
+		PopularUrls:      map[string]int{"/api/analyze": 2, "https://example.com": 3},
+	}}
+	data, _ := json.Marshal(legacy)
+	if err := os.WriteFile(filepath.Join(tempDir, "stats.json"), data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	storage, err := NewStorage(tempDir)
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	defer storage.Shutdown()
+	stats := storage.GetCurrentStats()
+	if _, ok := stats.PopularUrls["/api/analyze"]; ok || stats.PopularUrls["https://example.com"] != 3 {
+		t.Errorf("Expected only the analyzed page in popular URLs, got %v", stats.PopularUrls)
+	}
+	if stats.TotalRequests != 3 {
+		t.Errorf("Expected the endpoint's requests to be dropped, got %d", stats.TotalRequests)
+	}
+}
+
+func TestStorageGetTopURLs(t *testing.T) {
+	storage, err := NewStorage(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	defer storage.Shutdown()
+
+	if top := storage.GetTopURLs(3); len(top) != 0 {
+		t.Errorf("Expected no URLs yet, got %v", top)
+	}
+	for i := 1; i <= 6; i++ {
+		for j := 0; j < i%4; j++ {
+			storage.TrackAnalysis(fmt.Sprintf("https://example.com/%d", i), 10, false)
+		}
+	}
+
+	// Counts are 1, 2, 3, 0, 1, 2; ties are in URL order
+	want := []URLCount{{"https://example.com/3", 3}, {"https://example.com/2", 2}, {"https://example.com/6", 2}}
+	if top := storage.GetTopURLs(3); fmt.Sprint(top) != fmt.Sprint(want) {
+		t.Errorf("Expected %v, got %v", want, top)
+	}
+	if top := storage.GetTopURLs(10); len(top) != 5 {
+		t.Errorf("Expected all 5 URLs, got %v", top)
+	}
+}